@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/amangirdhar210/inventory-manager/config"
+	"github.com/amangirdhar210/inventory-manager/internal/adapters/repository"
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+	"github.com/amangirdhar210/inventory-manager/internal/core/service"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const usage = `usage: inventory-admin [-config path] <command> [flags]
+
+commands:
+  create -email <email> -password <password> [-role admin|manager|clerk|read_only]
+  list
+  disable -email <email>
+  enable -email <email>
+  reset-password -email <email> -password <password>
+  delete -email <email>
+
+New and reset passwords must be changed by the manager on their next login.`
+
+func main() {
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	configPath := flag.String("config", os.Getenv("INVENTORY_CONFIG"), "path to a JSON config file")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := openDatabase(cfg.Database.Path)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSQLiteRepository(db)
+	if err := run(repo, service.NewManagerService(repo, repo, repo, cfg.Auth.TokenTTL.Duration), flag.Arg(0), flag.Args()[1:]); err != nil {
+		log.Fatalf("%s failed: %v", flag.Arg(0), err)
+	}
+}
+
+func openDatabase(path string) (*sql.DB, error) {
+	db, err := repository.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := repository.NewMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := migrator.Up(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func run(repo ports.ManagerRepository, managers service.ManagerService, command string, args []string) error {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	email := flags.String("email", "", "manager email")
+	password := flags.String("password", "", "password, at least "+strconv.Itoa(domain.MinPasswordLength)+" characters")
+	role := flags.String("role", string(domain.RoleAdmin), "role for a new manager")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch command {
+	case "list":
+		return listManagers(managers)
+	case "create", "disable", "enable", "reset-password", "delete":
+	default:
+		return fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	if command == "create" {
+		manager, err := managers.CreateManager(*email, *password, domain.Role(*role))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s manager %s (%s).\n", manager.Role, manager.Email, manager.Id)
+		return nil
+	}
+
+	manager, err := repo.FindByEmail(*email)
+	if err != nil {
+		return fmt.Errorf("%w: no manager with email %s", domain.ErrManagerNotFound, *email)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "disable", "enable":
+		if _, err := managers.SetManagerDisabled(ctx, manager.Id, command == "disable"); err != nil {
+			return err
+		}
+	case "reset-password":
+		if _, err := managers.ResetPassword(manager.Id, *password); err != nil {
+			return err
+		}
+	case "delete":
+		if err := managers.DeleteManager(ctx, manager.Id); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%s: done for %s.\n", command, manager.Email)
+	return nil
+}
+
+func listManagers(managers service.ManagerService) error {
+	list, err := managers.ListManagers()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tROLE\tDISABLED\tMUST CHANGE PASSWORD")
+	for _, manager := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%t\n", manager.Id, manager.Email, manager.Role, manager.Disabled, manager.MustChangePassword)
+	}
+	return w.Flush()
+}
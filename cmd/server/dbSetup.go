@@ -1,79 +1,165 @@
 package main
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/base64"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/amangirdhar210/inventory-manager/internal/adapters/repository"
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
-	"github.com/google/uuid"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
 )
 
-func SetupDatabase(dbName string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", dbName)
+const (
+	defaultAdminEmail   = "admin@example.com"
+	legacyAdminPassword = "password123"
+	adminPasswordFile   = "admin-password.txt"
+)
+
+func OpenDatabase(dbName string) (*sql.DB, error) {
+	db, err := repository.Open(dbName)
 	if err != nil {
 		return nil, err
 	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
 
-	createProductsTableSQL := `
-    CREATE TABLE IF NOT EXISTS products(
-        "id" TEXT NOT NULL PRIMARY KEY,
-        "name" TEXT,
-        "price" REAL,
-        "quantity" INTEGER
-    );`
-	if _, err := db.Exec(createProductsTableSQL); err != nil {
+func SetupDatabase(dbName string) (*sql.DB, error) {
+	db, err := OpenDatabase(dbName)
+	if err != nil {
 		return nil, err
 	}
 
-	createManagersTableSQL := `
-    CREATE TABLE IF NOT EXISTS managers(
-        "id" TEXT NOT NULL PRIMARY KEY,
-        "email" TEXT UNIQUE,
-        "password" TEXT
-    );`
-	if _, err := db.Exec(createManagersTableSQL); err != nil {
+	migrator, err := repository.NewMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	applied, err := migrator.Up()
+	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	seedAdmin(db)
+	seedAdmin(db, filepath.Join(filepath.Dir(dbName), adminPasswordFile))
 
-	log.Println("Database Initialized and Tables created successfully.")
+	log.Printf("Database Initialized, %d migration(s) applied.", applied)
 	return db, nil
 }
 
-func seedAdmin(db *sql.DB) {
-	var count int
-	row := db.QueryRow("SELECT COUNT(*) FROM managers WHERE email = ?", "admin@example.com")
-	if err := row.Scan(&count); err != nil {
-		log.Printf("Could not check for admin user: %v", err)
+// seedAdmin bootstraps an empty database with an admin whose one-time password
+// must be changed on first login. The password is written to passwordPath, readable
+// by the owner only, and never logged. Use inventory-admin to manage accounts after that.
+func seedAdmin(db *sql.DB, passwordPath string) {
+	repo := repository.NewSQLiteRepository(db)
+	managers, err := repo.ListManagers()
+	if err != nil {
+		log.Printf("Could not check for managers: %v", err)
 		return
 	}
 
-	if count == 0 {
-		admin := &domain.Manager{
-			Id:       uuid.NewString(),
-			Email:    "admin@example.com",
-			Password: "password123",
-		}
-		if err := admin.HashPassword(); err != nil {
-			log.Printf("Could not hash admin password: %v", err)
-			return
-		}
+	if len(managers) > 0 {
+		replaceLegacyAdminPassword(repo, repo, passwordPath)
+		return
+	}
 
-		stmt, err := db.Prepare("INSERT INTO managers(id, email, password) VALUES(?,?,?)")
-		if err != nil {
-			log.Printf("Could not prepare admin insert statement: %v", err)
-			return
-		}
-		defer stmt.Close()
+	password, err := generatePassword()
+	if err != nil {
+		log.Printf("Could not generate admin password: %v", err)
+		return
+	}
+	admin, err := domain.NewManager(defaultAdminEmail, password, domain.RoleAdmin)
+	if err != nil {
+		log.Printf("Could not create admin user: %v", err)
+		return
+	}
+	if err := writePasswordFile(passwordPath, admin.Email, password); err != nil {
+		log.Printf("Could not write the admin password, create an admin with inventory-admin instead: %v", err)
+		return
+	}
+	if err := repo.SaveManager(admin); err != nil {
+		os.Remove(passwordPath)
+		log.Printf("Could not seed admin user: %v", err)
+		return
+	}
+	log.Printf("Admin user %s seeded, its one-time password is in %s.", admin.Email, passwordPath)
+	log.Println("The password must be changed on first login via POST /login/password, then delete the file.")
+}
 
-		_, err = stmt.Exec(admin.Id, admin.Email, admin.Password)
-		if err != nil {
-			log.Printf("Could not seed admin user: %v", err)
+// writePasswordFile refuses to overwrite an existing file, so a stale password
+// is never mistaken for the current one.
+func writePasswordFile(path, email, password string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(file, "email: %s\npassword: %s\n", email, password); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// replaceLegacyAdminPassword protects admins seeded by older releases, which all
+// shared the same published password. The password is replaced by a one-time
+// password written to passwordPath, as for a newly seeded admin, and existing
+// sessions are ended. If the password cannot be written the account is disabled.
+func replaceLegacyAdminPassword(repo ports.ManagerRepository, sessions ports.RefreshTokenRepository, passwordPath string) {
+	admin, err := repo.FindByEmail(defaultAdminEmail)
+	if err != nil || admin.CheckPassword(legacyAdminPassword) != nil {
+		return
+	}
+	if err := sessions.RevokeManagerRefreshTokens(admin.Id, time.Now()); err != nil {
+		log.Printf("Could not end the sessions of %s: %v", admin.Email, err)
+	}
+
+	password, writeErr := generatePassword()
+	if writeErr == nil {
+		writeErr = writePasswordFile(passwordPath, admin.Email, password)
+	}
+	if writeErr != nil {
+		admin.Disabled = true
+		if err := repo.UpdateManager(admin); err != nil {
+			log.Printf("Could not disable %s, which still uses the published default password: %v", admin.Email, err)
 			return
 		}
-		log.Println("Admin user seeded successfully.")
-		log.Println("Email: admin@example.com")
-		log.Println("Password: password123")
+		log.Printf("%s still used the published default password and was disabled (%v). "+
+			"Use inventory-admin reset-password and enable to restore it.", admin.Email, writeErr)
+		return
+	}
+	if err := admin.SetPassword(password); err != nil {
+		os.Remove(passwordPath)
+		log.Printf("Could not replace the default password of %s: %v", admin.Email, err)
+		return
+	}
+	admin.MustChangePassword = true
+	if err := repo.UpdateManager(admin); err != nil {
+		os.Remove(passwordPath)
+		log.Printf("Could not replace the default password of %s: %v", admin.Email, err)
+		return
+	}
+	log.Printf("%s still used the published default password. Its new one-time password is in %s; "+
+		"change it on first login, or manage the account with inventory-admin.", admin.Email, passwordPath)
+}
+
+func generatePassword() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amangirdhar210/inventory-manager/config"
+	"github.com/amangirdhar210/inventory-manager/utils/auth"
+)
+
+const defaultKeyId = "default"
+
+// buildKeyRing loads auth.signing_keys, or falls back to a single HS256 key
+// made from auth.jwt_secret when no key ring is configured.
+func buildKeyRing(cfg config.AuthConfig) (*auth.KeyRing, error) {
+	if len(cfg.SigningKeys) == 0 {
+		return auth.NewKeyRing(auth.NewHMACKey(defaultKeyId, []byte(cfg.JWTSecret)))
+	}
+
+	var active *auth.SigningKey
+	var retired []*auth.SigningKey
+	for _, keyConfig := range cfg.SigningKeys {
+		key, err := loadSigningKey(keyConfig)
+		if err != nil {
+			return nil, err
+		}
+		if key.Id == cfg.ActiveKeyId {
+			active = key
+		} else {
+			retired = append(retired, key)
+		}
+	}
+	return auth.NewKeyRing(active, retired...)
+}
+
+func loadSigningKey(keyConfig config.SigningKeyConfig) (*auth.SigningKey, error) {
+	if keyConfig.Algorithm == auth.AlgorithmHS256 {
+		return auth.ParseSigningKey(keyConfig.Id, keyConfig.Algorithm, []byte(keyConfig.Secret))
+	}
+	if keyConfig.PublicKeyFile != "" {
+		material, err := os.ReadFile(keyConfig.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read public key for %q: %w", keyConfig.Id, err)
+		}
+		return auth.ParseVerificationKey(keyConfig.Id, keyConfig.Algorithm, material)
+	}
+	material, err := os.ReadFile(keyConfig.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read private key for %q: %w", keyConfig.Id, err)
+	}
+	return auth.ParseSigningKey(keyConfig.Id, keyConfig.Algorithm, material)
+}
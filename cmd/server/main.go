@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/amangirdhar210/inventory-manager/config"
 	"github.com/amangirdhar210/inventory-manager/internal/adapters/handler"
-	"github.com/amangirdhar210/inventory-manager/internal/adapters/notifier"
 	"github.com/amangirdhar210/inventory-manager/internal/adapters/repository"
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
 	"github.com/amangirdhar210/inventory-manager/internal/core/service"
 	"github.com/amangirdhar210/inventory-manager/utils/auth"
 	"github.com/gorilla/mux"
@@ -17,44 +22,184 @@ import (
 )
 
 func main() {
-	db, err := SetupDatabase("./inventory.db")
+	configPath := flag.String("config", os.Getenv("INVENTORY_CONFIG"), "path to a JSON config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrateCommand(cfg.Database.Path, flag.Args()[1:]); err != nil {
+			log.Fatalf("Migration command failed: %v", err)
+		}
+		return
+	}
+
+	if len(cfg.Auth.SigningKeys) == 0 && cfg.Auth.JWTSecret == config.DevJWTSecret {
+		log.Println("WARNING: using the development JWT secret, set INVENTORY_JWT_SECRET before deploying.")
+	}
+
+	db, err := SetupDatabase(cfg.Database.Path)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
 	sqliteRepo := repository.NewSQLiteRepository(db)
-	logNotifier := notifier.NewLogNotifier()
-	tokenGenerator := auth.NewJWTGenerator(config.JWTSecretKey)
+	lowStockNotifier, closeNotifier, err := buildNotifier(cfg, sqliteRepo, sqliteRepo)
+	if err != nil {
+		log.Fatalf("Failed to configure notifications: %v", err)
+	}
 
-	inventoryService := service.NewInventoryService(sqliteRepo, logNotifier)
-	authService := service.NewAuthService(sqliteRepo, tokenGenerator)
+	pruneCtx, stopPruner := context.WithCancel(context.Background())
+	defer stopPruner()
+	go auth.RunTokenPruner(pruneCtx, sqliteRepo, sqliteRepo, cfg.Auth.TokenPruneInterval.Duration)
+	keyRing, err := buildKeyRing(cfg.Auth)
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
+	tokenGenerator := auth.NewJWTGenerator(keyRing, cfg.Auth.TokenTTL.Duration, cfg.Auth.MFAChallengeTTL.Duration)
+
+	alertService := service.NewAlertService(sqliteRepo, lowStockNotifier, cfg.Inventory.LowStockThreshold, cfg.Inventory.AlertCooldown.Duration)
+	inventoryService := service.NewInventoryService(sqliteRepo, sqliteRepo, sqliteRepo, sqliteRepo, alertService, cfg.Inventory.LowStockThreshold)
+	tokenValidator := auth.NewRevokingValidator(tokenGenerator, sqliteRepo)
+	login := cfg.Auth.Login
+	loginPolicy := domain.LoginPolicy{
+		MaxFailures:      login.MaxFailures,
+		LockoutDuration:  login.LockoutDuration.Duration,
+		Window:           login.Window.Duration,
+		MaxFailuresPerIP: login.MaxFailuresPerIP,
+		BaseDelay:        login.BaseDelay.Duration,
+		MaxDelay:         login.MaxDelay.Duration,
+	}
+	authService := service.NewAuthService(sqliteRepo, tokenGenerator, tokenGenerator, sqliteRepo, sqliteRepo, sqliteRepo, sqliteRepo,
+		cfg.Auth.RefreshTokenTTL.Duration, loginPolicy)
+	managerService := service.NewManagerService(sqliteRepo, sqliteRepo, sqliteRepo, cfg.Auth.TokenTTL.Duration)
+	apiKeyService := service.NewAPIKeyService(sqliteRepo)
+	auditService := service.NewAuditService(sqliteRepo)
+	categoryService := service.NewCategoryService(sqliteRepo)
+	locationService := service.NewLocationService(sqliteRepo, sqliteRepo, sqliteRepo, alertService)
+	binService := service.NewBinService(sqliteRepo, sqliteRepo, sqliteRepo)
+	lotService := service.NewLotService(sqliteRepo, sqliteRepo, sqliteRepo, lowStockNotifier, cfg.Inventory.ExpiryWarning.Duration)
+	go service.RunExpiryChecker(pruneCtx, lotService, cfg.Inventory.ExpiryCheckInterval.Duration)
 
-	inventoryHandler := handler.NewHTTPHandler(inventoryService, authService)
+	inventoryHandler := handler.NewHTTPHandler(handler.Services{
+		Inventory:  inventoryService,
+		Alerts:     alertService,
+		Managers:   managerService,
+		Auth:       authService,
+		APIKeys:    apiKeyService,
+		Audit:      auditService,
+		Categories: categoryService,
+		Locations:  locationService,
+		Bins:       binService,
+		Lots:       lotService,
+	}, tokenValidator)
 
 	router := mux.NewRouter()
+	router.Use(inventoryHandler.RequestMiddleware)
 
 	router.HandleFunc("/login", inventoryHandler.Login).Methods("POST")
-	router.HandleFunc("/logout", inventoryHandler.Logout).Methods("POST")
+	router.Handle("/logout", inventoryHandler.AuthMiddleware(http.HandlerFunc(inventoryHandler.Logout))).Methods("POST")
+	router.HandleFunc("/login/mfa", inventoryHandler.VerifyMFA).Methods("POST")
+	router.HandleFunc("/login/password", inventoryHandler.ChangePassword).Methods("POST")
+	router.HandleFunc("/token/refresh", inventoryHandler.RefreshToken).Methods("POST")
+	router.HandleFunc("/.well-known/jwks.json", auth.NewJWKSHandler(keyRing)).Methods("GET")
 
 	apiRouter := router.PathPrefix("/api").Subrouter()
 	apiRouter.Use(inventoryHandler.AuthMiddleware)
 
-	apiRouter.HandleFunc("/products", inventoryHandler.AddProduct).Methods("POST")
-	apiRouter.HandleFunc("/products/{id}", inventoryHandler.GetProduct).Methods("GET")
-	apiRouter.HandleFunc("/products/{id}/sell", inventoryHandler.SellProductUnits).Methods("POST")
-	apiRouter.HandleFunc("/products/{id}/restock", inventoryHandler.RestockProduct).Methods("POST")
-	apiRouter.HandleFunc("/products/{id}/price", inventoryHandler.UpdateProductPrice).Methods("PUT")
-	apiRouter.HandleFunc("/products/{id}", inventoryHandler.DeleteProduct).Methods("DELETE")
-	apiRouter.HandleFunc("/products", inventoryHandler.GetAllProducts).Methods("GET")
-	apiRouter.HandleFunc("/inventory/value", inventoryHandler.GetInventoryValue).Methods("GET")
+	apiRouter.HandleFunc("/mfa/totp", inventoryHandler.EnrollTOTP).Methods("POST")
+	apiRouter.HandleFunc("/mfa/totp/verify", inventoryHandler.ConfirmTOTP).Methods("POST")
+	apiRouter.HandleFunc("/mfa/totp/disable", inventoryHandler.DisableTOTP).Methods("POST")
+	apiRouter.HandleFunc("/products", inventoryHandler.RequirePermission(domain.PermProductsWrite, inventoryHandler.AddProduct)).Methods("POST")
+	apiRouter.HandleFunc("/products/purge", inventoryHandler.RequirePermission(domain.PermProductsPurge, inventoryHandler.PurgeDeletedProducts)).Methods("POST")
+	apiRouter.HandleFunc("/products/by-sku/{sku}", inventoryHandler.RequireCatalogPermission(domain.PermProductsRead, inventoryHandler.GetProductBySKU)).Methods("GET")
+	apiRouter.HandleFunc("/products/by-barcode/{code}", inventoryHandler.RequireCatalogPermission(domain.PermProductsRead, inventoryHandler.GetProductByBarcode)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}", inventoryHandler.RequireProductPermission(domain.PermProductsRead, inventoryHandler.GetProduct)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}/sell", inventoryHandler.RequireProductPermission(domain.PermStockSell, inventoryHandler.SellProductUnits)).Methods("POST")
+	apiRouter.HandleFunc("/products/{id}/restock", inventoryHandler.RequireProductPermission(domain.PermStockRestock, inventoryHandler.RestockProduct)).Methods("POST")
+	apiRouter.HandleFunc("/products/{id}/adjust", inventoryHandler.RequireProductPermission(domain.PermStockAdjust, inventoryHandler.AdjustProductStock)).Methods("POST")
+	apiRouter.HandleFunc("/products/{id}/stock", inventoryHandler.RequireProductPermission(domain.PermProductsRead, inventoryHandler.GetProductStock)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}/stock/{locationId}", inventoryHandler.RequireProductPermission(domain.PermProductsWrite, inventoryHandler.SetLocationReorderPoint)).Methods("PUT")
+	apiRouter.HandleFunc("/products/{id}/bins", inventoryHandler.RequireProductPermission(domain.PermProductsRead, inventoryHandler.GetProductBins)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}/lots", inventoryHandler.RequireProductPermission(domain.PermProductsRead, inventoryHandler.GetProductLots)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}/lots/{lotId}/write-off", inventoryHandler.RequireProductPermission(domain.PermStockAdjust, inventoryHandler.WriteOffLot)).Methods("POST")
+	apiRouter.HandleFunc("/products/{id}/movements", inventoryHandler.RequireProductPermission(domain.PermReportsRead, inventoryHandler.GetStockMovements)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}/price", inventoryHandler.RequireProductPermission(domain.PermProductsWrite, inventoryHandler.UpdateProductPrice)).Methods("PUT")
+	apiRouter.HandleFunc("/products/{id}/details", inventoryHandler.RequireProductPermission(domain.PermProductsWrite, inventoryHandler.UpdateProductDetails)).Methods("PUT")
+	apiRouter.HandleFunc("/products/{id}/category", inventoryHandler.RequireProductPermission(domain.PermProductsWrite, inventoryHandler.AssignProductCategory)).Methods("PUT")
+	apiRouter.HandleFunc("/products/{id}/thresholds", inventoryHandler.RequireProductPermission(domain.PermProductsWrite, inventoryHandler.UpdateReorderThresholds)).Methods("PUT")
+	apiRouter.HandleFunc("/products/{id}", inventoryHandler.RequireProductPermission(domain.PermProductsDelete, inventoryHandler.DeleteProduct)).Methods("DELETE")
+	apiRouter.HandleFunc("/products/{id}/restore", inventoryHandler.RequireProductPermission(domain.PermProductsDelete, inventoryHandler.RestoreProduct)).Methods("POST")
+	apiRouter.HandleFunc("/products", inventoryHandler.RequirePermission(domain.PermProductsRead, inventoryHandler.ListProducts)).Methods("GET")
+	apiRouter.HandleFunc("/inventory/value", inventoryHandler.RequirePermission(domain.PermReportsRead, inventoryHandler.GetInventoryValue)).Methods("GET")
+	apiRouter.HandleFunc("/inventory/expiring", inventoryHandler.RequirePermission(domain.PermReportsRead, inventoryHandler.GetExpiringLots)).Methods("GET")
+	apiRouter.HandleFunc("/categories", inventoryHandler.RequirePermission(domain.PermProductsWrite, inventoryHandler.CreateCategory)).Methods("POST")
+	apiRouter.HandleFunc("/categories", inventoryHandler.RequirePermission(domain.PermProductsRead, inventoryHandler.ListCategories)).Methods("GET")
+	apiRouter.HandleFunc("/categories/{id}", inventoryHandler.RequirePermission(domain.PermProductsRead, inventoryHandler.GetCategory)).Methods("GET")
+	apiRouter.HandleFunc("/categories/{id}", inventoryHandler.RequirePermission(domain.PermProductsWrite, inventoryHandler.UpdateCategory)).Methods("PUT")
+	apiRouter.HandleFunc("/categories/{id}", inventoryHandler.RequirePermission(domain.PermProductsWrite, inventoryHandler.DeleteCategory)).Methods("DELETE")
+	apiRouter.HandleFunc("/locations", inventoryHandler.RequirePermission(domain.PermLocationsManage, inventoryHandler.CreateLocation)).Methods("POST")
+	apiRouter.HandleFunc("/locations", inventoryHandler.RequirePermission(domain.PermProductsRead, inventoryHandler.ListLocations)).Methods("GET")
+	apiRouter.HandleFunc("/locations/{id}/bins", inventoryHandler.RequirePermission(domain.PermLocationsManage, inventoryHandler.CreateBin)).Methods("POST")
+	apiRouter.HandleFunc("/locations/{id}/bins", inventoryHandler.RequirePermission(domain.PermProductsRead, inventoryHandler.ListBins)).Methods("GET")
+	apiRouter.HandleFunc("/bins/{id}/products/{productId}", inventoryHandler.RequirePermission(domain.PermStockTransfer, inventoryHandler.SetBinStock)).Methods("PUT")
+	apiRouter.HandleFunc("/pick-lists", inventoryHandler.RequirePermission(domain.PermStockSell, inventoryHandler.CreatePickList)).Methods("POST")
+	apiRouter.HandleFunc("/transfers", inventoryHandler.RequirePermission(domain.PermStockTransfer, inventoryHandler.CreateTransfer)).Methods("POST")
+	apiRouter.HandleFunc("/transfers", inventoryHandler.RequirePermission(domain.PermStockTransfer, inventoryHandler.ListTransfers)).Methods("GET")
+	apiRouter.HandleFunc("/transfers/{id}/receive", inventoryHandler.RequirePermission(domain.PermStockTransfer, inventoryHandler.ReceiveTransfer)).Methods("POST")
+	apiRouter.HandleFunc("/transfers/{id}/cancel", inventoryHandler.RequirePermission(domain.PermStockTransfer, inventoryHandler.CancelTransfer)).Methods("POST")
+	apiRouter.HandleFunc("/alerts", inventoryHandler.RequirePermission(domain.PermAlertsRead, inventoryHandler.ListAlerts)).Methods("GET")
+	apiRouter.HandleFunc("/alerts/{id}/ack", inventoryHandler.RequirePermission(domain.PermAlertsAck, inventoryHandler.AcknowledgeAlert)).Methods("POST")
+	apiRouter.HandleFunc("/managers", inventoryHandler.RequirePermission(domain.PermManagersManage, inventoryHandler.CreateManager)).Methods("POST")
+	apiRouter.HandleFunc("/managers", inventoryHandler.RequirePermission(domain.PermManagersManage, inventoryHandler.ListManagers)).Methods("GET")
+	apiRouter.HandleFunc("/managers/{id}", inventoryHandler.RequirePermission(domain.PermManagersManage, inventoryHandler.DeleteManager)).Methods("DELETE")
+	apiRouter.HandleFunc("/managers/{id}/disable", inventoryHandler.RequirePermission(domain.PermManagersManage, inventoryHandler.DisableManager)).Methods("POST")
+	apiRouter.HandleFunc("/managers/{id}/enable", inventoryHandler.RequirePermission(domain.PermManagersManage, inventoryHandler.EnableManager)).Methods("POST")
+	apiRouter.HandleFunc("/managers/{id}/password", inventoryHandler.RequirePermission(domain.PermManagersManage, inventoryHandler.ResetManagerPassword)).Methods("PUT")
+	apiRouter.HandleFunc("/keys", inventoryHandler.RequirePermission(domain.PermManagersManage, inventoryHandler.CreateAPIKey)).Methods("POST")
+	apiRouter.HandleFunc("/keys", inventoryHandler.RequirePermission(domain.PermManagersManage, inventoryHandler.ListAPIKeys)).Methods("GET")
+	apiRouter.HandleFunc("/keys/{id}", inventoryHandler.RequirePermission(domain.PermManagersManage, inventoryHandler.RevokeAPIKey)).Methods("DELETE")
+	apiRouter.HandleFunc("/audit", inventoryHandler.RequirePermission(domain.PermAuditRead, inventoryHandler.ListAuditEntries)).Methods("GET")
+	apiRouter.HandleFunc("/audit/export", inventoryHandler.RequirePermission(domain.PermAuditRead, inventoryHandler.ExportAuditEntries)).Methods("GET")
 
 	server := &http.Server{
 		Handler:      router,
-		Addr:         ":8080",
-		WriteTimeout: 10 * time.Second,
-		ReadTimeout:  10 * time.Second,
+		Addr:         cfg.Server.Addr,
+		WriteTimeout: cfg.Server.WriteTimeout.Duration,
+		ReadTimeout:  cfg.Server.ReadTimeout.Duration,
+	}
+	fmt.Printf("Inventory Management Server (%s mode) starting on %s....\n", cfg.Mode, cfg.Server.Addr)
+	err = serve(server, cfg.Server.WriteTimeout.Duration)
+	stopPruner()
+	closeNotifier()
+	if err != nil {
+		log.Fatalf("Server failed: %v", err)
 	}
-	fmt.Println("Inventory Management Server starting on port 8080....")
-	log.Fatal(server.ListenAndServe())
+	log.Println("Server stopped.")
+}
+
+// serve runs the server until it fails or the process receives SIGINT or
+// SIGTERM. It then stops accepting connections and waits up to timeout for
+// in-flight requests, so the notifications they raise are queued before the
+// notifier is closed.
+func serve(server *http.Server, timeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- server.ListenAndServe() }()
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down, waiting for in-flight requests and queued notifications.")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/amangirdhar210/inventory-manager/config"
+	"github.com/amangirdhar210/inventory-manager/internal/adapters/notifier"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+func buildNotifier(cfg *config.Config, deadLetters ports.DeadLetterRepository, categories ports.CategoryRepository) (ports.Notifier, func(), error) {
+	defaultReorderPoint := cfg.Inventory.LowStockThreshold
+	if err := checkFilterCategories(cfg.Notifications.Channels, categories); err != nil {
+		return nil, nil, err
+	}
+
+	var channels []notifier.Channel
+	for _, channelConfig := range cfg.Notifications.Channels {
+		var channelNotifier ports.Notifier
+		switch channelConfig.Type {
+		case config.ChannelLog:
+			channelNotifier = notifier.NewLogNotifier(defaultReorderPoint)
+		case config.ChannelWebhook:
+			webhook := channelConfig.Webhook
+			channelNotifier = notifier.NewWebhookNotifier(notifier.WebhookConfig{
+				URLs:                webhook.URLs,
+				Secret:              webhook.Secret,
+				MaxAttempts:         webhook.MaxAttempts,
+				InitialBackoff:      webhook.InitialBackoff.Duration,
+				Timeout:             webhook.Timeout.Duration,
+				DefaultReorderPoint: defaultReorderPoint,
+			}, deadLetters)
+		case config.ChannelEmail:
+			email := channelConfig.Email
+			channelNotifier = notifier.NewEmailNotifier(notifier.EmailConfig{
+				Host:                email.Host,
+				Port:                email.Port,
+				Username:            email.Username,
+				Password:            email.Password,
+				From:                email.From,
+				To:                  email.To,
+				DefaultReorderPoint: defaultReorderPoint,
+			})
+		case config.ChannelFile:
+			channelNotifier = notifier.NewFileNotifier(channelConfig.File.Path, defaultReorderPoint)
+		default:
+			return nil, nil, fmt.Errorf("unknown notification channel type %q", channelConfig.Type)
+		}
+
+		channels = append(channels, notifier.Channel{
+			Name:     channelConfig.Name,
+			Notifier: channelNotifier,
+			Filter: notifier.ChannelFilter{
+				ProductIds:  channelConfig.Filter.ProductIds,
+				CategoryIds: channelConfig.Filter.CategoryIds,
+				MinSeverity: notifier.Severity(channelConfig.Filter.MinSeverity),
+			},
+		})
+	}
+	multiNotifier := notifier.NewMultiNotifier(categories, channels...)
+	return multiNotifier, multiNotifier.Close, nil
+}
+
+// checkFilterCategories rejects channel filters naming categories that do
+// not exist, which would otherwise silently never match.
+func checkFilterCategories(channels []config.ChannelConfig, categories ports.CategoryRepository) error {
+	known := map[string]bool{}
+	for i, channelConfig := range channels {
+		if len(channelConfig.Filter.CategoryIds) == 0 {
+			continue
+		}
+		if len(known) == 0 {
+			existing, err := categories.ListCategories()
+			if err != nil {
+				return fmt.Errorf("could not load categories: %w", err)
+			}
+			for _, category := range existing {
+				known[category.Id] = true
+			}
+		}
+		for _, categoryId := range channelConfig.Filter.CategoryIds {
+			if !known[categoryId] {
+				return fmt.Errorf("notifications.channels[%d].filter.category_ids: unknown category %q", i, categoryId)
+			}
+		}
+	}
+	return nil
+}
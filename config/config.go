@@ -1,4 +1,491 @@
 package config
 
-const ThresholdAlertQty int = 10
-const JWTSecretKey string = "amanisagoodboy"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/utils/auth"
+)
+
+const (
+	ModeDev        = "dev"
+	ModeProduction = "production"
+
+	ChannelLog     = "log"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	ChannelFile    = "file"
+
+	DevJWTSecret   = "dev-only-insecure-jwt-secret"
+	minSecretBytes = 32
+	envPrefix      = "INVENTORY_"
+)
+
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+type Config struct {
+	Mode          string              `json:"mode"`
+	Server        ServerConfig        `json:"server"`
+	Database      DatabaseConfig      `json:"database"`
+	Auth          AuthConfig          `json:"auth"`
+	Inventory     InventoryConfig     `json:"inventory"`
+	Notifications NotificationsConfig `json:"notifications"`
+}
+
+type ServerConfig struct {
+	Addr         string   `json:"addr"`
+	ReadTimeout  Duration `json:"read_timeout"`
+	WriteTimeout Duration `json:"write_timeout"`
+}
+
+type DatabaseConfig struct {
+	Path string `json:"path"`
+}
+
+type AuthConfig struct {
+	JWTSecret          string             `json:"jwt_secret"`
+	SigningKeys        []SigningKeyConfig `json:"signing_keys"`
+	ActiveKeyId        string             `json:"active_kid"`
+	TokenTTL           Duration           `json:"token_ttl"`
+	RefreshTokenTTL    Duration           `json:"refresh_token_ttl"`
+	TokenPruneInterval Duration           `json:"token_prune_interval"`
+	MFAChallengeTTL    Duration           `json:"mfa_challenge_ttl"`
+	Login              LoginConfig        `json:"login"`
+}
+
+// LoginConfig throttles password attempts. An email is locked for
+// lockout_duration after max_failures failures within window, and an address
+// after max_failures_per_ip; each failure before that doubles the wait from
+// base_delay up to max_delay.
+type LoginConfig struct {
+	MaxFailures      int      `json:"max_failures"`
+	LockoutDuration  Duration `json:"lockout_duration"`
+	Window           Duration `json:"window"`
+	MaxFailuresPerIP int      `json:"max_failures_per_ip"`
+	BaseDelay        Duration `json:"base_delay"`
+	MaxDelay         Duration `json:"max_delay"`
+}
+
+// SigningKeyConfig describes one key of the token key ring. HS256 keys carry a
+// secret; RS256 and EdDSA keys point at a PEM private key, or at a public key
+// when the key is only kept to verify tokens issued before a rotation.
+type SigningKeyConfig struct {
+	Id             string `json:"kid"`
+	Algorithm      string `json:"algorithm"`
+	Secret         string `json:"secret"`
+	PrivateKeyFile string `json:"private_key_file"`
+	PublicKeyFile  string `json:"public_key_file"`
+}
+
+// InventoryConfig's expiry_warning is how far ahead a lot's expiry date is
+// warned about; lots are checked every expiry_check_interval.
+type InventoryConfig struct {
+	LowStockThreshold   int      `json:"low_stock_threshold"`
+	AlertCooldown       Duration `json:"alert_cooldown"`
+	ExpiryWarning       Duration `json:"expiry_warning"`
+	ExpiryCheckInterval Duration `json:"expiry_check_interval"`
+}
+
+type NotificationsConfig struct {
+	Channels []ChannelConfig `json:"channels"`
+}
+
+type ChannelConfig struct {
+	Name    string        `json:"name"`
+	Type    string        `json:"type"`
+	Filter  FilterConfig  `json:"filter"`
+	Webhook WebhookConfig `json:"webhook"`
+	Email   EmailConfig   `json:"email"`
+	File    FileConfig    `json:"file"`
+}
+
+// FilterConfig limits a channel. CategoryIds also match every subcategory;
+// the server checks that they exist when it starts, since config loading
+// cannot see the database.
+type FilterConfig struct {
+	ProductIds  []string `json:"product_ids"`
+	CategoryIds []string `json:"category_ids"`
+	MinSeverity string   `json:"min_severity"`
+}
+
+type WebhookConfig struct {
+	URLs           []string `json:"urls"`
+	Secret         string   `json:"secret"`
+	MaxAttempts    int      `json:"max_attempts"`
+	InitialBackoff Duration `json:"initial_backoff"`
+	Timeout        Duration `json:"timeout"`
+}
+
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+type FileConfig struct {
+	Path string `json:"path"`
+}
+
+func Default() *Config {
+	return &Config{
+		Mode: ModeDev,
+		Server: ServerConfig{
+			Addr:         ":8080",
+			ReadTimeout:  Duration{10 * time.Second},
+			WriteTimeout: Duration{10 * time.Second},
+		},
+		Database: DatabaseConfig{
+			Path: "./inventory.db",
+		},
+		Auth: AuthConfig{
+			JWTSecret:          DevJWTSecret,
+			TokenTTL:           Duration{15 * time.Minute},
+			RefreshTokenTTL:    Duration{30 * 24 * time.Hour},
+			TokenPruneInterval: Duration{time.Hour},
+			MFAChallengeTTL:    Duration{5 * time.Minute},
+			Login: LoginConfig{
+				MaxFailures:      5,
+				LockoutDuration:  Duration{15 * time.Minute},
+				Window:           Duration{15 * time.Minute},
+				MaxFailuresPerIP: 50,
+				BaseDelay:        Duration{time.Second},
+				MaxDelay:         Duration{30 * time.Second},
+			},
+		},
+		Inventory: InventoryConfig{
+			LowStockThreshold:   10,
+			AlertCooldown:       Duration{time.Hour},
+			ExpiryWarning:       Duration{7 * 24 * time.Hour},
+			ExpiryCheckInterval: Duration{time.Hour},
+		},
+		Notifications: NotificationsConfig{
+			Channels: []ChannelConfig{
+				{Name: ChannelLog, Type: ChannelLog},
+			},
+		},
+	}
+}
+
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read config file: %w", err)
+		}
+		defaultChannels := cfg.Notifications.Channels
+		cfg.Notifications.Channels = nil
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("could not parse config file %s: %w", path, err)
+		}
+		if cfg.Notifications.Channels == nil {
+			cfg.Notifications.Channels = defaultChannels
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) applyEnv(lookup func(string) (string, bool)) error {
+	stringVars := map[string]*string{
+		"MODE":          &cfg.Mode,
+		"SERVER_ADDR":   &cfg.Server.Addr,
+		"DATABASE_PATH": &cfg.Database.Path,
+		"JWT_SECRET":    &cfg.Auth.JWTSecret,
+		"ACTIVE_KID":    &cfg.Auth.ActiveKeyId,
+	}
+	for name, target := range stringVars {
+		if value, ok := lookup(envPrefix + name); ok {
+			*target = value
+		}
+	}
+
+	urls, hasURLs := lookup(envPrefix + "WEBHOOK_URLS")
+	secret, hasSecret := lookup(envPrefix + "WEBHOOK_SECRET")
+	if hasURLs || hasSecret {
+		webhook := cfg.Notifications.channel(ChannelWebhook)
+		if webhook == nil && hasURLs {
+			cfg.Notifications.Channels = append(cfg.Notifications.Channels, ChannelConfig{Name: ChannelWebhook, Type: ChannelWebhook})
+			webhook = &cfg.Notifications.Channels[len(cfg.Notifications.Channels)-1]
+		}
+		if webhook != nil && hasURLs {
+			webhook.Webhook.URLs = splitList(urls)
+		}
+		if webhook != nil && hasSecret {
+			webhook.Webhook.Secret = secret
+		}
+	}
+	if password, ok := lookup(envPrefix + "SMTP_PASSWORD"); ok {
+		for i := range cfg.Notifications.Channels {
+			if cfg.Notifications.Channels[i].Type == ChannelEmail {
+				cfg.Notifications.Channels[i].Email.Password = password
+			}
+		}
+	}
+
+	durationVars := map[string]*Duration{
+		"SERVER_READ_TIMEOUT":   &cfg.Server.ReadTimeout,
+		"SERVER_WRITE_TIMEOUT":  &cfg.Server.WriteTimeout,
+		"TOKEN_TTL":             &cfg.Auth.TokenTTL,
+		"REFRESH_TOKEN_TTL":     &cfg.Auth.RefreshTokenTTL,
+		"TOKEN_PRUNE_INTERVAL":  &cfg.Auth.TokenPruneInterval,
+		"MFA_CHALLENGE_TTL":     &cfg.Auth.MFAChallengeTTL,
+		"LOGIN_LOCKOUT":         &cfg.Auth.Login.LockoutDuration,
+		"ALERT_COOLDOWN":        &cfg.Inventory.AlertCooldown,
+		"EXPIRY_WARNING":        &cfg.Inventory.ExpiryWarning,
+		"EXPIRY_CHECK_INTERVAL": &cfg.Inventory.ExpiryCheckInterval,
+	}
+	for name, target := range durationVars {
+		if value, ok := lookup(envPrefix + name); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s%s: %w", envPrefix, name, err)
+			}
+			target.Duration = parsed
+		}
+	}
+
+	intVars := map[string]*int{
+		"LOW_STOCK_THRESHOLD": &cfg.Inventory.LowStockThreshold,
+		"LOGIN_MAX_FAILURES":  &cfg.Auth.Login.MaxFailures,
+	}
+	for name, target := range intVars {
+		if value, ok := lookup(envPrefix + name); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s%s: %w", envPrefix, name, err)
+			}
+			*target = parsed
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) Validate() error {
+	var problems []string
+
+	if cfg.Mode != ModeDev && cfg.Mode != ModeProduction {
+		problems = append(problems, fmt.Sprintf("mode must be %q or %q, got %q", ModeDev, ModeProduction, cfg.Mode))
+	}
+	if cfg.Server.Addr == "" {
+		problems = append(problems, "server.addr must not be empty")
+	}
+	if cfg.Server.ReadTimeout.Duration <= 0 || cfg.Server.WriteTimeout.Duration <= 0 {
+		problems = append(problems, "server timeouts must be positive")
+	}
+	if cfg.Database.Path == "" {
+		problems = append(problems, "database.path must not be empty")
+	}
+	if cfg.Auth.TokenTTL.Duration <= 0 {
+		problems = append(problems, "auth.token_ttl must be positive")
+	}
+	if cfg.Auth.RefreshTokenTTL.Duration <= cfg.Auth.TokenTTL.Duration {
+		problems = append(problems, "auth.refresh_token_ttl must be longer than auth.token_ttl")
+	}
+	if cfg.Auth.TokenPruneInterval.Duration <= 0 {
+		problems = append(problems, "auth.token_prune_interval must be positive")
+	}
+	if cfg.Auth.MFAChallengeTTL.Duration <= 0 {
+		problems = append(problems, "auth.mfa_challenge_ttl must be positive")
+	}
+	problems = append(problems, cfg.Auth.Login.validate()...)
+	if len(cfg.Auth.SigningKeys) > 0 {
+		problems = append(problems, cfg.validateSigningKeys()...)
+	} else if cfg.Auth.JWTSecret == "" {
+		problems = append(problems, "auth.jwt_secret must not be empty")
+	} else if cfg.Mode != ModeDev {
+		if cfg.Auth.JWTSecret == DevJWTSecret {
+			problems = append(problems, "auth.jwt_secret is still the development default, set INVENTORY_JWT_SECRET")
+		} else if len(cfg.Auth.JWTSecret) < minSecretBytes {
+			problems = append(problems, fmt.Sprintf("auth.jwt_secret must be at least %d bytes outside dev mode", minSecretBytes))
+		}
+	}
+	if cfg.Inventory.LowStockThreshold < 0 {
+		problems = append(problems, "inventory.low_stock_threshold must not be negative")
+	}
+	if cfg.Inventory.AlertCooldown.Duration < 0 {
+		problems = append(problems, "inventory.alert_cooldown must not be negative")
+	}
+	if cfg.Inventory.ExpiryWarning.Duration <= 0 {
+		problems = append(problems, "inventory.expiry_warning must be positive")
+	}
+	if cfg.Inventory.ExpiryCheckInterval.Duration <= 0 {
+		problems = append(problems, "inventory.expiry_check_interval must be positive")
+	}
+	names := make(map[string]bool)
+	for i, channel := range cfg.Notifications.Channels {
+		label := fmt.Sprintf("notifications.channels[%d]", i)
+		if channel.Name == "" {
+			problems = append(problems, label+".name must not be empty")
+		} else if names[channel.Name] {
+			problems = append(problems, fmt.Sprintf("%s.name %q is used by more than one channel", label, channel.Name))
+		}
+		names[channel.Name] = true
+		problems = append(problems, channel.validate(label)...)
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (login LoginConfig) validate() []string {
+	var problems []string
+	if login.MaxFailures <= 0 || login.MaxFailuresPerIP <= 0 {
+		problems = append(problems, "auth.login.max_failures and max_failures_per_ip must be positive")
+	}
+	if login.LockoutDuration.Duration <= 0 || login.Window.Duration <= 0 {
+		problems = append(problems, "auth.login.lockout_duration and window must be positive")
+	}
+	if login.BaseDelay.Duration < 0 || login.MaxDelay.Duration < login.BaseDelay.Duration {
+		problems = append(problems, "auth.login.base_delay must not be negative or exceed max_delay")
+	}
+	return problems
+}
+
+func (cfg *Config) validateSigningKeys() []string {
+	var problems []string
+	kids := make(map[string]bool)
+	activeFound := false
+	for i, key := range cfg.Auth.SigningKeys {
+		label := fmt.Sprintf("auth.signing_keys[%d]", i)
+		if key.Id == "" {
+			problems = append(problems, label+".kid must not be empty")
+		} else if kids[key.Id] {
+			problems = append(problems, fmt.Sprintf("%s.kid %q is used by more than one key", label, key.Id))
+		}
+		kids[key.Id] = true
+
+		switch key.Algorithm {
+		case auth.AlgorithmHS256:
+			if key.Secret == "" {
+				problems = append(problems, label+" needs a secret")
+			} else if cfg.Mode != ModeDev && len(key.Secret) < minSecretBytes {
+				problems = append(problems, fmt.Sprintf("%s.secret must be at least %d bytes outside dev mode", label, minSecretBytes))
+			}
+			if key.PrivateKeyFile != "" || key.PublicKeyFile != "" {
+				problems = append(problems, label+" is HS256 and cannot use key files")
+			}
+		case auth.AlgorithmRS256, auth.AlgorithmEdDSA:
+			if (key.PrivateKeyFile == "") == (key.PublicKeyFile == "") {
+				problems = append(problems, label+" needs exactly one of private_key_file or public_key_file")
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("%s.algorithm must be HS256, RS256 or EdDSA, got %q", label, key.Algorithm))
+		}
+
+		if key.Id == cfg.Auth.ActiveKeyId {
+			activeFound = true
+			if key.PublicKeyFile != "" {
+				problems = append(problems, fmt.Sprintf("auth.active_kid %q only has a public key and cannot sign", key.Id))
+			}
+		}
+	}
+	if !activeFound {
+		problems = append(problems, fmt.Sprintf("auth.active_kid %q does not match any signing key", cfg.Auth.ActiveKeyId))
+	}
+	return problems
+}
+
+func (notifications *NotificationsConfig) channel(channelType string) *ChannelConfig {
+	for i := range notifications.Channels {
+		if notifications.Channels[i].Type == channelType {
+			return &notifications.Channels[i]
+		}
+	}
+	return nil
+}
+
+func (channel ChannelConfig) validate(label string) []string {
+	var problems []string
+
+	switch channel.Filter.MinSeverity {
+	case "", "warning", "critical":
+	default:
+		problems = append(problems, fmt.Sprintf("%s.filter.min_severity must be \"warning\" or \"critical\", got %q", label, channel.Filter.MinSeverity))
+	}
+	for _, categoryId := range channel.Filter.CategoryIds {
+		if strings.TrimSpace(categoryId) == "" {
+			problems = append(problems, label+".filter.category_ids must not contain empty ids")
+			break
+		}
+	}
+
+	switch channel.Type {
+	case ChannelLog:
+	case ChannelWebhook:
+		webhook := channel.Webhook
+		if len(webhook.URLs) == 0 {
+			problems = append(problems, label+".webhook.urls must not be empty")
+		}
+		if webhook.Secret == "" {
+			problems = append(problems, label+".webhook.secret is required")
+		}
+		if webhook.MaxAttempts < 0 || webhook.InitialBackoff.Duration < 0 || webhook.Timeout.Duration < 0 {
+			problems = append(problems, label+".webhook retry settings must not be negative")
+		}
+	case ChannelEmail:
+		email := channel.Email
+		if email.Host == "" || email.Port <= 0 {
+			problems = append(problems, label+".email needs a host and a positive port")
+		}
+		if email.From == "" || len(email.To) == 0 {
+			problems = append(problems, label+".email needs a from address and at least one recipient")
+		}
+	case ChannelFile:
+		if channel.File.Path == "" {
+			problems = append(problems, label+".file.path must not be empty")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("%s.type must be one of log, webhook, email or file, got %q", label, channel.Type))
+	}
+	return problems
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/utils/auth"
+)
+
+const strongSecret = "0123456789abcdef0123456789abcdef"
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() returned an unexpected error: %v", err)
+	}
+	if cfg.Mode != ModeDev || cfg.Server.Addr != ":8080" || cfg.Database.Path != "./inventory.db" {
+		t.Errorf("Load() defaults = %+v", cfg)
+	}
+	if cfg.Inventory.LowStockThreshold != 10 || cfg.Server.ReadTimeout.Duration != 10*time.Second {
+		t.Errorf("Load() defaults = %+v", cfg)
+	}
+}
+
+func TestLoad_FileAndEnvOverrides(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"mode": "production",
+		"server": {"addr": ":9090", "read_timeout": "5s"},
+		"auth": {"jwt_secret": "`+strongSecret+`"},
+		"inventory": {"low_stock_threshold": 3}
+	}`)
+	t.Setenv("INVENTORY_SERVER_ADDR", ":7070")
+	t.Setenv("INVENTORY_LOW_STOCK_THRESHOLD", "25")
+	t.Setenv("INVENTORY_SERVER_WRITE_TIMEOUT", "30s")
+	t.Setenv("INVENTORY_ALERT_COOLDOWN", "15m")
+	t.Setenv("INVENTORY_WEBHOOK_URLS", "https://a.example.com/hook, https://b.example.com/hook")
+	t.Setenv("INVENTORY_WEBHOOK_SECRET", "hook-secret")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned an unexpected error: %v", err)
+	}
+	if cfg.Mode != ModeProduction {
+		t.Errorf("Mode = %q, want %q", cfg.Mode, ModeProduction)
+	}
+	if cfg.Server.Addr != ":7070" {
+		t.Errorf("Server.Addr = %q, want env override %q", cfg.Server.Addr, ":7070")
+	}
+	if cfg.Server.ReadTimeout.Duration != 5*time.Second || cfg.Server.WriteTimeout.Duration != 30*time.Second {
+		t.Errorf("timeouts = %v/%v, want 5s/30s", cfg.Server.ReadTimeout, cfg.Server.WriteTimeout)
+	}
+	if cfg.Inventory.LowStockThreshold != 25 {
+		t.Errorf("LowStockThreshold = %d, want 25", cfg.Inventory.LowStockThreshold)
+	}
+	if cfg.Inventory.AlertCooldown.Duration != 15*time.Minute {
+		t.Errorf("AlertCooldown = %v, want 15m", cfg.Inventory.AlertCooldown)
+	}
+	if cfg.Database.Path != "./inventory.db" {
+		t.Errorf("Database.Path = %q, want default", cfg.Database.Path)
+	}
+	webhook := cfg.Notifications.channel(ChannelWebhook)
+	if webhook == nil {
+		t.Fatalf("Channels = %+v, want a webhook channel added from the environment", cfg.Notifications.Channels)
+	}
+	if urls := webhook.Webhook.URLs; len(urls) != 2 || urls[1] != "https://b.example.com/hook" {
+		t.Errorf("Webhook.URLs = %v, want both env urls", urls)
+	}
+	if webhook.Webhook.Secret != "hook-secret" {
+		t.Errorf("Webhook.Secret = %q, want env secret", webhook.Webhook.Secret)
+	}
+}
+
+func TestLoad_NotificationChannels(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"notifications": {"channels": [
+			{"name": "ops-mail", "type": "email", "filter": {"min_severity": "critical"},
+			 "email": {"host": "smtp.example.com", "port": 587, "from": "stock@example.com", "to": ["ops@example.com"]}},
+			{"name": "audit-file", "type": "file", "filter": {"product_ids": ["prod-1"], "category_ids": ["c-dairy"]}, "file": {"path": "/tmp/alerts.jsonl"}}
+		]}
+	}`)
+	t.Setenv("INVENTORY_SMTP_PASSWORD", "mail-secret")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned an unexpected error: %v", err)
+	}
+	channels := cfg.Notifications.Channels
+	if len(channels) != 2 {
+		t.Fatalf("Channels = %+v, want the two configured channels to replace the default", channels)
+	}
+	if channels[0].Name != "ops-mail" || channels[0].Filter.MinSeverity != "critical" || channels[0].Email.Password != "mail-secret" {
+		t.Errorf("email channel = %+v", channels[0])
+	}
+	if channels[1].Type != ChannelFile || channels[1].Filter.ProductIds[0] != "prod-1" || channels[1].Filter.CategoryIds[0] != "c-dairy" {
+		t.Errorf("file channel = %+v", channels[1])
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"unknown_field", `{"serverr": {}}`, nil, "unknown field"},
+		{"bad_duration", `{"server": {"read_timeout": "soon"}}`, nil, "invalid duration"},
+		{"bad_env_int", `{}`, map[string]string{"INVENTORY_LOW_STOCK_THRESHOLD": "ten"}, "INVENTORY_LOW_STOCK_THRESHOLD"},
+		{"default_secret_in_production", `{"mode": "production"}`, nil, "development default"},
+		{"short_secret_in_production", `{}`, map[string]string{"INVENTORY_MODE": "production", "INVENTORY_JWT_SECRET": "short"}, "at least"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, err := Load(writeConfigFile(t, tt.file))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(*Config)
+		expectErr bool
+	}{
+		{"dev_defaults", func(c *Config) {}, false},
+		{"production_with_strong_secret", func(c *Config) { c.Mode = ModeProduction; c.Auth.JWTSecret = strongSecret }, false},
+		{"production_with_default_secret", func(c *Config) { c.Mode = ModeProduction }, true},
+		{"unknown_mode", func(c *Config) { c.Mode = "staging" }, true},
+		{"empty_secret", func(c *Config) { c.Auth.JWTSecret = "" }, true},
+		{"empty_addr", func(c *Config) { c.Server.Addr = "" }, true},
+		{"zero_timeout", func(c *Config) { c.Server.WriteTimeout = Duration{} }, true},
+		{"rotated_key_ring", func(c *Config) {
+			c.Auth.SigningKeys = []SigningKeyConfig{
+				{Id: "2024-05", Algorithm: auth.AlgorithmEdDSA, PrivateKeyFile: "/keys/2024-05.pem"},
+				{Id: "2024-01", Algorithm: auth.AlgorithmRS256, PublicKeyFile: "/keys/2024-01.pub.pem"},
+				{Id: "legacy", Algorithm: auth.AlgorithmHS256, Secret: strongSecret},
+			}
+			c.Auth.ActiveKeyId = "2024-05"
+		}, false},
+		{"key_ring_ignores_dev_jwt_secret_in_production", func(c *Config) {
+			c.Mode = ModeProduction
+			c.Auth.SigningKeys = []SigningKeyConfig{{Id: "k1", Algorithm: auth.AlgorithmRS256, PrivateKeyFile: "/keys/k1.pem"}}
+			c.Auth.ActiveKeyId = "k1"
+		}, false},
+		{"unknown_active_kid", func(c *Config) {
+			c.Auth.SigningKeys = []SigningKeyConfig{{Id: "k1", Algorithm: auth.AlgorithmHS256, Secret: strongSecret}}
+			c.Auth.ActiveKeyId = "k2"
+		}, true},
+		{"active_key_is_public_only", func(c *Config) {
+			c.Auth.SigningKeys = []SigningKeyConfig{{Id: "k1", Algorithm: auth.AlgorithmRS256, PublicKeyFile: "/keys/k1.pub.pem"}}
+			c.Auth.ActiveKeyId = "k1"
+		}, true},
+		{"unsupported_algorithm", func(c *Config) {
+			c.Auth.SigningKeys = []SigningKeyConfig{{Id: "k1", Algorithm: "none", Secret: strongSecret}}
+			c.Auth.ActiveKeyId = "k1"
+		}, true},
+		{"duplicate_kid", func(c *Config) {
+			c.Auth.SigningKeys = []SigningKeyConfig{
+				{Id: "k1", Algorithm: auth.AlgorithmHS256, Secret: strongSecret},
+				{Id: "k1", Algorithm: auth.AlgorithmEdDSA, PrivateKeyFile: "/keys/k1.pem"},
+			}
+			c.Auth.ActiveKeyId = "k1"
+		}, true},
+		{"zero_prune_interval", func(c *Config) { c.Auth.TokenPruneInterval = Duration{} }, true},
+		{"zero_mfa_challenge_ttl", func(c *Config) { c.Auth.MFAChallengeTTL = Duration{} }, true},
+		{"refresh_ttl_not_longer_than_access_ttl", func(c *Config) { c.Auth.RefreshTokenTTL = c.Auth.TokenTTL }, true},
+		{"no_login_failure_limit", func(c *Config) { c.Auth.Login.MaxFailures = 0 }, true},
+		{"login_base_delay_above_max", func(c *Config) { c.Auth.Login.BaseDelay = Duration{time.Minute} }, true},
+		{"negative_threshold", func(c *Config) { c.Inventory.LowStockThreshold = -1 }, true},
+		{"zero_alert_cooldown", func(c *Config) { c.Inventory.AlertCooldown = Duration{} }, false},
+		{"negative_alert_cooldown", func(c *Config) { c.Inventory.AlertCooldown = Duration{-time.Minute} }, true},
+		{"zero_expiry_warning", func(c *Config) { c.Inventory.ExpiryWarning = Duration{} }, true},
+		{"zero_expiry_check_interval", func(c *Config) { c.Inventory.ExpiryCheckInterval = Duration{} }, true},
+		{"webhook_with_secret", func(c *Config) {
+			c.Notifications.Channels = []ChannelConfig{{Name: "hooks", Type: ChannelWebhook,
+				Webhook: WebhookConfig{URLs: []string{"https://hooks.example.com"}, Secret: "s3cret"}}}
+		}, false},
+		{"webhook_without_secret", func(c *Config) {
+			c.Notifications.Channels = []ChannelConfig{{Name: "hooks", Type: ChannelWebhook,
+				Webhook: WebhookConfig{URLs: []string{"https://hooks.example.com"}}}}
+		}, true},
+		{"no_channels", func(c *Config) { c.Notifications.Channels = nil }, false},
+		{"unknown_channel_type", func(c *Config) { c.Notifications.Channels[0].Type = "pager" }, true},
+		{"duplicate_channel_name", func(c *Config) {
+			c.Notifications.Channels = append(c.Notifications.Channels, ChannelConfig{Name: ChannelLog, Type: ChannelLog})
+		}, true},
+		{"unknown_severity", func(c *Config) { c.Notifications.Channels[0].Filter.MinSeverity = "panic" }, true},
+		{"blank_category_id", func(c *Config) { c.Notifications.Channels[0].Filter.CategoryIds = []string{" "} }, true},
+		{"email_without_recipients", func(c *Config) {
+			c.Notifications.Channels = []ChannelConfig{{Name: "mail", Type: ChannelEmail,
+				Email: EmailConfig{Host: "smtp.example.com", Port: 25, From: "stock@example.com"}}}
+		}, true},
+		{"file_without_path", func(c *Config) { c.Notifications.Channels = []ChannelConfig{{Name: "file", Type: ChannelFile}} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.mutate(cfg)
+			if err := cfg.Validate(); (err != nil) != tt.expectErr {
+				t.Errorf("Validate() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
@@ -3,25 +3,69 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/amangirdhar210/inventory-manager/config"
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
 	"github.com/amangirdhar210/inventory-manager/internal/core/service"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+// maxRequestIdLength bounds request ids taken from the X-Request-Id header.
+const maxRequestIdLength = 128
+
 type HTTPHandler struct {
 	inventoryService service.InventoryService
+	alertService     service.AlertService
+	managerService   service.ManagerService
 	authService      service.AuthService
+	apiKeyService    service.APIKeyService
+	auditService     service.AuditService
+	categoryService  service.CategoryService
+	locationService  service.LocationService
+	binService       service.BinService
+	lotService       service.LotService
+	tokenValidator   ports.TokenValidator
+}
+
+// Services are the core services the handler serves. A nil service is only
+// safe when none of its routes are registered, as in tests.
+type Services struct {
+	Inventory  service.InventoryService
+	Alerts     service.AlertService
+	Managers   service.ManagerService
+	Auth       service.AuthService
+	APIKeys    service.APIKeyService
+	Audit      service.AuditService
+	Categories service.CategoryService
+	Locations  service.LocationService
+	Bins       service.BinService
+	Lots       service.LotService
 }
 
-func NewHTTPHandler(invService service.InventoryService, authService service.AuthService) *HTTPHandler {
+func NewHTTPHandler(services Services, tokenValidator ports.TokenValidator) *HTTPHandler {
 	return &HTTPHandler{
-		inventoryService: invService,
-		authService:      authService,
+		inventoryService: services.Inventory,
+		alertService:     services.Alerts,
+		managerService:   services.Managers,
+		authService:      services.Auth,
+		apiKeyService:    services.APIKeys,
+		auditService:     services.Audit,
+		categoryService:  services.Categories,
+		locationService:  services.Locations,
+		binService:       services.Bins,
+		lotService:       services.Lots,
+		tokenValidator:   tokenValidator,
 	}
 }
 
@@ -35,59 +79,251 @@ func (h *HTTPHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.authService.Login(req.Email, req.Password)
+	result, err := h.authService.Login(req.Email, req.Password, clientIP(r))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.respondWithLoginResult(w, result)
+}
+
+func (h *HTTPHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MFAToken == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	pair, err := h.authService.VerifyMFA(req.MFAToken, req.Code, clientIP(r))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.respondWithTokens(w, pair)
+}
+
+func (h *HTTPHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	enrollment, err := h.authService.EnrollTOTP(r.Context())
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]string{
+		"secret":      enrollment.Secret,
+		"otpauth_uri": enrollment.URI,
+	})
+}
+
+func (h *HTTPHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	codes, err := h.authService.ConfirmTOTP(r.Context(), req.Code)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string][]string{"recovery_codes": codes})
+}
+
+func (h *HTTPHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.authService.DisableTOTP(r.Context(), req.Code); err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "two-factor authentication disabled"})
+}
+
+func (h *HTTPHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	pair, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.respondWithTokens(w, pair)
+}
+
+func (h *HTTPHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email           string `json:"email"`
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+		Code            string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.authService.ChangePassword(req.Email, req.CurrentPassword, req.NewPassword, req.Code, clientIP(r))
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
 
-	h.respondWithJSON(w, http.StatusOK, map[string]string{"token": token})
+	h.respondWithLoginResult(w, result)
 }
 
 func (h *HTTPHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.authService.Logout(r.Context(), req.RefreshToken); err != nil {
+		h.handleError(w, err)
+		return
+	}
 	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "logout successful"})
 }
 
-func (h *HTTPHandler) AuthMiddleware(next http.Handler) http.Handler {
+// RequestMiddleware tags every request with an id, taken from X-Request-Id
+// when the caller sent one, and the client address for the audit log.
+func (h *HTTPHandler) RequestMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			h.handleError(w, domain.ErrUnauthorized)
-			return
+		id := r.Header.Get("X-Request-Id")
+		if id == "" || len(id) > maxRequestIdLength {
+			id = uuid.NewString()
 		}
+		w.Header().Set("X-Request-Id", id)
+
+		ctx := domain.ContextWithRequestMeta(r.Context(), domain.RequestMeta{Id: id, ClientIP: clientIP(r)})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
+// AuthMiddleware accepts either an X-API-Key header or a Bearer token.
+func (h *HTTPHandler) AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := h.authenticate(r)
+		if err != nil {
 			h.handleError(w, domain.ErrUnauthorized)
 			return
 		}
 
-		claims := &jwt.RegisteredClaims{}
-		_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(config.JWTSecretKey), nil
-		})
+		ctx := domain.ContextWithPrincipal(r.Context(), *principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-		if err != nil {
+func (h *HTTPHandler) authenticate(r *http.Request) (*domain.Principal, error) {
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		return h.apiKeyService.AuthenticateAPIKey(apiKey)
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if authHeader == "" || tokenString == authHeader {
+		return nil, domain.ErrUnauthorized
+	}
+	return h.tokenValidator.ValidateToken(tokenString)
+}
+
+// RequirePermission guards routes that are not about a single product, which
+// API keys scoped to particular products may not use.
+func (h *HTTPHandler) RequirePermission(permission domain.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return h.authorize(permission, func(principal domain.Principal, r *http.Request) bool {
+		return len(principal.ProductIds) == 0
+	}, next)
+}
+
+// RequireProductPermission guards /products/{id} routes and also checks the
+// product against the principal's product scope.
+func (h *HTTPHandler) RequireProductPermission(permission domain.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return h.authorize(permission, func(principal domain.Principal, r *http.Request) bool {
+		return principal.CanAccessProduct(mux.Vars(r)["id"])
+	}, next)
+}
+
+func (h *HTTPHandler) authorize(permission domain.Permission, inScope func(domain.Principal, *http.Request) bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := domain.PrincipalFromContext(r.Context())
+		if !ok {
 			h.handleError(w, domain.ErrUnauthorized)
 			return
 		}
+		if !principal.Can(permission) || !inScope(principal, r) {
+			h.handleError(w, domain.ErrForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
 
-		next.ServeHTTP(w, r)
-	})
+// RequireCatalogPermission guards lookups that name a product by something
+// other than its id. The handler checks the product scope once it is found.
+func (h *HTTPHandler) RequireCatalogPermission(permission domain.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return h.authorize(permission, func(domain.Principal, *http.Request) bool {
+		return true
+	}, next)
+}
+
+type productDetailsRequest struct {
+	SKU         string   `json:"sku"`
+	Barcode     string   `json:"barcode"`
+	Description string   `json:"description"`
+	CategoryId  string   `json:"category_id"`
+	Unit        string   `json:"unit"`
+	Tags        []string `json:"tags"`
+}
+
+func (req productDetailsRequest) toDetails() domain.ProductDetails {
+	return domain.ProductDetails{
+		SKU:         req.SKU,
+		Barcode:     req.Barcode,
+		Description: req.Description,
+		CategoryId:  req.CategoryId,
+		Unit:        domain.UnitOfMeasure(req.Unit),
+		Tags:        req.Tags,
+	}
 }
 
 func (h *HTTPHandler) AddProduct(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name     string  `json:"name"`
-		Price    float64 `json:"price"`
-		Quantity int     `json:"quantity"`
+		Name            string  `json:"name"`
+		Price           float64 `json:"price"`
+		Quantity        int     `json:"quantity"`
+		ReorderPoint    int     `json:"reorder_point"`
+		ReorderQuantity int     `json:"reorder_quantity"`
+		productDetailsRequest
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	product, err := h.inventoryService.AddProduct(req.Name, req.Price, req.Quantity)
+	product, err := h.inventoryService.AddProduct(r.Context(), req.Name, req.Price, req.Quantity, req.ReorderPoint, req.ReorderQuantity,
+		req.toDetails())
 	if err != nil {
 		h.handleError(w, err)
 		return
@@ -105,6 +341,32 @@ func (h *HTTPHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 		h.handleError(w, err)
 		return
 	}
+	h.setETag(w, product)
+	h.respondWithJSON(w, http.StatusOK, product)
+}
+
+func (h *HTTPHandler) GetProductBySKU(w http.ResponseWriter, r *http.Request) {
+	product, err := h.inventoryService.GetProductBySKU(mux.Vars(r)["sku"])
+	h.respondWithScopedProduct(w, r, product, err)
+}
+
+func (h *HTTPHandler) GetProductByBarcode(w http.ResponseWriter, r *http.Request) {
+	product, err := h.inventoryService.GetProductByBarcode(mux.Vars(r)["code"])
+	h.respondWithScopedProduct(w, r, product, err)
+}
+
+// respondWithScopedProduct reports a product outside the principal's scope as
+// not found, so a scoped key cannot probe for SKUs or barcodes it may not see.
+func (h *HTTPHandler) respondWithScopedProduct(w http.ResponseWriter, r *http.Request, product *domain.Product, err error) {
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if principal, _ := domain.PrincipalFromContext(r.Context()); !principal.CanAccessProduct(product.Id) {
+		h.handleError(w, domain.ErrProductNotFound)
+		return
+	}
+	h.setETag(w, product)
 	h.respondWithJSON(w, http.StatusOK, product)
 }
 
@@ -112,17 +374,24 @@ func (h *HTTPHandler) SellProductUnits(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	var req struct {
-		Quantity int `json:"quantity"`
+		Quantity   int    `json:"quantity"`
+		LocationId string `json:"location_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
-	product, err := h.inventoryService.SellProductUnits(id, req.Quantity)
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid If-Match header")
+		return
+	}
+	product, err := h.inventoryService.SellProductUnits(r.Context(), id, req.LocationId, req.Quantity, expectedVersion)
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
+	h.setETag(w, product)
 	h.respondWithJSON(w, http.StatusOK, product)
 }
 
@@ -130,25 +399,103 @@ func (h *HTTPHandler) RestockProduct(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	var req struct {
-		Quantity int `json:"quantity"`
+		Quantity   int        `json:"quantity"`
+		LocationId string     `json:"location_id"`
+		LotNumber  string     `json:"lot_number"`
+		ExpiresAt  *time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid If-Match header")
+		return
+	}
+	receipt := domain.LotReceipt{LotNumber: req.LotNumber, ExpiresAt: req.ExpiresAt}
+	product, err := h.inventoryService.RestockProduct(r.Context(), id, req.LocationId, req.Quantity, receipt, expectedVersion)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.setETag(w, product)
+	h.respondWithJSON(w, http.StatusOK, product)
+}
+
+func (h *HTTPHandler) AdjustProductStock(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	var req struct {
+		Delta      int    `json:"delta"`
+		LocationId string `json:"location_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
-	product, err := h.inventoryService.RestockProduct(id, req.Quantity)
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid If-Match header")
+		return
+	}
+	product, err := h.inventoryService.AdjustProductStock(r.Context(), id, req.LocationId, req.Delta, expectedVersion)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.setETag(w, product)
+	h.respondWithJSON(w, http.StatusOK, product)
+}
+
+func (h *HTTPHandler) WriteOffLot(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid If-Match header")
+		return
+	}
+	product, err := h.inventoryService.WriteOffLot(r.Context(), vars["id"], vars["lotId"], expectedVersion)
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
+	h.setETag(w, product)
 	h.respondWithJSON(w, http.StatusOK, product)
 }
 
+func (h *HTTPHandler) GetStockMovements(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	from, err := parseTimeParam(r, "from")
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid 'from' parameter, expected RFC3339 time")
+		return
+	}
+	to, err := parseTimeParam(r, "to")
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid 'to' parameter, expected RFC3339 time")
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		h.respondWithError(w, http.StatusBadRequest, "'to' must not be before 'from'")
+		return
+	}
+
+	movements, err := h.inventoryService.GetStockMovements(id, from, to)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, movements)
+}
+
 func (h *HTTPHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.inventoryService.DeleteProduct(id)
+	err := h.inventoryService.DeleteProduct(r.Context(), id)
 	if err != nil {
 		h.handleError(w, err)
 		return
@@ -156,6 +503,40 @@ func (h *HTTPHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "product deleted successfully"})
 }
 
+func (h *HTTPHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	product, err := h.inventoryService.RestoreProduct(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.setETag(w, product)
+	h.respondWithJSON(w, http.StatusOK, product)
+}
+
+func (h *HTTPHandler) PurgeDeletedProducts(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OlderThanDays int `json:"older_than_days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	purged, err := h.inventoryService.PurgeDeletedProducts(r.Context(), req.OlderThanDays)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	ids := make([]string, 0, len(purged))
+	for _, product := range purged {
+		ids = append(ids, product.Id)
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]interface{}{"purged": len(ids), "product_ids": ids})
+}
+
 func (h *HTTPHandler) UpdateProductPrice(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -167,51 +548,864 @@ func (h *HTTPHandler) UpdateProductPrice(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.inventoryService.UpdateProductPrice(id, req.NewPrice)
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid If-Match header")
+		return
+	}
+
+	product, err := h.inventoryService.UpdateProductPrice(r.Context(), id, req.NewPrice, expectedVersion)
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
+	h.setETag(w, product)
 	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "product price updated successfully"})
 }
 
-func (h *HTTPHandler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
-	products, err := h.inventoryService.GetAllProducts()
+func (h *HTTPHandler) UpdateProductDetails(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	var req productDetailsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	expectedVersion, err := parseIfMatch(r)
 	if err != nil {
-		h.handleError(w, err)
+		h.respondWithError(w, http.StatusBadRequest, "Invalid If-Match header")
 		return
 	}
-	h.respondWithJSON(w, http.StatusOK, products)
-}
 
-func (h *HTTPHandler) GetInventoryValue(w http.ResponseWriter, r *http.Request) {
-	value, err := h.inventoryService.GetInventoryValue()
+	product, err := h.inventoryService.UpdateProductDetails(r.Context(), id, req.toDetails(), expectedVersion)
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
-	h.respondWithJSON(w, http.StatusOK, map[string]float64{"inventory_value": value})
+	h.setETag(w, product)
+	h.respondWithJSON(w, http.StatusOK, product)
 }
 
-func (h *HTTPHandler) respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	response, _ := json.Marshal(payload)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	w.Write(response)
-}
+func (h *HTTPHandler) AssignProductCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	var req struct {
+		CategoryId string `json:"category_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
 
-func (h *HTTPHandler) respondWithError(w http.ResponseWriter, code int, message string) {
-	h.respondWithJSON(w, code, map[string]string{"error": message})
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid If-Match header")
+		return
+	}
+
+	product, err := h.inventoryService.AssignProductCategory(r.Context(), id, req.CategoryId, expectedVersion)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.setETag(w, product)
+	h.respondWithJSON(w, http.StatusOK, product)
 }
 
-func (h *HTTPHandler) handleError(w http.ResponseWriter, err error) {
+func (h *HTTPHandler) UpdateReorderThresholds(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	var req struct {
+		ReorderPoint    int `json:"reorder_point"`
+		ReorderQuantity int `json:"reorder_quantity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid If-Match header")
+		return
+	}
+
+	product, err := h.inventoryService.UpdateReorderThresholds(r.Context(), id, req.ReorderPoint, req.ReorderQuantity, expectedVersion)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.setETag(w, product)
+	h.respondWithJSON(w, http.StatusOK, product)
+}
+
+func (h *HTTPHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
+	query, err := parseProductQuery(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	page, err := h.inventoryService.ListProducts(query)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"products":    page.Products,
+		"next_cursor": page.NextCursor,
+	})
+}
+
+func (h *HTTPHandler) GetInventoryValue(w http.ResponseWriter, r *http.Request) {
+	report, err := h.inventoryService.GetInventoryValue(r.URL.Query().Get("location"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"inventory_value":     report.Total,
+		"uncategorized_value": report.Uncategorized,
+		"categories":          report.Categories,
+	})
+}
+
+func (h *HTTPHandler) GetProductLots(w http.ResponseWriter, r *http.Request) {
+	lots, err := h.lotService.ListLots(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, lots)
+}
+
+// GetExpiringLots takes ?days= for the window and ?location= to look at one
+// location only.
+func (h *HTTPHandler) GetExpiringLots(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	days, err := parseIntParam(query, "days")
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	var within time.Duration
+	if days != nil {
+		if *days < 1 {
+			h.handleError(w, fmt.Errorf("%w: 'days' must be positive", domain.ErrInvalidQuery))
+			return
+		}
+		within = time.Duration(*days) * 24 * time.Hour
+	}
+
+	lots, err := h.lotService.ExpiringLots(query.Get("location"), within)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, lots)
+}
+
+func (h *HTTPHandler) setETag(w http.ResponseWriter, product *domain.Product) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, product.Version))
+}
+
+// parseIfMatch returns the version an update must apply to, or 0 for any.
+// If-Match uses strong comparison, so a weak validator never matches.
+func parseIfMatch(r *http.Request) (int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+	if strings.HasPrefix(ifMatch, "W/") {
+		return 0, fmt.Errorf("weak If-Match validator %q", ifMatch)
+	}
+	ifMatch = strings.Trim(ifMatch, `"`)
+	version, err := strconv.Atoi(ifMatch)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("malformed If-Match header %q", ifMatch)
+	}
+	return version, nil
+}
+
+func (h *HTTPHandler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.alertService.ListAlerts(r.URL.Query().Get("status"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, alerts)
+}
+
+func (h *HTTPHandler) AcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	alert, err := h.alertService.AcknowledgeAlert(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, alert)
+}
+
+type managerResponse struct {
+	Id                 string
+	Email              string
+	Role               domain.Role
+	Disabled           bool
+	MustChangePassword bool
+	MFAEnabled         bool
+}
+
+func newManagerResponse(manager *domain.Manager) managerResponse {
+	return managerResponse{
+		Id:                 manager.Id,
+		Email:              manager.Email,
+		Role:               manager.Role,
+		Disabled:           manager.Disabled,
+		MustChangePassword: manager.MustChangePassword,
+		MFAEnabled:         manager.TOTPEnabled,
+	}
+}
+
+func (h *HTTPHandler) CreateManager(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	manager, err := h.managerService.CreateManager(req.Email, req.Password, domain.Role(req.Role))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, newManagerResponse(manager))
+}
+
+func (h *HTTPHandler) ListManagers(w http.ResponseWriter, r *http.Request) {
+	managers, err := h.managerService.ListManagers()
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response := make([]managerResponse, 0, len(managers))
+	for i := range managers {
+		response = append(response, newManagerResponse(&managers[i]))
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *HTTPHandler) DisableManager(w http.ResponseWriter, r *http.Request) {
+	h.setManagerDisabled(w, r, true)
+}
+
+func (h *HTTPHandler) EnableManager(w http.ResponseWriter, r *http.Request) {
+	h.setManagerDisabled(w, r, false)
+}
+
+func (h *HTTPHandler) setManagerDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	manager, err := h.managerService.SetManagerDisabled(r.Context(), id, disabled)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, newManagerResponse(manager))
+}
+
+func (h *HTTPHandler) ResetManagerPassword(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	manager, err := h.managerService.ResetPassword(id, req.Password)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, newManagerResponse(manager))
+}
+
+func (h *HTTPHandler) DeleteManager(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	err := h.managerService.DeleteManager(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "manager deleted successfully"})
+}
+
+type categoryRequest struct {
+	Name     string `json:"name"`
+	ParentId string `json:"parent_id"`
+}
+
+func (h *HTTPHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(req.Name, req.ParentId)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, category)
+}
+
+func (h *HTTPHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.categoryService.ListCategories()
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, categories)
+}
+
+func (h *HTTPHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	category, err := h.categoryService.GetCategory(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, category)
+}
+
+func (h *HTTPHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(mux.Vars(r)["id"], req.Name, req.ParentId)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, category)
+}
+
+func (h *HTTPHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	if err := h.categoryService.DeleteCategory(mux.Vars(r)["id"]); err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "category deleted successfully"})
+}
+
+type locationRequest struct {
+	Code string              `json:"code"`
+	Name string              `json:"name"`
+	Kind domain.LocationKind `json:"kind"`
+}
+
+func (h *HTTPHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var req locationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	location, err := h.locationService.CreateLocation(req.Code, req.Name, req.Kind)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, location)
+}
+
+func (h *HTTPHandler) ListLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.locationService.ListLocations()
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, locations)
+}
+
+func (h *HTTPHandler) GetProductStock(w http.ResponseWriter, r *http.Request) {
+	stock, err := h.locationService.GetProductStock(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, stock)
+}
+
+func (h *HTTPHandler) SetLocationReorderPoint(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var req struct {
+		ReorderPoint int `json:"reorder_point"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	level, err := h.locationService.SetLocationReorderPoint(vars["id"], vars["locationId"], req.ReorderPoint)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, level)
+}
+
+func (h *HTTPHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ProductId      string `json:"product_id"`
+		FromLocationId string `json:"from_location_id"`
+		ToLocationId   string `json:"to_location_id"`
+		Quantity       int    `json:"quantity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	transfer, err := h.locationService.CreateTransfer(r.Context(), req.ProductId, req.FromLocationId, req.ToLocationId, req.Quantity)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, transfer)
+}
+
+func (h *HTTPHandler) ListTransfers(w http.ResponseWriter, r *http.Request) {
+	transfers, err := h.locationService.ListTransfers(r.URL.Query().Get("status"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, transfers)
+}
+
+func (h *HTTPHandler) ReceiveTransfer(w http.ResponseWriter, r *http.Request) {
+	transfer, err := h.locationService.ReceiveTransfer(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, transfer)
+}
+
+func (h *HTTPHandler) CancelTransfer(w http.ResponseWriter, r *http.Request) {
+	transfer, err := h.locationService.CancelTransfer(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, transfer)
+}
+
+func (h *HTTPHandler) CreateBin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code  string `json:"code"`
+		Aisle int    `json:"aisle"`
+		Shelf int    `json:"shelf"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	bin, err := h.binService.CreateBin(mux.Vars(r)["id"], req.Code, req.Aisle, req.Shelf)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, bin)
+}
+
+func (h *HTTPHandler) ListBins(w http.ResponseWriter, r *http.Request) {
+	bins, err := h.binService.ListBins(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, bins)
+}
+
+func (h *HTTPHandler) SetBinStock(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var req struct {
+		Quantity int `json:"quantity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	stock, err := h.binService.SetBinStock(vars["id"], vars["productId"], req.Quantity)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, stock)
+}
+
+func (h *HTTPHandler) GetProductBins(w http.ResponseWriter, r *http.Request) {
+	slots, err := h.binService.ProductBins(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, slots)
+}
+
+func (h *HTTPHandler) CreatePickList(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		LocationId string `json:"location_id"`
+		Items      []struct {
+			ProductId string `json:"product_id"`
+			Quantity  int    `json:"quantity"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	items := make([]domain.PickRequest, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, domain.PickRequest{ProductId: item.ProductId, Quantity: item.Quantity})
+	}
+	list, err := h.binService.GetPickList(req.LocationId, items)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, list)
+}
+
+type apiKeyResponse struct {
+	Id          string
+	Name        string
+	Prefix      string
+	Permissions []domain.Permission
+	ProductIds  []string
+	CreatedBy   string
+	CreatedAt   time.Time
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+func newAPIKeyResponse(key *domain.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		Id:          key.Id,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Permissions: key.Permissions,
+		ProductIds:  key.ProductIds,
+		CreatedBy:   key.CreatedBy,
+		CreatedAt:   key.CreatedAt,
+		ExpiresAt:   key.ExpiresAt,
+		LastUsedAt:  key.LastUsedAt,
+		RevokedAt:   key.RevokedAt,
+	}
+}
+
+// CreateAPIKey returns the full key once; only its prefix can be seen later.
+func (h *HTTPHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name        string     `json:"name"`
+		Permissions []string   `json:"permissions"`
+		ProductIds  []string   `json:"product_ids"`
+		ExpiresAt   *time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	permissions := make([]domain.Permission, 0, len(req.Permissions))
+	for _, permission := range req.Permissions {
+		permissions = append(permissions, domain.Permission(permission))
+	}
+
+	key, raw, err := h.apiKeyService.CreateAPIKey(r.Context(), req.Name, permissions, req.ProductIds, req.ExpiresAt)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, struct {
+		apiKeyResponse
+		Key string
+	}{newAPIKeyResponse(key), raw})
+}
+
+func (h *HTTPHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.ListAPIKeys()
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response := make([]apiKeyResponse, 0, len(keys))
+	for i := range keys {
+		response = append(response, newAPIKeyResponse(&keys[i]))
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *HTTPHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.apiKeyService.RevokeAPIKey(id); err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "api key revoked successfully"})
+}
+
+func (h *HTTPHandler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAuditQuery(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	entries, err := h.auditService.ListAuditEntries(query)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, entries)
+}
+
+// ExportAuditEntries streams the matching entries as JSON Lines, oldest first.
+// Errors found once streaming has started can only cut the export short.
+func (h *HTTPHandler) ExportAuditEntries(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAuditQuery(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	started := false
+	start := func() {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+		w.WriteHeader(http.StatusOK)
+		started = true
+	}
+	encoder := json.NewEncoder(w)
+	err = h.auditService.ExportAuditEntries(query, func(entry domain.AuditEntry) error {
+		if !started {
+			start()
+		}
+		return encoder.Encode(entry)
+	})
+	if err != nil {
+		if !started {
+			h.handleError(w, err)
+			return
+		}
+		log.Printf("audit export stopped early: %v", err)
+		return
+	}
+	if !started {
+		start()
+	}
+}
+
+func parseAuditQuery(r *http.Request) (domain.AuditQuery, error) {
+	values := r.URL.Query()
+	query := domain.AuditQuery{
+		Actor:     values.Get("actor"),
+		Action:    domain.AuditAction(values.Get("action")),
+		ProductId: values.Get("product_id"),
+	}
+
+	var err error
+	if query.From, err = parseTimeParam(r, "from"); err != nil {
+		return query, fmt.Errorf("%w: invalid 'from' parameter, expected RFC3339 time", domain.ErrInvalidQuery)
+	}
+	if query.To, err = parseTimeParam(r, "to"); err != nil {
+		return query, fmt.Errorf("%w: invalid 'to' parameter, expected RFC3339 time", domain.ErrInvalidQuery)
+	}
+	limit, err := parseIntParam(values, "limit")
+	if err != nil {
+		return query, err
+	}
+	if limit != nil {
+		query.Limit = *limit
+	}
+	return query, nil
+}
+
+func parseProductQuery(r *http.Request) (domain.ProductQuery, error) {
+	values := r.URL.Query()
+	query := domain.ProductQuery{
+		NameContains: values.Get("name"),
+		CategoryId:   values.Get("category"),
+		SortBy:       domain.ProductSortField(values.Get("sort")),
+		Cursor:       values.Get("cursor"),
+	}
+
+	var err error
+	if query.MinPrice, err = parseFloatParam(values, "min_price"); err != nil {
+		return query, err
+	}
+	if query.MaxPrice, err = parseFloatParam(values, "max_price"); err != nil {
+		return query, err
+	}
+	if query.MinQuantity, err = parseIntParam(values, "min_quantity"); err != nil {
+		return query, err
+	}
+	if query.MaxQuantity, err = parseIntParam(values, "max_quantity"); err != nil {
+		return query, err
+	}
+	if limit, err := parseIntParam(values, "limit"); err != nil {
+		return query, err
+	} else if limit != nil {
+		if *limit <= 0 {
+			return query, fmt.Errorf("%w: 'limit' must be positive", domain.ErrInvalidQuery)
+		}
+		query.Limit = *limit
+	}
+	if value := values.Get("low_stock"); value != "" {
+		if query.LowStockOnly, err = strconv.ParseBool(value); err != nil {
+			return query, fmt.Errorf("%w: invalid 'low_stock' parameter", domain.ErrInvalidQuery)
+		}
+	}
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, fmt.Errorf("%w: 'order' must be asc or desc", domain.ErrInvalidQuery)
+	}
+	return query, nil
+}
+
+func parseFloatParam(values url.Values, name string) (*float64, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid '%s' parameter", domain.ErrInvalidQuery, name)
+	}
+	return &parsed, nil
+}
+
+func parseIntParam(values url.Values, name string) (*int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid '%s' parameter", domain.ErrInvalidQuery, name)
+	}
+	return &parsed, nil
+}
+
+func parseTimeParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (h *HTTPHandler) respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+func (h *HTTPHandler) respondWithTokens(w http.ResponseWriter, pair *domain.TokenPair) {
+	h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  pair.AccessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(time.Until(pair.AccessExpiresAt).Round(time.Second).Seconds()),
+		"refresh_token": pair.RefreshToken,
+	})
+}
+
+// respondWithLoginResult answers a login that still needs a second factor with
+// the challenge token to send to /login/mfa instead of tokens.
+func (h *HTTPHandler) respondWithLoginResult(w http.ResponseWriter, result *domain.LoginResult) {
+	if result.Tokens != nil {
+		h.respondWithTokens(w, result.Tokens)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"mfa_required": true,
+		"mfa_token":    result.MFAToken,
+		"expires_in":   int(time.Until(result.MFAExpiresAt).Round(time.Second).Seconds()),
+	})
+}
+
+func (h *HTTPHandler) respondWithError(w http.ResponseWriter, code int, message string) {
+	h.respondWithJSON(w, code, map[string]string{"error": message})
+}
+
+func (h *HTTPHandler) handleError(w http.ResponseWriter, err error) {
+	var throttled *domain.LoginThrottledError
+	if errors.As(err, &throttled) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	}
+
 	switch {
-	case errors.Is(err, domain.ErrProductNotFound):
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrAlertNotFound), errors.Is(err, domain.ErrManagerNotFound),
+		errors.Is(err, domain.ErrAPIKeyNotFound), errors.Is(err, domain.ErrCategoryNotFound), errors.Is(err, domain.ErrLocationNotFound),
+		errors.Is(err, domain.ErrTransferNotFound), errors.Is(err, domain.ErrBinNotFound), errors.Is(err, domain.ErrLotNotFound):
 		h.respondWithError(w, http.StatusNotFound, err.Error())
-	case errors.Is(err, domain.ErrInsufficientStock), errors.Is(err, domain.ErrProductInvalid):
+	case errors.Is(err, domain.ErrInsufficientStock), errors.Is(err, domain.ErrProductInvalid), errors.Is(err, domain.ErrAlertInvalid),
+		errors.Is(err, domain.ErrInvalidQuery), errors.Is(err, domain.ErrManagerInvalid), errors.Is(err, domain.ErrMFANotEnrolled),
+		errors.Is(err, domain.ErrAPIKeyInvalid), errors.Is(err, domain.ErrCategoryInvalid), errors.Is(err, domain.ErrLocationInvalid),
+		errors.Is(err, domain.ErrTransferInvalid), errors.Is(err, domain.ErrBinInvalid), errors.Is(err, domain.ErrPickListInvalid),
+		errors.Is(err, domain.ErrLotInvalid):
 		h.respondWithError(w, http.StatusBadRequest, err.Error())
-	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrUnauthorized):
+	case errors.Is(err, domain.ErrConflict), errors.Is(err, domain.ErrManagerExists), errors.Is(err, domain.ErrMFAAlreadyEnabled),
+		errors.Is(err, domain.ErrProductNotDeleted), errors.Is(err, domain.ErrDuplicateSKU), errors.Is(err, domain.ErrDuplicateBarcode),
+		errors.Is(err, domain.ErrCategoryExists), errors.Is(err, domain.ErrCategoryInUse), errors.Is(err, domain.ErrLocationExists),
+		errors.Is(err, domain.ErrTransferNotInTransit), errors.Is(err, domain.ErrBinExists):
+		h.respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrUnauthorized),
+		errors.Is(err, domain.ErrRefreshTokenInvalid), errors.Is(err, domain.ErrRefreshTokenReused),
+		errors.Is(err, domain.ErrMFAChallengeInvalid), errors.Is(err, domain.ErrMFACodeInvalid):
 		h.respondWithError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, domain.ErrForbidden), errors.Is(err, domain.ErrAccountDisabled), errors.Is(err, domain.ErrPasswordChangeRequired):
+		h.respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrTooManyAttempts):
+		h.respondWithError(w, http.StatusTooManyRequests, err.Error())
 	default:
 		h.respondWithError(w, http.StatusInternalServerError, "An internal server error occurred")
 	}
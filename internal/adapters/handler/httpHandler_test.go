@@ -1,87 +1,407 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/amangirdhar210/inventory-manager/config"
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/service"
+	"github.com/amangirdhar210/inventory-manager/utils/auth"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
 type mockInventoryService struct {
-	AddProductFunc         func(name string, price float64, quantity int) (*domain.Product, error)
+	AddProductFunc         func(ctx context.Context, name string, price float64, quantity int, reorderPoint int, reorderQuantity int, details domain.ProductDetails) (*domain.Product, error)
 	GetProductFunc         func(id string) (*domain.Product, error)
-	SellProductUnitsFunc   func(id string, quantity int) (*domain.Product, error)
-	RestockProductFunc     func(id string, quantity int) (*domain.Product, error)
-	DeleteProductFunc      func(id string) error
-	UpdateProductPriceFunc func(id string, newPrice float64) error
-	GetAllProductsFunc     func() ([]domain.Product, error)
-	GetInventoryValueFunc  func() (float64, error)
+	GetProductBySKUFunc    func(sku string) (*domain.Product, error)
+	GetByBarcodeFunc       func(barcode string) (*domain.Product, error)
+	SellProductUnitsFunc   func(ctx context.Context, id string, locationId string, quantity int, expectedVersion int) (*domain.Product, error)
+	RestockProductFunc     func(ctx context.Context, id string, locationId string, quantity int, receipt domain.LotReceipt, expectedVersion int) (*domain.Product, error)
+	AdjustStockFunc        func(ctx context.Context, id string, locationId string, delta int, expectedVersion int) (*domain.Product, error)
+	WriteOffLotFunc        func(ctx context.Context, id string, lotId string, expectedVersion int) (*domain.Product, error)
+	DeleteProductFunc      func(ctx context.Context, id string) error
+	RestoreProductFunc     func(ctx context.Context, id string) (*domain.Product, error)
+	PurgeDeletedFunc       func(ctx context.Context, olderThanDays int) ([]domain.Product, error)
+	UpdateProductPriceFunc func(ctx context.Context, id string, newPrice float64, expectedVersion int) (*domain.Product, error)
+	UpdateDetailsFunc      func(ctx context.Context, id string, details domain.ProductDetails, expectedVersion int) (*domain.Product, error)
+	AssignCategoryFunc     func(ctx context.Context, id string, categoryId string, expectedVersion int) (*domain.Product, error)
+	UpdateThresholdsFunc   func(ctx context.Context, id string, reorderPoint int, reorderQuantity int, expectedVersion int) (*domain.Product, error)
+	ListProductsFunc       func(query domain.ProductQuery) (*domain.ProductPage, error)
+	GetInventoryValueFunc  func(locationId string) (*domain.InventoryValueReport, error)
+	GetStockMovementsFunc  func(id string, from, to time.Time) ([]domain.StockMovement, error)
 }
 
-func (m *mockInventoryService) AddProduct(name string, price float64, quantity int) (*domain.Product, error) {
-	return m.AddProductFunc(name, price, quantity)
+func (m *mockInventoryService) AddProduct(ctx context.Context, name string, price float64, quantity int, reorderPoint int, reorderQuantity int,
+	details domain.ProductDetails) (*domain.Product, error) {
+	return m.AddProductFunc(ctx, name, price, quantity, reorderPoint, reorderQuantity, details)
 }
 func (m *mockInventoryService) GetProduct(id string) (*domain.Product, error) {
 	return m.GetProductFunc(id)
 }
-func (m *mockInventoryService) SellProductUnits(id string, quantity int) (*domain.Product, error) {
-	return m.SellProductUnitsFunc(id, quantity)
+func (m *mockInventoryService) GetProductBySKU(sku string) (*domain.Product, error) {
+	return m.GetProductBySKUFunc(sku)
 }
-func (m *mockInventoryService) RestockProduct(id string, quantity int) (*domain.Product, error) {
-	return m.RestockProductFunc(id, quantity)
+func (m *mockInventoryService) GetProductByBarcode(barcode string) (*domain.Product, error) {
+	return m.GetByBarcodeFunc(barcode)
 }
-func (m *mockInventoryService) DeleteProduct(id string) error {
-	return m.DeleteProductFunc(id)
+func (m *mockInventoryService) SellProductUnits(ctx context.Context, id string, locationId string, quantity int, expectedVersion int) (*domain.Product, error) {
+	return m.SellProductUnitsFunc(ctx, id, locationId, quantity, expectedVersion)
 }
-func (m *mockInventoryService) UpdateProductPrice(id string, newPrice float64) error {
-	return m.UpdateProductPriceFunc(id, newPrice)
+func (m *mockInventoryService) RestockProduct(ctx context.Context, id string, locationId string, quantity int, receipt domain.LotReceipt, expectedVersion int) (*domain.Product, error) {
+	return m.RestockProductFunc(ctx, id, locationId, quantity, receipt, expectedVersion)
 }
-func (m *mockInventoryService) GetAllProducts() ([]domain.Product, error) {
-	return m.GetAllProductsFunc()
+func (m *mockInventoryService) AdjustProductStock(ctx context.Context, id string, locationId string, delta int, expectedVersion int) (*domain.Product, error) {
+	return m.AdjustStockFunc(ctx, id, locationId, delta, expectedVersion)
 }
-func (m *mockInventoryService) GetInventoryValue() (float64, error) {
-	return m.GetInventoryValueFunc()
+func (m *mockInventoryService) WriteOffLot(ctx context.Context, id string, lotId string, expectedVersion int) (*domain.Product, error) {
+	return m.WriteOffLotFunc(ctx, id, lotId, expectedVersion)
+}
+func (m *mockInventoryService) DeleteProduct(ctx context.Context, id string) error {
+	return m.DeleteProductFunc(ctx, id)
+}
+func (m *mockInventoryService) RestoreProduct(ctx context.Context, id string) (*domain.Product, error) {
+	return m.RestoreProductFunc(ctx, id)
+}
+func (m *mockInventoryService) PurgeDeletedProducts(ctx context.Context, olderThanDays int) ([]domain.Product, error) {
+	return m.PurgeDeletedFunc(ctx, olderThanDays)
+}
+func (m *mockInventoryService) UpdateProductPrice(ctx context.Context, id string, newPrice float64, expectedVersion int) (*domain.Product, error) {
+	return m.UpdateProductPriceFunc(ctx, id, newPrice, expectedVersion)
+}
+func (m *mockInventoryService) UpdateProductDetails(ctx context.Context, id string, details domain.ProductDetails, expectedVersion int) (*domain.Product, error) {
+	return m.UpdateDetailsFunc(ctx, id, details, expectedVersion)
+}
+func (m *mockInventoryService) AssignProductCategory(ctx context.Context, id string, categoryId string, expectedVersion int) (*domain.Product, error) {
+	return m.AssignCategoryFunc(ctx, id, categoryId, expectedVersion)
+}
+func (m *mockInventoryService) UpdateReorderThresholds(ctx context.Context, id string, reorderPoint int, reorderQuantity int, expectedVersion int) (*domain.Product, error) {
+	return m.UpdateThresholdsFunc(ctx, id, reorderPoint, reorderQuantity, expectedVersion)
+}
+func (m *mockInventoryService) ListProducts(query domain.ProductQuery) (*domain.ProductPage, error) {
+	return m.ListProductsFunc(query)
+}
+func (m *mockInventoryService) GetInventoryValue(locationId string) (*domain.InventoryValueReport, error) {
+	return m.GetInventoryValueFunc(locationId)
+}
+func (m *mockInventoryService) GetStockMovements(id string, from, to time.Time) ([]domain.StockMovement, error) {
+	return m.GetStockMovementsFunc(id, from, to)
+}
+
+type mockAlertService struct {
+	CheckStockLevelFunc    func(product *domain.Product)
+	CheckLocationStockFunc func(level *domain.StockLevel)
+	ListAlertsFunc         func(status string) ([]domain.Alert, error)
+	AcknowledgeAlertFunc   func(ctx context.Context, id string) (*domain.Alert, error)
+}
+
+func (m *mockAlertService) CheckStockLevel(product *domain.Product) {
+	m.CheckStockLevelFunc(product)
+}
+func (m *mockAlertService) CheckLocationStock(level *domain.StockLevel) {
+	m.CheckLocationStockFunc(level)
+}
+func (m *mockAlertService) ListAlerts(status string) ([]domain.Alert, error) {
+	return m.ListAlertsFunc(status)
+}
+func (m *mockAlertService) AcknowledgeAlert(ctx context.Context, id string) (*domain.Alert, error) {
+	return m.AcknowledgeAlertFunc(ctx, id)
 }
 
 type mockAuthService struct {
-	LoginFunc func(email, password string) (string, error)
+	LoginFunc          func(email, password, clientIP string) (*domain.LoginResult, error)
+	VerifyMFAFunc      func(mfaToken, code, clientIP string) (*domain.TokenPair, error)
+	ChangePasswordFunc func(email, currentPassword, newPassword, code, clientIP string) (*domain.LoginResult, error)
+	RefreshFunc        func(refreshToken string) (*domain.TokenPair, error)
+	LogoutFunc         func(ctx context.Context, refreshToken string) error
+	EnrollTOTPFunc     func(ctx context.Context) (*domain.TOTPEnrollment, error)
+	ConfirmTOTPFunc    func(ctx context.Context, code string) ([]string, error)
+	DisableTOTPFunc    func(ctx context.Context, code string) error
+}
+
+func (m *mockAuthService) Login(email, password, clientIP string) (*domain.LoginResult, error) {
+	return m.LoginFunc(email, password, clientIP)
+}
+func (m *mockAuthService) VerifyMFA(mfaToken, code, clientIP string) (*domain.TokenPair, error) {
+	return m.VerifyMFAFunc(mfaToken, code, clientIP)
+}
+func (m *mockAuthService) ChangePassword(email, currentPassword, newPassword, code, clientIP string) (*domain.LoginResult, error) {
+	return m.ChangePasswordFunc(email, currentPassword, newPassword, code, clientIP)
+}
+func (m *mockAuthService) Refresh(refreshToken string) (*domain.TokenPair, error) {
+	return m.RefreshFunc(refreshToken)
+}
+func (m *mockAuthService) Logout(ctx context.Context, refreshToken string) error {
+	return m.LogoutFunc(ctx, refreshToken)
+}
+func (m *mockAuthService) EnrollTOTP(ctx context.Context) (*domain.TOTPEnrollment, error) {
+	return m.EnrollTOTPFunc(ctx)
+}
+func (m *mockAuthService) ConfirmTOTP(ctx context.Context, code string) ([]string, error) {
+	return m.ConfirmTOTPFunc(ctx, code)
+}
+func (m *mockAuthService) DisableTOTP(ctx context.Context, code string) error {
+	return m.DisableTOTPFunc(ctx, code)
+}
+
+func testTokenPair(accessToken string) *domain.TokenPair {
+	return &domain.TokenPair{AccessToken: accessToken, AccessExpiresAt: time.Now().Add(15 * time.Minute), RefreshToken: "refresh-" + accessToken}
+}
+
+type mockManagerService struct {
+	CreateManagerFunc      func(email, password string, role domain.Role) (*domain.Manager, error)
+	ListManagersFunc       func() ([]domain.Manager, error)
+	SetManagerDisabledFunc func(ctx context.Context, id string, disabled bool) (*domain.Manager, error)
+	ResetPasswordFunc      func(id, newPassword string) (*domain.Manager, error)
+	DeleteManagerFunc      func(ctx context.Context, id string) error
+}
+
+func (m *mockManagerService) CreateManager(email, password string, role domain.Role) (*domain.Manager, error) {
+	return m.CreateManagerFunc(email, password, role)
+}
+func (m *mockManagerService) ListManagers() ([]domain.Manager, error) {
+	return m.ListManagersFunc()
+}
+func (m *mockManagerService) SetManagerDisabled(ctx context.Context, id string, disabled bool) (*domain.Manager, error) {
+	return m.SetManagerDisabledFunc(ctx, id, disabled)
+}
+func (m *mockManagerService) ResetPassword(id, newPassword string) (*domain.Manager, error) {
+	return m.ResetPasswordFunc(id, newPassword)
+}
+func (m *mockManagerService) DeleteManager(ctx context.Context, id string) error {
+	return m.DeleteManagerFunc(ctx, id)
+}
+
+type mockAPIKeyService struct {
+	CreateAPIKeyFunc       func(ctx context.Context, name string, permissions []domain.Permission, productIds []string, expiresAt *time.Time) (*domain.APIKey, string, error)
+	ListAPIKeysFunc        func() ([]domain.APIKey, error)
+	RevokeAPIKeyFunc       func(id string) error
+	AuthenticateAPIKeyFunc func(raw string) (*domain.Principal, error)
+}
+
+func (m *mockAPIKeyService) CreateAPIKey(ctx context.Context, name string, permissions []domain.Permission, productIds []string, expiresAt *time.Time) (*domain.APIKey, string, error) {
+	return m.CreateAPIKeyFunc(ctx, name, permissions, productIds, expiresAt)
+}
+func (m *mockAPIKeyService) ListAPIKeys() ([]domain.APIKey, error) {
+	return m.ListAPIKeysFunc()
+}
+func (m *mockAPIKeyService) RevokeAPIKey(id string) error {
+	return m.RevokeAPIKeyFunc(id)
+}
+func (m *mockAPIKeyService) AuthenticateAPIKey(raw string) (*domain.Principal, error) {
+	return m.AuthenticateAPIKeyFunc(raw)
+}
+
+type mockAuditService struct {
+	ListAuditEntriesFunc   func(query domain.AuditQuery) ([]domain.AuditEntry, error)
+	ExportAuditEntriesFunc func(query domain.AuditQuery, visit func(domain.AuditEntry) error) error
+}
+
+func (m *mockAuditService) ListAuditEntries(query domain.AuditQuery) ([]domain.AuditEntry, error) {
+	return m.ListAuditEntriesFunc(query)
+}
+func (m *mockAuditService) ExportAuditEntries(query domain.AuditQuery, visit func(domain.AuditEntry) error) error {
+	return m.ExportAuditEntriesFunc(query, visit)
+}
+
+type mockCategoryService struct {
+	CreateCategoryFunc func(name, parentId string) (*domain.Category, error)
+	GetCategoryFunc    func(id string) (*domain.Category, error)
+	ListCategoriesFunc func() ([]domain.Category, error)
+	UpdateCategoryFunc func(id, name, parentId string) (*domain.Category, error)
+	DeleteCategoryFunc func(id string) error
+}
+
+func (m *mockCategoryService) CreateCategory(name, parentId string) (*domain.Category, error) {
+	return m.CreateCategoryFunc(name, parentId)
+}
+func (m *mockCategoryService) GetCategory(id string) (*domain.Category, error) {
+	return m.GetCategoryFunc(id)
+}
+func (m *mockCategoryService) ListCategories() ([]domain.Category, error) {
+	return m.ListCategoriesFunc()
+}
+func (m *mockCategoryService) UpdateCategory(id, name, parentId string) (*domain.Category, error) {
+	return m.UpdateCategoryFunc(id, name, parentId)
+}
+func (m *mockCategoryService) DeleteCategory(id string) error {
+	return m.DeleteCategoryFunc(id)
+}
+
+type mockLocationService struct {
+	CreateLocationFunc  func(code, name string, kind domain.LocationKind) (*domain.Location, error)
+	ListLocationsFunc   func() ([]domain.Location, error)
+	GetProductStockFunc func(productId string) (*domain.ProductStock, error)
+	SetReorderPointFunc func(productId, locationId string, reorderPoint int) (*domain.StockLevel, error)
+	CreateTransferFunc  func(ctx context.Context, productId, fromLocationId, toLocationId string, quantity int) (*domain.Transfer, error)
+	ReceiveTransferFunc func(id string) (*domain.Transfer, error)
+	CancelTransferFunc  func(id string) (*domain.Transfer, error)
+	ListTransfersFunc   func(status string) ([]domain.Transfer, error)
+}
+
+func (m *mockLocationService) CreateLocation(code, name string, kind domain.LocationKind) (*domain.Location, error) {
+	return m.CreateLocationFunc(code, name, kind)
+}
+func (m *mockLocationService) ListLocations() ([]domain.Location, error) {
+	return m.ListLocationsFunc()
+}
+func (m *mockLocationService) GetProductStock(productId string) (*domain.ProductStock, error) {
+	return m.GetProductStockFunc(productId)
+}
+func (m *mockLocationService) SetLocationReorderPoint(productId, locationId string, reorderPoint int) (*domain.StockLevel, error) {
+	return m.SetReorderPointFunc(productId, locationId, reorderPoint)
+}
+func (m *mockLocationService) CreateTransfer(ctx context.Context, productId, fromLocationId, toLocationId string, quantity int) (*domain.Transfer, error) {
+	return m.CreateTransferFunc(ctx, productId, fromLocationId, toLocationId, quantity)
+}
+func (m *mockLocationService) ReceiveTransfer(id string) (*domain.Transfer, error) {
+	return m.ReceiveTransferFunc(id)
+}
+func (m *mockLocationService) CancelTransfer(id string) (*domain.Transfer, error) {
+	return m.CancelTransferFunc(id)
+}
+func (m *mockLocationService) ListTransfers(status string) ([]domain.Transfer, error) {
+	return m.ListTransfersFunc(status)
+}
+
+type mockBinService struct {
+	CreateBinFunc   func(locationId, code string, aisle, shelf int) (*domain.Bin, error)
+	ListBinsFunc    func(locationId string) ([]domain.Bin, error)
+	SetBinStockFunc func(binId, productId string, quantity int) (*domain.BinStock, error)
+	ProductBinsFunc func(productId string) ([]domain.BinSlot, error)
+	GetPickListFunc func(locationId string, items []domain.PickRequest) (*domain.PickList, error)
+}
+
+func (m *mockBinService) CreateBin(locationId, code string, aisle, shelf int) (*domain.Bin, error) {
+	return m.CreateBinFunc(locationId, code, aisle, shelf)
+}
+func (m *mockBinService) ListBins(locationId string) ([]domain.Bin, error) {
+	return m.ListBinsFunc(locationId)
+}
+func (m *mockBinService) SetBinStock(binId, productId string, quantity int) (*domain.BinStock, error) {
+	return m.SetBinStockFunc(binId, productId, quantity)
+}
+func (m *mockBinService) ProductBins(productId string) ([]domain.BinSlot, error) {
+	return m.ProductBinsFunc(productId)
+}
+func (m *mockBinService) GetPickList(locationId string, items []domain.PickRequest) (*domain.PickList, error) {
+	return m.GetPickListFunc(locationId, items)
+}
+
+type mockLotService struct {
+	ListLotsFunc     func(productId string) ([]domain.Lot, error)
+	ExpiringLotsFunc func(locationId string, within time.Duration) ([]domain.ExpiringLot, error)
 }
 
-func (m *mockAuthService) Login(email, password string) (string, error) {
-	return m.LoginFunc(email, password)
+func (m *mockLotService) ListLots(productId string) ([]domain.Lot, error) {
+	return m.ListLotsFunc(productId)
+}
+func (m *mockLotService) ExpiringLots(locationId string, within time.Duration) ([]domain.ExpiringLot, error) {
+	return m.ExpiringLotsFunc(locationId, within)
 }
+func (m *mockLotService) NotifyExpiringLots() (int, error) {
+	return 0, nil
+}
+
+const testJWTSecret = "handler-test-secret"
+
+var testRevocations = auth.NewMemoryRevocationStore()
+
+var testKeyRing, _ = auth.NewKeyRing(auth.NewHMACKey("test", []byte(testJWTSecret)))
+
+var testTokenValidator = auth.NewRevokingValidator(auth.NewJWTGenerator(testKeyRing, time.Hour, 5*time.Minute), testRevocations)
 
 func getTestToken() string {
-	claims := &jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 1)),
+	return getTestTokenWithRole(domain.RoleAdmin)
+}
+
+func getTestTokenWithRole(role domain.Role) string {
+	claims := jwt.MapClaims{
+		"sub":  "manager-123",
+		"role": string(role),
+		"jti":  uuid.NewString(),
+		"iss":  auth.TokenIssuer,
+		"aud":  auth.TokenAudience,
+		"exp":  jwt.NewNumericDate(time.Now().Add(time.Hour * 1)),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, _ := token.SignedString([]byte(config.JWTSecretKey))
+	token.Header["kid"] = "test"
+	signedToken, _ := token.SignedString([]byte(testJWTSecret))
 	return signedToken
 }
 
 func newTestRouter(handler *HTTPHandler) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(handler.RequestMiddleware)
 	router.HandleFunc("/login", handler.Login).Methods("POST")
-	router.HandleFunc("/logout", handler.Logout).Methods("POST")
+	router.Handle("/logout", handler.AuthMiddleware(http.HandlerFunc(handler.Logout))).Methods("POST")
+	router.HandleFunc("/login/mfa", handler.VerifyMFA).Methods("POST")
+	router.HandleFunc("/login/password", handler.ChangePassword).Methods("POST")
+	router.HandleFunc("/token/refresh", handler.RefreshToken).Methods("POST")
 
 	apiRouter := router.PathPrefix("/api").Subrouter()
 	apiRouter.Use(handler.AuthMiddleware)
-	apiRouter.HandleFunc("/products", handler.AddProduct).Methods("POST")
-	apiRouter.HandleFunc("/products", handler.GetAllProducts).Methods("GET")
-	apiRouter.HandleFunc("/products/{id}", handler.GetProduct).Methods("GET")
-	apiRouter.HandleFunc("/products/{id}", handler.DeleteProduct).Methods("DELETE")
-	apiRouter.HandleFunc("/products/{id}/sell", handler.SellProductUnits).Methods("POST")
-	apiRouter.HandleFunc("/products/{id}/restock", handler.RestockProduct).Methods("POST")
-	apiRouter.HandleFunc("/products/{id}/price", handler.UpdateProductPrice).Methods("PUT")
-	apiRouter.HandleFunc("/inventory/value", handler.GetInventoryValue).Methods("GET")
+	apiRouter.HandleFunc("/mfa/totp", handler.EnrollTOTP).Methods("POST")
+	apiRouter.HandleFunc("/mfa/totp/verify", handler.ConfirmTOTP).Methods("POST")
+	apiRouter.HandleFunc("/mfa/totp/disable", handler.DisableTOTP).Methods("POST")
+	apiRouter.HandleFunc("/products", handler.RequirePermission(domain.PermProductsWrite, handler.AddProduct)).Methods("POST")
+	apiRouter.HandleFunc("/products/purge", handler.RequirePermission(domain.PermProductsPurge, handler.PurgeDeletedProducts)).Methods("POST")
+	apiRouter.HandleFunc("/products", handler.RequirePermission(domain.PermProductsRead, handler.ListProducts)).Methods("GET")
+	apiRouter.HandleFunc("/products/by-sku/{sku}", handler.RequireCatalogPermission(domain.PermProductsRead, handler.GetProductBySKU)).Methods("GET")
+	apiRouter.HandleFunc("/products/by-barcode/{code}", handler.RequireCatalogPermission(domain.PermProductsRead, handler.GetProductByBarcode)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}", handler.RequireProductPermission(domain.PermProductsRead, handler.GetProduct)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}", handler.RequireProductPermission(domain.PermProductsDelete, handler.DeleteProduct)).Methods("DELETE")
+	apiRouter.HandleFunc("/products/{id}/restore", handler.RequireProductPermission(domain.PermProductsDelete, handler.RestoreProduct)).Methods("POST")
+	apiRouter.HandleFunc("/products/{id}/sell", handler.RequireProductPermission(domain.PermStockSell, handler.SellProductUnits)).Methods("POST")
+	apiRouter.HandleFunc("/products/{id}/restock", handler.RequireProductPermission(domain.PermStockRestock, handler.RestockProduct)).Methods("POST")
+	apiRouter.HandleFunc("/products/{id}/adjust", handler.RequireProductPermission(domain.PermStockAdjust, handler.AdjustProductStock)).Methods("POST")
+	apiRouter.HandleFunc("/products/{id}/movements", handler.RequireProductPermission(domain.PermReportsRead, handler.GetStockMovements)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}/stock", handler.RequireProductPermission(domain.PermProductsRead, handler.GetProductStock)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}/stock/{locationId}", handler.RequireProductPermission(domain.PermProductsWrite, handler.SetLocationReorderPoint)).Methods("PUT")
+	apiRouter.HandleFunc("/products/{id}/bins", handler.RequireProductPermission(domain.PermProductsRead, handler.GetProductBins)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}/lots", handler.RequireProductPermission(domain.PermProductsRead, handler.GetProductLots)).Methods("GET")
+	apiRouter.HandleFunc("/products/{id}/lots/{lotId}/write-off", handler.RequireProductPermission(domain.PermStockAdjust, handler.WriteOffLot)).Methods("POST")
+	apiRouter.HandleFunc("/products/{id}/price", handler.RequireProductPermission(domain.PermProductsWrite, handler.UpdateProductPrice)).Methods("PUT")
+	apiRouter.HandleFunc("/products/{id}/details", handler.RequireProductPermission(domain.PermProductsWrite, handler.UpdateProductDetails)).Methods("PUT")
+	apiRouter.HandleFunc("/products/{id}/category", handler.RequireProductPermission(domain.PermProductsWrite, handler.AssignProductCategory)).Methods("PUT")
+	apiRouter.HandleFunc("/products/{id}/thresholds", handler.RequireProductPermission(domain.PermProductsWrite, handler.UpdateReorderThresholds)).Methods("PUT")
+	apiRouter.HandleFunc("/inventory/value", handler.RequirePermission(domain.PermReportsRead, handler.GetInventoryValue)).Methods("GET")
+	apiRouter.HandleFunc("/inventory/expiring", handler.RequirePermission(domain.PermReportsRead, handler.GetExpiringLots)).Methods("GET")
+	apiRouter.HandleFunc("/categories", handler.RequirePermission(domain.PermProductsWrite, handler.CreateCategory)).Methods("POST")
+	apiRouter.HandleFunc("/categories", handler.RequirePermission(domain.PermProductsRead, handler.ListCategories)).Methods("GET")
+	apiRouter.HandleFunc("/categories/{id}", handler.RequirePermission(domain.PermProductsRead, handler.GetCategory)).Methods("GET")
+	apiRouter.HandleFunc("/categories/{id}", handler.RequirePermission(domain.PermProductsWrite, handler.UpdateCategory)).Methods("PUT")
+	apiRouter.HandleFunc("/categories/{id}", handler.RequirePermission(domain.PermProductsWrite, handler.DeleteCategory)).Methods("DELETE")
+	apiRouter.HandleFunc("/locations", handler.RequirePermission(domain.PermLocationsManage, handler.CreateLocation)).Methods("POST")
+	apiRouter.HandleFunc("/locations", handler.RequirePermission(domain.PermProductsRead, handler.ListLocations)).Methods("GET")
+	apiRouter.HandleFunc("/locations/{id}/bins", handler.RequirePermission(domain.PermLocationsManage, handler.CreateBin)).Methods("POST")
+	apiRouter.HandleFunc("/locations/{id}/bins", handler.RequirePermission(domain.PermProductsRead, handler.ListBins)).Methods("GET")
+	apiRouter.HandleFunc("/bins/{id}/products/{productId}", handler.RequirePermission(domain.PermStockTransfer, handler.SetBinStock)).Methods("PUT")
+	apiRouter.HandleFunc("/pick-lists", handler.RequirePermission(domain.PermStockSell, handler.CreatePickList)).Methods("POST")
+	apiRouter.HandleFunc("/transfers", handler.RequirePermission(domain.PermStockTransfer, handler.CreateTransfer)).Methods("POST")
+	apiRouter.HandleFunc("/transfers", handler.RequirePermission(domain.PermStockTransfer, handler.ListTransfers)).Methods("GET")
+	apiRouter.HandleFunc("/transfers/{id}/receive", handler.RequirePermission(domain.PermStockTransfer, handler.ReceiveTransfer)).Methods("POST")
+	apiRouter.HandleFunc("/transfers/{id}/cancel", handler.RequirePermission(domain.PermStockTransfer, handler.CancelTransfer)).Methods("POST")
+	apiRouter.HandleFunc("/alerts", handler.RequirePermission(domain.PermAlertsRead, handler.ListAlerts)).Methods("GET")
+	apiRouter.HandleFunc("/alerts/{id}/ack", handler.RequirePermission(domain.PermAlertsAck, handler.AcknowledgeAlert)).Methods("POST")
+	apiRouter.HandleFunc("/managers", handler.RequirePermission(domain.PermManagersManage, handler.CreateManager)).Methods("POST")
+	apiRouter.HandleFunc("/managers", handler.RequirePermission(domain.PermManagersManage, handler.ListManagers)).Methods("GET")
+	apiRouter.HandleFunc("/managers/{id}", handler.RequirePermission(domain.PermManagersManage, handler.DeleteManager)).Methods("DELETE")
+	apiRouter.HandleFunc("/managers/{id}/disable", handler.RequirePermission(domain.PermManagersManage, handler.DisableManager)).Methods("POST")
+	apiRouter.HandleFunc("/managers/{id}/enable", handler.RequirePermission(domain.PermManagersManage, handler.EnableManager)).Methods("POST")
+	apiRouter.HandleFunc("/managers/{id}/password", handler.RequirePermission(domain.PermManagersManage, handler.ResetManagerPassword)).Methods("PUT")
+	apiRouter.HandleFunc("/keys", handler.RequirePermission(domain.PermManagersManage, handler.CreateAPIKey)).Methods("POST")
+	apiRouter.HandleFunc("/keys", handler.RequirePermission(domain.PermManagersManage, handler.ListAPIKeys)).Methods("GET")
+	apiRouter.HandleFunc("/keys/{id}", handler.RequirePermission(domain.PermManagersManage, handler.RevokeAPIKey)).Methods("DELETE")
+	apiRouter.HandleFunc("/audit", handler.RequirePermission(domain.PermAuditRead, handler.ListAuditEntries)).Methods("GET")
+	apiRouter.HandleFunc("/audit/export", handler.RequirePermission(domain.PermAuditRead, handler.ExportAuditEntries)).Methods("GET")
 
 	return router
 }
@@ -93,29 +413,67 @@ func TestHTTPHandler_Login(t *testing.T) {
 		setupMock      func(*mockAuthService)
 		wantStatusCode int
 		wantBody       string
+		wantRetryAfter string
 	}{
 		{
 			name:    "success",
 			reqBody: `{"email":"test@example.com","password":"password123"}`,
 			setupMock: func(m *mockAuthService) {
-				m.LoginFunc = func(email, password string) (string, error) {
-					return "fake-jwt-token", nil
+				m.LoginFunc = func(email, password, clientIP string) (*domain.LoginResult, error) {
+					if clientIP != "192.0.2.1" {
+						return nil, fmt.Errorf("unexpected client IP %q", clientIP)
+					}
+					return &domain.LoginResult{Tokens: testTokenPair("fake-jwt-token")}, nil
+				}
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `"access_token":"fake-jwt-token","expires_in":900,"refresh_token":"refresh-fake-jwt-token","token_type":"Bearer"`,
+		},
+		{
+			name:    "success_mfa_required",
+			reqBody: `{"email":"mfa@example.com","password":"password123"}`,
+			setupMock: func(m *mockAuthService) {
+				m.LoginFunc = func(email, password, clientIP string) (*domain.LoginResult, error) {
+					return &domain.LoginResult{MFAToken: "challenge-token", MFAExpiresAt: time.Now().Add(5 * time.Minute)}, nil
 				}
 			},
 			wantStatusCode: http.StatusOK,
-			wantBody:       `"token":"fake-jwt-token"`,
+			wantBody:       `{"expires_in":300,"mfa_required":true,"mfa_token":"challenge-token"}`,
 		},
 		{
 			name:    "fail_invalid_credentials",
 			reqBody: `{"email":"wrong@example.com","password":"wrong"}`,
 			setupMock: func(m *mockAuthService) {
-				m.LoginFunc = func(email, password string) (string, error) {
-					return "", domain.ErrInvalidCredentials
+				m.LoginFunc = func(email, password, clientIP string) (*domain.LoginResult, error) {
+					return nil, domain.ErrInvalidCredentials
 				}
 			},
 			wantStatusCode: http.StatusUnauthorized,
 			wantBody:       domain.ErrInvalidCredentials.Error(),
 		},
+		{
+			name:    "fail_password_change_required",
+			reqBody: `{"email":"new@example.com","password":"temporary-password"}`,
+			setupMock: func(m *mockAuthService) {
+				m.LoginFunc = func(email, password, clientIP string) (*domain.LoginResult, error) {
+					return nil, domain.ErrPasswordChangeRequired
+				}
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantBody:       domain.ErrPasswordChangeRequired.Error(),
+		},
+		{
+			name:    "fail_throttled",
+			reqBody: `{"email":"test@example.com","password":"guess"}`,
+			setupMock: func(m *mockAuthService) {
+				m.LoginFunc = func(email, password, clientIP string) (*domain.LoginResult, error) {
+					return nil, &domain.LoginThrottledError{RetryAfter: 90*time.Second + time.Millisecond}
+				}
+			},
+			wantStatusCode: http.StatusTooManyRequests,
+			wantBody:       domain.ErrTooManyAttempts.Error(),
+			wantRetryAfter: "91",
+		},
 		{
 			name:           "fail_invalid_body",
 			reqBody:        `{"email":"bad"`,
@@ -128,7 +486,7 @@ func TestHTTPHandler_Login(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockAuth := &mockAuthService{}
 			tt.setupMock(mockAuth)
-			handler := NewHTTPHandler(nil, mockAuth)
+			handler := NewHTTPHandler(Services{Auth: mockAuth}, testTokenValidator)
 			router := newTestRouter(handler)
 
 			req := httptest.NewRequest("POST", "/login", strings.NewReader(tt.reqBody))
@@ -141,6 +499,9 @@ func TestHTTPHandler_Login(t *testing.T) {
 			if !strings.Contains(rr.Body.String(), tt.wantBody) {
 				t.Errorf("body does not contain %q, got %q", tt.wantBody, rr.Body.String())
 			}
+			if got := rr.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
 		})
 	}
 }
@@ -148,10 +509,10 @@ func TestHTTPHandler_Login(t *testing.T) {
 func TestHTTPHandler_ProductEndpoints_Auth(t *testing.T) {
 	mockInventory := &mockInventoryService{
 		GetProductFunc: func(id string) (*domain.Product, error) {
-			return &domain.Product{Id: "prod-123"}, nil
+			return &domain.Product{Id: "prod-123", Version: 3}, nil
 		},
 	}
-	handler := NewHTTPHandler(mockInventory, nil)
+	handler := NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator)
 	router := newTestRouter(handler)
 
 	t.Run("success_with_valid_token", func(t *testing.T) {
@@ -163,6 +524,9 @@ func TestHTTPHandler_ProductEndpoints_Auth(t *testing.T) {
 		if rr.Code != http.StatusOK {
 			t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
 		}
+		if etag := rr.Header().Get("ETag"); etag != `"3"` {
+			t.Errorf("got ETag %q, want %q", etag, `"3"`)
+		}
 	})
 
 	t.Run("fail_without_token", func(t *testing.T) {
@@ -185,6 +549,287 @@ func TestHTTPHandler_ProductEndpoints_Auth(t *testing.T) {
 			t.Errorf("got status %d, want %d", rr.Code, http.StatusUnauthorized)
 		}
 	})
+
+	t.Run("fail_with_token_without_role", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/products/prod-123", nil)
+		req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(""))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestHTTPHandler_RequirePermission(t *testing.T) {
+	product := &domain.Product{Id: "prod-123", Quantity: 10, Version: 1}
+	mockInventory := &mockInventoryService{
+		GetProductFunc: func(id string) (*domain.Product, error) { return product, nil },
+		SellProductUnitsFunc: func(ctx context.Context, id string, locationId string, quantity int, expectedVersion int) (*domain.Product, error) {
+			return product, nil
+		},
+		UpdateProductPriceFunc: func(ctx context.Context, id string, newPrice float64, expectedVersion int) (*domain.Product, error) {
+			return product, nil
+		},
+		DeleteProductFunc: func(ctx context.Context, id string) error { return nil },
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		role           domain.Role
+		method         string
+		path           string
+		body           string
+		wantStatusCode int
+	}{
+		{"read_only_can_read", domain.RoleReadOnly, "GET", "/api/products/prod-123", "", http.StatusOK},
+		{"read_only_cannot_sell", domain.RoleReadOnly, "POST", "/api/products/prod-123/sell", `{"quantity": 1}`, http.StatusForbidden},
+		{"clerk_can_sell", domain.RoleClerk, "POST", "/api/products/prod-123/sell", `{"quantity": 1}`, http.StatusOK},
+		{"clerk_cannot_change_price", domain.RoleClerk, "PUT", "/api/products/prod-123/price", `{"price": 9.99}`, http.StatusForbidden},
+		{"clerk_cannot_delete", domain.RoleClerk, "DELETE", "/api/products/prod-123", "", http.StatusForbidden},
+		{"manager_can_change_price", domain.RoleManager, "PUT", "/api/products/prod-123/price", `{"price": 9.99}`, http.StatusOK},
+		{"manager_can_delete", domain.RoleManager, "DELETE", "/api/products/prod-123", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+			if tt.wantStatusCode == http.StatusForbidden && !strings.Contains(rr.Body.String(), domain.ErrForbidden.Error()) {
+				t.Errorf("body does not mention %q, got %q", domain.ErrForbidden.Error(), rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_APIKeyAuth(t *testing.T) {
+	product := &domain.Product{Id: "prod-123", Quantity: 10, Version: 1}
+	mockInventory := &mockInventoryService{
+		SellProductUnitsFunc: func(ctx context.Context, id string, locationId string, quantity int, expectedVersion int) (*domain.Product, error) {
+			if actor := domain.ActorFromContext(ctx); actor != "api-key:key-1" {
+				t.Errorf("SellProductUnits() actor = %q", actor)
+			}
+			return product, nil
+		},
+		ListProductsFunc: func(query domain.ProductQuery) (*domain.ProductPage, error) {
+			return &domain.ProductPage{}, nil
+		},
+	}
+	mockKeys := &mockAPIKeyService{
+		AuthenticateAPIKeyFunc: func(raw string) (*domain.Principal, error) {
+			if raw != "ik_abc.secret" {
+				return nil, domain.ErrUnauthorized
+			}
+			return &domain.Principal{APIKeyId: "key-1", Permissions: []domain.Permission{domain.PermStockSell, domain.PermProductsRead},
+				ProductIds: []string{"prod-123"}}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockInventory, APIKeys: mockKeys}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		apiKey         string
+		method         string
+		path           string
+		body           string
+		wantStatusCode int
+	}{
+		{"key_can_sell_scoped_product", "ik_abc.secret", "POST", "/api/products/prod-123/sell", `{"quantity": 1}`, http.StatusOK},
+		{"key_cannot_sell_other_product", "ik_abc.secret", "POST", "/api/products/prod-999/sell", `{"quantity": 1}`, http.StatusForbidden},
+		{"key_cannot_restock", "ik_abc.secret", "POST", "/api/products/prod-123/restock", `{"quantity": 1}`, http.StatusForbidden},
+		{"scoped_key_cannot_list_products", "ik_abc.secret", "GET", "/api/products", "", http.StatusForbidden},
+		{"key_cannot_manage_keys", "ik_abc.secret", "GET", "/api/keys", "", http.StatusForbidden},
+		{"unknown_key", "ik_abc.wrong", "POST", "/api/products/prod-123/sell", `{"quantity": 1}`, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("X-API-Key", tt.apiKey)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_APIKeys(t *testing.T) {
+	key := &domain.APIKey{Id: "key-1", Name: "till 1", Prefix: "ik_abc", SecretHash: "hash",
+		Permissions: []domain.Permission{domain.PermStockSell}, CreatedAt: time.Now()}
+	mockKeys := &mockAPIKeyService{
+		CreateAPIKeyFunc: func(ctx context.Context, name string, permissions []domain.Permission, productIds []string, expiresAt *time.Time) (*domain.APIKey, string, error) {
+			if len(permissions) == 0 || permissions[0] == domain.PermManagersManage {
+				return nil, "", domain.ErrAPIKeyInvalid
+			}
+			return key, "ik_abc.secret", nil
+		},
+		ListAPIKeysFunc: func() ([]domain.APIKey, error) { return []domain.APIKey{*key}, nil },
+		RevokeAPIKeyFunc: func(id string) error {
+			if id != key.Id {
+				return domain.ErrAPIKeyNotFound
+			}
+			return nil
+		},
+	}
+	handler := NewHTTPHandler(Services{APIKeys: mockKeys}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		role           domain.Role
+		method         string
+		path           string
+		body           string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"create", domain.RoleAdmin, "POST", "/api/keys", `{"name": "till 1", "permissions": ["stock:sell"]}`, http.StatusCreated, `"Key":"ik_abc.secret"`},
+		{"create_invalid", domain.RoleAdmin, "POST", "/api/keys", `{"name": "till 1", "permissions": ["managers:manage"]}`, http.StatusBadRequest, "api key data is invalid"},
+		{"list_hides_secret", domain.RoleAdmin, "GET", "/api/keys", "", http.StatusOK, `"Prefix":"ik_abc"`},
+		{"revoke", domain.RoleAdmin, "DELETE", "/api/keys/key-1", "", http.StatusOK, "revoked"},
+		{"revoke_not_found", domain.RoleAdmin, "DELETE", "/api/keys/missing", "", http.StatusNotFound, "api key not found"},
+		{"manager_forbidden", domain.RoleManager, "GET", "/api/keys", "", http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Fatalf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) || strings.Contains(rr.Body.String(), "SecretHash") {
+				t.Errorf("body = %q, want it to contain %q", rr.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_RequestMetaReachesServices(t *testing.T) {
+	var meta domain.RequestMeta
+	mockInventory := &mockInventoryService{
+		UpdateProductPriceFunc: func(ctx context.Context, id string, newPrice float64, expectedVersion int) (*domain.Product, error) {
+			meta, _ = domain.RequestMetaFromContext(ctx)
+			return &domain.Product{Id: id, Price: newPrice, Version: 2}, nil
+		},
+	}
+	router := newTestRouter(NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator))
+
+	tests := []struct {
+		name      string
+		requestId string
+		wantId    string
+	}{
+		{"caller_request_id", "trace-42", "trace-42"},
+		{"generated_request_id", "", ""},
+		{"oversized_request_id_replaced", strings.Repeat("x", 200), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/api/products/prod-123/price", strings.NewReader(`{"price": 9.99}`))
+			req.Header.Set("Authorization", "Bearer "+getTestToken())
+			req.RemoteAddr = "192.0.2.7:5555"
+			if tt.requestId != "" {
+				req.Header.Set("X-Request-Id", tt.requestId)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200 (body %q)", rr.Code, rr.Body.String())
+			}
+			if meta.ClientIP != "192.0.2.7" || meta.Id == "" || meta.Id != rr.Header().Get("X-Request-Id") {
+				t.Errorf("request meta = %+v, response id %q", meta, rr.Header().Get("X-Request-Id"))
+			}
+			if tt.wantId != "" && meta.Id != tt.wantId {
+				t.Errorf("request id = %q, want %q", meta.Id, tt.wantId)
+			}
+			if tt.wantId == "" && meta.Id == tt.requestId {
+				t.Errorf("request id %q was not replaced", meta.Id)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_AuditEntries(t *testing.T) {
+	entries := []domain.AuditEntry{
+		{Id: "a1", Action: domain.AuditProductCreated, ProductId: "prod-1", Actor: "manager-1", After: &domain.Product{Id: "prod-1"}},
+		{Id: "a2", Action: domain.AuditProductDeleted, ProductId: "prod-1", Actor: "manager-1", Before: &domain.Product{Id: "prod-1"}},
+	}
+	var gotQuery domain.AuditQuery
+	mockAudit := &mockAuditService{
+		ListAuditEntriesFunc: func(query domain.AuditQuery) ([]domain.AuditEntry, error) {
+			gotQuery = query
+			return entries, nil
+		},
+		ExportAuditEntriesFunc: func(query domain.AuditQuery, visit func(domain.AuditEntry) error) error {
+			gotQuery = query
+			if query.Action == "bogus" {
+				return domain.ErrInvalidQuery
+			}
+			for _, entry := range entries {
+				if err := visit(entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	router := newTestRouter(NewHTTPHandler(Services{Audit: mockAudit}, testTokenValidator))
+
+	tests := []struct {
+		name           string
+		role           domain.Role
+		path           string
+		wantStatusCode int
+		wantLines      int
+		wantQuery      domain.AuditQuery
+	}{
+		{"list_filtered", domain.RoleAdmin, "/api/audit?actor=manager-1&product_id=prod-1&limit=5", http.StatusOK, 1,
+			domain.AuditQuery{Actor: "manager-1", ProductId: "prod-1", Limit: 5}},
+		{"list_bad_from", domain.RoleAdmin, "/api/audit?from=yesterday", http.StatusBadRequest, 1, domain.AuditQuery{}},
+		{"export_jsonl", domain.RoleAdmin, "/api/audit/export?action=product.deleted", http.StatusOK, 2,
+			domain.AuditQuery{Action: domain.AuditProductDeleted}},
+		{"export_invalid_query", domain.RoleAdmin, "/api/audit/export?action=bogus", http.StatusBadRequest, 1, domain.AuditQuery{Action: "bogus"}},
+		{"manager_forbidden", domain.RoleManager, "/api/audit", http.StatusForbidden, 1, domain.AuditQuery{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQuery = domain.AuditQuery{}
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Fatalf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+			if gotQuery != tt.wantQuery {
+				t.Errorf("query = %+v, want %+v", gotQuery, tt.wantQuery)
+			}
+			if lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n"); len(lines) != tt.wantLines {
+				t.Errorf("got %d lines, want %d: %q", len(lines), tt.wantLines, rr.Body.String())
+			}
+		})
+	}
 }
 
 func TestHTTPHandler_GetProduct(t *testing.T) {
@@ -194,7 +839,7 @@ func TestHTTPHandler_GetProduct(t *testing.T) {
 				return nil, domain.ErrProductNotFound
 			},
 		}
-		handler := NewHTTPHandler(mockInventory, nil)
+		handler := NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator)
 		router := newTestRouter(handler)
 
 		req := httptest.NewRequest("GET", "/api/products/prod-456", nil)
@@ -214,11 +859,11 @@ func TestHTTPHandler_GetProduct(t *testing.T) {
 func TestHTTPHandler_SellProductUnits(t *testing.T) {
 	t.Run("fail_insufficient_stock", func(t *testing.T) {
 		mockInventory := &mockInventoryService{
-			SellProductUnitsFunc: func(id string, quantity int) (*domain.Product, error) {
+			SellProductUnitsFunc: func(ctx context.Context, id string, locationId string, quantity int, expectedVersion int) (*domain.Product, error) {
 				return nil, domain.ErrInsufficientStock
 			},
 		}
-		handler := NewHTTPHandler(mockInventory, nil)
+		handler := NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator)
 		router := newTestRouter(handler)
 
 		reqBody := `{"quantity": 50}`
@@ -234,19 +879,41 @@ func TestHTTPHandler_SellProductUnits(t *testing.T) {
 			t.Errorf("body does not contain %q", domain.ErrInsufficientStock.Error())
 		}
 	})
+
+	t.Run("at_location", func(t *testing.T) {
+		var gotLocation string
+		mockInventory := &mockInventoryService{
+			SellProductUnitsFunc: func(ctx context.Context, id string, locationId string, quantity int, expectedVersion int) (*domain.Product, error) {
+				gotLocation = locationId
+				return &domain.Product{Id: id, Version: 2}, nil
+			},
+		}
+		router := newTestRouter(NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator))
+
+		req := httptest.NewRequest("POST", "/api/products/prod-123/sell", strings.NewReader(`{"quantity": 1, "location_id": "store-1"}`))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK || gotLocation != "store-1" {
+			t.Errorf("got status %d with location %q, want 200 at store-1", rr.Code, gotLocation)
+		}
+	})
 }
 
 func TestHTTPHandler_AddProduct(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockInventory := &mockInventoryService{
-			AddProductFunc: func(name string, price float64, quantity int) (*domain.Product, error) {
-				return &domain.Product{Id: "new-id", Name: name, Price: price, Quantity: quantity}, nil
+			AddProductFunc: func(ctx context.Context, name string, price float64, quantity int, reorderPoint int, reorderQuantity int,
+				details domain.ProductDetails) (*domain.Product, error) {
+				return &domain.Product{Id: "new-id", Name: name, Price: price, Quantity: quantity,
+					ReorderPoint: reorderPoint, ReorderQuantity: reorderQuantity, SKU: details.SKU, Unit: details.Unit}, nil
 			},
 		}
-		handler := NewHTTPHandler(mockInventory, nil)
+		handler := NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator)
 		router := newTestRouter(handler)
 
-		reqBody := `{"name":"Test Laptop","price":1500.50,"quantity":10}`
+		reqBody := `{"name":"Test Laptop","price":1500.50,"quantity":10,"reorder_point":2,"reorder_quantity":5,"sku":"LAP-1","unit":"each"}`
 		req := httptest.NewRequest("POST", "/api/products", strings.NewReader(reqBody))
 		req.Header.Set("Authorization", "Bearer "+getTestToken())
 		rr := httptest.NewRecorder()
@@ -260,59 +927,179 @@ func TestHTTPHandler_AddProduct(t *testing.T) {
 		if product.Id != "new-id" {
 			t.Errorf("expected product id to be 'new-id', got %s", product.Id)
 		}
+		if product.ReorderPoint != 2 || product.ReorderQuantity != 5 {
+			t.Errorf("expected reorder thresholds 2/5, got %d/%d", product.ReorderPoint, product.ReorderQuantity)
+		}
+		if product.SKU != "LAP-1" || product.Unit != domain.UnitEach {
+			t.Errorf("expected catalog fields to reach the service, got sku %q unit %q", product.SKU, product.Unit)
+		}
 	})
-}
 
-func TestHTTPHandler_GetAllProducts(t *testing.T) {
-	tests := []struct {
-		name           string
-		setupMock      func(*mockInventoryService)
-		wantStatusCode int
-		wantBody       string
-	}{
-		{
-			name: "success_with_products",
-			setupMock: func(m *mockInventoryService) {
-				m.GetAllProductsFunc = func() ([]domain.Product, error) {
-					return []domain.Product{
-						{Id: "p1", Name: "Product 1"},
-						{Id: "p2", Name: "Product 2"},
-					}, nil
-				}
+	t.Run("duplicate_sku", func(t *testing.T) {
+		mockInventory := &mockInventoryService{
+			AddProductFunc: func(ctx context.Context, name string, price float64, quantity int, reorderPoint int, reorderQuantity int,
+				details domain.ProductDetails) (*domain.Product, error) {
+				return nil, domain.ErrDuplicateSKU
 			},
-			wantStatusCode: http.StatusOK,
-			wantBody:       `"Id":"p2"`,
+		}
+		handler := NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator)
+		router := newTestRouter(handler)
+
+		req := httptest.NewRequest("POST", "/api/products", strings.NewReader(`{"name":"Test Laptop","price":10,"sku":"LAP-1"}`))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusConflict {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusConflict)
+		}
+	})
+}
+
+func TestHTTPHandler_CatalogLookups(t *testing.T) {
+	product := &domain.Product{Id: "prod-123", SKU: "LAP-1", Barcode: "4006381333931", Version: 2}
+	lookup := func(match func(*domain.Product) bool) (*domain.Product, error) {
+		if match(product) {
+			return product, nil
+		}
+		return nil, domain.ErrProductNotFound
+	}
+	mockInventory := &mockInventoryService{
+		GetProductBySKUFunc: func(sku string) (*domain.Product, error) {
+			return lookup(func(p *domain.Product) bool { return p.SKU == sku })
 		},
-		{
-			name: "success_no_products",
-			setupMock: func(m *mockInventoryService) {
-				m.GetAllProductsFunc = func() ([]domain.Product, error) {
-					return []domain.Product{}, nil
-				}
-			},
-			wantStatusCode: http.StatusOK,
-			wantBody:       `[]`,
+		GetByBarcodeFunc: func(barcode string) (*domain.Product, error) {
+			return lookup(func(p *domain.Product) bool { return p.Barcode == barcode })
 		},
-		{
-			name: "fail_service_error",
-			setupMock: func(m *mockInventoryService) {
-				m.GetAllProductsFunc = func() ([]domain.Product, error) {
-					return nil, domain.ErrRepository
-				}
-			},
-			wantStatusCode: http.StatusInternalServerError,
-			wantBody:       "internal server error",
+	}
+	mockKeys := &mockAPIKeyService{
+		AuthenticateAPIKeyFunc: func(raw string) (*domain.Principal, error) {
+			return &domain.Principal{APIKeyId: "key-1", Permissions: []domain.Permission{domain.PermProductsRead},
+				ProductIds: []string{"prod-999"}}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockInventory, APIKeys: mockKeys}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		path           string
+		apiKey         string
+		wantStatusCode int
+	}{
+		{"by_sku", "/api/products/by-sku/LAP-1", "", http.StatusOK},
+		{"by_barcode", "/api/products/by-barcode/4006381333931", "", http.StatusOK},
+		{"unknown_sku", "/api/products/by-sku/NOPE", "", http.StatusNotFound},
+		{"scoped_key_cannot_see_other_product", "/api/products/by-sku/LAP-1", "ik_abc.secret", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			} else {
+				req.Header.Set("Authorization", "Bearer "+getTestToken())
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+			if tt.wantStatusCode == http.StatusOK && rr.Header().Get("ETag") != `"2"` {
+				t.Errorf("got ETag %q, want \"2\"", rr.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_UpdateProductDetails(t *testing.T) {
+	mockInventory := &mockInventoryService{
+		UpdateDetailsFunc: func(ctx context.Context, id string, details domain.ProductDetails, expectedVersion int) (*domain.Product, error) {
+			if expectedVersion != 3 {
+				return nil, domain.ErrConflict
+			}
+			if details.Barcode != "" {
+				return nil, domain.ErrDuplicateBarcode
+			}
+			return &domain.Product{Id: id, CategoryId: details.CategoryId, Tags: details.Tags, Version: 4}, nil
 		},
 	}
+	handler := NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		body           string
+		ifMatch        string
+		wantStatusCode int
+	}{
+		{"success", `{"category_id":"cat-1","tags":["sale"]}`, `"3"`, http.StatusOK},
+		{"stale_version", `{"category_id":"cat-1"}`, `"2"`, http.StatusConflict},
+		{"duplicate_barcode", `{"barcode":"4006381333931"}`, `"3"`, http.StatusConflict},
+		{"invalid_body", `{`, `"3"`, http.StatusBadRequest},
+		{"weak_validator", `{"category_id":"cat-1"}`, `W/"3"`, http.StatusBadRequest},
+	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockService := &mockInventoryService{}
-			tt.setupMock(mockService)
-			handler := NewHTTPHandler(mockService, nil)
-			router := newTestRouter(handler)
+			req := httptest.NewRequest("PUT", "/api/products/prod-123/details", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+getTestToken())
+			req.Header.Set("If-Match", tt.ifMatch)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+			if tt.wantStatusCode == http.StatusOK && rr.Header().Get("ETag") != `"4"` {
+				t.Errorf("got ETag %q, want \"4\"", rr.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_ListProducts(t *testing.T) {
+	mockService := &mockInventoryService{
+		ListProductsFunc: func(query domain.ProductQuery) (*domain.ProductPage, error) {
+			if query.SortBy == "colour" {
+				return nil, domain.ErrInvalidQuery
+			}
+			if query.NameContains == "broken" {
+				return nil, domain.ErrRepository
+			}
+			if query.NameContains == "none" {
+				return &domain.ProductPage{Products: []domain.Product{}}, nil
+			}
+			return &domain.ProductPage{
+				Products:   []domain.Product{{Id: "p1", Name: "Product 1"}, {Id: "p2", Name: "Product 2"}},
+				NextCursor: "next-page",
+			}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		query          string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"success_with_products", "", http.StatusOK, `"next_cursor":"next-page"`},
+		{"success_no_products", "?name=none", http.StatusOK, `"products":[]`},
+		{"success_all_filters", "?name=cab&min_price=1.5&max_price=9&min_quantity=0&max_quantity=5&low_stock=true&sort=price&order=desc&limit=20&cursor=abc", http.StatusOK, `"Id":"p2"`},
+		{"fail_invalid_price", "?min_price=cheap", http.StatusBadRequest, "min_price"},
+		{"fail_invalid_limit", "?limit=0", http.StatusBadRequest, "limit"},
+		{"fail_invalid_order", "?order=sideways", http.StatusBadRequest, "order"},
+		{"fail_invalid_sort", "?sort=colour", http.StatusBadRequest, domain.ErrInvalidQuery.Error()},
+		{"fail_service_error", "?name=broken", http.StatusInternalServerError, "internal server error"},
+	}
 
-			req := httptest.NewRequest("GET", "/api/products", nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/products"+tt.query, nil)
 			req.Header.Set("Authorization", "Bearer "+getTestToken())
 			rr := httptest.NewRecorder()
 			router.ServeHTTP(rr, req)
@@ -327,16 +1114,29 @@ func TestHTTPHandler_GetAllProducts(t *testing.T) {
 	}
 }
 
+func TestParseProductQuery(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/products?name=cab&min_price=1.5&max_quantity=5&low_stock=true&sort=price&order=desc&limit=20&cursor=abc", nil)
+
+	query, err := parseProductQuery(req)
+	if err != nil {
+		t.Fatalf("parseProductQuery() returned an unexpected error: %v", err)
+	}
+	if query.NameContains != "cab" || *query.MinPrice != 1.5 || query.MaxPrice != nil || *query.MaxQuantity != 5 ||
+		!query.LowStockOnly || query.SortBy != domain.SortByPrice || !query.Descending || query.Limit != 20 || query.Cursor != "abc" {
+		t.Errorf("parseProductQuery() = %+v", query)
+	}
+}
+
 func TestHTTPHandler_DeleteProduct(t *testing.T) {
 	mockService := &mockInventoryService{
-		DeleteProductFunc: func(id string) error {
+		DeleteProductFunc: func(ctx context.Context, id string) error {
 			if id == "prod-123" {
 				return nil
 			}
 			return domain.ErrProductNotFound
 		},
 	}
-	handler := NewHTTPHandler(mockService, nil)
+	handler := NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator)
 	router := newTestRouter(handler)
 
 	t.Run("success", func(t *testing.T) {
@@ -365,13 +1165,69 @@ func TestHTTPHandler_DeleteProduct(t *testing.T) {
 	})
 }
 
+func TestHTTPHandler_RestoreAndPurgeProducts(t *testing.T) {
+	mockService := &mockInventoryService{
+		RestoreProductFunc: func(ctx context.Context, id string) (*domain.Product, error) {
+			switch id {
+			case "prod-123":
+				return &domain.Product{Id: id, Quantity: 4, Version: 3}, nil
+			case "prod-live":
+				return nil, domain.ErrProductNotDeleted
+			}
+			return nil, domain.ErrProductNotFound
+		},
+		PurgeDeletedFunc: func(ctx context.Context, olderThanDays int) ([]domain.Product, error) {
+			if olderThanDays < 1 {
+				return nil, domain.ErrInvalidQuery
+			}
+			return []domain.Product{{Id: "prod-old"}}, nil
+		},
+	}
+	router := newTestRouter(NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator))
+
+	tests := []struct {
+		name           string
+		role           domain.Role
+		path           string
+		body           string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"restore", domain.RoleManager, "/api/products/prod-123/restore", "", http.StatusOK, `"Quantity":4`},
+		{"restore_not_deleted", domain.RoleManager, "/api/products/prod-live/restore", "", http.StatusConflict, "product is not deleted"},
+		{"restore_not_found", domain.RoleManager, "/api/products/prod-456/restore", "", http.StatusNotFound, "product not found"},
+		{"clerk_cannot_restore", domain.RoleClerk, "/api/products/prod-123/restore", "", http.StatusForbidden, ""},
+		{"purge", domain.RoleAdmin, "/api/products/purge", `{"older_than_days": 30}`, http.StatusOK, `"product_ids":["prod-old"]`},
+		{"purge_invalid_days", domain.RoleAdmin, "/api/products/purge", `{"older_than_days": 0}`, http.StatusBadRequest, "invalid query"},
+		{"manager_cannot_purge", domain.RoleManager, "/api/products/purge", `{"older_than_days": 30}`, http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Fatalf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rr.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
 func TestHTTPHandler_GetInventoryValue(t *testing.T) {
 	mockService := &mockInventoryService{
-		GetInventoryValueFunc: func() (float64, error) {
-			return 1234.56, nil
+		GetInventoryValueFunc: func(locationId string) (*domain.InventoryValueReport, error) {
+			return &domain.InventoryValueReport{Total: 1234.56, Uncategorized: 34.56, Categories: []domain.CategoryValue{
+				{CategoryId: "c-electronics", Name: "Electronics", Value: 1200, OwnValue: 1200},
+			}}, nil
 		},
 	}
-	handler := NewHTTPHandler(mockService, nil)
+	handler := NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator)
 	router := newTestRouter(handler)
 
 	req := httptest.NewRequest("GET", "/api/inventory/value", nil)
@@ -382,97 +1238,1055 @@ func TestHTTPHandler_GetInventoryValue(t *testing.T) {
 	if rr.Code != http.StatusOK {
 		t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
 	}
-	if !strings.Contains(rr.Body.String(), "1234.56") {
-		t.Errorf("body does not contain expected value, got %s", rr.Body.String())
+	var body struct {
+		InventoryValue     float64                `json:"inventory_value"`
+		UncategorizedValue float64                `json:"uncategorized_value"`
+		Categories         []domain.CategoryValue `json:"categories"`
+	}
+	json.NewDecoder(rr.Body).Decode(&body)
+	if body.InventoryValue != 1234.56 || body.UncategorizedValue != 34.56 || len(body.Categories) != 1 || body.Categories[0].Value != 1200 {
+		t.Errorf("unexpected inventory value response %+v", body)
 	}
 }
 
-func TestHTTPHandler_RestockProduct(t *testing.T) {
+func TestHTTPHandler_GetInventoryValueAtLocation(t *testing.T) {
+	var gotLocation string
 	mockService := &mockInventoryService{
-		RestockProductFunc: func(id string, quantity int) (*domain.Product, error) {
-			return &domain.Product{Id: id, Quantity: 100 + quantity}, nil
+		GetInventoryValueFunc: func(locationId string) (*domain.InventoryValueReport, error) {
+			gotLocation = locationId
+			if locationId == "nowhere" {
+				return nil, domain.ErrInvalidQuery
+			}
+			return &domain.InventoryValueReport{Total: 50}, nil
 		},
 	}
-	handler := NewHTTPHandler(mockService, nil)
-	router := newTestRouter(handler)
+	router := newTestRouter(NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator))
 
-	t.Run("success", func(t *testing.T) {
-		reqBody := `{"quantity": 50}`
-		req := httptest.NewRequest("POST", "/api/products/prod-123/restock", strings.NewReader(reqBody))
-		req.Header.Set("Authorization", "Bearer "+getTestToken())
-		rr := httptest.NewRecorder()
-		router.ServeHTTP(rr, req)
-
-		if rr.Code != http.StatusOK {
-			t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
-		}
-	})
+	tests := []struct {
+		name           string
+		location       string
+		wantStatusCode int
+	}{
+		{"all_locations", "", http.StatusOK},
+		{"one_location", "store-1", http.StatusOK},
+		{"unknown_location", "nowhere", http.StatusBadRequest},
+	}
 
-	t.Run("fail_invalid_body", func(t *testing.T) {
-		reqBody := `{"quantity":}`
-		req := httptest.NewRequest("POST", "/api/products/prod-123/restock", strings.NewReader(reqBody))
-		req.Header.Set("Authorization", "Bearer "+getTestToken())
-		rr := httptest.NewRecorder()
-		router.ServeHTTP(rr, req)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/inventory/value?location="+tt.location, nil)
+			req.Header.Set("Authorization", "Bearer "+getTestToken())
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
 
-		if rr.Code != http.StatusBadRequest {
-			t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
-		}
-	})
+			if rr.Code != tt.wantStatusCode || gotLocation != tt.location {
+				t.Errorf("got status %d for location %q, want %d for %q", rr.Code, gotLocation, tt.wantStatusCode, tt.location)
+			}
+		})
+	}
 }
 
-func TestHTTPHandler_UpdateProductPrice(t *testing.T) {
-	mockService := &mockInventoryService{
-		UpdateProductPriceFunc: func(id string, newPrice float64) error {
-			if id == "prod-456" {
-				return domain.ErrProductNotFound
+func TestHTTPHandler_LocationsAndTransfers(t *testing.T) {
+	transfer := &domain.Transfer{Id: "t-1", ProductId: "p-1", FromLocationId: "main", ToLocationId: "store-1", Quantity: 3, Status: domain.TransferInTransit}
+	mockLocations := &mockLocationService{
+		CreateLocationFunc: func(code, name string, kind domain.LocationKind) (*domain.Location, error) {
+			if code == "MAIN" {
+				return nil, domain.ErrLocationExists
 			}
-			if newPrice <= 0 {
-				return domain.ErrProductInvalid
+			return &domain.Location{Id: "l-new", Code: code, Name: name, Kind: kind}, nil
+		},
+		ListLocationsFunc: func() ([]domain.Location, error) {
+			return []domain.Location{{Id: "main", Code: "MAIN"}}, nil
+		},
+		GetProductStockFunc: func(productId string) (*domain.ProductStock, error) {
+			return &domain.ProductStock{ProductId: productId, InTransit: 3}, nil
+		},
+		SetReorderPointFunc: func(productId, locationId string, reorderPoint int) (*domain.StockLevel, error) {
+			if locationId == "nowhere" {
+				return nil, domain.ErrLocationNotFound
 			}
-			return nil
+			return &domain.StockLevel{ProductId: productId, LocationId: locationId, ReorderPoint: reorderPoint}, nil
+		},
+		CreateTransferFunc: func(ctx context.Context, productId, fromLocationId, toLocationId string, quantity int) (*domain.Transfer, error) {
+			if quantity > 10 {
+				return nil, domain.ErrInsufficientStock
+			}
+			if fromLocationId == toLocationId {
+				return nil, domain.ErrTransferInvalid
+			}
+			return transfer, nil
+		},
+		ReceiveTransferFunc: func(id string) (*domain.Transfer, error) {
+			if id != transfer.Id {
+				return nil, domain.ErrTransferNotFound
+			}
+			return transfer, nil
+		},
+		CancelTransferFunc: func(id string) (*domain.Transfer, error) {
+			return nil, domain.ErrTransferNotInTransit
+		},
+		ListTransfersFunc: func(status string) ([]domain.Transfer, error) {
+			return []domain.Transfer{*transfer}, nil
 		},
 	}
-	handler := NewHTTPHandler(mockService, nil)
-	router := newTestRouter(handler)
-
-	t.Run("success", func(t *testing.T) {
-		reqBody := `{"price": 99.99}`
-		req := httptest.NewRequest("PUT", "/api/products/prod-123/price", strings.NewReader(reqBody))
-		req.Header.Set("Authorization", "Bearer "+getTestToken())
-		rr := httptest.NewRecorder()
-		router.ServeHTTP(rr, req)
+	router := newTestRouter(NewHTTPHandler(Services{Locations: mockLocations}, testTokenValidator))
 
-		if rr.Code != http.StatusOK {
-			t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
-		}
-	})
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		role           domain.Role
+		wantStatusCode int
+	}{
+		{"create_location", "POST", "/api/locations", `{"code":"STORE-1","name":"High Street","kind":"store"}`, domain.RoleAdmin, http.StatusCreated},
+		{"create_location_taken", "POST", "/api/locations", `{"code":"MAIN","name":"Main"}`, domain.RoleAdmin, http.StatusConflict},
+		{"create_location_needs_admin", "POST", "/api/locations", `{"code":"STORE-2","name":"Mall"}`, domain.RoleManager, http.StatusForbidden},
+		{"list_locations", "GET", "/api/locations", "", domain.RoleReadOnly, http.StatusOK},
+		{"product_stock", "GET", "/api/products/p-1/stock", "", domain.RoleReadOnly, http.StatusOK},
+		{"set_reorder_point", "PUT", "/api/products/p-1/stock/store-1", `{"reorder_point":4}`, domain.RoleManager, http.StatusOK},
+		{"set_reorder_point_unknown_location", "PUT", "/api/products/p-1/stock/nowhere", `{"reorder_point":4}`, domain.RoleManager, http.StatusNotFound},
+		{"create_transfer", "POST", "/api/transfers", `{"product_id":"p-1","from_location_id":"main","to_location_id":"store-1","quantity":3}`, domain.RoleClerk, http.StatusCreated},
+		{"create_transfer_short", "POST", "/api/transfers", `{"product_id":"p-1","from_location_id":"main","to_location_id":"store-1","quantity":30}`, domain.RoleClerk, http.StatusBadRequest},
+		{"create_transfer_same_location", "POST", "/api/transfers", `{"product_id":"p-1","from_location_id":"main","to_location_id":"main","quantity":3}`, domain.RoleClerk, http.StatusBadRequest},
+		{"create_transfer_read_only", "POST", "/api/transfers", `{"product_id":"p-1","from_location_id":"main","to_location_id":"store-1","quantity":3}`, domain.RoleReadOnly, http.StatusForbidden},
+		{"list_transfers", "GET", "/api/transfers?status=in_transit", "", domain.RoleClerk, http.StatusOK},
+		{"receive_transfer", "POST", "/api/transfers/t-1/receive", "", domain.RoleClerk, http.StatusOK},
+		{"receive_missing_transfer", "POST", "/api/transfers/t-9/receive", "", domain.RoleClerk, http.StatusNotFound},
+		{"cancel_completed_transfer", "POST", "/api/transfers/t-1/cancel", "", domain.RoleClerk, http.StatusConflict},
+	}
 
-	t.Run("fail_not_found", func(t *testing.T) {
-		reqBody := `{"price": 99.99}`
-		req := httptest.NewRequest("PUT", "/api/products/prod-456/price", strings.NewReader(reqBody))
-		req.Header.Set("Authorization", "Bearer "+getTestToken())
-		rr := httptest.NewRecorder()
-		router.ServeHTTP(rr, req)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
 
-		if rr.Code != http.StatusNotFound {
-			t.Errorf("got status %d, want %d", rr.Code, http.StatusNotFound)
-		}
-	})
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+		})
+	}
 }
 
-func TestHTTPHandler_Logout(t *testing.T) {
-	handler := NewHTTPHandler(nil, nil)
-	router := newTestRouter(handler)
+func TestHTTPHandler_BinsAndPickLists(t *testing.T) {
+	mockBins := &mockBinService{
+		CreateBinFunc: func(locationId, code string, aisle, shelf int) (*domain.Bin, error) {
+			if locationId == "nowhere" {
+				return nil, domain.ErrLocationNotFound
+			}
+			if code == "A1-01" {
+				return nil, domain.ErrBinExists
+			}
+			return &domain.Bin{Id: "b-new", LocationId: locationId, Code: code, Aisle: aisle, Shelf: shelf}, nil
+		},
+		ListBinsFunc: func(locationId string) ([]domain.Bin, error) {
+			return []domain.Bin{{Id: "b-1", LocationId: locationId, Code: "A1-01"}}, nil
+		},
+		SetBinStockFunc: func(binId, productId string, quantity int) (*domain.BinStock, error) {
+			if binId != "b-1" {
+				return nil, domain.ErrBinNotFound
+			}
+			if quantity < 0 {
+				return nil, domain.ErrBinInvalid
+			}
+			return &domain.BinStock{BinId: binId, ProductId: productId, Quantity: quantity}, nil
+		},
+		ProductBinsFunc: func(productId string) ([]domain.BinSlot, error) {
+			return []domain.BinSlot{{BinId: "b-1", ProductId: productId, Quantity: 4}}, nil
+		},
+		GetPickListFunc: func(locationId string, items []domain.PickRequest) (*domain.PickList, error) {
+			if len(items) == 0 {
+				return nil, domain.ErrPickListInvalid
+			}
+			if items[0].ProductId != "p-1" || items[0].Quantity != 2 {
+				t.Errorf("GetPickList() items = %+v, want two of p-1", items)
+			}
+			return &domain.PickList{LocationId: locationId}, nil
+		},
+	}
+	router := newTestRouter(NewHTTPHandler(Services{Bins: mockBins}, testTokenValidator))
 
-	req := httptest.NewRequest("POST", "/logout", nil)
-	rr := httptest.NewRecorder()
-	router.ServeHTTP(rr, req)
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		role           domain.Role
+		wantStatusCode int
+	}{
+		{"create_bin", "POST", "/api/locations/main/bins", `{"code":"B2-01","aisle":2,"shelf":1}`, domain.RoleAdmin, http.StatusCreated},
+		{"create_bin_taken", "POST", "/api/locations/main/bins", `{"code":"A1-01","aisle":1,"shelf":1}`, domain.RoleAdmin, http.StatusConflict},
+		{"create_bin_unknown_location", "POST", "/api/locations/nowhere/bins", `{"code":"B2-01"}`, domain.RoleAdmin, http.StatusNotFound},
+		{"create_bin_needs_admin", "POST", "/api/locations/main/bins", `{"code":"B2-01"}`, domain.RoleManager, http.StatusForbidden},
+		{"list_bins", "GET", "/api/locations/main/bins", "", domain.RoleReadOnly, http.StatusOK},
+		{"set_bin_stock", "PUT", "/api/bins/b-1/products/p-1", `{"quantity":4}`, domain.RoleClerk, http.StatusOK},
+		{"set_bin_stock_negative", "PUT", "/api/bins/b-1/products/p-1", `{"quantity":-1}`, domain.RoleClerk, http.StatusBadRequest},
+		{"set_bin_stock_missing_bin", "PUT", "/api/bins/b-9/products/p-1", `{"quantity":4}`, domain.RoleClerk, http.StatusNotFound},
+		{"product_bins", "GET", "/api/products/p-1/bins", "", domain.RoleReadOnly, http.StatusOK},
+		{"pick_list", "POST", "/api/pick-lists", `{"location_id":"main","items":[{"product_id":"p-1","quantity":2}]}`, domain.RoleClerk, http.StatusOK},
+		{"pick_list_without_items", "POST", "/api/pick-lists", `{"location_id":"main","items":[]}`, domain.RoleClerk, http.StatusBadRequest},
+		{"pick_list_read_only", "POST", "/api/pick-lists", `{"items":[{"product_id":"p-1","quantity":2}]}`, domain.RoleReadOnly, http.StatusForbidden},
+	}
 
-	if rr.Code != http.StatusOK {
-		t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_Lots(t *testing.T) {
+	mockLots := &mockLotService{
+		ListLotsFunc: func(productId string) ([]domain.Lot, error) {
+			if productId != "p-1" {
+				return nil, domain.ErrProductNotFound
+			}
+			return []domain.Lot{{Id: "lot-1", ProductId: productId, LotNumber: "L-7", Quantity: 4}}, nil
+		},
+		ExpiringLotsFunc: func(locationId string, within time.Duration) ([]domain.ExpiringLot, error) {
+			if locationId == "nowhere" {
+				return nil, domain.ErrInvalidQuery
+			}
+			if within != 0 && within != 3*24*time.Hour {
+				t.Errorf("ExpiringLots() window = %v, want 3 days", within)
+			}
+			return []domain.ExpiringLot{}, nil
+		},
 	}
-	if !strings.Contains(rr.Body.String(), "logout successful") {
-		t.Errorf("body does not contain logout message")
+	router := newTestRouter(NewHTTPHandler(Services{Lots: mockLots}, testTokenValidator))
+
+	tests := []struct {
+		name           string
+		path           string
+		role           domain.Role
+		wantStatusCode int
+	}{
+		{"product_lots", "/api/products/p-1/lots", domain.RoleReadOnly, http.StatusOK},
+		{"missing_product_lots", "/api/products/p-9/lots", domain.RoleReadOnly, http.StatusNotFound},
+		{"expiring_default_window", "/api/inventory/expiring", domain.RoleManager, http.StatusOK},
+		{"expiring_within_days", "/api/inventory/expiring?days=3&location=main", domain.RoleManager, http.StatusOK},
+		{"expiring_zero_days", "/api/inventory/expiring?days=0", domain.RoleManager, http.StatusBadRequest},
+		{"expiring_bad_days", "/api/inventory/expiring?days=soon", domain.RoleManager, http.StatusBadRequest},
+		{"expiring_unknown_location", "/api/inventory/expiring?location=nowhere", domain.RoleManager, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_WriteOffLot(t *testing.T) {
+	mockService := &mockInventoryService{
+		WriteOffLotFunc: func(ctx context.Context, id string, lotId string, expectedVersion int) (*domain.Product, error) {
+			if lotId != "lot-1" {
+				return nil, domain.ErrLotNotFound
+			}
+			return &domain.Product{Id: id, Quantity: 3, Version: 2}, nil
+		},
+	}
+	router := newTestRouter(NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator))
+
+	tests := []struct {
+		name           string
+		path           string
+		role           domain.Role
+		wantStatusCode int
+	}{
+		{"manager_writes_off", "/api/products/p-1/lots/lot-1/write-off", domain.RoleManager, http.StatusOK},
+		{"clerk_forbidden", "/api/products/p-1/lots/lot-1/write-off", domain.RoleClerk, http.StatusForbidden},
+		{"unknown_lot", "/api/products/p-1/lots/lot-9/write-off", domain.RoleManager, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_Categories(t *testing.T) {
+	electronics := &domain.Category{Id: "c-electronics", Name: "Electronics"}
+	mockCategories := &mockCategoryService{
+		CreateCategoryFunc: func(name, parentId string) (*domain.Category, error) {
+			if parentId == "c-missing" {
+				return nil, domain.ErrCategoryInvalid
+			}
+			return &domain.Category{Id: "c-new", Name: name, ParentId: parentId}, nil
+		},
+		GetCategoryFunc: func(id string) (*domain.Category, error) {
+			if id != electronics.Id {
+				return nil, domain.ErrCategoryNotFound
+			}
+			return electronics, nil
+		},
+		ListCategoriesFunc: func() ([]domain.Category, error) {
+			return []domain.Category{*electronics}, nil
+		},
+		UpdateCategoryFunc: func(id, name, parentId string) (*domain.Category, error) {
+			if name == "Taken" {
+				return nil, domain.ErrCategoryExists
+			}
+			return &domain.Category{Id: id, Name: name, ParentId: parentId}, nil
+		},
+		DeleteCategoryFunc: func(id string) error {
+			if id == electronics.Id {
+				return domain.ErrCategoryInUse
+			}
+			return nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Categories: mockCategories}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		role           domain.Role
+		wantStatusCode int
+	}{
+		{"create", "POST", "/api/categories", `{"name":"Cables","parent_id":"c-electronics"}`, domain.RoleManager, http.StatusCreated},
+		{"create_unknown_parent", "POST", "/api/categories", `{"name":"Cables","parent_id":"c-missing"}`, domain.RoleManager, http.StatusBadRequest},
+		{"create_needs_write", "POST", "/api/categories", `{"name":"Cables"}`, domain.RoleReadOnly, http.StatusForbidden},
+		{"list", "GET", "/api/categories", "", domain.RoleReadOnly, http.StatusOK},
+		{"get", "GET", "/api/categories/c-electronics", "", domain.RoleReadOnly, http.StatusOK},
+		{"get_missing", "GET", "/api/categories/c-missing", "", domain.RoleReadOnly, http.StatusNotFound},
+		{"update", "PUT", "/api/categories/c-cables", `{"name":"Leads"}`, domain.RoleManager, http.StatusOK},
+		{"update_duplicate", "PUT", "/api/categories/c-cables", `{"name":"Taken"}`, domain.RoleManager, http.StatusConflict},
+		{"delete", "DELETE", "/api/categories/c-cables", "", domain.RoleManager, http.StatusOK},
+		{"delete_in_use", "DELETE", "/api/categories/c-electronics", "", domain.RoleManager, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+getTestTokenWithRole(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d (body %q)", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_AssignProductCategory(t *testing.T) {
+	var gotCategory string
+	mockInventory := &mockInventoryService{
+		AssignCategoryFunc: func(ctx context.Context, id string, categoryId string, expectedVersion int) (*domain.Product, error) {
+			if categoryId == "c-missing" {
+				return nil, domain.ErrProductInvalid
+			}
+			gotCategory = categoryId
+			return &domain.Product{Id: id, CategoryId: categoryId, Version: expectedVersion + 1}, nil
+		},
+		ListProductsFunc: func(query domain.ProductQuery) (*domain.ProductPage, error) {
+			gotCategory = query.CategoryId
+			return &domain.ProductPage{}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockInventory}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		req.Header.Set("If-Match", `"1"`)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send("PUT", "/api/products/prod-123/category", `{"category_id":"c-cables"}`); rr.Code != http.StatusOK || gotCategory != "c-cables" || rr.Header().Get("ETag") != `"2"` {
+		t.Errorf("assign got status %d, category %q, ETag %q", rr.Code, gotCategory, rr.Header().Get("ETag"))
+	}
+	if rr := send("PUT", "/api/products/prod-123/category", `{"category_id":"c-missing"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("assign to an unknown category got status %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+	if rr := send("GET", "/api/products?category=c-electronics", ""); rr.Code != http.StatusOK || gotCategory != "c-electronics" {
+		t.Errorf("list by category got status %d, category %q", rr.Code, gotCategory)
+	}
+}
+
+func TestHTTPHandler_RestockProduct(t *testing.T) {
+	mockService := &mockInventoryService{
+		RestockProductFunc: func(ctx context.Context, id string, locationId string, quantity int, receipt domain.LotReceipt, expectedVersion int) (*domain.Product, error) {
+			if receipt.ExpiresAt != nil && receipt.ExpiresAt.Before(time.Now()) {
+				return nil, domain.ErrLotInvalid
+			}
+			return &domain.Product{Id: id, Quantity: 100 + quantity}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	t.Run("success", func(t *testing.T) {
+		reqBody := `{"quantity": 50}`
+		req := httptest.NewRequest("POST", "/api/products/prod-123/restock", strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("fail_expired_lot", func(t *testing.T) {
+		reqBody := `{"quantity": 50, "lot_number": "L-7", "expires_at": "2001-01-01T00:00:00Z"}`
+		req := httptest.NewRequest("POST", "/api/products/prod-123/restock", strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("fail_invalid_body", func(t *testing.T) {
+		reqBody := `{"quantity":}`
+		req := httptest.NewRequest("POST", "/api/products/prod-123/restock", strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestHTTPHandler_UpdateProductPrice(t *testing.T) {
+	mockService := &mockInventoryService{
+		UpdateProductPriceFunc: func(ctx context.Context, id string, newPrice float64, expectedVersion int) (*domain.Product, error) {
+			if id == "prod-456" {
+				return nil, domain.ErrProductNotFound
+			}
+			if newPrice <= 0 {
+				return nil, domain.ErrProductInvalid
+			}
+			if expectedVersion != 0 && expectedVersion != 4 {
+				return nil, domain.ErrConflict
+			}
+			return &domain.Product{Id: id, Price: newPrice, Version: 5}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	t.Run("success", func(t *testing.T) {
+		reqBody := `{"price": 99.99}`
+		req := httptest.NewRequest("PUT", "/api/products/prod-123/price", strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("fail_not_found", func(t *testing.T) {
+		reqBody := `{"price": 99.99}`
+		req := httptest.NewRequest("PUT", "/api/products/prod-456/price", strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("success_matching_if_match", func(t *testing.T) {
+		reqBody := `{"price": 99.99}`
+		req := httptest.NewRequest("PUT", "/api/products/prod-123/price", strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		req.Header.Set("If-Match", `"4"`)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
+		}
+		if etag := rr.Header().Get("ETag"); etag != `"5"` {
+			t.Errorf("got ETag %q, want %q", etag, `"5"`)
+		}
+	})
+
+	t.Run("fail_stale_if_match", func(t *testing.T) {
+		reqBody := `{"price": 99.99}`
+		req := httptest.NewRequest("PUT", "/api/products/prod-123/price", strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		req.Header.Set("If-Match", `"3"`)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusConflict {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("fail_malformed_if_match", func(t *testing.T) {
+		reqBody := `{"price": 99.99}`
+		req := httptest.NewRequest("PUT", "/api/products/prod-123/price", strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		req.Header.Set("If-Match", `"abc"`)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("fail_weak_if_match", func(t *testing.T) {
+		reqBody := `{"price": 99.99}`
+		req := httptest.NewRequest("PUT", "/api/products/prod-123/price", strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer "+getTestToken())
+		req.Header.Set("If-Match", `W/"3"`)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestHTTPHandler_Logout(t *testing.T) {
+	mockInventory := &mockInventoryService{
+		GetInventoryValueFunc: func(locationId string) (*domain.InventoryValueReport, error) {
+			return &domain.InventoryValueReport{}, nil
+		},
+	}
+	authService := service.NewAuthService(nil, nil, nil, testRevocations, nil, nil, nil, time.Hour, domain.LoginPolicy{})
+	handler := NewHTTPHandler(Services{Inventory: mockInventory, Auth: authService}, testTokenValidator)
+	router := newTestRouter(handler)
+	token := getTestToken()
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		token          string
+		wantStatusCode int
+	}{
+		{"token_works_before_logout", "GET", "/api/inventory/value", token, http.StatusOK},
+		{"logout_without_token", "POST", "/logout", "", http.StatusUnauthorized},
+		{"logout", "POST", "/logout", token, http.StatusOK},
+		{"token_rejected_after_logout", "GET", "/api/inventory/value", token, http.StatusUnauthorized},
+		{"logout_twice", "POST", "/logout", token, http.StatusUnauthorized},
+		{"other_tokens_still_work", "GET", "/api/inventory/value", getTestToken(), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatusCode)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_AdjustProductStock(t *testing.T) {
+	var gotManagerId string
+	mockService := &mockInventoryService{
+		AdjustStockFunc: func(ctx context.Context, id string, locationId string, delta int, expectedVersion int) (*domain.Product, error) {
+			gotManagerId = domain.ManagerIdFromContext(ctx)
+			return &domain.Product{Id: id, Quantity: 10 + delta, Version: 2}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	reqBody := `{"delta": -3}`
+	req := httptest.NewRequest("POST", "/api/products/prod-123/adjust", strings.NewReader(reqBody))
+	req.Header.Set("Authorization", "Bearer "+getTestToken())
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
+	}
+	if gotManagerId != "manager-123" {
+		t.Errorf("service received manager id %q, want %q", gotManagerId, "manager-123")
+	}
+}
+
+func TestHTTPHandler_GetStockMovements(t *testing.T) {
+	mockService := &mockInventoryService{
+		GetStockMovementsFunc: func(id string, from, to time.Time) ([]domain.StockMovement, error) {
+			if from.IsZero() || !to.IsZero() {
+				return nil, domain.ErrRepository
+			}
+			return []domain.StockMovement{{Id: "m1", ProductId: id, Delta: -40, Reason: domain.MovementSale}}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		query          string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"success_from_filter", "?from=2024-01-02T00:00:00Z", http.StatusOK, `"Reason":"sale"`},
+		{"fail_invalid_from", "?from=yesterday", http.StatusBadRequest, "from"},
+		{"fail_inverted_range", "?from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z", http.StatusBadRequest, "before"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/products/prod-123/movements"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+getTestToken())
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q, got %q", tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_UpdateReorderThresholds(t *testing.T) {
+	mockService := &mockInventoryService{
+		UpdateThresholdsFunc: func(ctx context.Context, id string, reorderPoint int, reorderQuantity int, expectedVersion int) (*domain.Product, error) {
+			if reorderPoint < 0 || reorderQuantity < 0 {
+				return nil, domain.ErrProductInvalid
+			}
+			return &domain.Product{Id: id, ReorderPoint: reorderPoint, ReorderQuantity: reorderQuantity, Version: 2}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Inventory: mockService}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		reqBody        string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"success", `{"reorder_point": 5000, "reorder_quantity": 20000}`, http.StatusOK, `"ReorderPoint":5000`},
+		{"fail_negative_threshold", `{"reorder_point": -1, "reorder_quantity": 10}`, http.StatusBadRequest, domain.ErrProductInvalid.Error()},
+		{"fail_invalid_body", `{"reorder_point":}`, http.StatusBadRequest, "Invalid request body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/api/products/prod-123/thresholds", strings.NewReader(tt.reqBody))
+			req.Header.Set("Authorization", "Bearer "+getTestToken())
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q, got %q", tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_ListAlerts(t *testing.T) {
+	mockAlerts := &mockAlertService{
+		ListAlertsFunc: func(status string) ([]domain.Alert, error) {
+			if _, err := domain.ParseAlertStatus(status); err != nil {
+				return nil, err
+			}
+			return []domain.Alert{{Id: "alert-1", ProductId: "prod-1", Status: domain.AlertOpen}}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Alerts: mockAlerts}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		query          string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"success", "", http.StatusOK, `"Status":"open"`},
+		{"success_status_filter", "?status=open", http.StatusOK, `"Id":"alert-1"`},
+		{"fail_unknown_status", "?status=snoozed", http.StatusBadRequest, "snoozed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/alerts"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+getTestToken())
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q, got %q", tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_AcknowledgeAlert(t *testing.T) {
+	mockAlerts := &mockAlertService{
+		AcknowledgeAlertFunc: func(ctx context.Context, id string) (*domain.Alert, error) {
+			if id != "alert-1" {
+				return nil, domain.ErrAlertNotFound
+			}
+			return &domain.Alert{Id: id, Status: domain.AlertAcknowledged, AcknowledgedBy: domain.ManagerIdFromContext(ctx)}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Alerts: mockAlerts}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		alertId        string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"success", "alert-1", http.StatusOK, `"AcknowledgedBy":"manager-123"`},
+		{"fail_not_found", "alert-9", http.StatusNotFound, domain.ErrAlertNotFound.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/alerts/"+tt.alertId+"/ack", nil)
+			req.Header.Set("Authorization", "Bearer "+getTestToken())
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q, got %q", tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_ChangePassword(t *testing.T) {
+	mockAuth := &mockAuthService{
+		ChangePasswordFunc: func(email, currentPassword, newPassword, code, clientIP string) (*domain.LoginResult, error) {
+			if currentPassword != "temporary-password" {
+				return nil, domain.ErrInvalidCredentials
+			}
+			if email == "mfa@example.com" && code != "123456" {
+				return nil, domain.ErrMFACodeInvalid
+			}
+			if len(newPassword) < domain.MinPasswordLength {
+				return nil, domain.ErrManagerInvalid
+			}
+			return &domain.LoginResult{Tokens: testTokenPair("fresh-token")}, nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Auth: mockAuth}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		body           string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"success", `{"email":"a@example.com","current_password":"temporary-password","new_password":"brand-new-password"}`, http.StatusOK, "fresh-token"},
+		{"fail_wrong_current_password", `{"email":"a@example.com","current_password":"nope","new_password":"brand-new-password"}`, http.StatusUnauthorized, domain.ErrInvalidCredentials.Error()},
+		{"fail_weak_new_password", `{"email":"a@example.com","current_password":"temporary-password","new_password":"short"}`, http.StatusBadRequest, domain.ErrManagerInvalid.Error()},
+		{"success_with_code", `{"email":"mfa@example.com","current_password":"temporary-password","new_password":"brand-new-password","code":"123456"}`, http.StatusOK, "fresh-token"},
+		{"fail_missing_code", `{"email":"mfa@example.com","current_password":"temporary-password","new_password":"brand-new-password"}`, http.StatusUnauthorized, domain.ErrMFACodeInvalid.Error()},
+		{"fail_bad_body", `{`, http.StatusBadRequest, "Invalid request body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/login/password", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q, got %q", tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_VerifyMFA(t *testing.T) {
+	mockAuth := &mockAuthService{
+		VerifyMFAFunc: func(mfaToken, code, clientIP string) (*domain.TokenPair, error) {
+			switch {
+			case mfaToken != "challenge-token":
+				return nil, domain.ErrMFAChallengeInvalid
+			case code != "123456":
+				return nil, domain.ErrMFACodeInvalid
+			}
+			return testTokenPair("mfa-token"), nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Auth: mockAuth}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		body           string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"success", `{"mfa_token":"challenge-token","code":"123456"}`, http.StatusOK, `"access_token":"mfa-token"`},
+		{"fail_wrong_code", `{"mfa_token":"challenge-token","code":"000000"}`, http.StatusUnauthorized, domain.ErrMFACodeInvalid.Error()},
+		{"fail_bad_challenge", `{"mfa_token":"forged","code":"123456"}`, http.StatusUnauthorized, domain.ErrMFAChallengeInvalid.Error()},
+		{"fail_missing_challenge", `{"code":"123456"}`, http.StatusBadRequest, "Invalid request body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/login/mfa", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q, got %q", tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_TOTPEnrollment(t *testing.T) {
+	enabled := false
+	mockAuth := &mockAuthService{
+		EnrollTOTPFunc: func(ctx context.Context) (*domain.TOTPEnrollment, error) {
+			if domain.ManagerIdFromContext(ctx) != "manager-123" {
+				return nil, domain.ErrUnauthorized
+			}
+			if enabled {
+				return nil, domain.ErrMFAAlreadyEnabled
+			}
+			return &domain.TOTPEnrollment{Secret: "JBSWY3DPEHPK3PXP", URI: "otpauth://totp/test"}, nil
+		},
+		ConfirmTOTPFunc: func(ctx context.Context, code string) ([]string, error) {
+			if code != "123456" {
+				return nil, domain.ErrMFACodeInvalid
+			}
+			enabled = true
+			return []string{"aaaaa-bbbbb"}, nil
+		},
+		DisableTOTPFunc: func(ctx context.Context, code string) error {
+			if !enabled {
+				return domain.ErrMFANotEnrolled
+			}
+			enabled = false
+			return nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Auth: mockAuth}, testTokenValidator)
+	router := newTestRouter(handler)
+	token := getTestTokenWithRole(domain.RoleReadOnly)
+
+	steps := []struct {
+		name           string
+		path           string
+		body           string
+		token          string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"enroll_requires_token", "/api/mfa/totp", "", "", http.StatusUnauthorized, domain.ErrUnauthorized.Error()},
+		{"enroll", "/api/mfa/totp", "", token, http.StatusOK, `"otpauth_uri":"otpauth://totp/test","secret":"JBSWY3DPEHPK3PXP"`},
+		{"verify_wrong_code", "/api/mfa/totp/verify", `{"code":"000000"}`, token, http.StatusUnauthorized, domain.ErrMFACodeInvalid.Error()},
+		{"verify", "/api/mfa/totp/verify", `{"code":"123456"}`, token, http.StatusOK, `{"recovery_codes":["aaaaa-bbbbb"]}`},
+		{"enroll_again", "/api/mfa/totp", "", token, http.StatusConflict, domain.ErrMFAAlreadyEnabled.Error()},
+		{"disable", "/api/mfa/totp/disable", `{"code":"aaaaa-bbbbb"}`, token, http.StatusOK, "two-factor authentication disabled"},
+		{"disable_when_off", "/api/mfa/totp/disable", `{"code":"123456"}`, token, http.StatusBadRequest, domain.ErrMFANotEnrolled.Error()},
+	}
+
+	for _, step := range steps {
+		req := httptest.NewRequest("POST", step.path, strings.NewReader(step.body))
+		if step.token != "" {
+			req.Header.Set("Authorization", "Bearer "+step.token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != step.wantStatusCode {
+			t.Errorf("%s: got status %d, want %d", step.name, rr.Code, step.wantStatusCode)
+		}
+		if !strings.Contains(rr.Body.String(), step.wantBody) {
+			t.Errorf("%s: body does not contain %q, got %q", step.name, step.wantBody, rr.Body.String())
+		}
+	}
+}
+
+func TestHTTPHandler_RefreshToken(t *testing.T) {
+	mockAuth := &mockAuthService{
+		RefreshFunc: func(refreshToken string) (*domain.TokenPair, error) {
+			switch refreshToken {
+			case "refresh-current":
+				return testTokenPair("rotated"), nil
+			case "refresh-rotated-earlier":
+				return nil, domain.ErrRefreshTokenReused
+			default:
+				return nil, domain.ErrRefreshTokenInvalid
+			}
+		},
+	}
+	handler := NewHTTPHandler(Services{Auth: mockAuth}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		body           string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"success", `{"refresh_token":"refresh-current"}`, http.StatusOK, `"refresh_token":"refresh-rotated"`},
+		{"fail_reused", `{"refresh_token":"refresh-rotated-earlier"}`, http.StatusUnauthorized, domain.ErrRefreshTokenReused.Error()},
+		{"fail_unknown", `{"refresh_token":"nope"}`, http.StatusUnauthorized, domain.ErrRefreshTokenInvalid.Error()},
+		{"fail_missing_token", `{}`, http.StatusBadRequest, "Invalid request body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/token/refresh", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q, got %q", tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_ManagerEndpoints(t *testing.T) {
+	clerk := &domain.Manager{Id: "manager-456", Email: "clerk@example.com", Password: "secret-hash", Role: domain.RoleClerk}
+	mockManagers := &mockManagerService{
+		CreateManagerFunc: func(email, password string, role domain.Role) (*domain.Manager, error) {
+			if email == clerk.Email {
+				return nil, domain.ErrManagerExists
+			}
+			if _, err := domain.ParseRole(string(role)); err != nil {
+				return nil, err
+			}
+			return &domain.Manager{Id: "manager-789", Email: email, Password: "secret-hash", Role: role, MustChangePassword: true}, nil
+		},
+		ListManagersFunc: func() ([]domain.Manager, error) {
+			return []domain.Manager{*clerk}, nil
+		},
+		SetManagerDisabledFunc: func(ctx context.Context, id string, disabled bool) (*domain.Manager, error) {
+			if id == domain.ManagerIdFromContext(ctx) {
+				return nil, domain.ErrManagerInvalid
+			}
+			if id != clerk.Id {
+				return nil, domain.ErrManagerNotFound
+			}
+			return &domain.Manager{Id: id, Email: clerk.Email, Role: clerk.Role, Disabled: disabled}, nil
+		},
+		ResetPasswordFunc: func(id, newPassword string) (*domain.Manager, error) {
+			if id != clerk.Id {
+				return nil, domain.ErrManagerNotFound
+			}
+			return &domain.Manager{Id: id, Email: clerk.Email, Role: clerk.Role, MustChangePassword: true}, nil
+		},
+		DeleteManagerFunc: func(ctx context.Context, id string) error {
+			if id != clerk.Id {
+				return domain.ErrManagerNotFound
+			}
+			return nil
+		},
+	}
+	handler := NewHTTPHandler(Services{Managers: mockManagers}, testTokenValidator)
+	router := newTestRouter(handler)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		token          string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"create_success", "POST", "/api/managers", `{"email":"new@example.com","password":"long-enough-pw","role":"clerk"}`, getTestToken(), http.StatusCreated, `"MustChangePassword":true`},
+		{"create_duplicate", "POST", "/api/managers", `{"email":"clerk@example.com","password":"long-enough-pw","role":"clerk"}`, getTestToken(), http.StatusConflict, domain.ErrManagerExists.Error()},
+		{"create_invalid_role", "POST", "/api/managers", `{"email":"new@example.com","password":"long-enough-pw","role":"owner"}`, getTestToken(), http.StatusBadRequest, domain.ErrManagerInvalid.Error()},
+		{"create_forbidden_for_manager_role", "POST", "/api/managers", `{"email":"new@example.com","password":"long-enough-pw","role":"clerk"}`, getTestTokenWithRole(domain.RoleManager), http.StatusForbidden, domain.ErrForbidden.Error()},
+		{"list_success", "GET", "/api/managers", "", getTestToken(), http.StatusOK, `"Email":"clerk@example.com"`},
+		{"disable_success", "POST", "/api/managers/manager-456/disable", "", getTestToken(), http.StatusOK, `"Disabled":true`},
+		{"disable_self", "POST", "/api/managers/manager-123/disable", "", getTestToken(), http.StatusBadRequest, domain.ErrManagerInvalid.Error()},
+		{"enable_success", "POST", "/api/managers/manager-456/enable", "", getTestToken(), http.StatusOK, `"Disabled":false`},
+		{"reset_password_success", "PUT", "/api/managers/manager-456/password", `{"password":"temporary-password"}`, getTestToken(), http.StatusOK, `"MustChangePassword":true`},
+		{"reset_password_not_found", "PUT", "/api/managers/manager-999/password", `{"password":"temporary-password"}`, getTestToken(), http.StatusNotFound, domain.ErrManagerNotFound.Error()},
+		{"delete_success", "DELETE", "/api/managers/manager-456", "", getTestToken(), http.StatusOK, "manager deleted successfully"},
+		{"delete_not_found", "DELETE", "/api/managers/manager-999", "", getTestToken(), http.StatusNotFound, domain.ErrManagerNotFound.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %q, got %q", tt.wantBody, rr.Body.String())
+			}
+			if strings.Contains(rr.Body.String(), "secret-hash") {
+				t.Errorf("response leaked the password hash: %q", rr.Body.String())
+			}
+		})
 	}
 }
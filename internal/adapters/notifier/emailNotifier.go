@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.Notifier = (*emailNotifier)(nil)
+
+type EmailConfig struct {
+	Host                string
+	Port                int
+	Username            string
+	Password            string
+	From                string
+	To                  []string
+	DefaultReorderPoint int
+}
+
+type emailNotifier struct {
+	config   EmailConfig
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewEmailNotifier(config EmailConfig) ports.Notifier {
+	return &emailNotifier{config: config, sendMail: smtp.SendMail}
+}
+
+func (notifier *emailNotifier) NotifyLowStock(product *domain.Product) {
+	payload := newLowStockPayload(product, notifier.config.DefaultReorderPoint)
+	subject := fmt.Sprintf("[%s] Low stock: %s", payload.Severity, payload.ProductName)
+	body := fmt.Sprintf(
+		"Product Id: %s\r\nProduct Name: %s\r\nAvailable Quantity: %d\r\nReorder Point: %d\r\nSuggested Reorder Quantity: %d\r\n",
+		payload.ProductId, payload.ProductName, payload.Quantity, payload.ReorderPoint, payload.ReorderQuantity)
+	notifier.send(subject, body, "low stock alert for "+product.Id)
+}
+
+func (notifier *emailNotifier) NotifyExpiring(lot *domain.ExpiringLot) {
+	payload := newExpiringPayload(lot)
+	subject := fmt.Sprintf("[%s] Lot expiring: %s", payload.Severity, payload.ProductName)
+	body := fmt.Sprintf(
+		"Product Id: %s\r\nProduct Name: %s\r\nLocation: %s\r\nLot Number: %s\r\nQuantity: %d\r\nExpires At: %s\r\n",
+		payload.ProductId, payload.ProductName, payload.LocationId, payload.LotNumber, payload.Quantity,
+		payload.ExpiresAt.Format(time.RFC3339))
+	notifier.send(subject, body, "expiry alert for lot "+payload.LotId)
+}
+
+// send delivers one message, logging rather than returning failures since
+// notifications are best effort. what names the message in that log line.
+func (notifier *emailNotifier) send(subject, body, what string) {
+	message := "From: " + notifier.config.From + "\r\n" +
+		"To: " + strings.Join(notifier.config.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" + body
+
+	var auth smtp.Auth
+	if notifier.config.Username != "" {
+		auth = smtp.PlainAuth("", notifier.config.Username, notifier.config.Password, notifier.config.Host)
+	}
+	addr := net.JoinHostPort(notifier.config.Host, strconv.Itoa(notifier.config.Port))
+	if err := notifier.sendMail(addr, auth, notifier.config.From, notifier.config.To, []byte(message)); err != nil {
+		log.Printf("email notifier: could not send %s: %v", what, err)
+	}
+}
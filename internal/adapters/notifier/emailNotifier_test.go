@@ -0,0 +1,82 @@
+package notifier
+
+import (
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestEmailNotifier_NotifyLowStock(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   EmailConfig
+		sendErr  error
+		wantAuth bool
+	}{
+		{"with_credentials", EmailConfig{Host: "smtp.example.com", Port: 587, Username: "stock", Password: "pw"}, nil, true},
+		{"without_credentials", EmailConfig{Host: "smtp.example.com", Port: 25}, nil, false},
+		{"send_failure_is_logged", EmailConfig{Host: "smtp.example.com", Port: 25}, errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.From = "stock@example.com"
+			tt.config.To = []string{"ops@example.com", "buyer@example.com"}
+			tt.config.DefaultReorderPoint = 10
+
+			var gotAddr string
+			var gotAuth smtp.Auth
+			var gotTo []string
+			var gotMessage string
+			notifier := &emailNotifier{config: tt.config, sendMail: func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+				gotAddr, gotAuth, gotTo, gotMessage = addr, auth, to, string(msg)
+				return tt.sendErr
+			}}
+
+			notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Name: "Screws", Quantity: 0, ReorderQuantity: 50})
+
+			if !strings.HasPrefix(gotAddr, "smtp.example.com:") {
+				t.Errorf("addr = %q", gotAddr)
+			}
+			if (gotAuth != nil) != tt.wantAuth {
+				t.Errorf("auth = %v, want auth %v", gotAuth, tt.wantAuth)
+			}
+			if len(gotTo) != 2 {
+				t.Errorf("recipients = %v", gotTo)
+			}
+			for _, sub := range []string{"Subject: [critical] Low stock: Screws", "To: ops@example.com, buyer@example.com", "Reorder Point: 10", "Suggested Reorder Quantity: 50"} {
+				if !strings.Contains(gotMessage, sub) {
+					t.Errorf("message did not contain %q. Full message: %q", sub, gotMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestEmailNotifier_NotifyExpiring(t *testing.T) {
+	var gotMessage string
+	notifier := &emailNotifier{
+		config: EmailConfig{Host: "smtp.example.com", Port: 25, From: "stock@example.com", To: []string{"ops@example.com"}},
+		sendMail: func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+			gotMessage = string(msg)
+			return nil
+		},
+	}
+
+	expiresAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	notifier.NotifyExpiring(&domain.ExpiringLot{
+		Lot:         domain.Lot{Id: "lot-1", ProductId: "prod-1", LocationId: "main", LotNumber: "L-7", Quantity: 12, ExpiresAt: &expiresAt},
+		ProductName: "Milk",
+		Expired:     true,
+	})
+
+	for _, sub := range []string{"Subject: [critical] Lot expiring: Milk", "Lot Number: L-7", "Quantity: 12", "Expires At: 2024-05-01T00:00:00Z"} {
+		if !strings.Contains(gotMessage, sub) {
+			t.Errorf("message did not contain %q. Full message: %q", sub, gotMessage)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package notifier
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.Notifier = (*fileNotifier)(nil)
+
+type fileNotifier struct {
+	mu                  sync.Mutex
+	path                string
+	defaultReorderPoint int
+}
+
+func NewFileNotifier(path string, defaultReorderPoint int) ports.Notifier {
+	return &fileNotifier{path: path, defaultReorderPoint: defaultReorderPoint}
+}
+
+func (notifier *fileNotifier) NotifyLowStock(product *domain.Product) {
+	line, err := json.Marshal(newLowStockPayload(product, notifier.defaultReorderPoint))
+	if err != nil {
+		log.Printf("file notifier: could not encode low stock payload: %v", err)
+		return
+	}
+	notifier.appendLine(line)
+}
+
+func (notifier *fileNotifier) NotifyExpiring(lot *domain.ExpiringLot) {
+	line, err := json.Marshal(newExpiringPayload(lot))
+	if err != nil {
+		log.Printf("file notifier: could not encode lot expiring payload: %v", err)
+		return
+	}
+	notifier.appendLine(line)
+}
+
+func (notifier *fileNotifier) appendLine(line []byte) {
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	file, err := os.OpenFile(notifier.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Printf("file notifier: could not open %s: %v", notifier.path, err)
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		log.Printf("file notifier: could not write to %s: %v", notifier.path, err)
+	}
+}
@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestFileNotifier_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.jsonl")
+	notifier := NewFileNotifier(path, 10)
+
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Name: "Screws", Quantity: 4})
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-2", Name: "Bolts", Quantity: 0, ReorderPoint: 25})
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("alert file was not created: %v", err)
+	}
+	defer file.Close()
+
+	var payloads []lowStockPayload
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var payload lowStockPayload
+		if err := json.Unmarshal(scanner.Bytes(), &payload); err != nil {
+			t.Fatalf("line %q is not valid JSON: %v", scanner.Text(), err)
+		}
+		payloads = append(payloads, payload)
+	}
+
+	if len(payloads) != 2 {
+		t.Fatalf("got %d lines, want 2", len(payloads))
+	}
+	if payloads[0].ProductId != "prod-1" || payloads[0].ReorderPoint != 10 || payloads[0].Severity != SeverityWarning {
+		t.Errorf("first line = %+v", payloads[0])
+	}
+	if payloads[1].ProductId != "prod-2" || payloads[1].ReorderPoint != 25 || payloads[1].Severity != SeverityCritical {
+		t.Errorf("second line = %+v", payloads[1])
+	}
+}
@@ -2,15 +2,18 @@ package notifier
 
 import (
 	"log"
+	"time"
 
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
 	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
 )
 
-type logNotifier struct{}
+type logNotifier struct {
+	defaultReorderPoint int
+}
 
-func NewLogNotifier() ports.Notifier {
-	return &logNotifier{}
+func NewLogNotifier(defaultReorderPoint int) ports.Notifier {
+	return &logNotifier{defaultReorderPoint: defaultReorderPoint}
 }
 
 func (notifier *logNotifier) NotifyLowStock(product *domain.Product) {
@@ -19,6 +22,24 @@ func (notifier *logNotifier) NotifyLowStock(product *domain.Product) {
 		Product Id: %s
 		Product Name: %s
 		Available Quantity: %d
+		Reorder Point: %d
+		Suggested Reorder Quantity: %d
 		Please restock soon to avoid running out of stock.`,
-		product.Id, product.Name, product.Quantity)
+		product.Id, product.Name, product.Quantity,
+		product.EffectiveReorderPoint(notifier.defaultReorderPoint), product.ReorderQuantity)
+}
+
+func (notifier *logNotifier) NotifyExpiring(lot *domain.ExpiringLot) {
+	payload := newExpiringPayload(lot)
+	log.Printf(
+		`ALERT: LOT EXPIRING
+		Product Id: %s
+		Product Name: %s
+		Location: %s
+		Lot Number: %s
+		Quantity: %d
+		Expires At: %s
+		Sell or write off this lot before it expires.`,
+		payload.ProductId, payload.ProductName, payload.LocationId, payload.LotNumber, payload.Quantity,
+		payload.ExpiresAt.Format(time.RFC3339))
 }
@@ -10,11 +10,13 @@ import (
 )
 
 func TestLogNotifier_NotifyLowStock(t *testing.T) {
-	notifier := NewLogNotifier()
+	notifier := NewLogNotifier(10)
 	product := &domain.Product{
-		Id:       "prod-abc-123",
-		Name:     "Gaming Mouse",
-		Quantity: 5,
+		Id:              "prod-abc-123",
+		Name:            "Gaming Mouse",
+		Quantity:        5,
+		ReorderPoint:    8,
+		ReorderQuantity: 40,
 	}
 
 	var buf bytes.Buffer
@@ -31,6 +33,8 @@ func TestLogNotifier_NotifyLowStock(t *testing.T) {
 		"Product Id: prod-abc-123",
 		"Product Name: Gaming Mouse",
 		"Available Quantity: 5",
+		"Reorder Point: 8",
+		"Suggested Reorder Quantity: 40",
 	}
 
 	for _, sub := range expectedSubstrings {
@@ -39,3 +43,19 @@ func TestLogNotifier_NotifyLowStock(t *testing.T) {
 		}
 	}
 }
+
+func TestLogNotifier_NotifyLowStock_DefaultReorderPoint(t *testing.T) {
+	notifier := NewLogNotifier(10)
+	product := &domain.Product{Id: "prod-def-456", Name: "USB Hub", Quantity: 2}
+
+	var buf bytes.Buffer
+	originalOutput := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(originalOutput)
+
+	notifier.NotifyLowStock(product)
+
+	if !strings.Contains(buf.String(), "Reorder Point: 10") {
+		t.Errorf("log output did not fall back to the default reorder point. Full output: %q", buf.String())
+	}
+}
@@ -0,0 +1,134 @@
+package notifier
+
+import (
+	"log"
+	"sync"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.Notifier = (*multiNotifier)(nil)
+
+// ChannelFilter limits what a channel is sent. CategoryIds also match
+// products in any of their subcategories.
+type ChannelFilter struct {
+	ProductIds  []string
+	CategoryIds []string
+	MinSeverity Severity
+}
+
+// Matches takes the product's category path, its category followed by the
+// category's ancestors, as returned by domain.CategoryPath.
+func (filter ChannelFilter) Matches(product *domain.Product, categoryPath []string) bool {
+	return filter.matches(product.Id, categoryPath, severityOf(product))
+}
+
+func (filter ChannelFilter) MatchesLot(lot *domain.ExpiringLot, categoryPath []string) bool {
+	return filter.matches(lot.ProductId, categoryPath, newExpiringPayload(lot).Severity)
+}
+
+func (filter ChannelFilter) matches(productId string, categoryPath []string, severity Severity) bool {
+	if len(filter.ProductIds) > 0 && !containsAny(filter.ProductIds, productId) {
+		return false
+	}
+	if len(filter.CategoryIds) > 0 && !containsAny(filter.CategoryIds, categoryPath...) {
+		return false
+	}
+	if filter.MinSeverity != "" && severity.rank() < filter.MinSeverity.rank() {
+		return false
+	}
+	return true
+}
+
+func containsAny(list []string, values ...string) bool {
+	for _, item := range list {
+		for _, value := range values {
+			if item == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type Channel struct {
+	Name     string
+	Notifier ports.Notifier
+	Filter   ChannelFilter
+}
+
+type multiNotifier struct {
+	categories ports.CategoryRepository
+	channels   []Channel
+	inFlight   sync.WaitGroup
+}
+
+// NewMultiNotifier needs categories only when a channel filters on
+// category_ids.
+func NewMultiNotifier(categories ports.CategoryRepository, channels ...Channel) *multiNotifier {
+	return &multiNotifier{categories: categories, channels: channels}
+}
+
+// categoryPath looks up the ancestors of categoryId when a channel needs
+// them. If the lookup fails, only the category itself is matched.
+func (notifier *multiNotifier) categoryPath(categoryId string) []string {
+	if categoryId == "" {
+		return nil
+	}
+	for _, channel := range notifier.channels {
+		if len(channel.Filter.CategoryIds) == 0 {
+			continue
+		}
+		categories, err := notifier.categories.ListCategories()
+		if err != nil {
+			log.Printf("could not load categories for notification filters: %v", err)
+			return []string{categoryId}
+		}
+		return domain.CategoryPath(categories, categoryId)
+	}
+	return []string{categoryId}
+}
+
+func (notifier *multiNotifier) NotifyLowStock(product *domain.Product) {
+	categoryPath := notifier.categoryPath(product.CategoryId)
+	for _, channel := range notifier.channels {
+		if !channel.Filter.Matches(product, categoryPath) {
+			continue
+		}
+		snapshot := *product
+		notifier.inFlight.Add(1)
+		go notifier.dispatch(channel, func(target ports.Notifier) { target.NotifyLowStock(&snapshot) })
+	}
+}
+
+func (notifier *multiNotifier) NotifyExpiring(lot *domain.ExpiringLot) {
+	categoryPath := notifier.categoryPath(lot.CategoryId)
+	for _, channel := range notifier.channels {
+		if !channel.Filter.MatchesLot(lot, categoryPath) {
+			continue
+		}
+		snapshot := *lot
+		notifier.inFlight.Add(1)
+		go notifier.dispatch(channel, func(target ports.Notifier) { target.NotifyExpiring(&snapshot) })
+	}
+}
+
+func (notifier *multiNotifier) dispatch(channel Channel, notify func(ports.Notifier)) {
+	defer notifier.inFlight.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("notifier channel %s failed: %v", channel.Name, r)
+		}
+	}()
+	notify(channel.Notifier)
+}
+
+func (notifier *multiNotifier) Close() {
+	notifier.inFlight.Wait()
+	for _, channel := range notifier.channels {
+		if closer, ok := channel.Notifier.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package notifier
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type recordingNotifier struct {
+	mu       sync.Mutex
+	products []domain.Product
+	lots     []domain.ExpiringLot
+	block    chan struct{}
+	panics   bool
+}
+
+func (r *recordingNotifier) NotifyLowStock(product *domain.Product) {
+	if r.block != nil {
+		<-r.block
+	}
+	if r.panics {
+		panic("channel is broken")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.products = append(r.products, *product)
+}
+
+func (r *recordingNotifier) NotifyExpiring(lot *domain.ExpiringLot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lots = append(r.lots, *lot)
+}
+
+func (r *recordingNotifier) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.products)
+}
+
+type stubCategories struct {
+	categories []domain.Category
+	err        error
+}
+
+func (s *stubCategories) SaveCategory(category *domain.Category) error { return nil }
+func (s *stubCategories) FindCategoryById(id string) (*domain.Category, error) {
+	return nil, domain.ErrCategoryNotFound
+}
+func (s *stubCategories) ListCategories() ([]domain.Category, error)     { return s.categories, s.err }
+func (s *stubCategories) UpdateCategory(category *domain.Category) error { return nil }
+func (s *stubCategories) DeleteCategory(id string) error                 { return nil }
+
+func TestChannelFilter_Matches(t *testing.T) {
+	tests := []struct {
+		name         string
+		filter       ChannelFilter
+		product      domain.Product
+		categoryPath []string
+		want         bool
+	}{
+		{"empty_filter_matches_everything", ChannelFilter{}, domain.Product{Id: "prod-1", Quantity: 3}, nil, true},
+		{"product_in_list", ChannelFilter{ProductIds: []string{"prod-1", "prod-2"}}, domain.Product{Id: "prod-2", Quantity: 3}, nil, true},
+		{"product_not_in_list", ChannelFilter{ProductIds: []string{"prod-1"}}, domain.Product{Id: "prod-9", Quantity: 3}, nil, false},
+		{"warning_below_critical", ChannelFilter{MinSeverity: SeverityCritical}, domain.Product{Id: "prod-1", Quantity: 3}, nil, false},
+		{"out_of_stock_is_critical", ChannelFilter{MinSeverity: SeverityCritical}, domain.Product{Id: "prod-1", Quantity: 0}, nil, true},
+		{"critical_meets_warning", ChannelFilter{MinSeverity: SeverityWarning}, domain.Product{Id: "prod-1", Quantity: 0}, nil, true},
+		{"category_in_list", ChannelFilter{CategoryIds: []string{"dairy"}}, domain.Product{Id: "prod-1", CategoryId: "dairy"}, []string{"dairy"}, true},
+		{"subcategory_of_listed_category", ChannelFilter{CategoryIds: []string{"food"}}, domain.Product{Id: "prod-1", CategoryId: "cheese"},
+			[]string{"cheese", "dairy", "food"}, true},
+		{"category_not_in_list", ChannelFilter{CategoryIds: []string{"tools"}}, domain.Product{Id: "prod-1", CategoryId: "dairy"}, []string{"dairy"}, false},
+		{"uncategorized_product", ChannelFilter{CategoryIds: []string{"dairy"}}, domain.Product{Id: "prod-1"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(&tt.product, tt.categoryPath); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiNotifier_FansOutToMatchingChannels(t *testing.T) {
+	everything := &recordingNotifier{}
+	criticalOnly := &recordingNotifier{}
+	otherProduct := &recordingNotifier{}
+	notifier := NewMultiNotifier(nil,
+		Channel{Name: "all", Notifier: everything},
+		Channel{Name: "critical", Notifier: criticalOnly, Filter: ChannelFilter{MinSeverity: SeverityCritical}},
+		Channel{Name: "other", Notifier: otherProduct, Filter: ChannelFilter{ProductIds: []string{"prod-2"}}},
+	)
+
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Quantity: 4})
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Quantity: 0})
+	notifier.Close()
+
+	if everything.count() != 2 {
+		t.Errorf("unfiltered channel got %d notifications, want 2", everything.count())
+	}
+	if criticalOnly.count() != 1 || criticalOnly.products[0].Quantity != 0 {
+		t.Errorf("critical channel got %+v, want only the out of stock alert", criticalOnly.products)
+	}
+	if otherProduct.count() != 0 {
+		t.Errorf("product filtered channel got %d notifications, want 0", otherProduct.count())
+	}
+}
+
+func TestMultiNotifier_NotifyExpiring(t *testing.T) {
+	everything := &recordingNotifier{}
+	criticalOnly := &recordingNotifier{}
+	otherProduct := &recordingNotifier{}
+	notifier := NewMultiNotifier(nil,
+		Channel{Name: "all", Notifier: everything},
+		Channel{Name: "critical", Notifier: criticalOnly, Filter: ChannelFilter{MinSeverity: SeverityCritical}},
+		Channel{Name: "other", Notifier: otherProduct, Filter: ChannelFilter{ProductIds: []string{"prod-2"}}},
+	)
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	notifier.NotifyExpiring(&domain.ExpiringLot{Lot: domain.Lot{Id: "lot-1", ProductId: "prod-1", ExpiresAt: &expiresAt}})
+	notifier.NotifyExpiring(&domain.ExpiringLot{Lot: domain.Lot{Id: "lot-2", ProductId: "prod-1", ExpiresAt: &expiresAt}, Expired: true})
+	notifier.Close()
+
+	if len(everything.lots) != 2 || len(everything.products) != 0 {
+		t.Errorf("unfiltered channel got %d lot notifications, want 2", len(everything.lots))
+	}
+	if len(criticalOnly.lots) != 1 || criticalOnly.lots[0].Id != "lot-2" {
+		t.Errorf("critical channel got %+v, want only the expired lot", criticalOnly.lots)
+	}
+	if len(otherProduct.lots) != 0 {
+		t.Errorf("product filtered channel got %d lot notifications, want 0", len(otherProduct.lots))
+	}
+}
+
+func TestMultiNotifier_CategoryFilterMatchesSubcategories(t *testing.T) {
+	food := &recordingNotifier{}
+	tools := &recordingNotifier{}
+	categories := &stubCategories{categories: []domain.Category{
+		{Id: "food"}, {Id: "dairy", ParentId: "food"}, {Id: "cheese", ParentId: "dairy"}, {Id: "tools"},
+	}}
+	notifier := NewMultiNotifier(categories,
+		Channel{Name: "food", Notifier: food, Filter: ChannelFilter{CategoryIds: []string{"food"}}},
+		Channel{Name: "tools", Notifier: tools, Filter: ChannelFilter{CategoryIds: []string{"tools"}}},
+	)
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-1", CategoryId: "cheese", Quantity: 1})
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-2", Quantity: 1})
+	notifier.NotifyExpiring(&domain.ExpiringLot{Lot: domain.Lot{Id: "lot-1", ProductId: "prod-1", ExpiresAt: &expiresAt}, CategoryId: "dairy"})
+	notifier.Close()
+
+	if food.count() != 1 || food.products[0].Id != "prod-1" || len(food.lots) != 1 {
+		t.Errorf("food channel got %+v and %+v, want the cheese product and the dairy lot", food.products, food.lots)
+	}
+	if tools.count() != 0 || len(tools.lots) != 0 {
+		t.Errorf("tools channel got %+v and %+v, want nothing", tools.products, tools.lots)
+	}
+}
+
+func TestMultiNotifier_FailingChannelDoesNotBlockOthers(t *testing.T) {
+	stuck := &recordingNotifier{block: make(chan struct{})}
+	broken := &recordingNotifier{panics: true}
+	healthy := &recordingNotifier{}
+	notifier := NewMultiNotifier(nil,
+		Channel{Name: "stuck", Notifier: stuck},
+		Channel{Name: "broken", Notifier: broken},
+		Channel{Name: "healthy", Notifier: healthy},
+	)
+
+	start := time.Now()
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Quantity: 1})
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("NotifyLowStock() blocked for %v on a stuck channel", elapsed)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for healthy.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if healthy.count() != 1 {
+		t.Errorf("healthy channel got %d notifications, want 1", healthy.count())
+	}
+
+	close(stuck.block)
+	notifier.Close()
+	if stuck.count() != 1 {
+		t.Errorf("stuck channel got %d notifications after release, want 1", stuck.count())
+	}
+}
@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type Severity string
+
+const (
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+func (severity Severity) rank() int {
+	if severity == SeverityCritical {
+		return 2
+	}
+	return 1
+}
+
+func severityOf(product *domain.Product) Severity {
+	if product.Quantity <= 0 {
+		return SeverityCritical
+	}
+	return SeverityWarning
+}
+
+type lowStockPayload struct {
+	Event           string    `json:"event"`
+	Severity        Severity  `json:"severity"`
+	ProductId       string    `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	Quantity        int       `json:"quantity"`
+	ReorderPoint    int       `json:"reorder_point"`
+	ReorderQuantity int       `json:"reorder_quantity"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+func newLowStockPayload(product *domain.Product, defaultReorderPoint int) lowStockPayload {
+	return lowStockPayload{
+		Event:           lowStockEvent,
+		Severity:        severityOf(product),
+		ProductId:       product.Id,
+		ProductName:     product.Name,
+		Quantity:        product.Quantity,
+		ReorderPoint:    product.EffectiveReorderPoint(defaultReorderPoint),
+		ReorderQuantity: product.ReorderQuantity,
+		OccurredAt:      time.Now().UTC(),
+	}
+}
+
+type expiringPayload struct {
+	Event       string     `json:"event"`
+	Severity    Severity   `json:"severity"`
+	ProductId   string     `json:"product_id"`
+	ProductName string     `json:"product_name"`
+	LocationId  string     `json:"location_id"`
+	LotId       string     `json:"lot_id"`
+	LotNumber   string     `json:"lot_number"`
+	Quantity    int        `json:"quantity"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	OccurredAt  time.Time  `json:"occurred_at"`
+}
+
+// newExpiringPayload reports a lot that has already expired as critical.
+func newExpiringPayload(lot *domain.ExpiringLot) expiringPayload {
+	severity := SeverityWarning
+	if lot.Expired {
+		severity = SeverityCritical
+	}
+	return expiringPayload{
+		Event:       expiringEvent,
+		Severity:    severity,
+		ProductId:   lot.ProductId,
+		ProductName: lot.ProductName,
+		LocationId:  lot.LocationId,
+		LotId:       lot.Id,
+		LotNumber:   lot.LotNumber,
+		Quantity:    lot.Quantity,
+		ExpiresAt:   lot.ExpiresAt,
+		OccurredAt:  time.Now().UTC(),
+	}
+}
@@ -0,0 +1,212 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+const (
+	webhookChannel     = "webhook"
+	lowStockEvent      = "low_stock"
+	expiringEvent      = "lot_expiring"
+	SignatureHeader    = "X-Inventory-Signature"
+	TimestampHeader    = "X-Inventory-Timestamp"
+	EventHeader        = "X-Inventory-Event"
+	defaultQueueSize   = 256
+	defaultWorkers     = 4
+	defaultMaxAttempts = 5
+)
+
+var _ ports.Notifier = (*webhookNotifier)(nil)
+
+type WebhookConfig struct {
+	URLs                []string
+	Secret              string
+	MaxAttempts         int
+	InitialBackoff      time.Duration
+	Timeout             time.Duration
+	QueueSize           int
+	Workers             int
+	DefaultReorderPoint int
+}
+
+type webhookDelivery struct {
+	url   string
+	event string
+	body  []byte
+}
+
+type webhookNotifier struct {
+	config      WebhookConfig
+	client      *http.Client
+	deadLetters ports.DeadLetterRepository
+	queue       chan webhookDelivery
+	workers     sync.WaitGroup
+	mu          sync.RWMutex
+	closed      bool
+	sleep       func(time.Duration)
+}
+
+func NewWebhookNotifier(config WebhookConfig, deadLetters ports.DeadLetterRepository) *webhookNotifier {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 500 * time.Millisecond
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaultWorkers
+	}
+
+	notifier := &webhookNotifier{
+		config:      config,
+		client:      &http.Client{Timeout: config.Timeout},
+		deadLetters: deadLetters,
+		queue:       make(chan webhookDelivery, config.QueueSize),
+		sleep:       time.Sleep,
+	}
+	for i := 0; i < config.Workers; i++ {
+		notifier.workers.Add(1)
+		go notifier.work()
+	}
+	return notifier
+}
+
+func (notifier *webhookNotifier) NotifyLowStock(product *domain.Product) {
+	body, err := json.Marshal(newLowStockPayload(product, notifier.config.DefaultReorderPoint))
+	if err != nil {
+		log.Printf("webhook notifier: could not encode low stock payload: %v", err)
+		return
+	}
+	notifier.enqueue(lowStockEvent, body)
+}
+
+func (notifier *webhookNotifier) NotifyExpiring(lot *domain.ExpiringLot) {
+	body, err := json.Marshal(newExpiringPayload(lot))
+	if err != nil {
+		log.Printf("webhook notifier: could not encode lot expiring payload: %v", err)
+		return
+	}
+	notifier.enqueue(expiringEvent, body)
+}
+
+// enqueue queues one delivery per configured URL, dead-lettering deliveries
+// that cannot be queued.
+func (notifier *webhookNotifier) enqueue(event string, body []byte) {
+	notifier.mu.RLock()
+	defer notifier.mu.RUnlock()
+	for _, url := range notifier.config.URLs {
+		delivery := webhookDelivery{url: url, event: event, body: body}
+		if notifier.closed {
+			notifier.deadLetter(delivery, "notifier is closed", 0)
+			continue
+		}
+		select {
+		case notifier.queue <- delivery:
+		default:
+			notifier.deadLetter(delivery, "delivery queue is full", 0)
+		}
+	}
+}
+
+func (notifier *webhookNotifier) Close() {
+	notifier.mu.Lock()
+	if !notifier.closed {
+		notifier.closed = true
+		close(notifier.queue)
+	}
+	notifier.mu.Unlock()
+	notifier.workers.Wait()
+}
+
+func (notifier *webhookNotifier) work() {
+	defer notifier.workers.Done()
+	for delivery := range notifier.queue {
+		notifier.deliver(delivery)
+	}
+}
+
+func (notifier *webhookNotifier) deliver(delivery webhookDelivery) {
+	backoff := notifier.config.InitialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= notifier.config.MaxAttempts; attempt++ {
+		retryable, err := notifier.post(delivery)
+		if err == nil {
+			return
+		}
+		lastErr = err
+		if !retryable {
+			notifier.deadLetter(delivery, err.Error(), attempt)
+			return
+		}
+		if attempt < notifier.config.MaxAttempts {
+			notifier.sleep(backoff)
+			backoff *= 2
+		}
+	}
+	notifier.deadLetter(delivery, lastErr.Error(), notifier.config.MaxAttempts)
+}
+
+func (notifier *webhookNotifier) post(delivery webhookDelivery) (bool, error) {
+	request, err := http.NewRequest(http.MethodPost, delivery.url, bytes.NewReader(delivery.body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, delivery.event)
+	request.Header.Set(TimestampHeader, timestamp)
+	request.Header.Set(SignatureHeader, SignPayload(notifier.config.Secret, timestamp, delivery.body))
+
+	response, err := notifier.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		return false, nil
+	case response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook rejected delivery with status %d", response.StatusCode)
+	}
+}
+
+func (notifier *webhookNotifier) deadLetter(delivery webhookDelivery, reason string, attempts int) {
+	log.Printf("webhook notifier: giving up on %s after %d attempt(s): %s", delivery.url, attempts, reason)
+	if notifier.deadLetters == nil {
+		return
+	}
+	letter := domain.NewDeadLetter(webhookChannel, delivery.url, delivery.event, string(delivery.body), reason, attempts)
+	if err := notifier.deadLetters.SaveDeadLetter(letter); err != nil {
+		log.Printf("webhook notifier: could not store dead letter: %v", err)
+	}
+}
+
+func SignPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,213 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type mockDeadLetterRepository struct {
+	mu      sync.Mutex
+	letters []domain.DeadLetter
+}
+
+func (m *mockDeadLetterRepository) SaveDeadLetter(letter *domain.DeadLetter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.letters = append(m.letters, *letter)
+	return nil
+}
+
+func (m *mockDeadLetterRepository) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.letters)
+}
+
+func newTestWebhookNotifier(urls []string, deadLetters *mockDeadLetterRepository) *webhookNotifier {
+	notifier := NewWebhookNotifier(WebhookConfig{
+		URLs:                urls,
+		Secret:              "webhook-secret",
+		MaxAttempts:         3,
+		InitialBackoff:      time.Millisecond,
+		Timeout:             time.Second,
+		DefaultReorderPoint: 10,
+	}, deadLetters)
+	return notifier
+}
+
+func TestWebhookNotifier_DeliversSignedPayload(t *testing.T) {
+	var gotBody []byte
+	var gotHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeaders = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	deadLetters := &mockDeadLetterRepository{}
+	notifier := newTestWebhookNotifier([]string{server.URL}, deadLetters)
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Name: "Screws", Quantity: 40, ReorderPoint: 5000, ReorderQuantity: 20000})
+	notifier.Close()
+
+	var payload lowStockPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("webhook body is not valid JSON: %v", err)
+	}
+	if payload.Event != "low_stock" || payload.ProductId != "prod-1" || payload.Quantity != 40 || payload.ReorderPoint != 5000 {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	wantSignature := SignPayload("webhook-secret", gotHeaders.Get(TimestampHeader), gotBody)
+	if gotHeaders.Get(SignatureHeader) != wantSignature {
+		t.Errorf("signature header = %q, want %q", gotHeaders.Get(SignatureHeader), wantSignature)
+	}
+	if gotHeaders.Get(EventHeader) != "low_stock" {
+		t.Errorf("event header = %q, want %q", gotHeaders.Get(EventHeader), "low_stock")
+	}
+	if deadLetters.count() != 0 {
+		t.Errorf("expected no dead letters, got %d", deadLetters.count())
+	}
+}
+
+func TestWebhookNotifier_NotifyExpiring(t *testing.T) {
+	var gotBody []byte
+	var gotEvent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotEvent = r.Header.Get(EventHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := newTestWebhookNotifier([]string{server.URL}, &mockDeadLetterRepository{})
+	expiresAt := time.Now().Add(48 * time.Hour).UTC()
+	notifier.NotifyExpiring(&domain.ExpiringLot{
+		Lot:         domain.Lot{Id: "lot-1", ProductId: "prod-1", LocationId: "main", LotNumber: "L-7", Quantity: 12, ExpiresAt: &expiresAt},
+		ProductName: "Milk",
+	})
+	notifier.Close()
+
+	var payload expiringPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("webhook body is not valid JSON: %v", err)
+	}
+	if gotEvent != "lot_expiring" || payload.Event != "lot_expiring" || payload.LotNumber != "L-7" || payload.Quantity != 12 ||
+		payload.Severity != SeverityWarning || payload.ExpiresAt == nil || !payload.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("unexpected event %q with payload %+v", gotEvent, payload)
+	}
+}
+
+func TestWebhookNotifier_RetriesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	deadLetters := &mockDeadLetterRepository{}
+	notifier := newTestWebhookNotifier([]string{server.URL}, deadLetters)
+	var mu sync.Mutex
+	var backoffs []time.Duration
+	notifier.sleep = func(d time.Duration) {
+		mu.Lock()
+		backoffs = append(backoffs, d)
+		mu.Unlock()
+	}
+
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Quantity: 1})
+	notifier.Close()
+
+	if calls.Load() != 3 {
+		t.Errorf("webhook called %d times, want 3", calls.Load())
+	}
+	if len(backoffs) != 2 || backoffs[1] != 2*backoffs[0] {
+		t.Errorf("backoffs = %v, want two exponentially growing delays", backoffs)
+	}
+	if deadLetters.count() != 0 {
+		t.Errorf("expected no dead letters after eventual success, got %d", deadLetters.count())
+	}
+}
+
+func TestWebhookNotifier_DeadLetters(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantCalls    int32
+		wantAttempts int
+	}{
+		{"server_errors_exhaust_retries", http.StatusInternalServerError, 3, 3},
+		{"client_errors_are_not_retried", http.StatusBadRequest, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			deadLetters := &mockDeadLetterRepository{}
+			notifier := newTestWebhookNotifier([]string{server.URL}, deadLetters)
+			notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Quantity: 1})
+			notifier.Close()
+
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("webhook called %d times, want %d", calls.Load(), tt.wantCalls)
+			}
+			if deadLetters.count() != 1 {
+				t.Fatalf("expected 1 dead letter, got %d", deadLetters.count())
+			}
+			letter := deadLetters.letters[0]
+			if letter.Target != server.URL || letter.Attempts != tt.wantAttempts || letter.Channel != "webhook" {
+				t.Errorf("unexpected dead letter %+v", letter)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifier_IsAsynchronous(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := newTestWebhookNotifier([]string{server.URL}, &mockDeadLetterRepository{})
+
+	start := time.Now()
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Quantity: 1})
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("NotifyLowStock() blocked for %v while the webhook was pending", elapsed)
+	}
+
+	close(release)
+	notifier.Close()
+}
+
+func TestWebhookNotifier_AfterClose(t *testing.T) {
+	deadLetters := &mockDeadLetterRepository{}
+	notifier := newTestWebhookNotifier([]string{"http://127.0.0.1:0/unused"}, deadLetters)
+	notifier.Close()
+
+	notifier.NotifyLowStock(&domain.Product{Id: "prod-1", Quantity: 1})
+
+	if deadLetters.count() != 1 {
+		t.Errorf("expected notifications after Close() to be dead-lettered, got %d", deadLetters.count())
+	}
+}
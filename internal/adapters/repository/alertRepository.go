@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+const alertColumns = "id, product_id, location_id, quantity, reorder_point, status, triggered_at, acknowledged_at, acknowledged_by, resolved_at"
+
+func scanAlert(row rowScanner) (*domain.Alert, error) {
+	var alert domain.Alert
+	var status, triggeredAt string
+	var acknowledgedAt, resolvedAt sql.NullString
+	err := row.Scan(&alert.Id, &alert.ProductId, &alert.LocationId, &alert.Quantity, &alert.ReorderPoint, &status,
+		&triggeredAt, &acknowledgedAt, &alert.AcknowledgedBy, &resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	alert.Status = domain.AlertStatus(status)
+	if alert.TriggeredAt, err = parseTimestamp(triggeredAt); err != nil {
+		return nil, err
+	}
+	if alert.AcknowledgedAt, err = parseNullTimestamp(acknowledgedAt); err != nil {
+		return nil, err
+	}
+	if alert.ResolvedAt, err = parseNullTimestamp(resolvedAt); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func parseNullTimestamp(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	parsed, err := parseTimestamp(value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func (repo *sqliteRepository) OpenAlert(alert *domain.Alert, notTriggeredSince time.Time) (bool, error) {
+	res, err := repo.db.Exec(
+		`INSERT INTO stock_alerts(id, product_id, location_id, quantity, reorder_point, status, triggered_at)
+		SELECT ?,?,?,?,?,?,?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM stock_alerts WHERE product_id = ? AND location_id = ? AND (status != ? OR triggered_at > ?)
+		)`,
+		alert.Id, alert.ProductId, alert.LocationId, alert.Quantity, alert.ReorderPoint, string(alert.Status), formatTimestamp(alert.TriggeredAt),
+		alert.ProductId, alert.LocationId, string(domain.AlertResolved), formatTimestamp(notTriggeredSince))
+	if err != nil {
+		return false, domain.ErrRepository
+	}
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// ResolveAlerts resolves the product's alerts for one location, or its
+// aggregate alerts when locationId is empty.
+func (repo *sqliteRepository) ResolveAlerts(productId, locationId string, resolvedAt time.Time) error {
+	_, err := repo.db.Exec("UPDATE stock_alerts SET status = ?, resolved_at = ? WHERE product_id = ? AND location_id = ? AND status != ?",
+		string(domain.AlertResolved), formatTimestamp(resolvedAt), productId, locationId, string(domain.AlertResolved))
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) ListAlerts(status domain.AlertStatus) ([]domain.Alert, error) {
+	query := "SELECT " + alertColumns + " FROM stock_alerts"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, string(status))
+	}
+	query += " ORDER BY triggered_at DESC, rowid DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	alerts := []domain.Alert{}
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		alerts = append(alerts, *alert)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return alerts, nil
+}
+
+func (repo *sqliteRepository) AcknowledgeAlert(id string, managerId string, acknowledgedAt time.Time) (*domain.Alert, error) {
+	row := repo.db.QueryRow(
+		`UPDATE stock_alerts SET status = ?, acknowledged_at = ?, acknowledged_by = ?
+		WHERE id = ? AND status = ?
+		RETURNING `+alertColumns,
+		string(domain.AlertAcknowledged), formatTimestamp(acknowledgedAt), managerId, id, string(domain.AlertOpen))
+
+	alert, err := scanAlert(row)
+	if err == nil {
+		return alert, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, domain.ErrRepository
+	}
+
+	alert, err = scanAlert(repo.db.QueryRow("SELECT "+alertColumns+" FROM stock_alerts WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAlertNotFound
+		}
+		return nil, domain.ErrRepository
+	}
+	return alert, nil
+}
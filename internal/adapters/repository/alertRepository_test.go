@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func newTestAlert(productId string, triggeredAt time.Time) *domain.Alert {
+	alert := domain.NewLowStockAlert(&domain.Product{Id: productId, Quantity: 2}, 10)
+	alert.TriggeredAt = triggeredAt
+	return alert
+}
+
+func TestSqliteRepository_OpenAlert(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	opened, err := repo.OpenAlert(newTestAlert("prod-1", start), start.Add(-time.Hour))
+	if err != nil || !opened {
+		t.Fatalf("OpenAlert() = %v, %v, want the first alert to open", opened, err)
+	}
+	if opened, _ := repo.OpenAlert(newTestAlert("prod-1", start.Add(time.Minute)), start); opened {
+		t.Errorf("OpenAlert() opened a second alert while one is still active")
+	}
+	if opened, _ := repo.OpenAlert(newTestAlert("prod-2", start), start); !opened {
+		t.Errorf("OpenAlert() did not open an alert for a different product")
+	}
+	located := domain.NewLocationLowStockAlert(&domain.StockLevel{ProductId: "prod-1", LocationId: "store-1", Quantity: 1, ReorderPoint: 5})
+	located.TriggeredAt = start
+	if opened, _ := repo.OpenAlert(located, start); !opened {
+		t.Errorf("OpenAlert() did not open a location alert next to the aggregate one")
+	}
+
+	if err := repo.ResolveAlerts("prod-1", "", start.Add(2*time.Minute)); err != nil {
+		t.Fatalf("ResolveAlerts() returned an unexpected error: %v", err)
+	}
+	if opened, _ := repo.OpenAlert(newTestAlert("prod-1", start.Add(3*time.Minute)), start.Add(-time.Hour)); opened {
+		t.Errorf("OpenAlert() ignored the cooldown window after a resolved alert")
+	}
+	if opened, _ := repo.OpenAlert(newTestAlert("prod-1", start.Add(2*time.Hour)), start.Add(time.Hour)); !opened {
+		t.Errorf("OpenAlert() did not re-arm once the cooldown had passed")
+	}
+}
+
+func TestSqliteRepository_OpenAlert_Concurrent(t *testing.T) {
+	db := openTempDB(t)
+	createTestSchema(t, db)
+	repo := NewSQLiteRepository(db)
+	now := time.Now()
+
+	var opened atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repo.OpenAlert(newTestAlert("prod-1", now), now)
+			if err != nil {
+				t.Errorf("OpenAlert() returned an unexpected error: %v", err)
+			}
+			if ok {
+				opened.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if opened.Load() != 1 {
+		t.Errorf("concurrent OpenAlert() opened %d alerts, want 1", opened.Load())
+	}
+}
+
+func TestSqliteRepository_ListAndAcknowledgeAlerts(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	first := newTestAlert("prod-1", start)
+	second := newTestAlert("prod-2", start.Add(time.Minute))
+	repo.OpenAlert(first, start.Add(-time.Hour))
+	repo.OpenAlert(second, start.Add(-time.Hour))
+
+	acknowledged, err := repo.AcknowledgeAlert(first.Id, "manager-1", start.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("AcknowledgeAlert() returned an unexpected error: %v", err)
+	}
+	if acknowledged.Status != domain.AlertAcknowledged || acknowledged.AcknowledgedBy != "manager-1" ||
+		acknowledged.AcknowledgedAt == nil || !acknowledged.AcknowledgedAt.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("AcknowledgeAlert() = %+v", acknowledged)
+	}
+	again, err := repo.AcknowledgeAlert(first.Id, "manager-2", start.Add(10*time.Minute))
+	if err != nil || again.AcknowledgedBy != "manager-1" {
+		t.Errorf("second AcknowledgeAlert() = %+v, %v, want the original acknowledgement", again, err)
+	}
+	if _, err := repo.AcknowledgeAlert("missing", "manager-1", start); !errors.Is(err, domain.ErrAlertNotFound) {
+		t.Errorf("AcknowledgeAlert() error = %v, want ErrAlertNotFound", err)
+	}
+
+	tests := []struct {
+		name    string
+		status  domain.AlertStatus
+		wantIds []string
+	}{
+		{"all_newest_first", "", []string{second.Id, first.Id}},
+		{"open", domain.AlertOpen, []string{second.Id}},
+		{"acknowledged", domain.AlertAcknowledged, []string{first.Id}},
+		{"resolved", domain.AlertResolved, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts, err := repo.ListAlerts(tt.status)
+			if err != nil {
+				t.Fatalf("ListAlerts() returned an unexpected error: %v", err)
+			}
+			if len(alerts) != len(tt.wantIds) {
+				t.Fatalf("ListAlerts() returned %d alerts, want %d", len(alerts), len(tt.wantIds))
+			}
+			for i, alert := range alerts {
+				if alert.Id != tt.wantIds[i] {
+					t.Errorf("ListAlerts()[%d] = %s, want %s", i, alert.Id, tt.wantIds[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.APIKeyRepository = (*sqliteRepository)(nil)
+
+const apiKeyColumns = "id, name, prefix, secret_hash, permissions, product_ids, created_by, created_at, expires_at, last_used_at, revoked_at"
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var permissions, productIds, createdAt string
+	var expiresAt, lastUsedAt, revokedAt sql.NullString
+	err := row.Scan(&key.Id, &key.Name, &key.Prefix, &key.SecretHash, &permissions, &productIds,
+		&key.CreatedBy, &createdAt, &expiresAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range splitList(permissions) {
+		key.Permissions = append(key.Permissions, domain.Permission(permission))
+	}
+	key.ProductIds = splitList(productIds)
+	if key.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	if key.ExpiresAt, err = parseNullTimestamp(expiresAt); err != nil {
+		return nil, err
+	}
+	if key.LastUsedAt, err = parseNullTimestamp(lastUsedAt); err != nil {
+		return nil, err
+	}
+	if key.RevokedAt, err = parseNullTimestamp(revokedAt); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+func (repo *sqliteRepository) SaveAPIKey(key *domain.APIKey) error {
+	permissions := make([]string, 0, len(key.Permissions))
+	for _, permission := range key.Permissions {
+		permissions = append(permissions, string(permission))
+	}
+	var expiresAt interface{}
+	if key.ExpiresAt != nil {
+		expiresAt = formatTimestamp(*key.ExpiresAt)
+	}
+
+	_, err := repo.db.Exec(
+		`INSERT INTO api_keys(id, name, prefix, secret_hash, permissions, product_ids, created_by, created_at, expires_at)
+		VALUES(?,?,?,?,?,?,?,?,?)`,
+		key.Id, key.Name, key.Prefix, key.SecretHash, strings.Join(permissions, ","), strings.Join(key.ProductIds, ","),
+		key.CreatedBy, formatTimestamp(key.CreatedAt), expiresAt)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) FindAPIKeyByPrefix(prefix string) (*domain.APIKey, error) {
+	row := repo.db.QueryRow("SELECT "+apiKeyColumns+" FROM api_keys WHERE prefix = ?", prefix)
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, domain.ErrRepository
+	}
+	return key, nil
+}
+
+func (repo *sqliteRepository) ListAPIKeys() ([]domain.APIKey, error) {
+	rows, err := repo.db.Query("SELECT " + apiKeyColumns + " FROM api_keys ORDER BY created_at, name")
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		keys = append(keys, *key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return keys, nil
+}
+
+func (repo *sqliteRepository) RevokeAPIKey(id string, revokedAt time.Time) error {
+	res, err := repo.db.Exec("UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?", formatTimestamp(revokedAt), id)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) TouchAPIKey(id string, usedAt time.Time) error {
+	_, err := repo.db.Exec("UPDATE api_keys SET last_used_at = ? WHERE id = ?", formatTimestamp(usedAt), id)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
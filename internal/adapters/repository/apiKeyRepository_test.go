@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_APIKeys(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(24 * time.Hour)
+
+	scanner, _, _ := domain.NewAPIKey("scanner", []domain.Permission{domain.PermStockSell, domain.PermProductsRead},
+		[]string{"prod-1", "prod-2"}, "admin-1", now, &expiresAt)
+	terminal, _, _ := domain.NewAPIKey("terminal", []domain.Permission{domain.PermStockSell}, nil, "admin-1", now.Add(time.Minute), nil)
+	for _, key := range []*domain.APIKey{scanner, terminal} {
+		if err := repo.SaveAPIKey(key); err != nil {
+			t.Fatalf("SaveAPIKey() returned an unexpected error: %v", err)
+		}
+	}
+
+	found, err := repo.FindAPIKeyByPrefix(scanner.Prefix)
+	if err != nil {
+		t.Fatalf("FindAPIKeyByPrefix() returned an unexpected error: %v", err)
+	}
+	if found.SecretHash != scanner.SecretHash || !reflect.DeepEqual(found.Permissions, scanner.Permissions) ||
+		!reflect.DeepEqual(found.ProductIds, scanner.ProductIds) || !found.ExpiresAt.Equal(expiresAt) || found.LastUsedAt != nil {
+		t.Errorf("FindAPIKeyByPrefix() = %+v, want %+v", found, scanner)
+	}
+	if _, err := repo.FindAPIKeyByPrefix("ik_unknown"); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("FindAPIKeyByPrefix() of an unknown prefix error = %v, want ErrAPIKeyNotFound", err)
+	}
+
+	if err := repo.TouchAPIKey(terminal.Id, now.Add(time.Hour)); err != nil {
+		t.Fatalf("TouchAPIKey() returned an unexpected error: %v", err)
+	}
+	if err := repo.RevokeAPIKey(scanner.Id, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("RevokeAPIKey() returned an unexpected error: %v", err)
+	}
+	if err := repo.RevokeAPIKey("missing", now); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("RevokeAPIKey() of an unknown key error = %v, want ErrAPIKeyNotFound", err)
+	}
+
+	keys, err := repo.ListAPIKeys()
+	if err != nil || len(keys) != 2 || keys[0].Id != scanner.Id {
+		t.Fatalf("ListAPIKeys() = %+v, %v, want both keys oldest first", keys, err)
+	}
+	if keys[0].RevokedAt == nil || !keys[0].RevokedAt.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("revoked key = %+v", keys[0])
+	}
+	if keys[1].LastUsedAt == nil || !keys[1].LastUsedAt.Equal(now.Add(time.Hour)) || len(keys[1].ProductIds) != 0 {
+		t.Errorf("used key = %+v", keys[1])
+	}
+}
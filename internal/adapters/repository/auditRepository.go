@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.AuditRepository = (*sqliteRepository)(nil)
+
+func (repo *sqliteRepository) RecordAudit(entry *domain.AuditEntry) error {
+	return insertAudit(repo.db, entry)
+}
+
+// insertAudit writes entry through db or, for changes that are audited in
+// their own transaction, through the transaction.
+func insertAudit(db execer, entry *domain.AuditEntry) error {
+	before, err := marshalSnapshot(entry.Before)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	after, err := marshalSnapshot(entry.After)
+	if err != nil {
+		return domain.ErrRepository
+	}
+
+	_, err = db.Exec(
+		`INSERT INTO audit_log(id, action, product_id, actor, client_ip, request_id, before, after, created_at)
+		VALUES(?,?,?,?,?,?,?,?,?)`,
+		entry.Id, string(entry.Action), entry.ProductId, entry.Actor, entry.ClientIP, entry.RequestId,
+		before, after, formatTimestamp(entry.CreatedAt))
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) WalkAuditEntries(query domain.AuditQuery, newestFirst bool, visit func(domain.AuditEntry) error) error {
+	statement := `SELECT id, action, product_id, actor, client_ip, request_id, before, after, created_at
+		FROM audit_log WHERE 1 = 1`
+	args := []interface{}{}
+	if query.Actor != "" {
+		statement += " AND actor = ?"
+		args = append(args, query.Actor)
+	}
+	if query.Action != "" {
+		statement += " AND action = ?"
+		args = append(args, string(query.Action))
+	}
+	if query.ProductId != "" {
+		statement += " AND product_id = ?"
+		args = append(args, query.ProductId)
+	}
+	if !query.From.IsZero() {
+		statement += " AND created_at >= ?"
+		args = append(args, formatTimestamp(query.From))
+	}
+	if !query.To.IsZero() {
+		statement += " AND created_at <= ?"
+		args = append(args, formatTimestamp(query.To))
+	}
+	if newestFirst {
+		statement += " ORDER BY created_at DESC, rowid DESC"
+	} else {
+		statement += " ORDER BY created_at, rowid"
+	}
+	if query.Limit > 0 {
+		statement += " LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	rows, err := repo.db.Query(statement, args...)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := visit(*entry); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func scanAuditEntry(row rowScanner) (*domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	var action, createdAt string
+	var before, after sql.NullString
+	if err := row.Scan(&entry.Id, &action, &entry.ProductId, &entry.Actor, &entry.ClientIP, &entry.RequestId,
+		&before, &after, &createdAt); err != nil {
+		return nil, domain.ErrRepository
+	}
+	entry.Action = domain.AuditAction(action)
+
+	var err error
+	if entry.Before, err = unmarshalSnapshot(before); err != nil {
+		return nil, domain.ErrRepository
+	}
+	if entry.After, err = unmarshalSnapshot(after); err != nil {
+		return nil, domain.ErrRepository
+	}
+	if entry.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return &entry, nil
+}
+
+func marshalSnapshot(product *domain.Product) (sql.NullString, error) {
+	if product == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(product)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalSnapshot(value sql.NullString) (*domain.Product, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	var product domain.Product
+	if err := json.Unmarshal([]byte(value.String), &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_AuditLog(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	ctx := domain.ContextWithManagerId(context.Background(), "manager-1")
+	ctx = domain.ContextWithRequestMeta(ctx, domain.RequestMeta{Id: "req-1", ClientIP: "10.0.0.1"})
+	before := &domain.Product{Id: "prod-1", Name: "Laptop", Price: 10, Quantity: 5, Version: 1}
+	after := &domain.Product{Id: "prod-1", Name: "Laptop", Price: 12, Quantity: 5, Version: 2}
+
+	created := domain.NewAuditEntry(ctx, domain.AuditProductCreated, "prod-1", nil, before)
+	created.CreatedAt = now
+	priced := domain.NewAuditEntry(ctx, domain.AuditPriceChanged, "prod-1", before, after)
+	priced.CreatedAt = now.Add(time.Minute)
+	other := domain.NewAuditEntry(domain.ContextWithManagerId(context.Background(), "manager-2"), domain.AuditProductDeleted, "prod-2",
+		before, nil)
+	other.CreatedAt = now.Add(2 * time.Minute)
+	for _, entry := range []*domain.AuditEntry{created, priced, other} {
+		if err := repo.RecordAudit(entry); err != nil {
+			t.Fatalf("RecordAudit() returned an unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		query       domain.AuditQuery
+		newestFirst bool
+		wantIds     []string
+	}{
+		{"all_oldest_first", domain.AuditQuery{}, false, []string{created.Id, priced.Id, other.Id}},
+		{"all_newest_first_limited", domain.AuditQuery{Limit: 2}, true, []string{other.Id, priced.Id}},
+		{"by_actor", domain.AuditQuery{Actor: "manager-2"}, false, []string{other.Id}},
+		{"by_action", domain.AuditQuery{Action: domain.AuditPriceChanged}, false, []string{priced.Id}},
+		{"by_product", domain.AuditQuery{ProductId: "prod-1"}, false, []string{created.Id, priced.Id}},
+		{"by_time", domain.AuditQuery{From: now.Add(time.Minute), To: now.Add(time.Minute)}, false, []string{priced.Id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []string{}
+			err := repo.WalkAuditEntries(tt.query, tt.newestFirst, func(entry domain.AuditEntry) error {
+				ids = append(ids, entry.Id)
+				return nil
+			})
+			if err != nil || !reflect.DeepEqual(ids, tt.wantIds) {
+				t.Errorf("WalkAuditEntries() = %v, %v, want %v", ids, err, tt.wantIds)
+			}
+		})
+	}
+
+	var stored domain.AuditEntry
+	repo.WalkAuditEntries(domain.AuditQuery{Action: domain.AuditPriceChanged}, false, func(entry domain.AuditEntry) error {
+		stored = entry
+		return nil
+	})
+	if stored.Actor != "manager-1" || stored.ClientIP != "10.0.0.1" || stored.RequestId != "req-1" ||
+		!reflect.DeepEqual(stored.Before, before) || !reflect.DeepEqual(stored.After, after) || !stored.CreatedAt.Equal(priced.CreatedAt) {
+		t.Errorf("stored entry = %+v", stored)
+	}
+
+	if _, err := db.Exec("UPDATE audit_log SET actor = 'someone-else'"); err == nil {
+		t.Errorf("updating the audit log succeeded, want it rejected")
+	}
+	if _, err := db.Exec("DELETE FROM audit_log"); err == nil {
+		t.Errorf("deleting from the audit log succeeded, want it rejected")
+	}
+}
+
+func TestSqliteRepository_ChangesAreAuditedInTheirTransaction(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	ctx := domain.ContextWithManagerId(context.Background(), "manager-1")
+	entries := func() []domain.AuditEntry {
+		var found []domain.AuditEntry
+		repo.WalkAuditEntries(domain.AuditQuery{}, false, func(entry domain.AuditEntry) error {
+			found = append(found, entry)
+			return nil
+		})
+		return found
+	}
+
+	product, _ := domain.CreateNewProduct("Lamp", 10, 5)
+	if err := repo.Save(product, nil, domain.NewAuditEntry(ctx, domain.AuditProductCreated, product.Id, nil, nil)); err != nil {
+		t.Fatalf("Save() returned an unexpected error: %v", err)
+	}
+	sale := domain.NewStockMovement(product.Id, -2, domain.MovementSale, "manager-1")
+	sale.LocationId = domain.DefaultLocationId
+	if _, err := repo.ApplyStockMovement(sale, 0, domain.NewAuditEntry(ctx, domain.AuditStockSold, product.Id, product, nil)); err != nil {
+		t.Fatalf("ApplyStockMovement() returned an unexpected error: %v", err)
+	}
+	found := entries()
+	if len(found) != 2 || found[0].After == nil || found[0].After.Quantity != 5 || found[1].After == nil || found[1].After.Quantity != 3 {
+		t.Fatalf("audit entries = %+v, want the created and sold products as they were saved", found)
+	}
+
+	tooMany := domain.NewStockMovement(product.Id, -9, domain.MovementSale, "manager-1")
+	tooMany.LocationId = domain.DefaultLocationId
+	repo.ApplyStockMovement(tooMany, 0, domain.NewAuditEntry(ctx, domain.AuditStockSold, product.Id, product, nil))
+	stale := *product
+	repo.Update(&stale, domain.NewAuditEntry(ctx, domain.AuditPriceChanged, product.Id, product, nil))
+	if found := entries(); len(found) != 2 {
+		t.Errorf("audit entries after failed changes = %d, want 2", len(found))
+	}
+
+	// An entry that cannot be written rolls the change back with it.
+	current, _ := repo.FindById(product.Id)
+	repriced := *current
+	repriced.Price = 99
+	duplicate := domain.NewAuditEntry(ctx, domain.AuditPriceChanged, product.Id, current, nil)
+	duplicate.Id = found[0].Id
+	if err := repo.Update(&repriced, duplicate); err == nil {
+		t.Fatalf("Update() with an unwritable audit entry returned no error")
+	}
+	if stored, _ := repo.FindById(product.Id); stored.Price != 10 || stored.Version != current.Version {
+		t.Errorf("product after the failed audit = %+v, want it unchanged", stored)
+	}
+}
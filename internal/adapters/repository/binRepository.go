@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.BinRepository = (*sqliteRepository)(nil)
+
+const binColumns = "id, location_id, code, aisle, shelf, created_at"
+
+// binSlotQuery joins stock to its bin, with rows coming out in walking order.
+const binSlotQuery = `SELECT b.id, b.location_id, b.code, b.aisle, b.shelf, s.product_id, s.quantity
+	FROM bin_stock s JOIN bins b ON b.id = s.bin_id`
+
+func scanBin(row rowScanner) (*domain.Bin, error) {
+	var bin domain.Bin
+	var createdAt string
+	if err := row.Scan(&bin.Id, &bin.LocationId, &bin.Code, &bin.Aisle, &bin.Shelf, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if bin.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	return &bin, nil
+}
+
+func (repo *sqliteRepository) SaveBin(bin *domain.Bin) error {
+	_, err := repo.db.Exec("INSERT INTO bins("+binColumns+") VALUES(?,?,?,?,?,?)",
+		bin.Id, bin.LocationId, bin.Code, bin.Aisle, bin.Shelf, formatTimestamp(bin.CreatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrBinExists
+		}
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) FindBinById(id string) (*domain.Bin, error) {
+	bin, err := scanBin(repo.db.QueryRow("SELECT "+binColumns+" FROM bins WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrBinNotFound
+		}
+		return nil, domain.ErrRepository
+	}
+	return bin, nil
+}
+
+func (repo *sqliteRepository) ListBins(locationId string) ([]domain.Bin, error) {
+	rows, err := repo.db.Query("SELECT "+binColumns+" FROM bins WHERE location_id = ? ORDER BY aisle, shelf, code", locationId)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	bins := []domain.Bin{}
+	for rows.Next() {
+		bin, err := scanBin(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		bins = append(bins, *bin)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return bins, nil
+}
+
+// SetBinStock records how much of a product a bin holds. A quantity of zero
+// removes the product from the bin. The bins at a location may not hold more
+// of a product than the location has on hand.
+func (repo *sqliteRepository) SetBinStock(stock *domain.BinStock) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return domain.ErrRepository
+	}
+	defer tx.Rollback()
+
+	var locationId string
+	if err := tx.QueryRow("SELECT location_id FROM bins WHERE id = ?", stock.BinId).Scan(&locationId); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrBinNotFound
+		}
+		return domain.ErrRepository
+	}
+	if stock.Quantity == 0 {
+		_, err = tx.Exec("DELETE FROM bin_stock WHERE bin_id = ? AND product_id = ?", stock.BinId, stock.ProductId)
+	} else {
+		_, err = tx.Exec(`INSERT INTO bin_stock(bin_id, product_id, quantity) VALUES(?,?,?)
+			ON CONFLICT(bin_id, product_id) DO UPDATE SET quantity = excluded.quantity`,
+			stock.BinId, stock.ProductId, stock.Quantity)
+	}
+	if err != nil {
+		return domain.ErrRepository
+	}
+
+	onHand, binned, err := binnedStock(tx, stock.ProductId, locationId)
+	if err != nil {
+		return err
+	}
+	if binned > onHand {
+		return fmt.Errorf("%w: the location's bins would hold %d units but only %d are on hand", domain.ErrBinInvalid, binned, onHand)
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+// binnedStock returns how much of a product a location has on hand and how
+// much of that its bins hold.
+func binnedStock(tx *sql.Tx, productId, locationId string) (int, int, error) {
+	var onHand, binned int
+	err := tx.QueryRow(`SELECT
+		COALESCE((SELECT quantity FROM stock_levels WHERE product_id = ? AND location_id = ?), 0),
+		COALESCE((SELECT SUM(s.quantity) FROM bin_stock s JOIN bins b ON b.id = s.bin_id
+			WHERE s.product_id = ? AND b.location_id = ?), 0)`,
+		productId, locationId, productId, locationId).Scan(&onHand, &binned)
+	if err != nil {
+		return 0, 0, domain.ErrRepository
+	}
+	return onHand, binned, nil
+}
+
+// trimBinStock runs inside tx after stock leaves a location. Units the bins
+// still count beyond what is on hand were taken off the shelves, so they
+// are removed from the bins in walking order, the order they are picked in.
+func trimBinStock(tx *sql.Tx, productId, locationId string) error {
+	onHand, binned, err := binnedStock(tx, productId, locationId)
+	if err != nil {
+		return err
+	}
+	excess := binned - onHand
+	if excess <= 0 {
+		return nil
+	}
+
+	rows, err := tx.Query(`SELECT s.bin_id, s.quantity FROM bin_stock s JOIN bins b ON b.id = s.bin_id
+		WHERE s.product_id = ? AND b.location_id = ? ORDER BY b.aisle, b.shelf, b.code`, productId, locationId)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	stocks := []domain.BinStock{}
+	for rows.Next() {
+		stock := domain.BinStock{ProductId: productId}
+		if err := rows.Scan(&stock.BinId, &stock.Quantity); err != nil {
+			rows.Close()
+			return domain.ErrRepository
+		}
+		stocks = append(stocks, stock)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return domain.ErrRepository
+	}
+
+	for _, stock := range stocks {
+		if excess == 0 {
+			break
+		}
+		take := stock.Quantity
+		if take > excess {
+			take = excess
+		}
+		if take == stock.Quantity {
+			_, err = tx.Exec("DELETE FROM bin_stock WHERE bin_id = ? AND product_id = ?", stock.BinId, productId)
+		} else {
+			_, err = tx.Exec("UPDATE bin_stock SET quantity = quantity - ? WHERE bin_id = ? AND product_id = ?", take, stock.BinId, productId)
+		}
+		if err != nil {
+			return domain.ErrRepository
+		}
+		excess -= take
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) ProductBins(productId string) ([]domain.BinSlot, error) {
+	return repo.queryBinSlots(binSlotQuery+" WHERE s.product_id = ? ORDER BY b.location_id, b.aisle, b.shelf, b.code", productId)
+}
+
+func (repo *sqliteRepository) BinSlots(locationId string, productIds []string) ([]domain.BinSlot, error) {
+	if len(productIds) == 0 {
+		return []domain.BinSlot{}, nil
+	}
+	args := []interface{}{locationId}
+	for _, id := range productIds {
+		args = append(args, id)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(productIds)), ",")
+	return repo.queryBinSlots(binSlotQuery+" WHERE b.location_id = ? AND s.product_id IN ("+placeholders+") AND s.quantity > 0"+
+		" ORDER BY b.aisle, b.shelf, b.code", args...)
+}
+
+func (repo *sqliteRepository) queryBinSlots(query string, args ...interface{}) ([]domain.BinSlot, error) {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	slots := []domain.BinSlot{}
+	for rows.Next() {
+		var slot domain.BinSlot
+		if err := rows.Scan(&slot.BinId, &slot.LocationId, &slot.BinCode, &slot.Aisle, &slot.Shelf, &slot.ProductId, &slot.Quantity); err != nil {
+			return nil, domain.ErrRepository
+		}
+		slots = append(slots, slot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return slots, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_Bins(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	now := time.Now()
+
+	far, _ := domain.NewBin(domain.DefaultLocationId, "C2-01", 2, 1, now)
+	near, _ := domain.NewBin(domain.DefaultLocationId, "A1-02", 1, 2, now)
+	first, _ := domain.NewBin(domain.DefaultLocationId, "A1-01", 1, 1, now)
+	elsewhere, _ := domain.NewBin("store-1", "A1-01", 1, 1, now)
+	for _, bin := range []*domain.Bin{far, near, first, elsewhere} {
+		if err := repo.SaveBin(bin); err != nil {
+			t.Fatalf("SaveBin(%s) returned an unexpected error: %v", bin.Code, err)
+		}
+	}
+	duplicate, _ := domain.NewBin(domain.DefaultLocationId, "a1-01", 4, 4, now)
+	if err := repo.SaveBin(duplicate); !errors.Is(err, domain.ErrBinExists) {
+		t.Errorf("SaveBin() with a taken code error = %v, want ErrBinExists", err)
+	}
+	if _, err := repo.FindBinById("missing"); !errors.Is(err, domain.ErrBinNotFound) {
+		t.Errorf("FindBinById() error = %v, want ErrBinNotFound", err)
+	}
+
+	bins, err := repo.ListBins(domain.DefaultLocationId)
+	if err != nil || len(bins) != 3 || bins[0].Id != first.Id || bins[1].Id != near.Id || bins[2].Id != far.Id {
+		t.Errorf("ListBins() = %+v, %v, want the main bins in walking order", bins, err)
+	}
+
+	_, err = db.Exec(`INSERT INTO stock_levels(product_id, location_id, quantity)
+		VALUES('p1', 'main', 7), ('p1', 'store-1', 9), ('p2', 'main', 1), ('p3', 'main', 4)`)
+	if err != nil {
+		t.Fatalf("could not seed stock levels: %v", err)
+	}
+	for _, stock := range []domain.BinStock{
+		{BinId: far.Id, ProductId: "p1", Quantity: 5},
+		{BinId: near.Id, ProductId: "p1", Quantity: 2},
+		{BinId: first.Id, ProductId: "p2", Quantity: 1},
+		{BinId: elsewhere.Id, ProductId: "p1", Quantity: 9},
+		{BinId: first.Id, ProductId: "p3", Quantity: 4},
+		{BinId: first.Id, ProductId: "p3", Quantity: 0},
+	} {
+		if err := repo.SetBinStock(&stock); err != nil {
+			t.Fatalf("SetBinStock() returned an unexpected error: %v", err)
+		}
+	}
+
+	slots, err := repo.BinSlots(domain.DefaultLocationId, []string{"p1", "p2", "p3"})
+	if err != nil {
+		t.Fatalf("BinSlots() returned an unexpected error: %v", err)
+	}
+	wantBins := []string{first.Id, near.Id, far.Id}
+	if len(slots) != len(wantBins) {
+		t.Fatalf("BinSlots() = %+v, want bins %v", slots, wantBins)
+	}
+	for i, slot := range slots {
+		if slot.BinId != wantBins[i] {
+			t.Errorf("BinSlots()[%d] = %s, want %s", i, slot.BinCode, wantBins[i])
+		}
+	}
+
+	productBins, err := repo.ProductBins("p1")
+	if err != nil || len(productBins) != 3 {
+		t.Errorf("ProductBins() = %+v, %v, want three bins across both locations", productBins, err)
+	}
+}
+
+func TestSqliteRepository_BinStockFollowsOnHand(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	store, _ := domain.NewLocation("STORE-1", "High Street", domain.LocationStore, time.Now())
+	repo.SaveLocation(store)
+	product, _ := domain.CreateNewProduct("Lamp", 10, 10)
+	repo.Save(product, nil, nil)
+	now := time.Now()
+
+	first, _ := domain.NewBin(domain.DefaultLocationId, "A1-01", 1, 1, now)
+	second, _ := domain.NewBin(domain.DefaultLocationId, "B1-01", 2, 1, now)
+	for _, bin := range []*domain.Bin{first, second} {
+		repo.SaveBin(bin)
+	}
+	for _, stock := range []domain.BinStock{{BinId: first.Id, ProductId: product.Id, Quantity: 4}, {BinId: second.Id, ProductId: product.Id, Quantity: 6}} {
+		if err := repo.SetBinStock(&stock); err != nil {
+			t.Fatalf("SetBinStock() returned an unexpected error: %v", err)
+		}
+	}
+	if err := repo.SetBinStock(&domain.BinStock{BinId: second.Id, ProductId: product.Id, Quantity: 7}); !errors.Is(err, domain.ErrBinInvalid) {
+		t.Errorf("SetBinStock() above the on-hand quantity error = %v, want ErrBinInvalid", err)
+	}
+	if err := repo.SetBinStock(&domain.BinStock{BinId: "missing", ProductId: product.Id, Quantity: 1}); !errors.Is(err, domain.ErrBinNotFound) {
+		t.Errorf("SetBinStock() of an unknown bin error = %v, want ErrBinNotFound", err)
+	}
+
+	binned := func() (int, int) {
+		quantities := map[string]int{}
+		slots, _ := repo.ProductBins(product.Id)
+		for _, slot := range slots {
+			quantities[slot.BinId] = slot.Quantity
+		}
+		return quantities[first.Id], quantities[second.Id]
+	}
+
+	sale := domain.NewStockMovement(product.Id, -5, domain.MovementSale, "manager-1")
+	sale.LocationId = domain.DefaultLocationId
+	if _, err := repo.ApplyStockMovement(sale, 0, nil); err != nil {
+		t.Fatalf("ApplyStockMovement() returned an unexpected error: %v", err)
+	}
+	if a, b := binned(); a != 0 || b != 5 {
+		t.Errorf("bins after selling 5 = %d, %d, want the first bin in walking order emptied first", a, b)
+	}
+
+	transfer, _ := domain.NewTransfer(product.Id, domain.DefaultLocationId, store.Id, 2, "manager-1", now)
+	if err := repo.CreateTransfer(transfer); err != nil {
+		t.Fatalf("CreateTransfer() returned an unexpected error: %v", err)
+	}
+	if a, b := binned(); a != 0 || b != 3 {
+		t.Errorf("bins after transferring 2 out = %d, %d, want 0, 3", a, b)
+	}
+}
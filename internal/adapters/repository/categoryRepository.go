@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.CategoryRepository = (*sqliteRepository)(nil)
+
+const categoryColumns = "id, name, parent_id, created_at"
+
+// categoryTreeQuery selects the id bound to its placeholder together with the
+// ids of every category below it.
+const categoryTreeQuery = `WITH RECURSIVE tree(id) AS (
+		SELECT ? UNION SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+	) SELECT id FROM tree`
+
+func scanCategory(row rowScanner) (*domain.Category, error) {
+	var category domain.Category
+	var createdAt string
+	if err := row.Scan(&category.Id, &category.Name, &category.ParentId, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if category.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (repo *sqliteRepository) SaveCategory(category *domain.Category) error {
+	_, err := repo.db.Exec("INSERT INTO categories("+categoryColumns+") VALUES(?,?,?,?)",
+		category.Id, category.Name, category.ParentId, formatTimestamp(category.CreatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrCategoryExists
+		}
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) FindCategoryById(id string) (*domain.Category, error) {
+	category, err := scanCategory(repo.db.QueryRow("SELECT "+categoryColumns+" FROM categories WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, domain.ErrRepository
+	}
+	return category, nil
+}
+
+func (repo *sqliteRepository) ListCategories() ([]domain.Category, error) {
+	rows, err := repo.db.Query("SELECT " + categoryColumns + " FROM categories ORDER BY name COLLATE NOCASE, id")
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	categories := []domain.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		categories = append(categories, *category)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return categories, nil
+}
+
+func (repo *sqliteRepository) UpdateCategory(category *domain.Category) error {
+	res, err := repo.db.Exec("UPDATE categories SET name = ?, parent_id = ? WHERE id = ?", category.Name, category.ParentId, category.Id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrCategoryExists
+		}
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	return nil
+}
+
+// DeleteCategory only removes a category nothing live depends on. Deleted
+// products that still point at it lose their category so a restore does not
+// bring back a dangling reference.
+func (repo *sqliteRepository) DeleteCategory(id string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return domain.ErrRepository
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM categories WHERE parent_id = ?)
+		OR EXISTS(SELECT 1 FROM products WHERE category_id = ? AND deleted_at IS NULL)`, id, id).Scan(&inUse)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if inUse {
+		return domain.ErrCategoryInUse
+	}
+
+	res, err := tx.Exec("DELETE FROM categories WHERE id = ?", id)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	if _, err := tx.Exec("UPDATE products SET category_id = '' WHERE category_id = ?", id); err != nil {
+		return domain.ErrRepository
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_Categories(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	electronics := &domain.Category{Id: "c-electronics", Name: "Electronics", CreatedAt: now}
+	cables := &domain.Category{Id: "c-cables", Name: "Cables", ParentId: electronics.Id, CreatedAt: now}
+	grocery := &domain.Category{Id: "c-grocery", Name: "Grocery", CreatedAt: now}
+	for _, category := range []*domain.Category{electronics, cables, grocery} {
+		if err := repo.SaveCategory(category); err != nil {
+			t.Fatalf("SaveCategory() returned an unexpected error: %v", err)
+		}
+	}
+	duplicate := &domain.Category{Id: "c-dup", Name: "cables", ParentId: electronics.Id, CreatedAt: now}
+	if err := repo.SaveCategory(duplicate); !errors.Is(err, domain.ErrCategoryExists) {
+		t.Errorf("SaveCategory() of a duplicate name error = %v, want ErrCategoryExists", err)
+	}
+
+	found, err := repo.FindCategoryById(cables.Id)
+	if err != nil || *found != *cables {
+		t.Errorf("FindCategoryById() = %+v, %v, want %+v", found, err, cables)
+	}
+	if _, err := repo.FindCategoryById("missing"); !errors.Is(err, domain.ErrCategoryNotFound) {
+		t.Errorf("FindCategoryById() of a missing category error = %v, want ErrCategoryNotFound", err)
+	}
+	categories, err := repo.ListCategories()
+	if err != nil || len(categories) != 3 || categories[0].Id != cables.Id {
+		t.Errorf("ListCategories() = %+v, %v, want 3 categories sorted by name", categories, err)
+	}
+
+	seedListingProducts(t, repo)
+	for id, categoryId := range map[string]string{"p1": cables.Id, "p3": electronics.Id, "p5": grocery.Id} {
+		product, _ := repo.FindById(id)
+		product.CategoryId = categoryId
+		if err := repo.Update(product, nil); err != nil {
+			t.Fatalf("Update() returned an unexpected error: %v", err)
+		}
+	}
+
+	page, err := repo.ListProducts(domain.ProductQuery{CategoryId: electronics.Id})
+	if err != nil || productIds(page.Products) != "p1,p3," {
+		t.Errorf("ListProducts() in electronics = %v, %v, want p1 and p3 including subcategories", page, err)
+	}
+	page, _ = repo.ListProducts(domain.ProductQuery{CategoryId: cables.Id})
+	if productIds(page.Products) != "p1," {
+		t.Errorf("ListProducts() in cables = %s, want p1", productIds(page.Products))
+	}
+
+	values, err := repo.InventoryValueByCategory("")
+	if err != nil {
+		t.Fatalf("InventoryValueByCategory() returned an unexpected error: %v", err)
+	}
+	if values[cables.Id] != 480 || values[electronics.Id] != 2400 || values[grocery.Id] != 80 || values[""] != 18 {
+		t.Errorf("InventoryValueByCategory() = %v", values)
+	}
+
+	if err := repo.DeleteCategory(electronics.Id); !errors.Is(err, domain.ErrCategoryInUse) {
+		t.Errorf("DeleteCategory() with a subcategory error = %v, want ErrCategoryInUse", err)
+	}
+	if err := repo.DeleteCategory(grocery.Id); !errors.Is(err, domain.ErrCategoryInUse) {
+		t.Errorf("DeleteCategory() with products error = %v, want ErrCategoryInUse", err)
+	}
+	if err := repo.DeleteById("p5", domain.NewStockMovement("p5", 0, domain.MovementDelete, ""), nil); err != nil {
+		t.Fatalf("DeleteById() returned an unexpected error: %v", err)
+	}
+	if err := repo.DeleteCategory(grocery.Id); err != nil {
+		t.Fatalf("DeleteCategory() after its product was deleted returned an error: %v", err)
+	}
+	restored, err := repo.RestoreById("p5", domain.NewStockMovement("p5", 0, domain.MovementRestore, ""), nil)
+	if err != nil || restored.CategoryId != "" {
+		t.Errorf("RestoreById() = %+v, %v, want the product without its deleted category", restored, err)
+	}
+	if err := repo.DeleteCategory(grocery.Id); !errors.Is(err, domain.ErrCategoryNotFound) {
+		t.Errorf("DeleteCategory() twice error = %v, want ErrCategoryNotFound", err)
+	}
+
+	cables.Name, cables.ParentId = "Leads", ""
+	if err := repo.UpdateCategory(cables); err != nil {
+		t.Fatalf("UpdateCategory() returned an unexpected error: %v", err)
+	}
+	if found, _ := repo.FindCategoryById(cables.Id); found.Name != "Leads" || found.ParentId != "" {
+		t.Errorf("FindCategoryById() after update = %+v", found)
+	}
+	cables.Name = "electronics"
+	if err := repo.UpdateCategory(cables); !errors.Is(err, domain.ErrCategoryExists) {
+		t.Errorf("UpdateCategory() to a taken name error = %v, want ErrCategoryExists", err)
+	}
+	if err := repo.UpdateCategory(&domain.Category{Id: "missing", Name: "Missing"}); !errors.Is(err, domain.ErrCategoryNotFound) {
+		t.Errorf("UpdateCategory() of a missing category error = %v, want ErrCategoryNotFound", err)
+	}
+}
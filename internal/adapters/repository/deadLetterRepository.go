@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func (repo *sqliteRepository) SaveDeadLetter(letter *domain.DeadLetter) error {
+	_, err := repo.db.Exec(
+		`INSERT INTO notification_dead_letters(id, channel, target, event, payload, error, attempts, created_at)
+		VALUES(?,?,?,?,?,?,?,?)`,
+		letter.Id, letter.Channel, letter.Target, letter.Event, letter.Payload, letter.Error,
+		letter.Attempts, formatTimestamp(letter.CreatedAt))
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_SaveDeadLetter(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	letter := domain.NewDeadLetter("webhook", "https://hooks.example.com/stock", "low_stock", `{"quantity":3}`, "status 503", 5)
+	if err := repo.SaveDeadLetter(letter); err != nil {
+		t.Fatalf("SaveDeadLetter() returned an unexpected error: %v", err)
+	}
+
+	var target, reason string
+	var attempts int
+	row := db.QueryRow("SELECT target, error, attempts FROM notification_dead_letters WHERE id = ?", letter.Id)
+	if err := row.Scan(&target, &reason, &attempts); err != nil {
+		t.Fatalf("dead letter was not stored: %v", err)
+	}
+	if target != letter.Target || reason != letter.Error || attempts != 5 {
+		t.Errorf("stored dead letter = %s/%s/%d, want %+v", target, reason, attempts, letter)
+	}
+
+	if err := repo.SaveDeadLetter(letter); !errors.Is(err, domain.ErrRepository) {
+		t.Errorf("expected ErrRepository for duplicate id, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.LocationRepository = (*sqliteRepository)(nil)
+
+const locationColumns = "id, code, name, kind, created_at"
+
+func scanLocation(row rowScanner) (*domain.Location, error) {
+	var location domain.Location
+	var kind, createdAt string
+	if err := row.Scan(&location.Id, &location.Code, &location.Name, &kind, &createdAt); err != nil {
+		return nil, err
+	}
+	location.Kind = domain.LocationKind(kind)
+	var err error
+	if location.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// addStockLevel puts quantity units into a product's stock at one location
+// inside tx.
+func addStockLevel(tx *sql.Tx, productId, locationId string, quantity int) error {
+	_, err := tx.Exec(`INSERT INTO stock_levels(product_id, location_id, quantity) VALUES(?,?,?)
+		ON CONFLICT(product_id, location_id) DO UPDATE SET quantity = quantity + excluded.quantity`,
+		productId, locationId, quantity)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+// takeStockLevel takes quantity units out of a product's stock at one
+// location inside tx while leaving at least reserved units behind. Taking
+// more fails with ErrInsufficientStock. The location's bins are trimmed to
+// match.
+func takeStockLevel(tx *sql.Tx, productId, locationId string, quantity, reserved int) error {
+	res, err := tx.Exec(`UPDATE stock_levels SET quantity = quantity - ?
+		WHERE product_id = ? AND location_id = ? AND quantity - ? >= ?`,
+		quantity, productId, locationId, quantity, reserved)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrInsufficientStock
+	}
+	return trimBinStock(tx, productId, locationId)
+}
+
+// moveLocationStock applies a movement to the stock and lots at its location
+// inside tx. A decrease tied to a lot comes out of that lot only; any other
+// decrease is drawn from the location's unexpired lots.
+func moveLocationStock(tx *sql.Tx, movement *domain.StockMovement) error {
+	switch {
+	case movement.Delta >= 0:
+		return addStockLevel(tx, movement.ProductId, movement.LocationId, movement.Delta)
+	case movement.LotId != "":
+		return takeFromLot(tx, movement.ProductId, movement.LocationId, movement.LotId, -movement.Delta)
+	}
+	_, err := drawStock(tx, movement.ProductId, movement.LocationId, -movement.Delta, movement.CreatedAt)
+	return err
+}
+
+func (repo *sqliteRepository) SaveLocation(location *domain.Location) error {
+	_, err := repo.db.Exec("INSERT INTO locations("+locationColumns+") VALUES(?,?,?,?,?)",
+		location.Id, location.Code, location.Name, string(location.Kind), formatTimestamp(location.CreatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrLocationExists
+		}
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) FindLocationById(id string) (*domain.Location, error) {
+	location, err := scanLocation(repo.db.QueryRow("SELECT "+locationColumns+" FROM locations WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrLocationNotFound
+		}
+		return nil, domain.ErrRepository
+	}
+	return location, nil
+}
+
+func (repo *sqliteRepository) ListLocations() ([]domain.Location, error) {
+	rows, err := repo.db.Query("SELECT " + locationColumns + " FROM locations ORDER BY code")
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	locations := []domain.Location{}
+	for rows.Next() {
+		location, err := scanLocation(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		locations = append(locations, *location)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return locations, nil
+}
+
+// FindStockLevel returns an empty level for a location that never held the
+// product.
+func (repo *sqliteRepository) FindStockLevel(productId, locationId string) (*domain.StockLevel, error) {
+	level := &domain.StockLevel{ProductId: productId, LocationId: locationId}
+	err := repo.db.QueryRow("SELECT quantity, reorder_point FROM stock_levels WHERE product_id = ? AND location_id = ?",
+		productId, locationId).Scan(&level.Quantity, &level.ReorderPoint)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, domain.ErrRepository
+	}
+	return level, nil
+}
+
+func (repo *sqliteRepository) ProductStock(productId string) (*domain.ProductStock, error) {
+	rows, err := repo.db.Query(`SELECT s.location_id, s.quantity, s.reorder_point FROM stock_levels s
+		JOIN locations l ON l.id = s.location_id
+		WHERE s.product_id = ? ORDER BY l.code`, productId)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	stock := &domain.ProductStock{ProductId: productId, Levels: []domain.StockLevel{}}
+	for rows.Next() {
+		level := domain.StockLevel{ProductId: productId}
+		if err := rows.Scan(&level.LocationId, &level.Quantity, &level.ReorderPoint); err != nil {
+			return nil, domain.ErrRepository
+		}
+		stock.Levels = append(stock.Levels, level)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+
+	err = repo.db.QueryRow("SELECT COALESCE(SUM(quantity), 0) FROM stock_transfers WHERE product_id = ? AND status = ?",
+		productId, string(domain.TransferInTransit)).Scan(&stock.InTransit)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	return stock, nil
+}
+
+func (repo *sqliteRepository) SetStockReorderPoint(level *domain.StockLevel) error {
+	_, err := repo.db.Exec(`INSERT INTO stock_levels(product_id, location_id, reorder_point) VALUES(?,?,?)
+		ON CONFLICT(product_id, location_id) DO UPDATE SET reorder_point = excluded.reorder_point`,
+		level.ProductId, level.LocationId, level.ReorderPoint)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
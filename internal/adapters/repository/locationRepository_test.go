@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_Locations(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	store, _ := domain.NewLocation("store-1", "High Street", domain.LocationStore, time.Now())
+	if err := repo.SaveLocation(store); err != nil {
+		t.Fatalf("SaveLocation() returned an unexpected error: %v", err)
+	}
+	duplicate, _ := domain.NewLocation("STORE-1", "Other", domain.LocationStore, time.Now())
+	if err := repo.SaveLocation(duplicate); !errors.Is(err, domain.ErrLocationExists) {
+		t.Errorf("SaveLocation() with a taken code error = %v, want ErrLocationExists", err)
+	}
+
+	found, err := repo.FindLocationById(store.Id)
+	if err != nil || found.Code != "STORE-1" || found.Kind != domain.LocationStore {
+		t.Errorf("FindLocationById() = %+v, %v", found, err)
+	}
+	if _, err := repo.FindLocationById("missing"); !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Errorf("FindLocationById() error = %v, want ErrLocationNotFound", err)
+	}
+
+	locations, err := repo.ListLocations()
+	if err != nil || len(locations) != 2 || locations[0].Id != domain.DefaultLocationId || locations[1].Id != store.Id {
+		t.Errorf("ListLocations() = %+v, %v, want the main warehouse then the store", locations, err)
+	}
+}
+
+func TestSqliteRepository_StockLevels(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	store, _ := domain.NewLocation("STORE-1", "High Street", domain.LocationStore, time.Now())
+	repo.SaveLocation(store)
+	product, _ := domain.CreateNewProduct("Lamp", 10, 8)
+	repo.Save(product, nil, nil)
+
+	tests := []struct {
+		name       string
+		locationId string
+		delta      int
+		wantErr    error
+		wantMain   int
+		wantStore  int
+	}{
+		{"restock_store", store.Id, 5, nil, 8, 5},
+		{"sell_at_store", store.Id, -3, nil, 8, 2},
+		{"fail_more_than_store_holds", store.Id, -4, domain.ErrInsufficientStock, 8, 2},
+		{"sell_at_main", domain.DefaultLocationId, -8, nil, 0, 2},
+		{"fail_location_without_stock", "elsewhere", -1, domain.ErrInsufficientStock, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movement := domain.NewStockMovement(product.Id, tt.delta, domain.MovementAdjustment, "manager-1")
+			movement.LocationId = tt.locationId
+			if _, err := repo.ApplyStockMovement(movement, 0, nil); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyStockMovement() error = %v, want %v", err, tt.wantErr)
+			}
+			main, _ := repo.FindStockLevel(product.Id, domain.DefaultLocationId)
+			atStore, _ := repo.FindStockLevel(product.Id, store.Id)
+			if main.Quantity != tt.wantMain || atStore.Quantity != tt.wantStore {
+				t.Errorf("levels = main %d, store %d, want %d and %d", main.Quantity, atStore.Quantity, tt.wantMain, tt.wantStore)
+			}
+			stored, _ := repo.FindById(product.Id)
+			if stored.Quantity != tt.wantMain+tt.wantStore {
+				t.Errorf("product quantity = %d, want the sum of its levels %d", stored.Quantity, tt.wantMain+tt.wantStore)
+			}
+		})
+	}
+
+	movements, _ := repo.ListMovements(product.Id, time.Time{}, time.Time{})
+	if len(movements) != 3 || movements[0].LocationId != store.Id || movements[2].LocationId != domain.DefaultLocationId {
+		t.Errorf("ListMovements() = %+v, want three movements tagged with their locations", movements)
+	}
+
+	if err := repo.SetStockReorderPoint(&domain.StockLevel{ProductId: product.Id, LocationId: store.Id, ReorderPoint: 4}); err != nil {
+		t.Fatalf("SetStockReorderPoint() returned an unexpected error: %v", err)
+	}
+	stock, err := repo.ProductStock(product.Id)
+	if err != nil {
+		t.Fatalf("ProductStock() returned an unexpected error: %v", err)
+	}
+	want := []domain.StockLevel{
+		{ProductId: product.Id, LocationId: domain.DefaultLocationId, Quantity: 0},
+		{ProductId: product.Id, LocationId: store.Id, Quantity: 2, ReorderPoint: 4},
+	}
+	if len(stock.Levels) != len(want) || stock.Levels[0] != want[0] || stock.Levels[1] != want[1] || stock.InTransit != 0 {
+		t.Errorf("ProductStock() = %+v, want levels %+v", stock, want)
+	}
+
+	if values, _ := repo.InventoryValueByCategory(store.Id); values[""] != 20 {
+		t.Errorf("InventoryValueByCategory(store) = %v, want 20", values)
+	}
+	if values, _ := repo.InventoryValueByCategory(""); values[""] != 20 {
+		t.Errorf("InventoryValueByCategory() = %v, want 20", values)
+	}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.LoginAttemptTracker = (*sqliteRepository)(nil)
+
+func (repo *sqliteRepository) RecordLoginAttempt(attempt *domain.LoginAttempt) error {
+	_, err := repo.db.Exec(
+		"INSERT INTO auth_audit(id, email, ip, outcome, reason, occurred_at) VALUES(?,?,?,?,?,?)",
+		attempt.Id, attempt.Email, attempt.IP, string(attempt.Outcome), attempt.Reason, formatTimestamp(attempt.OccurredAt))
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) EmailFailures(email string, since time.Time) (domain.FailureStats, error) {
+	return repo.failureStats(
+		`SELECT COUNT(*), MIN(occurred_at), MAX(occurred_at) FROM auth_audit
+		WHERE email = ? AND outcome = ? AND occurred_at > ?
+		AND occurred_at > COALESCE((SELECT MAX(occurred_at) FROM auth_audit WHERE email = ? AND outcome = ?), '')`,
+		email, string(domain.LoginFailed), formatTimestamp(since), email, string(domain.LoginSucceeded))
+}
+
+func (repo *sqliteRepository) IPFailures(ip string, since time.Time) (domain.FailureStats, error) {
+	return repo.failureStats(
+		"SELECT COUNT(*), MIN(occurred_at), MAX(occurred_at) FROM auth_audit WHERE ip = ? AND outcome = ? AND occurred_at > ?",
+		ip, string(domain.LoginFailed), formatTimestamp(since))
+}
+
+func (repo *sqliteRepository) failureStats(query string, args ...interface{}) (domain.FailureStats, error) {
+	var stats domain.FailureStats
+	var first, last sql.NullString
+	if err := repo.db.QueryRow(query, args...).Scan(&stats.Count, &first, &last); err != nil {
+		return stats, domain.ErrRepository
+	}
+	if stats.Count == 0 {
+		return stats, nil
+	}
+	var err error
+	if stats.First, err = parseTimestamp(first.String); err != nil {
+		return stats, domain.ErrRepository
+	}
+	if stats.Last, err = parseTimestamp(last.String); err != nil {
+		return stats, domain.ErrRepository
+	}
+	return stats, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_LoginAttempts(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	attempts := []*domain.LoginAttempt{
+		domain.NewLoginAttempt("a@example.com", "10.0.0.1", domain.LoginFailed, "invalid credentials", now.Add(-2*time.Hour)),
+		domain.NewLoginAttempt("a@example.com", "10.0.0.1", domain.LoginFailed, "invalid credentials", now.Add(-10*time.Minute)),
+		domain.NewLoginAttempt("a@example.com", "10.0.0.1", domain.LoginSucceeded, "", now.Add(-9*time.Minute)),
+		domain.NewLoginAttempt("a@example.com", "10.0.0.2", domain.LoginFailed, "invalid credentials", now.Add(-5*time.Minute)),
+		domain.NewLoginAttempt("a@example.com", "10.0.0.2", domain.LoginThrottled, "", now.Add(-4*time.Minute)),
+		domain.NewLoginAttempt("a@example.com", "10.0.0.2", domain.LoginFailed, "invalid credentials", now.Add(-3*time.Minute)),
+		domain.NewLoginAttempt("b@example.com", "10.0.0.1", domain.LoginFailed, "invalid credentials", now.Add(-time.Minute)),
+	}
+	for _, attempt := range attempts {
+		if err := repo.RecordLoginAttempt(attempt); err != nil {
+			t.Fatalf("RecordLoginAttempt() returned an unexpected error: %v", err)
+		}
+	}
+
+	since := now.Add(-time.Hour)
+	tests := []struct {
+		name      string
+		stats     func() (domain.FailureStats, error)
+		wantCount int
+		wantFirst time.Time
+		wantLast  time.Time
+	}{
+		{"email_failures_after_last_success", func() (domain.FailureStats, error) { return repo.EmailFailures("a@example.com", since) }, 2, now.Add(-5 * time.Minute), now.Add(-3 * time.Minute)},
+		{"email_without_failures", func() (domain.FailureStats, error) { return repo.EmailFailures("c@example.com", since) }, 0, time.Time{}, time.Time{}},
+		{"ip_failures_ignore_success", func() (domain.FailureStats, error) { return repo.IPFailures("10.0.0.1", since) }, 2, now.Add(-10 * time.Minute), now.Add(-time.Minute)},
+		{"ip_failures_in_window", func() (domain.FailureStats, error) { return repo.IPFailures("10.0.0.1", now.Add(-2*time.Minute)) }, 1, now.Add(-time.Minute), now.Add(-time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := tt.stats()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stats.Count != tt.wantCount || !stats.First.Equal(tt.wantFirst) || !stats.Last.Equal(tt.wantLast) {
+				t.Errorf("stats = %+v, want %d failures from %v to %v", stats, tt.wantCount, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
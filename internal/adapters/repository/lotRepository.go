@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.LotRepository = (*sqliteRepository)(nil)
+
+const lotColumns = "id, product_id, location_id, lot_number, quantity, received_at, expires_at, expiry_notified_at"
+
+func scanLot(row rowScanner, extra ...interface{}) (*domain.Lot, error) {
+	var lot domain.Lot
+	var receivedAt string
+	var expiresAt, notifiedAt sql.NullString
+	dest := append([]interface{}{&lot.Id, &lot.ProductId, &lot.LocationId, &lot.LotNumber, &lot.Quantity,
+		&receivedAt, &expiresAt, &notifiedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	var err error
+	if lot.ReceivedAt, err = parseTimestamp(receivedAt); err != nil {
+		return nil, err
+	}
+	if lot.ExpiresAt, err = parseNullTimestamp(expiresAt); err != nil {
+		return nil, err
+	}
+	if lot.ExpiryNotifiedAt, err = parseNullTimestamp(notifiedAt); err != nil {
+		return nil, err
+	}
+	return &lot, nil
+}
+
+// locationLots loads the lots of a product at one location that still hold
+// stock.
+func locationLots(tx *sql.Tx, productId, locationId string) ([]domain.Lot, error) {
+	rows, err := tx.Query("SELECT "+lotColumns+" FROM lots WHERE product_id = ? AND location_id = ? AND quantity > 0",
+		productId, locationId)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	lots := []domain.Lot{}
+	for rows.Next() {
+		lot, err := scanLot(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		lots = append(lots, *lot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return lots, nil
+}
+
+// drawStock takes quantity units out of a location inside tx, from its lots
+// first-expiry-first-out and then from untracked stock. Lots that expired
+// before at are left for a write-off, so their units cannot be taken.
+func drawStock(tx *sql.Tx, productId, locationId string, quantity int, at time.Time) ([]domain.LotDraw, error) {
+	lots, err := locationLots(tx, productId, locationId)
+	if err != nil {
+		return nil, err
+	}
+	expired := 0
+	for i := range lots {
+		if lots[i].IsExpiredAt(at) {
+			expired += lots[i].Quantity
+		}
+	}
+	if err := takeStockLevel(tx, productId, locationId, quantity, expired); err != nil {
+		if errors.Is(err, domain.ErrInsufficientStock) && expired > 0 {
+			return nil, fmt.Errorf("%w: %d units at the location have expired and must be written off", err, expired)
+		}
+		return nil, err
+	}
+
+	draws := domain.DrawLotsFEFO(lots, quantity, at)
+	for _, draw := range draws {
+		if _, err := tx.Exec("UPDATE lots SET quantity = quantity - ? WHERE id = ?", draw.Quantity, draw.LotId); err != nil {
+			return nil, domain.ErrRepository
+		}
+	}
+	return draws, nil
+}
+
+// takeFromLot takes quantity units out of one lot and its location inside
+// tx, whether or not the lot has expired.
+func takeFromLot(tx *sql.Tx, productId, locationId, lotId string, quantity int) error {
+	res, err := tx.Exec("UPDATE lots SET quantity = quantity - ? WHERE id = ? AND product_id = ? AND location_id = ? AND quantity >= ?",
+		quantity, lotId, productId, locationId, quantity)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrInsufficientStock
+	}
+	return takeStockLevel(tx, productId, locationId, quantity, 0)
+}
+
+func insertLot(tx *sql.Tx, lot *domain.Lot) error {
+	var expiresAt interface{}
+	if lot.ExpiresAt != nil {
+		expiresAt = formatTimestamp(*lot.ExpiresAt)
+	}
+	_, err := tx.Exec(`INSERT INTO lots(id, product_id, location_id, lot_number, quantity, received_at, expires_at)
+		VALUES(?,?,?,?,?,?,?)`,
+		lot.Id, lot.ProductId, lot.LocationId, lot.LotNumber, lot.Quantity, formatTimestamp(lot.ReceivedAt), expiresAt)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) ReceiveLot(lot *domain.Lot, movement *domain.StockMovement, expectedVersion int, audit *domain.AuditEntry) (*domain.Product, error) {
+	movement.LotId = lot.Id
+	return repo.applyStockMovement(movement, expectedVersion, audit, func(tx *sql.Tx) error {
+		return insertLot(tx, lot)
+	})
+}
+
+func (repo *sqliteRepository) FindLotById(id string) (*domain.Lot, error) {
+	lot, err := scanLot(repo.db.QueryRow("SELECT "+lotColumns+" FROM lots WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrLotNotFound
+		}
+		return nil, domain.ErrRepository
+	}
+	return lot, nil
+}
+
+func (repo *sqliteRepository) ListLots(productId string) ([]domain.Lot, error) {
+	rows, err := repo.db.Query("SELECT "+lotColumns+" FROM lots WHERE product_id = ? ORDER BY received_at, rowid", productId)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	lots := []domain.Lot{}
+	for rows.Next() {
+		lot, err := scanLot(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		lots = append(lots, *lot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return lots, nil
+}
+
+// ExpiringLots lists lots of live products that still hold stock expiring at
+// or before by, soonest first. An empty locationId covers every location.
+func (repo *sqliteRepository) ExpiringLots(by time.Time, locationId string) ([]domain.ExpiringLot, error) {
+	query := `SELECT l.id, l.product_id, l.location_id, l.lot_number, l.quantity, l.received_at, l.expires_at,
+		l.expiry_notified_at, p.name, p.category_id
+		FROM lots l JOIN products p ON p.id = l.product_id
+		WHERE l.quantity > 0 AND l.expires_at IS NOT NULL AND l.expires_at <= ? AND p.deleted_at IS NULL`
+	args := []interface{}{formatTimestamp(by)}
+	if locationId != "" {
+		query += " AND l.location_id = ?"
+		args = append(args, locationId)
+	}
+	query += " ORDER BY l.expires_at, l.received_at"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	lots := []domain.ExpiringLot{}
+	for rows.Next() {
+		var productName, categoryId string
+		lot, err := scanLot(rows, &productName, &categoryId)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		lots = append(lots, domain.ExpiringLot{Lot: *lot, ProductName: productName, CategoryId: categoryId})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return lots, nil
+}
+
+func (repo *sqliteRepository) MarkLotExpiryNotified(id string, at time.Time) error {
+	if _, err := repo.db.Exec("UPDATE lots SET expiry_notified_at = ? WHERE id = ?", formatTimestamp(at), id); err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_LotsAreConsumedFEFO(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	product, _ := domain.CreateNewProduct("Milk", 2, 3)
+	repo.Save(product, nil, nil)
+
+	now := time.Now()
+	soon, later := now.Add(48*time.Hour), now.Add(240*time.Hour)
+	lateLot, _ := domain.NewLot(product.Id, domain.DefaultLocationId, 5, domain.LotReceipt{LotNumber: "L-LATE", ExpiresAt: &later}, now)
+	soonLot, _ := domain.NewLot(product.Id, domain.DefaultLocationId, 4, domain.LotReceipt{LotNumber: "L-SOON", ExpiresAt: &soon}, now)
+	for i, lot := range []*domain.Lot{lateLot, soonLot} {
+		movement := domain.NewStockMovement(product.Id, lot.Quantity, domain.MovementRestock, "manager-1")
+		movement.LocationId = domain.DefaultLocationId
+		if _, err := repo.ReceiveLot(lot, movement, i+1, nil); err != nil {
+			t.Fatalf("ReceiveLot(%s) returned an unexpected error: %v", lot.LotNumber, err)
+		}
+	}
+
+	sale := domain.NewStockMovement(product.Id, -6, domain.MovementSale, "manager-1")
+	sale.LocationId = domain.DefaultLocationId
+	updated, err := repo.ApplyStockMovement(sale, 3, nil)
+	if err != nil || updated.Quantity != 6 {
+		t.Fatalf("ApplyStockMovement() = %+v, %v, want 6 left", updated, err)
+	}
+
+	lots, err := repo.ListLots(product.Id)
+	if err != nil || len(lots) != 2 {
+		t.Fatalf("ListLots() = %+v, %v", lots, err)
+	}
+	remaining := map[string]int{}
+	for _, lot := range lots {
+		remaining[lot.LotNumber] = lot.Quantity
+	}
+	if remaining["L-SOON"] != 0 || remaining["L-LATE"] != 3 {
+		t.Errorf("lots after selling 6 = %v, want the soonest lot used up first", remaining)
+	}
+
+	// The three units from before lots were tracked go last.
+	sale = domain.NewStockMovement(product.Id, -5, domain.MovementSale, "manager-1")
+	sale.LocationId = domain.DefaultLocationId
+	if _, err := repo.ApplyStockMovement(sale, 4, nil); err != nil {
+		t.Fatalf("ApplyStockMovement() returned an unexpected error: %v", err)
+	}
+	if lots, _ := repo.ListLots(product.Id); lots[0].Quantity+lots[1].Quantity != 0 {
+		t.Errorf("lots after selling past them = %+v, want them empty", lots)
+	}
+
+	movements, _ := repo.ListMovements(product.Id, time.Time{}, time.Time{})
+	if len(movements) != 4 || movements[0].LotId != lateLot.Id || movements[2].LotId != "" {
+		t.Errorf("ListMovements() = %+v, want restocks to point at their lots", movements)
+	}
+}
+
+func TestSqliteRepository_ExpiringLots(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	milk, _ := domain.CreateNewProduct("Milk", 2, 0)
+	bread, _ := domain.CreateNewProduct("Bread", 3, 0)
+	repo.Save(milk, nil, nil)
+	repo.Save(bread, nil, nil)
+
+	now := time.Now()
+	inTwoDays, inThreeDays, nextMonth := now.Add(48*time.Hour), now.Add(72*time.Hour), now.Add(720*time.Hour)
+	receive := func(product *domain.Product, locationId string, expiresAt *time.Time) *domain.Lot {
+		lot, _ := domain.NewLot(product.Id, locationId, 2, domain.LotReceipt{ExpiresAt: expiresAt}, now)
+		movement := domain.NewStockMovement(product.Id, 2, domain.MovementRestock, "manager-1")
+		movement.LocationId = locationId
+		if _, err := repo.ReceiveLot(lot, movement, 0, nil); err != nil {
+			t.Fatalf("ReceiveLot() returned an unexpected error: %v", err)
+		}
+		return lot
+	}
+	breadLot := receive(bread, domain.DefaultLocationId, &inThreeDays)
+	milkLot := receive(milk, domain.DefaultLocationId, &inTwoDays)
+	receive(milk, "store-1", &inTwoDays)
+	receive(milk, domain.DefaultLocationId, &nextMonth)
+	receive(bread, domain.DefaultLocationId, nil)
+
+	lots, err := repo.ExpiringLots(now.Add(7*24*time.Hour), domain.DefaultLocationId)
+	if err != nil || len(lots) != 2 || lots[0].Id != milkLot.Id || lots[1].Id != breadLot.Id || lots[0].ProductName != "Milk" {
+		t.Fatalf("ExpiringLots() = %+v, %v, want milk then bread at main", lots, err)
+	}
+	if all, _ := repo.ExpiringLots(now.Add(7*24*time.Hour), ""); len(all) != 3 {
+		t.Errorf("ExpiringLots() across locations returned %d lots, want 3", len(all))
+	}
+
+	if err := repo.MarkLotExpiryNotified(milkLot.Id, now); err != nil {
+		t.Fatalf("MarkLotExpiryNotified() returned an unexpected error: %v", err)
+	}
+	lots, _ = repo.ExpiringLots(now.Add(7*24*time.Hour), domain.DefaultLocationId)
+	if lots[0].ExpiryNotifiedAt == nil || lots[1].ExpiryNotifiedAt != nil {
+		t.Errorf("ExpiringLots() after marking = %+v, want only the milk lot marked", lots)
+	}
+
+	repo.DeleteById(bread.Id, domain.NewStockMovement(bread.Id, 0, domain.MovementDelete, "manager-1"), nil)
+	if lots, _ := repo.ExpiringLots(now.Add(7*24*time.Hour), domain.DefaultLocationId); len(lots) != 1 {
+		t.Errorf("ExpiringLots() after deleting bread returned %d lots, want 1", len(lots))
+	}
+}
+
+func TestSqliteRepository_TransfersCarryLots(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	store, _ := domain.NewLocation("STORE-1", "High Street", domain.LocationStore, time.Now())
+	repo.SaveLocation(store)
+	product, _ := domain.CreateNewProduct("Milk", 2, 0)
+	repo.Save(product, nil, nil)
+
+	now := time.Now()
+	soon := now.Add(48 * time.Hour)
+	lot, _ := domain.NewLot(product.Id, domain.DefaultLocationId, 5, domain.LotReceipt{LotNumber: "L-7", ExpiresAt: &soon}, now)
+	movement := domain.NewStockMovement(product.Id, 5, domain.MovementRestock, "manager-1")
+	movement.LocationId = domain.DefaultLocationId
+	if _, err := repo.ReceiveLot(lot, movement, 0, nil); err != nil {
+		t.Fatalf("ReceiveLot() returned an unexpected error: %v", err)
+	}
+	expiring := func(locationId string) map[string]int {
+		lots, err := repo.ExpiringLots(now.Add(72*time.Hour), locationId)
+		if err != nil {
+			t.Fatalf("ExpiringLots() returned an unexpected error: %v", err)
+		}
+		quantities := map[string]int{}
+		for _, lot := range lots {
+			if lot.ExpiresAt == nil || !lot.ExpiresAt.Equal(soon) {
+				t.Errorf("lot %s expires at %v, want %v", lot.LotNumber, lot.ExpiresAt, soon)
+			}
+			quantities[lot.LotNumber] += lot.Quantity
+		}
+		return quantities
+	}
+
+	received, _ := domain.NewTransfer(product.Id, domain.DefaultLocationId, store.Id, 3, "manager-1", now)
+	cancelled, _ := domain.NewTransfer(product.Id, domain.DefaultLocationId, store.Id, 2, "manager-1", now)
+	for _, transfer := range []*domain.Transfer{received, cancelled} {
+		if err := repo.CreateTransfer(transfer); err != nil {
+			t.Fatalf("CreateTransfer() returned an unexpected error: %v", err)
+		}
+	}
+	if atMain := expiring(domain.DefaultLocationId); atMain["L-7"] != 0 {
+		t.Errorf("lots at main while in transit = %v, want L-7 emptied", atMain)
+	}
+
+	if _, err := repo.ReceiveTransfer(received.Id, now.Add(time.Hour)); err != nil {
+		t.Fatalf("ReceiveTransfer() returned an unexpected error: %v", err)
+	}
+	if _, err := repo.CancelTransfer(cancelled.Id, now.Add(time.Hour)); err != nil {
+		t.Fatalf("CancelTransfer() returned an unexpected error: %v", err)
+	}
+	if atStore := expiring(store.Id); len(atStore) != 1 || atStore["L-7"] != 3 {
+		t.Errorf("lots at the store after receiving = %v, want 3 of L-7", atStore)
+	}
+	if atMain := expiring(domain.DefaultLocationId); len(atMain) != 1 || atMain["L-7"] != 2 {
+		t.Errorf("lots at main after cancelling = %v, want 2 of L-7 back", atMain)
+	}
+
+	back, _ := domain.NewTransfer(product.Id, store.Id, domain.DefaultLocationId, 3, "manager-1", now)
+	repo.CreateTransfer(back)
+	if _, err := repo.ReceiveTransfer(back.Id, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("ReceiveTransfer() returned an unexpected error: %v", err)
+	}
+	if lots, _ := repo.ListLots(product.Id); len(lots) != 2 {
+		t.Errorf("ListLots() after sending the lot back = %+v, want it merged into the lot at main", lots)
+	}
+	if atMain := expiring(domain.DefaultLocationId); atMain["L-7"] != 5 {
+		t.Errorf("lots at main after sending back = %v, want all 5 of L-7", atMain)
+	}
+}
+
+func TestSqliteRepository_ExpiredLotsAreOnlyWrittenOff(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	store, _ := domain.NewLocation("STORE-1", "High Street", domain.LocationStore, time.Now())
+	repo.SaveLocation(store)
+	product, _ := domain.CreateNewProduct("Milk", 2, 1)
+	repo.Save(product, nil, nil)
+
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+	expired, _ := domain.NewLot(product.Id, domain.DefaultLocationId, 4, domain.LotReceipt{ExpiresAt: &yesterday}, now.Add(-72*time.Hour))
+	movement := domain.NewStockMovement(product.Id, 4, domain.MovementRestock, "manager-1")
+	movement.LocationId = domain.DefaultLocationId
+	if _, err := repo.ReceiveLot(expired, movement, 0, nil); err != nil {
+		t.Fatalf("ReceiveLot() returned an unexpected error: %v", err)
+	}
+
+	sale := domain.NewStockMovement(product.Id, -2, domain.MovementSale, "manager-1")
+	sale.LocationId = domain.DefaultLocationId
+	if _, err := repo.ApplyStockMovement(sale, 0, nil); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Errorf("ApplyStockMovement() of expired stock error = %v, want ErrInsufficientStock", err)
+	}
+	transfer, _ := domain.NewTransfer(product.Id, domain.DefaultLocationId, store.Id, 2, "manager-1", now)
+	if err := repo.CreateTransfer(transfer); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Errorf("CreateTransfer() of expired stock error = %v, want ErrInsufficientStock", err)
+	}
+
+	sale = domain.NewStockMovement(product.Id, -1, domain.MovementSale, "manager-1")
+	sale.LocationId = domain.DefaultLocationId
+	if _, err := repo.ApplyStockMovement(sale, 0, nil); err != nil {
+		t.Fatalf("ApplyStockMovement() of untracked stock returned an unexpected error: %v", err)
+	}
+
+	writeOff := domain.NewStockMovement(product.Id, -4, domain.MovementWriteOff, "manager-1")
+	writeOff.LocationId = domain.DefaultLocationId
+	writeOff.LotId = expired.Id
+	updated, err := repo.ApplyStockMovement(writeOff, 0, nil)
+	if err != nil || updated.Quantity != 0 {
+		t.Fatalf("ApplyStockMovement() of a write-off = %+v, %v, want nothing left", updated, err)
+	}
+	if lot, _ := repo.FindLotById(expired.Id); lot.Quantity != 0 {
+		t.Errorf("written off lot holds %d, want 0", lot.Quantity)
+	}
+	if level, _ := repo.FindStockLevel(product.Id, domain.DefaultLocationId); level.Quantity != 0 {
+		t.Errorf("stock at main = %d, want 0", level.Quantity)
+	}
+	if _, err := repo.FindLotById("missing"); !errors.Is(err, domain.ErrLotNotFound) {
+		t.Errorf("FindLotById() of an unknown lot error = %v, want ErrLotNotFound", err)
+	}
+}
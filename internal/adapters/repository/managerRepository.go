@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/mattn/go-sqlite3"
+)
+
+const managerColumns = "id, email, password, role, disabled, must_change_password, totp_secret, totp_enabled, totp_last_step"
+
+func scanManager(row rowScanner) (*domain.Manager, error) {
+	var manager domain.Manager
+	var role string
+	err := row.Scan(&manager.Id, &manager.Email, &manager.Password, &role, &manager.Disabled, &manager.MustChangePassword,
+		&manager.TOTPSecret, &manager.TOTPEnabled, &manager.TOTPLastStep)
+	if err != nil {
+		return nil, err
+	}
+	manager.Role = domain.Role(role)
+	return &manager, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+func (repo *sqliteRepository) FindByEmail(email string) (*domain.Manager, error) {
+	row := repo.db.QueryRow("SELECT "+managerColumns+" FROM managers WHERE email = ?", email)
+
+	manager, err := scanManager(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidCredentials
+		}
+		return nil, domain.ErrRepository
+	}
+	return manager, nil
+}
+
+func (repo *sqliteRepository) FindManagerById(id string) (*domain.Manager, error) {
+	row := repo.db.QueryRow("SELECT "+managerColumns+" FROM managers WHERE id = ?", id)
+
+	manager, err := scanManager(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrManagerNotFound
+		}
+		return nil, domain.ErrRepository
+	}
+	return manager, nil
+}
+
+func (repo *sqliteRepository) ListManagers() ([]domain.Manager, error) {
+	rows, err := repo.db.Query("SELECT " + managerColumns + " FROM managers ORDER BY email")
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	managers := []domain.Manager{}
+	for rows.Next() {
+		manager, err := scanManager(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		managers = append(managers, *manager)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return managers, nil
+}
+
+func (repo *sqliteRepository) SaveManager(manager *domain.Manager) error {
+	_, err := repo.db.Exec("INSERT INTO managers("+managerColumns+") VALUES(?,?,?,?,?,?,?,?,?)",
+		manager.Id, manager.Email, manager.Password, string(manager.Role), manager.Disabled, manager.MustChangePassword,
+		manager.TOTPSecret, manager.TOTPEnabled, manager.TOTPLastStep)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrManagerExists
+		}
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) UpdateManager(manager *domain.Manager) error {
+	res, err := repo.db.Exec(
+		`UPDATE managers SET email = ?, password = ?, role = ?, disabled = ?, must_change_password = ?,
+		totp_secret = ?, totp_enabled = ?, totp_last_step = ? WHERE id = ?`,
+		manager.Email, manager.Password, string(manager.Role), manager.Disabled, manager.MustChangePassword,
+		manager.TOTPSecret, manager.TOTPEnabled, manager.TOTPLastStep, manager.Id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrManagerExists
+		}
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrManagerNotFound
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) UpdateManagerTOTP(manager *domain.Manager) error {
+	res, err := repo.db.Exec("UPDATE managers SET totp_secret = ?, totp_enabled = ?, totp_last_step = ? WHERE id = ?",
+		manager.TOTPSecret, manager.TOTPEnabled, manager.TOTPLastStep, manager.Id)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrManagerNotFound
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) RecordTOTPStep(managerId string, step int64) error {
+	res, err := repo.db.Exec("UPDATE managers SET totp_last_step = ? WHERE id = ? AND totp_last_step < ?",
+		step, managerId, step)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrMFACodeInvalid
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) DeleteManager(id string) error {
+	res, err := repo.db.Exec("DELETE FROM managers WHERE id = ?", id)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrManagerNotFound
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_ManagerLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	clerk, _ := domain.NewManager("clerk@example.com", "clerk-password", domain.RoleClerk)
+	admin, _ := domain.NewManager("admin@example.com", "admin-password", domain.RoleAdmin)
+	for _, manager := range []*domain.Manager{clerk, admin} {
+		if err := repo.SaveManager(manager); err != nil {
+			t.Fatalf("SaveManager() returned an unexpected error: %v", err)
+		}
+	}
+
+	duplicate, _ := domain.NewManager("clerk@example.com", "other-password", domain.RoleAdmin)
+	if err := repo.SaveManager(duplicate); !errors.Is(err, domain.ErrManagerExists) {
+		t.Errorf("SaveManager() with a duplicate email error = %v, want ErrManagerExists", err)
+	}
+
+	found, err := repo.FindManagerById(clerk.Id)
+	if err != nil {
+		t.Fatalf("FindManagerById() returned an unexpected error: %v", err)
+	}
+	if found.Email != clerk.Email || found.Role != domain.RoleClerk || !found.MustChangePassword || found.Disabled {
+		t.Errorf("FindManagerById() = %+v", found)
+	}
+
+	found.Disabled = true
+	found.MustChangePassword = false
+	found.TOTPSecret = "JBSWY3DPEHPK3PXP"
+	found.TOTPEnabled = true
+	found.TOTPLastStep = 57000000
+	if err := repo.UpdateManager(found); err != nil {
+		t.Fatalf("UpdateManager() returned an unexpected error: %v", err)
+	}
+	updated, _ := repo.FindByEmail("clerk@example.com")
+	if !updated.Disabled || updated.MustChangePassword || updated.TOTPSecret != found.TOTPSecret ||
+		!updated.TOTPEnabled || updated.TOTPLastStep != found.TOTPLastStep {
+		t.Errorf("UpdateManager() stored = %+v", updated)
+	}
+
+	managers, err := repo.ListManagers()
+	if err != nil || len(managers) != 2 || managers[0].Email != "admin@example.com" {
+		t.Errorf("ListManagers() = %+v, %v, want both managers ordered by email", managers, err)
+	}
+
+	if err := repo.DeleteManager(clerk.Id); err != nil {
+		t.Fatalf("DeleteManager() returned an unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{"find_deleted", func() error { _, err := repo.FindManagerById(clerk.Id); return err }, domain.ErrManagerNotFound},
+		{"update_deleted", func() error { return repo.UpdateManager(clerk) }, domain.ErrManagerNotFound},
+		{"delete_deleted", func() error { return repo.DeleteManager(clerk.Id) }, domain.ErrManagerNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSqliteRepository_ManagerTOTPWrites(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	manager, _ := domain.NewManager("clerk@example.com", "clerk-password", domain.RoleClerk)
+	repo.SaveManager(manager)
+	stale := *manager
+
+	disabled := *manager
+	disabled.Disabled = true
+	if err := repo.UpdateManager(&disabled); err != nil {
+		t.Fatalf("UpdateManager() returned an unexpected error: %v", err)
+	}
+	stale.TOTPSecret = "JBSWY3DPEHPK3PXP"
+	stale.TOTPEnabled = true
+	if err := repo.UpdateManagerTOTP(&stale); err != nil {
+		t.Fatalf("UpdateManagerTOTP() returned an unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		step    int64
+		wantErr error
+	}{
+		{"first_step", 100, nil},
+		{"same_step_replayed", 100, domain.ErrMFACodeInvalid},
+		{"earlier_step", 99, domain.ErrMFACodeInvalid},
+		{"later_step", 101, nil},
+	}
+	for _, tt := range tests {
+		if err := repo.RecordTOTPStep(manager.Id, tt.step); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: RecordTOTPStep(%d) error = %v, want %v", tt.name, tt.step, err, tt.wantErr)
+		}
+	}
+
+	found, _ := repo.FindManagerById(manager.Id)
+	if !found.Disabled || !found.TOTPEnabled || found.TOTPSecret != stale.TOTPSecret || found.TOTPLastStep != 101 {
+		t.Errorf("stored manager = %+v, want the disable kept next to the two-factor fields", found)
+	}
+	if err := repo.UpdateManagerTOTP(&domain.Manager{Id: "missing"}); !errors.Is(err, domain.ErrManagerNotFound) {
+		t.Errorf("UpdateManagerTOTP() of a missing manager error = %v, want ErrManagerNotFound", err)
+	}
+}
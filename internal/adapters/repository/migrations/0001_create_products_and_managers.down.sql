@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS managers;
+DROP TABLE IF EXISTS products;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS products(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "name" TEXT,
+    "price" REAL,
+    "quantity" INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS managers(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "email" TEXT UNIQUE,
+    "password" TEXT
+);
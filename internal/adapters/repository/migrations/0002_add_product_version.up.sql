@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
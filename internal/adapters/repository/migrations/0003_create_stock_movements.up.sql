@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS stock_movements(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "product_id" TEXT NOT NULL,
+    "delta" INTEGER NOT NULL,
+    "reason" TEXT NOT NULL,
+    "resulting_quantity" INTEGER NOT NULL,
+    "manager_id" TEXT,
+    "created_at" TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_time ON stock_movements(product_id, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP COLUMN "reorder_quantity";
+ALTER TABLE products DROP COLUMN "reorder_point";
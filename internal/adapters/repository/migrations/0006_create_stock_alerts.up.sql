@@ -0,0 +1,13 @@
+CREATE TABLE stock_alerts(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "product_id" TEXT NOT NULL,
+    "quantity" INTEGER NOT NULL,
+    "reorder_point" INTEGER NOT NULL,
+    "status" TEXT NOT NULL,
+    "triggered_at" TEXT NOT NULL,
+    "acknowledged_at" TEXT,
+    "acknowledged_by" TEXT NOT NULL DEFAULT '',
+    "resolved_at" TEXT
+);
+CREATE INDEX idx_stock_alerts_product_triggered ON stock_alerts(product_id, triggered_at);
+CREATE UNIQUE INDEX idx_stock_alerts_one_active_per_product ON stock_alerts(product_id) WHERE status != 'resolved';
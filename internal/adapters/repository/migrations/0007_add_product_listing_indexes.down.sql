@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_name;
+DROP INDEX IF EXISTS idx_products_price;
+DROP INDEX IF EXISTS idx_products_quantity;
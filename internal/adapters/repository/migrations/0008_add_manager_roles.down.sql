@@ -0,0 +1 @@
+ALTER TABLE managers DROP COLUMN "role";
@@ -0,0 +1,2 @@
+ALTER TABLE managers ADD COLUMN "role" TEXT NOT NULL DEFAULT 'read_only';
+UPDATE managers SET "role" = 'admin';
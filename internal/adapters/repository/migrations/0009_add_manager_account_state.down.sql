@@ -0,0 +1,2 @@
+ALTER TABLE managers DROP COLUMN "must_change_password";
+ALTER TABLE managers DROP COLUMN "disabled";
@@ -0,0 +1,2 @@
+ALTER TABLE managers ADD COLUMN "disabled" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE managers ADD COLUMN "must_change_password" INTEGER NOT NULL DEFAULT 0;
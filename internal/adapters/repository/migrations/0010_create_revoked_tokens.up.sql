@@ -0,0 +1,5 @@
+CREATE TABLE revoked_tokens(
+    "token_id" TEXT NOT NULL PRIMARY KEY,
+    "expires_at" TEXT NOT NULL
+);
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
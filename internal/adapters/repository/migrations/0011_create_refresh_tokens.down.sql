@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_revoked_manager_tokens_expires_at;
+DROP TABLE IF EXISTS revoked_manager_tokens;
+DROP INDEX IF EXISTS idx_refresh_tokens_expires_at;
+DROP INDEX IF EXISTS idx_refresh_tokens_manager;
+DROP INDEX IF EXISTS idx_refresh_tokens_family;
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,20 @@
+CREATE TABLE refresh_tokens(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "family_id" TEXT NOT NULL,
+    "manager_id" TEXT NOT NULL,
+    "token_hash" TEXT NOT NULL UNIQUE,
+    "expires_at" TEXT NOT NULL,
+    "created_at" TEXT NOT NULL,
+    "rotated_at" TEXT,
+    "revoked_at" TEXT
+);
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens(family_id);
+CREATE INDEX idx_refresh_tokens_manager ON refresh_tokens(manager_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+
+CREATE TABLE revoked_manager_tokens(
+    "manager_id" TEXT NOT NULL PRIMARY KEY,
+    "revoked_at" TEXT NOT NULL,
+    "expires_at" TEXT NOT NULL
+);
+CREATE INDEX idx_revoked_manager_tokens_expires_at ON revoked_manager_tokens(expires_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_auth_audit_ip;
+DROP INDEX IF EXISTS idx_auth_audit_email;
+DROP TABLE IF EXISTS auth_audit;
@@ -0,0 +1,10 @@
+CREATE TABLE auth_audit(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "email" TEXT NOT NULL,
+    "ip" TEXT NOT NULL,
+    "outcome" TEXT NOT NULL,
+    "reason" TEXT NOT NULL,
+    "occurred_at" TEXT NOT NULL
+);
+CREATE INDEX idx_auth_audit_email ON auth_audit(email, outcome, occurred_at);
+CREATE INDEX idx_auth_audit_ip ON auth_audit(ip, outcome, occurred_at);
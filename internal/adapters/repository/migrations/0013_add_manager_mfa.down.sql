@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_recovery_codes_manager;
+DROP TABLE IF EXISTS recovery_codes;
+ALTER TABLE managers DROP COLUMN "totp_last_step";
+ALTER TABLE managers DROP COLUMN "totp_enabled";
+ALTER TABLE managers DROP COLUMN "totp_secret";
@@ -0,0 +1,10 @@
+ALTER TABLE managers ADD COLUMN "totp_secret" TEXT NOT NULL DEFAULT '';
+ALTER TABLE managers ADD COLUMN "totp_enabled" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE managers ADD COLUMN "totp_last_step" INTEGER NOT NULL DEFAULT 0;
+CREATE TABLE recovery_codes(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "manager_id" TEXT NOT NULL,
+    "code_hash" TEXT NOT NULL,
+    "used_at" TEXT
+);
+CREATE INDEX idx_recovery_codes_manager ON recovery_codes(manager_id, code_hash);
@@ -0,0 +1,13 @@
+CREATE TABLE api_keys(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "name" TEXT NOT NULL,
+    "prefix" TEXT NOT NULL UNIQUE,
+    "secret_hash" TEXT NOT NULL,
+    "permissions" TEXT NOT NULL,
+    "product_ids" TEXT NOT NULL,
+    "created_by" TEXT NOT NULL,
+    "created_at" TEXT NOT NULL,
+    "expires_at" TEXT,
+    "last_used_at" TEXT,
+    "revoked_at" TEXT
+);
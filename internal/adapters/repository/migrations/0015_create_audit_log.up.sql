@@ -0,0 +1,22 @@
+CREATE TABLE audit_log(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "action" TEXT NOT NULL,
+    "product_id" TEXT NOT NULL,
+    "actor" TEXT NOT NULL,
+    "client_ip" TEXT NOT NULL,
+    "request_id" TEXT NOT NULL,
+    "before" TEXT,
+    "after" TEXT,
+    "created_at" TEXT NOT NULL
+);
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX idx_audit_log_product ON audit_log(product_id, created_at);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor, created_at);
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit log entries cannot be changed');
+END;
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit log entries cannot be deleted');
+END;
@@ -0,0 +1,3 @@
+DELETE FROM products WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_products_deleted_at;
+ALTER TABLE products DROP COLUMN "deleted_at";
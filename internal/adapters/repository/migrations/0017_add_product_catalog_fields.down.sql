@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_products_barcode;
+DROP INDEX IF EXISTS idx_products_sku;
+ALTER TABLE products DROP COLUMN "tags";
+ALTER TABLE products DROP COLUMN "unit";
+ALTER TABLE products DROP COLUMN "category";
+ALTER TABLE products DROP COLUMN "description";
+ALTER TABLE products DROP COLUMN "barcode";
+ALTER TABLE products DROP COLUMN "sku";
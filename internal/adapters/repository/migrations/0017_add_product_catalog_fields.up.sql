@@ -0,0 +1,9 @@
+ALTER TABLE products ADD COLUMN "sku" TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN "barcode" TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN "description" TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN "category" TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN "unit" TEXT NOT NULL DEFAULT 'each';
+ALTER TABLE products ADD COLUMN "tags" TEXT NOT NULL DEFAULT '[]';
+UPDATE products SET sku = 'SKU-' || upper(substr(replace(id, '-', ''), 1, 12)) WHERE sku = '';
+CREATE UNIQUE INDEX idx_products_sku ON products(sku);
+CREATE UNIQUE INDEX idx_products_barcode ON products(barcode) WHERE barcode <> '';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_category_id;
+ALTER TABLE products ADD COLUMN "category" TEXT NOT NULL DEFAULT '';
+UPDATE products SET category = COALESCE((SELECT name FROM categories WHERE id = products.category_id), '');
+ALTER TABLE products DROP COLUMN "category_id";
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,18 @@
+CREATE TABLE categories(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "name" TEXT NOT NULL,
+    "parent_id" TEXT NOT NULL DEFAULT '',
+    "created_at" TEXT NOT NULL
+);
+CREATE UNIQUE INDEX idx_categories_parent_name ON categories(parent_id, name COLLATE NOCASE);
+CREATE INDEX idx_categories_parent ON categories(parent_id);
+INSERT INTO categories(id, name, created_at)
+SELECT lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-' || hex(randomblob(2)) || '-' || hex(randomblob(2)) || '-' || hex(randomblob(6))),
+    category, strftime('%Y-%m-%dT%H:%M:%S', 'now') || '.000000000Z'
+FROM products WHERE category <> '' GROUP BY category COLLATE NOCASE;
+ALTER TABLE products ADD COLUMN "category_id" TEXT NOT NULL DEFAULT '';
+UPDATE products SET category_id = (
+    SELECT id FROM categories WHERE parent_id = '' AND name = products.category COLLATE NOCASE
+) WHERE category <> '';
+ALTER TABLE products DROP COLUMN "category";
+CREATE INDEX idx_products_category_id ON products(category_id);
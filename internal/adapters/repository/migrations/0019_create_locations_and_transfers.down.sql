@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_stock_alerts_one_active_per_location;
+DELETE FROM stock_alerts WHERE location_id <> '';
+ALTER TABLE stock_alerts DROP COLUMN "location_id";
+CREATE UNIQUE INDEX idx_stock_alerts_one_active_per_product ON stock_alerts(product_id) WHERE status != 'resolved';
+ALTER TABLE stock_movements DROP COLUMN "location_id";
+DROP TABLE IF EXISTS stock_transfers;
+DROP TABLE IF EXISTS stock_levels;
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,40 @@
+CREATE TABLE locations(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "code" TEXT NOT NULL UNIQUE,
+    "name" TEXT NOT NULL,
+    "kind" TEXT NOT NULL,
+    "created_at" TEXT NOT NULL
+);
+INSERT INTO locations(id, code, name, kind, created_at)
+VALUES('main', 'MAIN', 'Main warehouse', 'warehouse', strftime('%Y-%m-%dT%H:%M:%S', 'now') || '.000000000Z');
+
+CREATE TABLE stock_levels(
+    "product_id" TEXT NOT NULL,
+    "location_id" TEXT NOT NULL,
+    "quantity" INTEGER NOT NULL DEFAULT 0,
+    "reorder_point" INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY(product_id, location_id)
+);
+CREATE INDEX idx_stock_levels_location ON stock_levels(location_id);
+INSERT INTO stock_levels(product_id, location_id, quantity) SELECT id, 'main', quantity FROM products;
+
+CREATE TABLE stock_transfers(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "product_id" TEXT NOT NULL,
+    "from_location_id" TEXT NOT NULL,
+    "to_location_id" TEXT NOT NULL,
+    "quantity" INTEGER NOT NULL,
+    "status" TEXT NOT NULL,
+    "created_by" TEXT NOT NULL DEFAULT '',
+    "created_at" TEXT NOT NULL,
+    "completed_at" TEXT
+);
+CREATE INDEX idx_stock_transfers_status ON stock_transfers(status, created_at);
+CREATE INDEX idx_stock_transfers_product ON stock_transfers(product_id, status);
+
+ALTER TABLE stock_movements ADD COLUMN "location_id" TEXT NOT NULL DEFAULT '';
+UPDATE stock_movements SET location_id = 'main' WHERE reason IN ('sale', 'restock', 'adjustment');
+
+ALTER TABLE stock_alerts ADD COLUMN "location_id" TEXT NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS idx_stock_alerts_one_active_per_product;
+CREATE UNIQUE INDEX idx_stock_alerts_one_active_per_location ON stock_alerts(product_id, location_id) WHERE status != 'resolved';
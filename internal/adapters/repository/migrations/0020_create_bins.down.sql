@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bin_stock;
+DROP TABLE IF EXISTS bins;
@@ -0,0 +1,18 @@
+CREATE TABLE bins(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "location_id" TEXT NOT NULL,
+    "code" TEXT NOT NULL,
+    "aisle" INTEGER NOT NULL DEFAULT 0,
+    "shelf" INTEGER NOT NULL DEFAULT 0,
+    "created_at" TEXT NOT NULL
+);
+CREATE UNIQUE INDEX idx_bins_location_code ON bins(location_id, code);
+CREATE INDEX idx_bins_walk ON bins(location_id, aisle, shelf, code);
+
+CREATE TABLE bin_stock(
+    "bin_id" TEXT NOT NULL,
+    "product_id" TEXT NOT NULL,
+    "quantity" INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY(bin_id, product_id)
+);
+CREATE INDEX idx_bin_stock_product ON bin_stock(product_id);
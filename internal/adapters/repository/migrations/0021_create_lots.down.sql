@@ -0,0 +1,2 @@
+ALTER TABLE stock_movements DROP COLUMN "lot_id";
+DROP TABLE IF EXISTS lots;
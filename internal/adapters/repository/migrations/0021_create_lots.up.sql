@@ -0,0 +1,14 @@
+CREATE TABLE lots(
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "product_id" TEXT NOT NULL,
+    "location_id" TEXT NOT NULL,
+    "lot_number" TEXT NOT NULL,
+    "quantity" INTEGER NOT NULL CHECK (quantity >= 0),
+    "received_at" TEXT NOT NULL,
+    "expires_at" TEXT,
+    "expiry_notified_at" TEXT
+);
+CREATE INDEX idx_lots_product_location ON lots(product_id, location_id);
+CREATE INDEX idx_lots_expires_at ON lots(expires_at) WHERE quantity > 0;
+
+ALTER TABLE stock_movements ADD COLUMN "lot_id" TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+CREATE TABLE stock_transfer_lots(
+    "transfer_id" TEXT NOT NULL,
+    "lot_id" TEXT NOT NULL,
+    "quantity" INTEGER NOT NULL,
+    PRIMARY KEY(transfer_id, lot_id)
+);
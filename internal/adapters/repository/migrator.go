@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func loadMigrations(files fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(files, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, path := range paths {
+		match := migrationFileName.FindStringSubmatch(path[len("migrations/"):])
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s does not match <version>_<name>.<up|down>.sql", path)
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(files, path)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func (m *Migrator) ensureMigrationsTable() error {
+	_, err := m.db.Exec(`
+    CREATE TABLE IF NOT EXISTS schema_migrations(
+        "version" INTEGER NOT NULL PRIMARY KEY,
+        "name" TEXT NOT NULL,
+        "applied_at" TEXT NOT NULL
+    );`)
+	return err
+}
+
+func (m *Migrator) appliedVersions() (map[int]time.Time, error) {
+	if err := m.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version], _ = parseTimestamp(appliedAt)
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) Up() (int, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := m.inTransaction(migration.Up,
+			"INSERT INTO schema_migrations(version, name, applied_at) VALUES(?,?,?)",
+			migration.Version, migration.Name, formatTimestamp(time.Now()))
+		if err != nil {
+			return count, fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (m *Migrator) Down(steps int) (int, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err := m.inTransaction(migration.Down,
+			"DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+		if err != nil {
+			return count, fmt.Errorf("rollback of migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) inTransaction(script string, bookkeeping string, args ...interface{}) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(bookkeeping, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTempDB(t *testing.T) *sql.DB {
+	db, err := Open(filepath.Join(t.TempDir(), "inventory.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database file: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&count); err != nil {
+		t.Fatalf("Failed to inspect schema: %v", err)
+	}
+	return count > 0
+}
+
+func TestMigrator_UpIsIdempotent(t *testing.T) {
+	db := openTempDB(t)
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator() returned an unexpected error: %v", err)
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("Up() returned an unexpected error: %v", err)
+	}
+	if applied != len(migrator.migrations) {
+		t.Errorf("Up() applied %d migrations, want %d", applied, len(migrator.migrations))
+	}
+
+	applied, err = migrator.Up()
+	if err != nil {
+		t.Fatalf("second Up() returned an unexpected error: %v", err)
+	}
+	if applied != 0 {
+		t.Errorf("second Up() applied %d migrations, want 0", applied)
+	}
+
+	for _, table := range []string{"products", "managers", "stock_movements", "schema_migrations"} {
+		if !tableExists(t, db, table) {
+			t.Errorf("table %s was not created", table)
+		}
+	}
+}
+
+func TestMigrator_DownAndStatus(t *testing.T) {
+	db := openTempDB(t)
+	migrator, _ := NewMigrator(db)
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up() returned an unexpected error: %v", err)
+	}
+
+	reverted, err := migrator.Down(1)
+	if err != nil {
+		t.Fatalf("Down() returned an unexpected error: %v", err)
+	}
+	if reverted != 1 {
+		t.Errorf("Down() reverted %d migrations, want 1", reverted)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Status() returned an unexpected error: %v", err)
+	}
+	last := statuses[len(statuses)-1]
+	if last.Applied {
+		t.Errorf("Status() reports migration %d_%s as applied after Down()", last.Version, last.Name)
+	}
+	for _, status := range statuses[:len(statuses)-1] {
+		if !status.Applied || status.AppliedAt.IsZero() {
+			t.Errorf("Status() reports migration %d_%s as pending", status.Version, status.Name)
+		}
+	}
+
+	reverted, err = migrator.Down(len(statuses))
+	if err != nil {
+		t.Fatalf("Down() of all migrations returned an unexpected error: %v", err)
+	}
+	if reverted != len(statuses)-1 {
+		t.Errorf("Down() reverted %d migrations, want %d", reverted, len(statuses)-1)
+	}
+	if tableExists(t, db, "products") {
+		t.Error("products table still exists after reverting every migration")
+	}
+
+	if applied, err := migrator.Up(); err != nil || applied != len(statuses) {
+		t.Errorf("Up() after full rollback applied %d migrations (err %v), want %d", applied, err, len(statuses))
+	}
+}
+
+func TestMigrator_UpgradesLegacyDatabase(t *testing.T) {
+	db := openTempDB(t)
+	legacySchema := `
+    CREATE TABLE products("id" TEXT NOT NULL PRIMARY KEY, "name" TEXT, "price" REAL, "quantity" INTEGER);
+    CREATE TABLE managers("id" TEXT NOT NULL PRIMARY KEY, "email" TEXT UNIQUE, "password" TEXT);
+    INSERT INTO products(id, name, price, quantity) VALUES('legacy-1', 'Old Stock', 2.5, 12);
+    INSERT INTO managers(id, email, password) VALUES('manager-1', 'legacy@example.com', 'hash');`
+	if _, err := db.Exec(legacySchema); err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	migrator, _ := NewMigrator(db)
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up() on legacy database returned an unexpected error: %v", err)
+	}
+
+	product, err := NewSQLiteRepository(db).FindById("legacy-1")
+	if err != nil {
+		t.Fatalf("FindById() after migration returned an unexpected error: %v", err)
+	}
+	if product.Quantity != 12 || product.Version != 1 {
+		t.Errorf("legacy product after migration = %+v, want quantity 12 at version 1", product)
+	}
+	if level, _ := NewSQLiteRepository(db).FindStockLevel("legacy-1", domain.DefaultLocationId); level == nil || level.Quantity != 12 {
+		t.Errorf("legacy stock level after migration = %+v, want all 12 units at the main location", level)
+	}
+
+	manager, err := NewSQLiteRepository(db).FindByEmail("legacy@example.com")
+	if err != nil || manager.Role != domain.RoleAdmin {
+		t.Errorf("legacy manager after migration = %+v, %v, want the admin role", manager, err)
+	}
+}
+
+func TestMigrator_MovesProductCategoriesIntoTable(t *testing.T) {
+	db := openTempDB(t)
+	migrator, _ := NewMigrator(db)
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up() returned an unexpected error: %v", err)
+	}
+	if _, err := migrator.Down(5); err != nil {
+		t.Fatalf("Down() returned an unexpected error: %v", err)
+	}
+	_, err := db.Exec(`INSERT INTO products(id, name, price, quantity, sku, category) VALUES
+		('p1', 'Rice', 2, 10, 'SKU-P1', 'Pantry'), ('p2', 'Beans', 3, 5, 'SKU-P2', 'pantry'), ('p3', 'Cable', 4, 1, 'SKU-P3', '')`)
+	if err != nil {
+		t.Fatalf("Failed to seed products: %v", err)
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up() returned an unexpected error: %v", err)
+	}
+	repo := NewSQLiteRepository(db)
+	categories, err := repo.ListCategories()
+	if err != nil || len(categories) != 1 || categories[0].Name != "Pantry" {
+		t.Fatalf("ListCategories() after migration = %+v, %v, want one Pantry category", categories, err)
+	}
+	for id, want := range map[string]string{"p1": categories[0].Id, "p2": categories[0].Id, "p3": ""} {
+		if product, _ := repo.FindById(id); product == nil || product.CategoryId != want {
+			t.Errorf("product %s after migration = %+v, want category %q", id, product, want)
+		}
+	}
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"bad_file_name", fstest.MapFS{"migrations/first.up.sql": {Data: []byte("SELECT 1;")}}},
+		{"missing_down", fstest.MapFS{"migrations/0001_first.up.sql": {Data: []byte("SELECT 1;")}}},
+		{"duplicate_version", fstest.MapFS{
+			"migrations/0001_first.up.sql":    {Data: []byte("SELECT 1;")},
+			"migrations/0001_first.down.sql":  {Data: []byte("SELECT 1;")},
+			"migrations/0001_second.up.sql":   {Data: []byte("SELECT 1;")},
+			"migrations/0001_second.down.sql": {Data: []byte("SELECT 1;")},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadMigrations(tt.files); err == nil {
+				t.Error("loadMigrations() expected an error, got nil")
+			}
+		})
+	}
+}
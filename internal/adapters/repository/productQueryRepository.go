@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+var sortColumns = map[domain.ProductSortField]string{
+	domain.SortByName:     "name COLLATE NOCASE",
+	domain.SortByPrice:    "price",
+	domain.SortByQuantity: "quantity",
+}
+
+type productCursor struct {
+	SortBy     domain.ProductSortField `json:"s"`
+	Descending bool                    `json:"d"`
+	Value      interface{}             `json:"v"`
+	Id         string                  `json:"id"`
+}
+
+func encodeCursor(query domain.ProductQuery, last domain.Product) string {
+	cursor := productCursor{SortBy: query.SortBy, Descending: query.Descending, Id: last.Id}
+	switch query.SortBy {
+	case domain.SortByPrice:
+		cursor.Value = last.Price
+	case domain.SortByQuantity:
+		cursor.Value = last.Quantity
+	default:
+		cursor.Value = last.Name
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(query domain.ProductQuery) (*productCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(query.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidQuery)
+	}
+	var cursor productCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id == "" || cursor.Value == nil {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidQuery)
+	}
+	if cursor.SortBy != query.SortBy || cursor.Descending != query.Descending {
+		return nil, fmt.Errorf("%w: cursor was issued for a different sort order", domain.ErrInvalidQuery)
+	}
+	return &cursor, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func (repo *sqliteRepository) ListProducts(query domain.ProductQuery) (*domain.ProductPage, error) {
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	if query.NameContains != "" {
+		conditions = append(conditions, `name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(query.NameContains)+"%")
+	}
+	if query.CategoryId != "" {
+		conditions = append(conditions, "category_id IN ("+categoryTreeQuery+")")
+		args = append(args, query.CategoryId)
+	}
+	if query.MinPrice != nil {
+		conditions = append(conditions, "price >= ?")
+		args = append(args, *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		conditions = append(conditions, "price <= ?")
+		args = append(args, *query.MaxPrice)
+	}
+	if query.MinQuantity != nil {
+		conditions = append(conditions, "quantity >= ?")
+		args = append(args, *query.MinQuantity)
+	}
+	if query.MaxQuantity != nil {
+		conditions = append(conditions, "quantity <= ?")
+		args = append(args, *query.MaxQuantity)
+	}
+	if query.LowStockOnly {
+		conditions = append(conditions, "quantity < (CASE WHEN reorder_point > 0 THEN reorder_point ELSE ? END)")
+		args = append(args, query.DefaultReorderPoint)
+	}
+
+	column := sortColumns[query.SortBy]
+	comparison, direction := ">", "ASC"
+	if query.Descending {
+		comparison, direction = "<", "DESC"
+	}
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, comparison, column, comparison))
+		args = append(args, cursor.Value, cursor.Value, cursor.Id)
+	}
+
+	statement := "SELECT " + productColumns + " FROM products WHERE " + strings.Join(conditions, " AND ")
+	statement += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %d", column, direction, direction, query.Limit+1)
+
+	rows, err := repo.db.Query(statement, args...)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	page := &domain.ProductPage{Products: []domain.Product{}}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		page.Products = append(page.Products, *product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+
+	if len(page.Products) > query.Limit {
+		page.Products = page.Products[:query.Limit]
+		page.NextCursor = encodeCursor(query, page.Products[query.Limit-1])
+	}
+	return page, nil
+}
+
+// InventoryValueByCategory sums the stock value of live products per category
+// id, with "" holding the products that have no category. Given a location it
+// only counts the stock on hand there, otherwise it counts all stock including
+// what is in transit.
+func (repo *sqliteRepository) InventoryValueByCategory(locationId string) (map[string]float64, error) {
+	statement := "SELECT category_id, SUM(price * quantity) FROM products WHERE deleted_at IS NULL GROUP BY category_id"
+	var args []interface{}
+	if locationId != "" {
+		statement = `SELECT p.category_id, SUM(p.price * s.quantity) FROM products p
+			JOIN stock_levels s ON s.product_id = p.id
+			WHERE p.deleted_at IS NULL AND s.location_id = ? GROUP BY p.category_id`
+		args = append(args, locationId)
+	}
+	rows, err := repo.db.Query(statement, args...)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	values := map[string]float64{}
+	for rows.Next() {
+		var categoryId string
+		var value float64
+		if err := rows.Scan(&categoryId, &value); err != nil {
+			return nil, domain.ErrRepository
+		}
+		values[categoryId] = value
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return values, nil
+}
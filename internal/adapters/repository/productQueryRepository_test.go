@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func seedListingProducts(t *testing.T, repo *sqliteRepository) {
+	products := []domain.Product{
+		{Id: "p1", SKU: "SKU-P1", Name: "Cable 1m", Price: 4, Quantity: 120, Version: 1},
+		{Id: "p2", SKU: "SKU-P2", Name: "cable 2m", Price: 6, Quantity: 3, Version: 1},
+		{Id: "p3", SKU: "SKU-P3", Name: "Monitor", Price: 300, Quantity: 8, ReorderPoint: 5, Version: 1},
+		{Id: "p4", SKU: "SKU-P4", Name: "Keyboard", Price: 45, Quantity: 0, Version: 1},
+		{Id: "p5", SKU: "SKU-P5", Name: "100%_Cotton Cloth", Price: 2, Quantity: 40, Version: 1},
+	}
+	for i := range products {
+		if err := repo.Save(&products[i], nil, nil); err != nil {
+			t.Fatalf("Failed to seed product: %v", err)
+		}
+	}
+}
+
+func productIds(products []domain.Product) string {
+	ids := ""
+	for _, product := range products {
+		ids += product.Id + ","
+	}
+	return ids
+}
+
+func TestSqliteRepository_ListProducts(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	seedListingProducts(t, repo)
+
+	minPrice, maxPrice := 4.0, 50.0
+	maxQuantity := 10
+
+	tests := []struct {
+		name    string
+		query   domain.ProductQuery
+		wantIds string
+		wantErr error
+	}{
+		{"default_sort_by_name_case_insensitive", domain.ProductQuery{}, "p5,p1,p2,p4,p3,", nil},
+		{"name_search", domain.ProductQuery{NameContains: "CABLE"}, "p1,p2,", nil},
+		{"name_search_escapes_wildcards", domain.ProductQuery{NameContains: "%_"}, "p5,", nil},
+		{"price_range", domain.ProductQuery{MinPrice: &minPrice, MaxPrice: &maxPrice, SortBy: domain.SortByPrice}, "p1,p2,p4,", nil},
+		{"quantity_range_descending", domain.ProductQuery{MaxQuantity: &maxQuantity, SortBy: domain.SortByQuantity, Descending: true}, "p3,p2,p4,", nil},
+		{"low_stock_uses_product_and_default_reorder_points", domain.ProductQuery{LowStockOnly: true, DefaultReorderPoint: 10, SortBy: domain.SortByQuantity}, "p4,p2,", nil},
+		{"invalid_sort", domain.ProductQuery{SortBy: "colour"}, "", domain.ErrInvalidQuery},
+		{"malformed_cursor", domain.ProductQuery{Cursor: "!!"}, "", domain.ErrInvalidQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.ListProducts(tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListProducts() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := productIds(page.Products); got != tt.wantIds {
+				t.Errorf("ListProducts() = %s, want %s", got, tt.wantIds)
+			}
+			if page.NextCursor != "" {
+				t.Errorf("ListProducts() returned a next cursor for a complete result")
+			}
+		})
+	}
+}
+
+func TestSqliteRepository_ListProducts_CursorPagination(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	for i := 0; i < 7; i++ {
+		product := &domain.Product{Id: fmt.Sprintf("p%d", i), SKU: fmt.Sprintf("SKU-P%d", i), Name: "Widget", Price: float64(i % 3), Quantity: i, Version: 1}
+		repo.Save(product, nil, nil)
+	}
+
+	for _, descending := range []bool{false, true} {
+		t.Run(fmt.Sprintf("descending_%v", descending), func(t *testing.T) {
+			query := domain.ProductQuery{SortBy: domain.SortByPrice, Descending: descending, Limit: 3}
+			seen := map[string]bool{}
+			pages := 0
+			for {
+				page, err := repo.ListProducts(query)
+				if err != nil {
+					t.Fatalf("ListProducts() returned an unexpected error: %v", err)
+				}
+				pages++
+				for _, product := range page.Products {
+					if seen[product.Id] {
+						t.Fatalf("product %s returned on more than one page", product.Id)
+					}
+					seen[product.Id] = true
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				query.Cursor = page.NextCursor
+			}
+			if len(seen) != 7 || pages != 3 {
+				t.Errorf("paged through %d products in %d pages, want 7 in 3", len(seen), pages)
+			}
+		})
+	}
+
+	page, _ := repo.ListProducts(domain.ProductQuery{SortBy: domain.SortByPrice, Limit: 3})
+	_, err := repo.ListProducts(domain.ProductQuery{SortBy: domain.SortByName, Limit: 3, Cursor: page.NextCursor})
+	if !errors.Is(err, domain.ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery for a cursor reused with another sort, got %v", err)
+	}
+}
+
+func TestSqliteRepository_InventoryValueByCategory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	values, err := repo.InventoryValueByCategory("")
+	if err != nil || len(values) != 0 {
+		t.Fatalf("InventoryValueByCategory() on empty table = %v, %v, want no values", values, err)
+	}
+
+	seedListingProducts(t, repo)
+	values, err = repo.InventoryValueByCategory("")
+	if err != nil {
+		t.Fatalf("InventoryValueByCategory() returned an unexpected error: %v", err)
+	}
+	if want := 4.0*120 + 6*3 + 300*8 + 2*40; len(values) != 1 || values[""] != want {
+		t.Errorf("InventoryValueByCategory() = %v, want %v uncategorized", values, want)
+	}
+}
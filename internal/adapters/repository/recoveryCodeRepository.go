@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+var _ ports.RecoveryCodeRepository = (*sqliteRepository)(nil)
+
+func (repo *sqliteRepository) ReplaceRecoveryCodes(managerId string, codeHashes []string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return domain.ErrRepository
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE manager_id = ?", managerId); err != nil {
+		return domain.ErrRepository
+	}
+	for _, codeHash := range codeHashes {
+		_, err := tx.Exec("INSERT INTO recovery_codes(id, manager_id, code_hash) VALUES(?,?,?)",
+			uuid.New().String(), managerId, codeHash)
+		if err != nil {
+			return domain.ErrRepository
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) UseRecoveryCode(managerId, codeHash string, usedAt time.Time) error {
+	res, err := repo.db.Exec(
+		`UPDATE recovery_codes SET used_at = ? WHERE id = (
+			SELECT id FROM recovery_codes WHERE manager_id = ? AND code_hash = ? AND used_at IS NULL LIMIT 1)`,
+		formatTimestamp(usedAt), managerId, codeHash)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrMFACodeInvalid
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_RecoveryCodes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	old := domain.HashRecoveryCode("aaaaa-aaaaa")
+	if err := repo.ReplaceRecoveryCodes("manager-1", []string{old}); err != nil {
+		t.Fatalf("ReplaceRecoveryCodes() returned an unexpected error: %v", err)
+	}
+	codes, hashes, _ := domain.NewRecoveryCodes()
+	if err := repo.ReplaceRecoveryCodes("manager-1", hashes); err != nil {
+		t.Fatalf("ReplaceRecoveryCodes() returned an unexpected error: %v", err)
+	}
+	repo.ReplaceRecoveryCodes("manager-2", []string{domain.HashRecoveryCode(codes[1])})
+
+	tests := []struct {
+		name      string
+		managerId string
+		codeHash  string
+		wantErr   error
+	}{
+		{"unused_code", "manager-1", domain.HashRecoveryCode(codes[0]), nil},
+		{"code_used_twice", "manager-1", domain.HashRecoveryCode(codes[0]), domain.ErrMFACodeInvalid},
+		{"replaced_code", "manager-1", old, domain.ErrMFACodeInvalid},
+		{"code_of_another_manager", "manager-2", domain.HashRecoveryCode(codes[2]), domain.ErrMFACodeInvalid},
+		{"same_code_per_manager", "manager-2", domain.HashRecoveryCode(codes[1]), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := repo.UseRecoveryCode(tt.managerId, tt.codeHash, now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("UseRecoveryCode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := repo.ReplaceRecoveryCodes("manager-1", nil); err != nil {
+		t.Fatalf("ReplaceRecoveryCodes() returned an unexpected error: %v", err)
+	}
+	if err := repo.UseRecoveryCode("manager-1", domain.HashRecoveryCode(codes[3]), now); !errors.Is(err, domain.ErrMFACodeInvalid) {
+		t.Errorf("UseRecoveryCode() after dropping all codes error = %v, want ErrMFACodeInvalid", err)
+	}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.RefreshTokenRepository = (*sqliteRepository)(nil)
+
+const refreshTokenColumns = "id, family_id, manager_id, token_hash, expires_at, created_at, rotated_at, revoked_at"
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func scanRefreshToken(row rowScanner) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	var expiresAt, createdAt string
+	var rotatedAt, revokedAt sql.NullString
+	err := row.Scan(&token.Id, &token.FamilyId, &token.ManagerId, &token.TokenHash,
+		&expiresAt, &createdAt, &rotatedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if token.ExpiresAt, err = parseTimestamp(expiresAt); err != nil {
+		return nil, err
+	}
+	if token.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	if token.RotatedAt, err = parseNullTimestamp(rotatedAt); err != nil {
+		return nil, err
+	}
+	if token.RevokedAt, err = parseNullTimestamp(revokedAt); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func insertRefreshToken(db execer, token *domain.RefreshToken) error {
+	_, err := db.Exec(
+		`INSERT INTO refresh_tokens(id, family_id, manager_id, token_hash, expires_at, created_at)
+		VALUES(?,?,?,?,?,?)`,
+		token.Id, token.FamilyId, token.ManagerId, token.TokenHash,
+		formatTimestamp(token.ExpiresAt), formatTimestamp(token.CreatedAt))
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) SaveRefreshToken(token *domain.RefreshToken) error {
+	return insertRefreshToken(repo.db, token)
+}
+
+func (repo *sqliteRepository) FindRefreshToken(tokenHash string) (*domain.RefreshToken, error) {
+	row := repo.db.QueryRow("SELECT "+refreshTokenColumns+" FROM refresh_tokens WHERE token_hash = ?", tokenHash)
+	token, err := scanRefreshToken(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrRefreshTokenInvalid
+		}
+		return nil, domain.ErrRepository
+	}
+	return token, nil
+}
+
+func (repo *sqliteRepository) RotateRefreshToken(currentId string, next *domain.RefreshToken, rotatedAt time.Time) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return domain.ErrRepository
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		"UPDATE refresh_tokens SET rotated_at = ? WHERE id = ? AND rotated_at IS NULL AND revoked_at IS NULL",
+		formatTimestamp(rotatedAt), currentId)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return domain.ErrRefreshTokenReused
+	}
+	if err := insertRefreshToken(tx, next); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) RevokeRefreshTokenFamily(familyId string, revokedAt time.Time) error {
+	_, err := repo.db.Exec(
+		"UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL",
+		formatTimestamp(revokedAt), familyId)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) RevokeManagerRefreshTokens(managerId string, revokedAt time.Time) error {
+	_, err := repo.db.Exec(
+		"UPDATE refresh_tokens SET revoked_at = ? WHERE manager_id = ? AND revoked_at IS NULL",
+		formatTimestamp(revokedAt), managerId)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) PruneRefreshTokens(now time.Time) (int64, error) {
+	result, err := repo.db.Exec("DELETE FROM refresh_tokens WHERE expires_at <= ?", formatTimestamp(now))
+	if err != nil {
+		return 0, domain.ErrRepository
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_RefreshTokenRotation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	first, raw, _ := domain.NewRefreshToken("manager-1", "", now, time.Hour)
+	if err := repo.SaveRefreshToken(first); err != nil {
+		t.Fatalf("SaveRefreshToken() returned an unexpected error: %v", err)
+	}
+	found, err := repo.FindRefreshToken(domain.HashRefreshToken(raw))
+	if err != nil || found.Id != first.Id || !found.ExpiresAt.Equal(first.ExpiresAt) || !found.IsActive(now) {
+		t.Fatalf("FindRefreshToken() = %+v, %v, want %+v", found, err, first)
+	}
+	if _, err := repo.FindRefreshToken(domain.HashRefreshToken("unknown")); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Errorf("FindRefreshToken() of an unknown token error = %v, want ErrRefreshTokenInvalid", err)
+	}
+
+	second, _, _ := domain.NewRefreshToken("manager-1", first.FamilyId, now, time.Hour)
+	if err := repo.RotateRefreshToken(first.Id, second, now); err != nil {
+		t.Fatalf("RotateRefreshToken() returned an unexpected error: %v", err)
+	}
+	third, _, _ := domain.NewRefreshToken("manager-1", first.FamilyId, now, time.Hour)
+	if err := repo.RotateRefreshToken(first.Id, third, now); !errors.Is(err, domain.ErrRefreshTokenReused) {
+		t.Fatalf("rotating an already rotated token error = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := repo.FindRefreshToken(third.TokenHash); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Errorf("a failed rotation must not store the next token, got %v", err)
+	}
+
+	if err := repo.RevokeRefreshTokenFamily(first.FamilyId, now); err != nil {
+		t.Fatalf("RevokeRefreshTokenFamily() returned an unexpected error: %v", err)
+	}
+	revoked, _ := repo.FindRefreshToken(second.TokenHash)
+	if revoked.RevokedAt == nil || revoked.IsActive(now) {
+		t.Errorf("token %+v should be revoked with its family", revoked)
+	}
+}
+
+func TestSqliteRepository_RevokeManagerRefreshTokensAndPrune(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mine, _, _ := domain.NewRefreshToken("manager-1", "", now, time.Hour)
+	theirs, _, _ := domain.NewRefreshToken("manager-2", "", now, time.Hour)
+	expired, _, _ := domain.NewRefreshToken("manager-2", "", now.Add(-2*time.Hour), time.Hour)
+	for _, token := range []*domain.RefreshToken{mine, theirs, expired} {
+		if err := repo.SaveRefreshToken(token); err != nil {
+			t.Fatalf("SaveRefreshToken() returned an unexpected error: %v", err)
+		}
+	}
+
+	if err := repo.RevokeManagerRefreshTokens("manager-1", now); err != nil {
+		t.Fatalf("RevokeManagerRefreshTokens() returned an unexpected error: %v", err)
+	}
+	if got, _ := repo.FindRefreshToken(mine.TokenHash); got.RevokedAt == nil {
+		t.Errorf("manager-1 token was not revoked")
+	}
+	if got, _ := repo.FindRefreshToken(theirs.TokenHash); got.RevokedAt != nil {
+		t.Errorf("manager-2 token should not be revoked")
+	}
+
+	pruned, err := repo.PruneRefreshTokens(now)
+	if err != nil || pruned != 1 {
+		t.Fatalf("PruneRefreshTokens() = %d, %v, want 1", pruned, err)
+	}
+}
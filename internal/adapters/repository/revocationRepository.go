@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.RevocationStore = (*sqliteRepository)(nil)
+
+func (repo *sqliteRepository) RevokeToken(tokenId string, expiresAt time.Time) error {
+	_, err := repo.db.Exec(
+		"INSERT INTO revoked_tokens(token_id, expires_at) VALUES(?,?) ON CONFLICT(token_id) DO NOTHING",
+		tokenId, formatTimestamp(expiresAt))
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) ConsumeToken(tokenId string, expiresAt time.Time) (bool, error) {
+	res, err := repo.db.Exec(
+		"INSERT INTO revoked_tokens(token_id, expires_at) VALUES(?,?) ON CONFLICT(token_id) DO NOTHING",
+		tokenId, formatTimestamp(expiresAt))
+	if err != nil {
+		return false, domain.ErrRepository
+	}
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+func (repo *sqliteRepository) IsTokenRevoked(tokenId string) (bool, error) {
+	var revoked bool
+	row := repo.db.QueryRow("SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE token_id = ?)", tokenId)
+	if err := row.Scan(&revoked); err != nil {
+		return false, domain.ErrRepository
+	}
+	return revoked, nil
+}
+
+func (repo *sqliteRepository) RevokeManagerTokens(managerId string, revokedAt, expiresAt time.Time) error {
+	_, err := repo.db.Exec(
+		`INSERT INTO revoked_manager_tokens(manager_id, revoked_at, expires_at) VALUES(?,?,?)
+		ON CONFLICT(manager_id) DO UPDATE SET
+			revoked_at = MAX(revoked_at, excluded.revoked_at), expires_at = MAX(expires_at, excluded.expires_at)`,
+		managerId, formatTimestamp(revokedAt), formatTimestamp(expiresAt))
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) IsManagerTokenRevoked(managerId string, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	row := repo.db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM revoked_manager_tokens WHERE manager_id = ? AND revoked_at > ?)",
+		managerId, formatTimestamp(issuedAt))
+	if err := row.Scan(&revoked); err != nil {
+		return false, domain.ErrRepository
+	}
+	return revoked, nil
+}
+
+func (repo *sqliteRepository) PruneRevokedTokens(now time.Time) (int64, error) {
+	var pruned int64
+	for _, table := range []string{"revoked_tokens", "revoked_manager_tokens"} {
+		result, err := repo.db.Exec("DELETE FROM "+table+" WHERE expires_at <= ?", formatTimestamp(now))
+		if err != nil {
+			return 0, domain.ErrRepository
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return 0, domain.ErrRepository
+		}
+		pruned += count
+	}
+	return pruned, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSqliteRepository_RevokedTokens(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := repo.RevokeToken("expired-jti", now.Add(-time.Minute)); err != nil {
+		t.Fatalf("RevokeToken() returned an unexpected error: %v", err)
+	}
+	if err := repo.RevokeToken("live-jti", now.Add(time.Hour)); err != nil {
+		t.Fatalf("RevokeToken() returned an unexpected error: %v", err)
+	}
+	if err := repo.RevokeToken("live-jti", now.Add(time.Hour)); err != nil {
+		t.Fatalf("revoking the same token twice should be a no-op, got %v", err)
+	}
+
+	tests := []struct {
+		tokenId string
+		want    bool
+	}{
+		{"expired-jti", true},
+		{"live-jti", true},
+		{"unknown-jti", false},
+	}
+	for _, tt := range tests {
+		revoked, err := repo.IsTokenRevoked(tt.tokenId)
+		if err != nil || revoked != tt.want {
+			t.Errorf("IsTokenRevoked(%q) = %v, %v, want %v", tt.tokenId, revoked, err, tt.want)
+		}
+	}
+
+	pruned, err := repo.PruneRevokedTokens(now)
+	if err != nil || pruned != 1 {
+		t.Fatalf("PruneRevokedTokens() = %d, %v, want 1 pruned", pruned, err)
+	}
+	if revoked, _ := repo.IsTokenRevoked("expired-jti"); revoked {
+		t.Errorf("expired revocation was not pruned")
+	}
+	if revoked, _ := repo.IsTokenRevoked("live-jti"); !revoked {
+		t.Errorf("live revocation was pruned")
+	}
+}
+
+func TestSqliteRepository_RevokedManagerTokens(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := repo.RevokeManagerTokens("manager-1", now, now.Add(15*time.Minute)); err != nil {
+		t.Fatalf("RevokeManagerTokens() returned an unexpected error: %v", err)
+	}
+	if err := repo.RevokeManagerTokens("manager-1", now.Add(-time.Hour), now.Add(time.Minute)); err != nil {
+		t.Fatalf("RevokeManagerTokens() returned an unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		managerId string
+		issuedAt  time.Time
+		want      bool
+	}{
+		{"issued_before", "manager-1", now.Add(-time.Minute), true},
+		{"issued_at_revocation", "manager-1", now, false},
+		{"issued_after", "manager-1", now.Add(time.Second), false},
+		{"other_manager", "manager-2", now.Add(-time.Minute), false},
+	}
+	for _, tt := range tests {
+		revoked, err := repo.IsManagerTokenRevoked(tt.managerId, tt.issuedAt)
+		if err != nil || revoked != tt.want {
+			t.Errorf("%s: IsManagerTokenRevoked() = %v, %v, want %v", tt.name, revoked, err, tt.want)
+		}
+	}
+
+	if pruned, err := repo.PruneRevokedTokens(now.Add(10 * time.Minute)); err != nil || pruned != 0 {
+		t.Fatalf("PruneRevokedTokens() = %d, %v, an earlier revocation should not shorten a later one", pruned, err)
+	}
+	if pruned, err := repo.PruneRevokedTokens(now.Add(15 * time.Minute)); err != nil || pruned != 1 {
+		t.Fatalf("PruneRevokedTokens() = %d, %v, want 1 pruned", pruned, err)
+	}
+}
+
+func TestSqliteRepository_ConsumeToken(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	expiresAt := time.Now().Add(time.Minute)
+	if consumed, err := repo.ConsumeToken("challenge-1", expiresAt); err != nil || !consumed {
+		t.Fatalf("ConsumeToken() = %v, %v, want the first use to succeed", consumed, err)
+	}
+	if consumed, err := repo.ConsumeToken("challenge-1", expiresAt); err != nil || consumed {
+		t.Errorf("ConsumeToken() of a used token = %v, %v, want false", consumed, err)
+	}
+	repo.RevokeToken("challenge-2", expiresAt)
+	if consumed, _ := repo.ConsumeToken("challenge-2", expiresAt); consumed {
+		t.Errorf("ConsumeToken() of a revoked token should fail")
+	}
+}
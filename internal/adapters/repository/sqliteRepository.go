@@ -2,111 +2,383 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 type sqliteRepository struct {
 	db *sql.DB
 }
 
+const productColumns = "id, name, price, quantity, reorder_point, reorder_quantity, version, sku, barcode, description, category_id, unit, tags"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 func NewSQLiteRepository(db *sql.DB) *sqliteRepository {
 	return &sqliteRepository{
 		db: db,
 	}
 }
 
-func (repo *sqliteRepository) FindById(id string) (*domain.Product, error) {
-	row := repo.db.QueryRow("SELECT id, name, price, quantity FROM products where id=?", id)
+// maxOpenConns keeps writers waiting for the database lock queued in the pool,
+// instead of all of them polling SQLite's busy handler at once.
+const maxOpenConns = 8
+
+// Open opens the SQLite database at path. Every transaction takes the write lock
+// when it begins and waits up to five seconds for it, so concurrent writers queue
+// instead of failing with SQLITE_BUSY when a read turns into a write.
+func Open(path string) (*sql.DB, error) {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	db, err := sql.Open("sqlite3", path+separator+"_txlock=immediate&_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(maxOpenConns)
+	return db, nil
+}
 
+func scanProduct(row rowScanner) (*domain.Product, error) {
 	var product domain.Product
-	err := row.Scan(&product.Id, &product.Name, &product.Price, &product.Quantity)
+	var unit, tags string
+	err := row.Scan(&product.Id, &product.Name, &product.Price, &product.Quantity,
+		&product.ReorderPoint, &product.ReorderQuantity, &product.Version,
+		&product.SKU, &product.Barcode, &product.Description, &product.CategoryId, &unit, &tags)
+	if err != nil {
+		return nil, err
+	}
+	product.Unit = domain.UnitOfMeasure(unit)
+	if err := json.Unmarshal([]byte(tags), &product.Tags); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func marshalTags(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(tags)
+	return string(data)
+}
+
+// productWriteError tells which catalog field collided with another product.
+func productWriteError(err error) error {
+	if !isUniqueViolation(err) {
+		return domain.ErrRepository
+	}
+	if strings.Contains(err.Error(), "products.barcode") {
+		return domain.ErrDuplicateBarcode
+	}
+	return domain.ErrDuplicateSKU
+}
+
+func (repo *sqliteRepository) FindById(id string) (*domain.Product, error) {
+	row := repo.db.QueryRow("SELECT "+productColumns+" FROM products WHERE id=? AND deleted_at IS NULL", id)
+
+	product, err := scanProduct(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrProductNotFound
 		}
 		return nil, domain.ErrRepository
 	}
-	return &product, nil
+	return product, nil
+}
+
+func (repo *sqliteRepository) FindBySKU(sku string) (*domain.Product, error) {
+	return repo.findProductBy("sku", sku)
 }
 
-func (repo *sqliteRepository) Save(product *domain.Product) error {
-	statement, err := repo.db.Prepare("INSERT INTO products(id, name, price,quantity) VALUES(?,?,?,?)")
+func (repo *sqliteRepository) FindByBarcode(barcode string) (*domain.Product, error) {
+	if barcode == "" {
+		return nil, domain.ErrProductNotFound
+	}
+	return repo.findProductBy("barcode", barcode)
+}
+
+func (repo *sqliteRepository) findProductBy(column, value string) (*domain.Product, error) {
+	row := repo.db.QueryRow("SELECT "+productColumns+" FROM products WHERE "+column+" = ? AND deleted_at IS NULL", value)
+
+	product, err := scanProduct(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, domain.ErrRepository
+	}
+	return product, nil
+}
+
+// recordChange writes audit, when it is not nil, inside the change's
+// transaction with After set to the product as the change left it.
+func recordChange(tx *sql.Tx, audit *domain.AuditEntry, after *domain.Product) error {
+	if audit == nil {
+		return nil
+	}
+	if after != nil {
+		snapshot := *after
+		audit.After = &snapshot
+	}
+	return insertAudit(tx, audit)
+}
+
+// Save stores a new product with all of its opening stock at the default
+// location.
+func (repo *sqliteRepository) Save(product *domain.Product, movement *domain.StockMovement, audit *domain.AuditEntry) error {
+	tx, err := repo.db.Begin()
 	if err != nil {
 		return domain.ErrRepository
 	}
-	defer statement.Close()
+	defer tx.Rollback()
 
-	_, err = statement.Exec(product.Id, product.Name, product.Price, product.Quantity)
+	_, err = tx.Exec("INSERT INTO products("+productColumns+") VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)",
+		product.Id, product.Name, product.Price, product.Quantity,
+		product.ReorderPoint, product.ReorderQuantity, product.Version,
+		product.SKU, product.Barcode, product.Description, product.CategoryId, string(product.Unit), marshalTags(product.Tags))
 	if err != nil {
+		return productWriteError(err)
+	}
+	if err := addStockLevel(tx, product.Id, domain.DefaultLocationId, product.Quantity); err != nil {
+		return err
+	}
+	if movement != nil {
+		movement.LocationId = domain.DefaultLocationId
+		movement.Delta = product.Quantity
+		movement.ResultingQuantity = product.Quantity
+		if err := insertMovement(tx, movement); err != nil {
+			return err
+		}
+	}
+	if err := recordChange(tx, audit, product); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
 		return domain.ErrRepository
 	}
 	return nil
 }
 
-func (repo *sqliteRepository) Update(product *domain.Product) error {
-	statement, err := repo.db.Prepare("UPDATE products SET name=?, price=?, quantity=? WHERE id =?")
+func (repo *sqliteRepository) Update(product *domain.Product, audit *domain.AuditEntry) error {
+	tx, err := repo.db.Begin()
 	if err != nil {
 		return domain.ErrRepository
 	}
-	defer statement.Close()
-	_, err = statement.Exec(product.Name, product.Price, product.Quantity, product.Id)
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE products SET name=?, price=?, quantity=?, reorder_point=?, reorder_quantity=?,
+		sku=?, barcode=?, description=?, category_id=?, unit=?, tags=?,
+		version=version+1 WHERE id =? AND version=? AND deleted_at IS NULL`,
+		product.Name, product.Price, product.Quantity,
+		product.ReorderPoint, product.ReorderQuantity,
+		product.SKU, product.Barcode, product.Description, product.CategoryId, string(product.Unit), marshalTags(product.Tags),
+		product.Id, product.Version)
 	if err != nil {
+		return productWriteError(err)
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		tx.Rollback()
+		if _, err := repo.FindById(product.Id); err != nil {
+			return err
+		}
+		return domain.ErrConflict
+	}
+	updated := *product
+	updated.Version++
+	if err := recordChange(tx, audit, &updated); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
 		return domain.ErrRepository
 	}
+	product.Version++
 	return nil
 }
 
-func (repo *sqliteRepository) DeleteById(id string) error {
-	statement, err := repo.db.Prepare("DELETE FROM products WHERE id =?")
+func (repo *sqliteRepository) ApplyStockMovement(movement *domain.StockMovement, expectedVersion int, audit *domain.AuditEntry) (*domain.Product, error) {
+	return repo.applyStockMovement(movement, expectedVersion, audit, nil)
+}
+
+// applyStockMovement calls also, when it is not nil, inside the movement's
+// transaction after the stock has changed, so its writes commit or roll back
+// together with the movement and its audit entry.
+func (repo *sqliteRepository) applyStockMovement(movement *domain.StockMovement, expectedVersion int, audit *domain.AuditEntry,
+	also func(tx *sql.Tx) error) (*domain.Product, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow(
+		`UPDATE products SET quantity = quantity + ?, version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND quantity + ? >= 0 AND (? = 0 OR version = ?)
+		RETURNING `+productColumns,
+		movement.Delta, movement.ProductId, movement.Delta, expectedVersion, expectedVersion)
+
+	product, err := scanProduct(row)
+	if err == nil {
+		movement.ResultingQuantity = product.Quantity
+		if movement.LocationId != "" {
+			if err := moveLocationStock(tx, movement); err != nil {
+				return nil, err
+			}
+		}
+		if also != nil {
+			if err := also(tx); err != nil {
+				return nil, err
+			}
+		}
+		if err := insertMovement(tx, movement); err != nil {
+			return nil, err
+		}
+		if err := recordChange(tx, audit, product); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, domain.ErrRepository
+		}
+		return product, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, domain.ErrRepository
+	}
+	tx.Rollback()
+
+	current, err := repo.FindById(movement.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	if err := current.MatchesVersion(expectedVersion); err != nil {
+		return nil, err
+	}
+	return nil, domain.ErrInsufficientStock
+}
+
+// DeleteById only marks the product deleted, so its history keeps pointing at
+// a row and it can be restored. The quantity stays on the row for a restore.
+func (repo *sqliteRepository) DeleteById(id string, movement *domain.StockMovement, audit *domain.AuditEntry) error {
+	tx, err := repo.db.Begin()
 	if err != nil {
 		return domain.ErrRepository
 	}
-	defer statement.Close()
+	defer tx.Rollback()
 
-	res, err := statement.Exec(id)
+	var quantity int
+	err = tx.QueryRow("UPDATE products SET deleted_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL RETURNING quantity",
+		formatTimestamp(movement.CreatedAt), id).Scan(&quantity)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrProductNotFound
+		}
 		return domain.ErrRepository
 	}
 
-	rowsAffected, _ := res.RowsAffected()
-	if rowsAffected == 0 {
-		return domain.ErrProductNotFound
+	movement.Delta = -quantity
+	movement.ResultingQuantity = 0
+	if err := insertMovement(tx, movement); err != nil {
+		return err
+	}
+	if err := recordChange(tx, audit, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ErrRepository
 	}
 	return nil
 }
 
-func (repo *sqliteRepository) ListAll() ([]domain.Product, error) {
-	rows, err := repo.db.Query("SELECT id, name, price, quantity FROM products")
+func (repo *sqliteRepository) RestoreById(id string, movement *domain.StockMovement, audit *domain.AuditEntry) (*domain.Product, error) {
+	tx, err := repo.db.Begin()
 	if err != nil {
 		return nil, domain.ErrRepository
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var products []domain.Product
-	for rows.Next() {
-		var product domain.Product
-		if err := rows.Scan(&product.Id, &product.Name, &product.Price, &product.Quantity); err != nil {
+	row := tx.QueryRow("UPDATE products SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL RETURNING "+productColumns, id)
+	product, err := scanProduct(row)
+	if err != nil {
+		if err != sql.ErrNoRows {
 			return nil, domain.ErrRepository
 		}
-		products = append(products, product)
+		tx.Rollback()
+		if _, err := repo.FindById(id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrProductNotDeleted
+	}
+
+	movement.Delta = product.Quantity
+	movement.ResultingQuantity = product.Quantity
+	if err := insertMovement(tx, movement); err != nil {
+		return nil, err
 	}
-	if err = rows.Err(); err != nil {
+	if err := recordChange(tx, audit, product); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
 		return nil, domain.ErrRepository
 	}
-	return products, nil
+	return product, nil
 }
 
-func (repo *sqliteRepository) FindByEmail(email string) (*domain.Manager, error) {
-	row := repo.db.QueryRow("SELECT id, email, password FROM managers WHERE email = ?", email)
+// PurgeDeletedProducts permanently removes products deleted before the cutoff
+// and returns them as they were last stored. audit, when it is not nil, gives
+// the entry to record for each purged product.
+func (repo *sqliteRepository) PurgeDeletedProducts(deletedBefore time.Time, audit func(product *domain.Product) *domain.AuditEntry) ([]domain.Product, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer tx.Rollback()
 
-	manager := &domain.Manager{}
-	err := row.Scan(&manager.Id, &manager.Email, &manager.Password)
+	rows, err := tx.Query("DELETE FROM products WHERE deleted_at IS NOT NULL AND deleted_at < ? RETURNING "+productColumns,
+		formatTimestamp(deletedBefore))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, domain.ErrInvalidCredentials
+		return nil, domain.ErrRepository
+	}
+	purged := []domain.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			rows.Close()
+			return nil, domain.ErrRepository
+		}
+		purged = append(purged, *product)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+
+	for _, product := range purged {
+		if _, err := tx.Exec("DELETE FROM stock_levels WHERE product_id = ?", product.Id); err != nil {
+			return nil, domain.ErrRepository
+		}
+		if _, err := tx.Exec("DELETE FROM bin_stock WHERE product_id = ?", product.Id); err != nil {
+			return nil, domain.ErrRepository
 		}
+		if _, err := tx.Exec("DELETE FROM lots WHERE product_id = ?", product.Id); err != nil {
+			return nil, domain.ErrRepository
+		}
+		if audit != nil {
+			if err := recordChange(tx, audit(&product), nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
 		return nil, domain.ErrRepository
 	}
-	return manager, nil
+	return purged, nil
 }
@@ -3,7 +3,12 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
 	"github.com/google/uuid"
@@ -11,33 +16,23 @@ import (
 )
 
 func setupTestDB(t *testing.T) *sql.DB {
-	db, err := sql.Open("sqlite3", ":memory:")
+	db, err := Open(":memory:")
 	if err != nil {
 		t.Fatalf("Failed to open in-memory database: %v", err)
 	}
+	db.SetMaxOpenConns(1)
+	createTestSchema(t, db)
+	return db
+}
 
-	productsTableSQL := `
-    CREATE TABLE products (
-        id TEXT NOT NULL PRIMARY KEY,
-        name TEXT,
-        price REAL,
-        quantity INTEGER
-    );`
-	if _, err := db.Exec(productsTableSQL); err != nil {
-		t.Fatalf("Failed to create products table: %v", err)
+func createTestSchema(t *testing.T, db *sql.DB) {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
 	}
-
-	managersTableSQL := `
-    CREATE TABLE managers (
-        id TEXT NOT NULL PRIMARY KEY,
-        email TEXT UNIQUE,
-        password TEXT
-    );`
-	if _, err := db.Exec(managersTableSQL); err != nil {
-		t.Fatalf("Failed to create managers table: %v", err)
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
 	}
-
-	return db
 }
 
 func TestSqliteRepository_SaveAndFindById(t *testing.T) {
@@ -45,8 +40,9 @@ func TestSqliteRepository_SaveAndFindById(t *testing.T) {
 	defer db.Close()
 	repo := NewSQLiteRepository(db)
 	product, _ := domain.CreateNewProduct("Test Keyboard", 99.99, 50)
+	product.SetReorderThresholds(12, 60)
 
-	if err := repo.Save(product); err != nil {
+	if err := repo.Save(product, nil, nil); err != nil {
 		t.Fatalf("Save() returned an unexpected error: %v", err)
 	}
 
@@ -55,7 +51,7 @@ func TestSqliteRepository_SaveAndFindById(t *testing.T) {
 		t.Fatalf("FindById() returned an unexpected error: %v", err)
 	}
 
-	if product.Id != found.Id || product.Name != found.Name {
+	if !reflect.DeepEqual(product, found) {
 		t.Errorf("FindById() got = %+v, want %+v", found, product)
 	}
 }
@@ -76,20 +72,38 @@ func TestSqliteRepository_Update(t *testing.T) {
 	defer db.Close()
 	repo := NewSQLiteRepository(db)
 	product, _ := domain.CreateNewProduct("Old Name", 10.0, 10)
-	repo.Save(product)
+	repo.Save(product, nil, nil)
 
 	product.Name = "New Name"
 	product.Price = 25.50
 	product.Quantity = 100
+	product.SetReorderThresholds(30, 90)
 
-	if err := repo.Update(product); err != nil {
+	if err := repo.Update(product, nil); err != nil {
 		t.Fatalf("Update() returned an unexpected error: %v", err)
 	}
 
 	updated, _ := repo.FindById(product.Id)
-	if updated.Name != "New Name" || updated.Price != 25.50 || updated.Quantity != 100 {
+	if updated.Name != "New Name" || updated.Price != 25.50 || updated.Quantity != 100 || updated.Version != 2 ||
+		updated.ReorderPoint != 30 || updated.ReorderQuantity != 90 {
 		t.Errorf("Update() failed. got = %+v, want %+v", updated, product)
 	}
+
+	t.Run("fail_stale_version", func(t *testing.T) {
+		stale := *product
+		stale.Version = 1
+		stale.Price = 1.0
+		if err := repo.Update(&stale, nil); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("expected error %v, got %v", domain.ErrConflict, err)
+		}
+	})
+
+	t.Run("fail_not_found", func(t *testing.T) {
+		missing := &domain.Product{Id: "non-existent-id", Name: "Ghost", Price: 1, Version: 1}
+		if err := repo.Update(missing, nil); !errors.Is(err, domain.ErrProductNotFound) {
+			t.Errorf("expected error %v, got %v", domain.ErrProductNotFound, err)
+		}
+	})
 }
 
 func TestSqliteRepository_DeleteById(t *testing.T) {
@@ -97,14 +111,20 @@ func TestSqliteRepository_DeleteById(t *testing.T) {
 	defer db.Close()
 	repo := NewSQLiteRepository(db)
 	product, _ := domain.CreateNewProduct("ToDelete", 1.0, 1)
-	repo.Save(product)
+	repo.Save(product, nil, nil)
 
 	t.Run("success", func(t *testing.T) {
-		err := repo.DeleteById(product.Id)
+		movement := domain.NewStockMovement(product.Id, 0, domain.MovementDelete, "manager-1")
+		err := repo.DeleteById(product.Id, movement, nil)
 		if err != nil {
 			t.Fatalf("DeleteById() returned an unexpected error: %v", err)
 		}
 
+		movements, _ := repo.ListMovements(product.Id, time.Time{}, time.Time{})
+		if len(movements) != 1 || movements[0].Delta != -1 || movements[0].Reason != domain.MovementDelete {
+			t.Errorf("DeleteById() recorded movements = %+v", movements)
+		}
+
 		_, err = repo.FindById(product.Id)
 		if !errors.Is(err, domain.ErrProductNotFound) {
 			t.Errorf("expected error %v after delete, but got %v", domain.ErrProductNotFound, err)
@@ -112,43 +132,103 @@ func TestSqliteRepository_DeleteById(t *testing.T) {
 	})
 
 	t.Run("fail_not_found", func(t *testing.T) {
-		err := repo.DeleteById("non-existent-id")
+		err := repo.DeleteById("non-existent-id", domain.NewStockMovement("non-existent-id", 0, domain.MovementDelete, ""), nil)
 		if !errors.Is(err, domain.ErrProductNotFound) {
 			t.Errorf("expected error %v for non-existent product, but got %v", domain.ErrProductNotFound, err)
 		}
 	})
 }
 
-func TestSqliteRepository_ListAll(t *testing.T) {
+func TestSqliteRepository_SoftDeleteAndRestore(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 	repo := NewSQLiteRepository(db)
+	kept, _ := domain.CreateNewProduct("Kept", 2.0, 10)
+	deleted, _ := domain.CreateNewProduct("Deleted", 5.0, 4)
+	repo.Save(kept, nil, nil)
+	repo.Save(deleted, nil, nil)
 
-	t.Run("list_all_with_products", func(t *testing.T) {
-		p1, _ := domain.CreateNewProduct("Product 1", 10, 1)
-		p2, _ := domain.CreateNewProduct("Product 2", 20, 2)
-		repo.Save(p1)
-		repo.Save(p2)
+	if err := repo.DeleteById(deleted.Id, domain.NewStockMovement(deleted.Id, 0, domain.MovementDelete, "manager-1"), nil); err != nil {
+		t.Fatalf("DeleteById() returned an unexpected error: %v", err)
+	}
 
-		products, err := repo.ListAll()
-		if err != nil {
-			t.Fatalf("ListAll() returned an error: %v", err)
-		}
-		if len(products) != 2 {
-			t.Errorf("ListAll() should return 2 products, got %d", len(products))
-		}
-	})
+	page, err := repo.ListProducts(domain.ProductQuery{})
+	if err != nil || len(page.Products) != 1 || page.Products[0].Id != kept.Id {
+		t.Errorf("ListProducts() = %+v, %v, want only the kept product", page, err)
+	}
+	if values, _ := repo.InventoryValueByCategory(""); values[""] != 20 {
+		t.Errorf("InventoryValueByCategory() = %v, want 20 without the deleted product", values)
+	}
+	if _, err := repo.ApplyStockMovement(domain.NewStockMovement(deleted.Id, 1, domain.MovementRestock, ""), 0, nil); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("ApplyStockMovement() on a deleted product error = %v, want ErrProductNotFound", err)
+	}
+	if err := repo.DeleteById(deleted.Id, domain.NewStockMovement(deleted.Id, 0, domain.MovementDelete, ""), nil); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("DeleteById() twice error = %v, want ErrProductNotFound", err)
+	}
 
-	t.Run("list_all_empty", func(t *testing.T) {
-		db.Exec("DELETE FROM products")
-		products, err := repo.ListAll()
-		if err != nil {
-			t.Fatalf("ListAll() on empty table returned an error: %v", err)
-		}
-		if len(products) != 0 {
-			t.Errorf("ListAll() on empty table should return 0 products, got %d", len(products))
-		}
-	})
+	restored, err := repo.RestoreById(deleted.Id, domain.NewStockMovement(deleted.Id, 0, domain.MovementRestore, "manager-1"), nil)
+	if err != nil {
+		t.Fatalf("RestoreById() returned an unexpected error: %v", err)
+	}
+	if restored.Quantity != 4 || restored.Version != deleted.Version+2 {
+		t.Errorf("RestoreById() = %+v, want quantity 4 at version %d", restored, deleted.Version+2)
+	}
+	movements, _ := repo.ListMovements(deleted.Id, time.Time{}, time.Time{})
+	if len(movements) != 2 || movements[1].Reason != domain.MovementRestore || movements[1].Delta != 4 || movements[1].ResultingQuantity != 4 {
+		t.Errorf("RestoreById() recorded movements = %+v", movements)
+	}
+	if _, err := repo.FindById(deleted.Id); err != nil {
+		t.Errorf("FindById() after restore returned %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{"fail_not_deleted", kept.Id, domain.ErrProductNotDeleted},
+		{"fail_not_found", "missing", domain.ErrProductNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.RestoreById(tt.id, domain.NewStockMovement(tt.id, 0, domain.MovementRestore, ""), nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RestoreById() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSqliteRepository_PurgeDeletedProducts(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	now := time.Now().UTC()
+
+	old, _ := domain.CreateNewProduct("Old", 1.0, 1)
+	recent, _ := domain.CreateNewProduct("Recent", 1.0, 1)
+	live, _ := domain.CreateNewProduct("Live", 1.0, 1)
+	for _, product := range []*domain.Product{old, recent, live} {
+		repo.Save(product, nil, nil)
+	}
+	oldDelete := domain.NewStockMovement(old.Id, 0, domain.MovementDelete, "")
+	oldDelete.CreatedAt = now.Add(-40 * 24 * time.Hour)
+	repo.DeleteById(old.Id, oldDelete, nil)
+	repo.DeleteById(recent.Id, domain.NewStockMovement(recent.Id, 0, domain.MovementDelete, ""), nil)
+
+	purged, err := repo.PurgeDeletedProducts(now.Add(-30*24*time.Hour), nil)
+	if err != nil || len(purged) != 1 || purged[0].Id != old.Id {
+		t.Fatalf("PurgeDeletedProducts() = %+v, %v, want only the old product", purged, err)
+	}
+	if _, err := repo.RestoreById(old.Id, domain.NewStockMovement(old.Id, 0, domain.MovementRestore, ""), nil); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("RestoreById() of a purged product error = %v, want ErrProductNotFound", err)
+	}
+	if _, err := repo.RestoreById(recent.Id, domain.NewStockMovement(recent.Id, 0, domain.MovementRestore, ""), nil); err != nil {
+		t.Errorf("RestoreById() of a recently deleted product error = %v", err)
+	}
+	if _, err := repo.FindById(live.Id); err != nil {
+		t.Errorf("FindById() of a live product after purge error = %v", err)
+	}
 }
 
 func TestSqliteRepository_FindByEmail(t *testing.T) {
@@ -160,15 +240,16 @@ func TestSqliteRepository_FindByEmail(t *testing.T) {
 		Id:       uuid.NewString(),
 		Email:    "test@example.com",
 		Password: "hashedpassword",
+		Role:     domain.RoleClerk,
 	}
-	db.Exec("INSERT INTO managers (id, email, password) VALUES (?, ?, ?)", manager.Id, manager.Email, manager.Password)
+	db.Exec("INSERT INTO managers (id, email, password, role) VALUES (?, ?, ?, ?)", manager.Id, manager.Email, manager.Password, manager.Role)
 
 	t.Run("success", func(t *testing.T) {
 		found, err := repo.FindByEmail("test@example.com")
 		if err != nil {
 			t.Fatalf("FindByEmail() returned an unexpected error: %v", err)
 		}
-		if found.Email != manager.Email || found.Id != manager.Id {
+		if found.Email != manager.Email || found.Id != manager.Id || found.Role != domain.RoleClerk {
 			t.Errorf("FindByEmail() got = %+v, want %+v", found, manager)
 		}
 	})
@@ -194,7 +275,7 @@ func TestSqliteRepository_DBError(t *testing.T) {
 	})
 
 	t.Run("Save_db_error", func(t *testing.T) {
-		err := repo.Save(&domain.Product{})
+		err := repo.Save(&domain.Product{}, nil, nil)
 		if !errors.Is(err, domain.ErrRepository) {
 			t.Errorf("expected ErrRepository, got %v", err)
 		}
@@ -207,3 +288,161 @@ func TestSqliteRepository_DBError(t *testing.T) {
 		}
 	})
 }
+
+func TestSqliteRepository_ApplyStockMovement(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	product, _ := domain.CreateNewProduct("Cable", 5.0, 10)
+	repo.Save(product, nil, nil)
+
+	tests := []struct {
+		name            string
+		productID       string
+		delta           int
+		expectedVersion int
+		wantQty         int
+		wantErr         error
+	}{
+		{"sell_units", product.Id, -4, 0, 6, nil},
+		{"restock_units_matching_version", product.Id, 14, 2, 20, nil},
+		{"fail_stale_version", product.Id, -1, 2, 20, domain.ErrConflict},
+		{"sell_all_units", product.Id, -20, 0, 0, nil},
+		{"fail_insufficient_stock", product.Id, -1, 0, 0, domain.ErrInsufficientStock},
+		{"fail_not_found", "non-existent-id", 5, 0, 0, domain.ErrProductNotFound},
+	}
+
+	recorded := 0
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movement := domain.NewStockMovement(tt.productID, tt.delta, domain.MovementAdjustment, "manager-1")
+			updated, err := repo.ApplyStockMovement(movement, tt.expectedVersion, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyStockMovement() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (updated.Quantity != tt.wantQty || movement.ResultingQuantity != tt.wantQty) {
+				t.Errorf("ApplyStockMovement() quantity = %d, movement = %+v, want %d", updated.Quantity, movement, tt.wantQty)
+			}
+			stored, _ := repo.FindById(product.Id)
+			if stored.Quantity != tt.wantQty {
+				t.Errorf("stored quantity = %d, want %d", stored.Quantity, tt.wantQty)
+			}
+
+			if tt.wantErr == nil {
+				recorded++
+			}
+			movements, _ := repo.ListMovements(product.Id, time.Time{}, time.Time{})
+			if len(movements) != recorded {
+				t.Errorf("recorded %d movements, want %d", len(movements), recorded)
+			}
+		})
+	}
+}
+
+func TestSqliteRepository_ApplyStockMovement_Concurrent(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "concurrent.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database file: %v", err)
+	}
+	defer db.Close()
+	createTestSchema(t, db)
+	repo := NewSQLiteRepository(db)
+
+	const initialQty = 250
+	const sellers = 400
+	product, _ := domain.CreateNewProduct("Hot Item", 1.0, initialQty)
+	if err := repo.Save(product, nil, nil); err != nil {
+		t.Fatalf("Save() returned an unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var sold, rejected atomic.Int64
+	for i := 0; i < sellers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.ApplyStockMovement(domain.NewStockMovement(product.Id, -1, domain.MovementSale, ""), 0, nil)
+			switch {
+			case err == nil:
+				sold.Add(1)
+			case errors.Is(err, domain.ErrInsufficientStock):
+				rejected.Add(1)
+			default:
+				t.Errorf("ApplyStockMovement() returned an unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if sold.Load() != initialQty {
+		t.Errorf("sold %d units, want %d", sold.Load(), initialQty)
+	}
+	if rejected.Load() != sellers-initialQty {
+		t.Errorf("rejected %d sales, want %d", rejected.Load(), sellers-initialQty)
+	}
+	final, _ := repo.FindById(product.Id)
+	if final.Quantity != 0 {
+		t.Errorf("final quantity = %d, want 0", final.Quantity)
+	}
+	movements, _ := repo.ListMovements(product.Id, time.Time{}, time.Time{})
+	if len(movements) != initialQty {
+		t.Errorf("recorded %d movements, want %d", len(movements), initialQty)
+	}
+}
+
+func TestSqliteRepository_CatalogFields(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+
+	scanned, _ := domain.CreateNewProduct("Olive Oil", 9.5, 12)
+	scanned.SetDetails(domain.ProductDetails{SKU: "oil-1l", Barcode: "4006381333931", Description: "Extra virgin",
+		CategoryId: "cat-pantry", Unit: domain.UnitLitre, Tags: []string{"organic", "imported"}})
+	plain, _ := domain.CreateNewProduct("Rice", 3, 40)
+	for _, product := range []*domain.Product{scanned, plain} {
+		if err := repo.Save(product, nil, nil); err != nil {
+			t.Fatalf("Save() returned an unexpected error: %v", err)
+		}
+	}
+
+	bySKU, err := repo.FindBySKU("OIL-1L")
+	if err != nil || !reflect.DeepEqual(bySKU, scanned) {
+		t.Errorf("FindBySKU() = %+v, %v, want %+v", bySKU, err, scanned)
+	}
+	if byBarcode, err := repo.FindByBarcode("4006381333931"); err != nil || byBarcode.Id != scanned.Id {
+		t.Errorf("FindByBarcode() = %+v, %v", byBarcode, err)
+	}
+	if _, err := repo.FindByBarcode(""); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("FindByBarcode() of an empty code error = %v, want ErrProductNotFound", err)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*domain.Product)
+		wantErr error
+	}{
+		{"duplicate_sku", func(p *domain.Product) { p.SKU = "OIL-1L" }, domain.ErrDuplicateSKU},
+		{"duplicate_barcode", func(p *domain.Product) { p.Barcode = "4006381333931" }, domain.ErrDuplicateBarcode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"_on_save", func(t *testing.T) {
+			product, _ := domain.CreateNewProduct("Copy", 1, 1)
+			tt.mutate(product)
+			if err := repo.Save(product, nil, nil); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Save() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+		t.Run(tt.name+"_on_update", func(t *testing.T) {
+			product, _ := repo.FindById(plain.Id)
+			tt.mutate(product)
+			if err := repo.Update(product, nil); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	repo.DeleteById(scanned.Id, domain.NewStockMovement(scanned.Id, 0, domain.MovementDelete, ""), nil)
+	if _, err := repo.FindBySKU(scanned.SKU); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("FindBySKU() of a deleted product error = %v, want ErrProductNotFound", err)
+	}
+}
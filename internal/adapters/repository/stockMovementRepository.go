@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+const timestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	return time.Parse(timestampLayout, value)
+}
+
+func insertMovement(tx *sql.Tx, movement *domain.StockMovement) error {
+	_, err := tx.Exec(
+		`INSERT INTO stock_movements(id, product_id, location_id, lot_id, delta, reason, resulting_quantity, manager_id, created_at)
+		VALUES(?,?,?,?,?,?,?,?,?)`,
+		movement.Id, movement.ProductId, movement.LocationId, movement.LotId, movement.Delta, string(movement.Reason),
+		movement.ResultingQuantity, movement.ManagerId, formatTimestamp(movement.CreatedAt))
+	if err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) ListMovements(productId string, from, to time.Time) ([]domain.StockMovement, error) {
+	query := `SELECT id, product_id, location_id, lot_id, delta, reason, resulting_quantity, manager_id, created_at
+		FROM stock_movements WHERE product_id = ?`
+	args := []interface{}{productId}
+	if !from.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, formatTimestamp(from))
+	}
+	if !to.IsZero() {
+		query += " AND created_at <= ?"
+		args = append(args, formatTimestamp(to))
+	}
+	query += " ORDER BY created_at, rowid"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	movements := []domain.StockMovement{}
+	for rows.Next() {
+		var movement domain.StockMovement
+		var reason, createdAt string
+		if err := rows.Scan(&movement.Id, &movement.ProductId, &movement.LocationId, &movement.LotId, &movement.Delta, &reason,
+			&movement.ResultingQuantity, &movement.ManagerId, &createdAt); err != nil {
+			return nil, domain.ErrRepository
+		}
+		movement.Reason = domain.MovementReason(reason)
+		if movement.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return nil, domain.ErrRepository
+		}
+		movements = append(movements, movement)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return movements, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_ListMovements(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	product, _ := domain.CreateNewProduct("Screws", 0.05, 1000)
+	repo.Save(product, nil, nil)
+
+	base := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+	for i, delta := range []int{-40, 200, -15} {
+		movement := domain.NewStockMovement(product.Id, delta, domain.MovementSale, "manager-1")
+		movement.CreatedAt = base.Add(time.Duration(i) * 24 * time.Hour)
+		if _, err := repo.ApplyStockMovement(movement, 0, nil); err != nil {
+			t.Fatalf("ApplyStockMovement() returned an unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		productID  string
+		from       time.Time
+		to         time.Time
+		wantDeltas []int
+	}{
+		{"full_history", product.Id, time.Time{}, time.Time{}, []int{-40, 200, -15}},
+		{"from_second_day", product.Id, base.Add(24 * time.Hour), time.Time{}, []int{200, -15}},
+		{"until_first_day", product.Id, time.Time{}, base.Add(time.Hour), []int{-40}},
+		{"single_day_window", product.Id, base.Add(23 * time.Hour), base.Add(25 * time.Hour), []int{200}},
+		{"unknown_product", "non-existent-id", time.Time{}, time.Time{}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movements, err := repo.ListMovements(tt.productID, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("ListMovements() returned an unexpected error: %v", err)
+			}
+			if len(movements) != len(tt.wantDeltas) {
+				t.Fatalf("ListMovements() returned %d movements, want %d", len(movements), len(tt.wantDeltas))
+			}
+			for i, movement := range movements {
+				if movement.Delta != tt.wantDeltas[i] {
+					t.Errorf("movement %d delta = %d, want %d", i, movement.Delta, tt.wantDeltas[i])
+				}
+				if movement.ManagerId != "manager-1" {
+					t.Errorf("movement %d manager = %q, want %q", i, movement.ManagerId, "manager-1")
+				}
+			}
+		})
+	}
+
+	t.Run("opening_stock_is_recorded_on_save", func(t *testing.T) {
+		opened, _ := domain.CreateNewProduct("Nails", 0.02, 500)
+		if err := repo.Save(opened, domain.NewStockMovement(opened.Id, 0, domain.MovementInitial, "manager-1"), nil); err != nil {
+			t.Fatalf("Save() returned an unexpected error: %v", err)
+		}
+		movements, err := repo.ListMovements(opened.Id, time.Time{}, time.Time{})
+		if err != nil || len(movements) != 1 {
+			t.Fatalf("ListMovements() = %+v, %v, want the opening movement", movements, err)
+		}
+		got := movements[0]
+		if got.Reason != domain.MovementInitial || got.Delta != 500 || got.ResultingQuantity != 500 || got.LocationId != domain.DefaultLocationId {
+			t.Errorf("opening movement = %+v, want +500 at %s", got, domain.DefaultLocationId)
+		}
+	})
+
+	t.Run("resulting_quantity_tracks_running_total", func(t *testing.T) {
+		movements, _ := repo.ListMovements(product.Id, time.Time{}, time.Time{})
+		want := []int{960, 1160, 1145}
+		for i, movement := range movements {
+			if movement.ResultingQuantity != want[i] {
+				t.Errorf("movement %d resulting quantity = %d, want %d", i, movement.ResultingQuantity, want[i])
+			}
+		}
+	})
+}
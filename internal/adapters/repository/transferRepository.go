@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.TransferRepository = (*sqliteRepository)(nil)
+
+const transferColumns = "id, product_id, from_location_id, to_location_id, quantity, status, created_by, created_at, completed_at"
+
+func scanTransfer(row rowScanner) (*domain.Transfer, error) {
+	var transfer domain.Transfer
+	var status, createdAt string
+	var completedAt sql.NullString
+	err := row.Scan(&transfer.Id, &transfer.ProductId, &transfer.FromLocationId, &transfer.ToLocationId,
+		&transfer.Quantity, &status, &transfer.CreatedBy, &createdAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Status = domain.TransferStatus(status)
+	if transfer.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	if transfer.CompletedAt, err = parseNullTimestamp(completedAt); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// CreateTransfer takes the quantity out of the source location in the same
+// transaction that records the transfer, so stock is never in both places.
+// The lots it was drawn from are kept with the transfer so they can follow
+// the stock. The product's own quantity is left alone since the stock is
+// still owned.
+func (repo *sqliteRepository) CreateTransfer(transfer *domain.Transfer) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return domain.ErrRepository
+	}
+	defer tx.Rollback()
+
+	var live int
+	err = tx.QueryRow("SELECT COUNT(*) FROM products WHERE id = ? AND deleted_at IS NULL", transfer.ProductId).Scan(&live)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if live == 0 {
+		return domain.ErrProductNotFound
+	}
+	draws, err := drawStock(tx, transfer.ProductId, transfer.FromLocationId, transfer.Quantity, transfer.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO stock_transfers("+transferColumns+") VALUES(?,?,?,?,?,?,?,?,NULL)",
+		transfer.Id, transfer.ProductId, transfer.FromLocationId, transfer.ToLocationId,
+		transfer.Quantity, string(transfer.Status), transfer.CreatedBy, formatTimestamp(transfer.CreatedAt))
+	if err != nil {
+		return domain.ErrRepository
+	}
+	for _, draw := range draws {
+		_, err := tx.Exec("INSERT INTO stock_transfer_lots(transfer_id, lot_id, quantity) VALUES(?,?,?)",
+			transfer.Id, draw.LotId, draw.Quantity)
+		if err != nil {
+			return domain.ErrRepository
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ErrRepository
+	}
+	return nil
+}
+
+func (repo *sqliteRepository) ReceiveTransfer(id string, receivedAt time.Time) (*domain.Transfer, error) {
+	return repo.completeTransfer(id, domain.TransferReceived, receivedAt)
+}
+
+func (repo *sqliteRepository) CancelTransfer(id string, cancelledAt time.Time) (*domain.Transfer, error) {
+	return repo.completeTransfer(id, domain.TransferCancelled, cancelledAt)
+}
+
+// completeTransfer closes an in-transit transfer and puts its quantity into
+// the destination when received or back into the source when cancelled. The
+// lots it was drawn from are recreated at the destination or refilled at
+// the source to match.
+func (repo *sqliteRepository) completeTransfer(id string, status domain.TransferStatus, at time.Time) (*domain.Transfer, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow("UPDATE stock_transfers SET status = ?, completed_at = ? WHERE id = ? AND status = ? RETURNING "+transferColumns,
+		string(status), formatTimestamp(at), id, string(domain.TransferInTransit))
+	transfer, err := scanTransfer(row)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			return nil, domain.ErrRepository
+		}
+		tx.Rollback()
+		var exists int
+		if err := repo.db.QueryRow("SELECT COUNT(*) FROM stock_transfers WHERE id = ?", id).Scan(&exists); err != nil {
+			return nil, domain.ErrRepository
+		}
+		if exists == 0 {
+			return nil, domain.ErrTransferNotFound
+		}
+		return nil, domain.ErrTransferNotInTransit
+	}
+
+	locationId := transfer.ToLocationId
+	if status == domain.TransferCancelled {
+		locationId = transfer.FromLocationId
+	}
+	if err := addStockLevel(tx, transfer.ProductId, locationId, transfer.Quantity); err != nil {
+		return nil, err
+	}
+	lots, quantities, err := transferLots(tx, transfer.Id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lots {
+		if status == domain.TransferCancelled {
+			_, err = tx.Exec("UPDATE lots SET quantity = quantity + ? WHERE id = ?", quantities[i], lots[i].Id)
+		} else {
+			err = receiveTransferredLot(tx, lots[i].TransferredTo(locationId, quantities[i], at))
+		}
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return transfer, nil
+}
+
+// transferLots loads the lots a transfer was drawn from together with how
+// many units it took from each.
+func transferLots(tx *sql.Tx, transferId string) ([]domain.Lot, []int, error) {
+	rows, err := tx.Query(`SELECT l.id, l.product_id, l.location_id, l.lot_number, l.quantity, l.received_at, l.expires_at,
+		l.expiry_notified_at, t.quantity
+		FROM stock_transfer_lots t JOIN lots l ON l.id = t.lot_id
+		WHERE t.transfer_id = ? ORDER BY t.rowid`, transferId)
+	if err != nil {
+		return nil, nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	lots := []domain.Lot{}
+	quantities := []int{}
+	for rows.Next() {
+		var quantity int
+		lot, err := scanLot(rows, &quantity)
+		if err != nil {
+			return nil, nil, domain.ErrRepository
+		}
+		lots = append(lots, *lot)
+		quantities = append(quantities, quantity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, domain.ErrRepository
+	}
+	return lots, quantities, nil
+}
+
+// receiveTransferredLot adds a transferred lot to the lot with the same
+// number and expiry at the destination, or stores it as a new lot there.
+func receiveTransferredLot(tx *sql.Tx, lot *domain.Lot) error {
+	var expiresAt interface{}
+	if lot.ExpiresAt != nil {
+		expiresAt = formatTimestamp(*lot.ExpiresAt)
+	}
+	res, err := tx.Exec(`UPDATE lots SET quantity = quantity + ? WHERE id = (SELECT id FROM lots
+		WHERE product_id = ? AND location_id = ? AND lot_number = ? AND expires_at IS ? ORDER BY received_at LIMIT 1)`,
+		lot.Quantity, lot.ProductId, lot.LocationId, lot.LotNumber, expiresAt)
+	if err != nil {
+		return domain.ErrRepository
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected > 0 {
+		return nil
+	}
+	return insertLot(tx, lot)
+}
+
+func (repo *sqliteRepository) ListTransfers(status domain.TransferStatus) ([]domain.Transfer, error) {
+	query := "SELECT " + transferColumns + " FROM stock_transfers"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, string(status))
+	}
+	query += " ORDER BY created_at DESC, rowid DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, domain.ErrRepository
+	}
+	defer rows.Close()
+
+	transfers := []domain.Transfer{}
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, domain.ErrRepository
+		}
+		transfers = append(transfers, *transfer)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, domain.ErrRepository
+	}
+	return transfers, nil
+}
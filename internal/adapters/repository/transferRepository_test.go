@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestSqliteRepository_Transfers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSQLiteRepository(db)
+	store, _ := domain.NewLocation("STORE-1", "High Street", domain.LocationStore, time.Now())
+	repo.SaveLocation(store)
+	product, _ := domain.CreateNewProduct("Lamp", 10, 8)
+	repo.Save(product, nil, nil)
+	start := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	levels := func() (int, int, int) {
+		stock, _ := repo.ProductStock(product.Id)
+		main, _ := repo.FindStockLevel(product.Id, domain.DefaultLocationId)
+		atStore, _ := repo.FindStockLevel(product.Id, store.Id)
+		return main.Quantity, atStore.Quantity, stock.InTransit
+	}
+
+	tooMany, _ := domain.NewTransfer(product.Id, domain.DefaultLocationId, store.Id, 9, "manager-1", start)
+	if err := repo.CreateTransfer(tooMany); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Errorf("CreateTransfer() of more than the source holds error = %v, want ErrInsufficientStock", err)
+	}
+	unknown, _ := domain.NewTransfer("missing", domain.DefaultLocationId, store.Id, 1, "manager-1", start)
+	if err := repo.CreateTransfer(unknown); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("CreateTransfer() of an unknown product error = %v, want ErrProductNotFound", err)
+	}
+
+	received, _ := domain.NewTransfer(product.Id, domain.DefaultLocationId, store.Id, 5, "manager-1", start)
+	cancelled, _ := domain.NewTransfer(product.Id, domain.DefaultLocationId, store.Id, 2, "manager-1", start.Add(time.Minute))
+	for _, transfer := range []*domain.Transfer{received, cancelled} {
+		if err := repo.CreateTransfer(transfer); err != nil {
+			t.Fatalf("CreateTransfer() returned an unexpected error: %v", err)
+		}
+	}
+	if main, atStore, inTransit := levels(); main != 1 || atStore != 0 || inTransit != 7 {
+		t.Errorf("after sending: main %d, store %d, in transit %d, want 1, 0, 7", main, atStore, inTransit)
+	}
+	if stored, _ := repo.FindById(product.Id); stored.Quantity != 8 {
+		t.Errorf("product quantity = %d, want 8 while stock is in transit", stored.Quantity)
+	}
+
+	done, err := repo.ReceiveTransfer(received.Id, start.Add(time.Hour))
+	if err != nil || done.Status != domain.TransferReceived || done.CompletedAt == nil {
+		t.Fatalf("ReceiveTransfer() = %+v, %v", done, err)
+	}
+	if _, err := repo.CancelTransfer(cancelled.Id, start.Add(time.Hour)); err != nil {
+		t.Fatalf("CancelTransfer() returned an unexpected error: %v", err)
+	}
+	if main, atStore, inTransit := levels(); main != 3 || atStore != 5 || inTransit != 0 {
+		t.Errorf("after completing: main %d, store %d, in transit %d, want 3, 5, 0", main, atStore, inTransit)
+	}
+
+	if _, err := repo.ReceiveTransfer(cancelled.Id, start.Add(2*time.Hour)); !errors.Is(err, domain.ErrTransferNotInTransit) {
+		t.Errorf("ReceiveTransfer() of a cancelled transfer error = %v, want ErrTransferNotInTransit", err)
+	}
+	if _, err := repo.CancelTransfer("missing", start); !errors.Is(err, domain.ErrTransferNotFound) {
+		t.Errorf("CancelTransfer() error = %v, want ErrTransferNotFound", err)
+	}
+
+	tests := []struct {
+		name    string
+		status  domain.TransferStatus
+		wantIds []string
+	}{
+		{"all_newest_first", "", []string{cancelled.Id, received.Id}},
+		{"received", domain.TransferReceived, []string{received.Id}},
+		{"in_transit", domain.TransferInTransit, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfers, err := repo.ListTransfers(tt.status)
+			if err != nil {
+				t.Fatalf("ListTransfers() returned an unexpected error: %v", err)
+			}
+			if len(transfers) != len(tt.wantIds) {
+				t.Fatalf("ListTransfers() returned %d transfers, want %d", len(transfers), len(tt.wantIds))
+			}
+			for i, transfer := range transfers {
+				if transfer.Id != tt.wantIds[i] {
+					t.Errorf("ListTransfers()[%d] = %s, want %s", i, transfer.Id, tt.wantIds[i])
+				}
+			}
+		})
+	}
+}
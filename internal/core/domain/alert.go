@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AlertStatus string
+
+const (
+	AlertOpen         AlertStatus = "open"
+	AlertAcknowledged AlertStatus = "acknowledged"
+	AlertResolved     AlertStatus = "resolved"
+)
+
+type Alert struct {
+	Id             string
+	ProductId      string
+	LocationId     string
+	Quantity       int
+	ReorderPoint   int
+	Status         AlertStatus
+	TriggeredAt    time.Time
+	AcknowledgedAt *time.Time
+	AcknowledgedBy string
+	ResolvedAt     *time.Time
+}
+
+func NewLowStockAlert(product *Product, reorderPoint int) *Alert {
+	return &Alert{
+		Id:           uuid.New().String(),
+		ProductId:    product.Id,
+		Quantity:     product.Quantity,
+		ReorderPoint: reorderPoint,
+		Status:       AlertOpen,
+		TriggeredAt:  time.Now().UTC(),
+	}
+}
+
+// NewLocationLowStockAlert is raised for one location running low even when
+// the product has enough stock overall.
+func NewLocationLowStockAlert(level *StockLevel) *Alert {
+	return &Alert{
+		Id:           uuid.New().String(),
+		ProductId:    level.ProductId,
+		LocationId:   level.LocationId,
+		Quantity:     level.Quantity,
+		ReorderPoint: level.ReorderPoint,
+		Status:       AlertOpen,
+		TriggeredAt:  time.Now().UTC(),
+	}
+}
+
+func ParseAlertStatus(value string) (AlertStatus, error) {
+	switch status := AlertStatus(value); status {
+	case "", AlertOpen, AlertAcknowledged, AlertResolved:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: unknown alert status %q", ErrAlertInvalid, value)
+	}
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const apiKeyPrefixTag = "ik_"
+
+// APIKey lets a machine such as a POS terminal call the API without a login.
+// The key handed out is "<prefix>.<secret>"; the prefix finds the row and only
+// a hash of the secret is kept. An empty ProductIds allows every product.
+type APIKey struct {
+	Id          string
+	Name        string
+	Prefix      string
+	SecretHash  string
+	Permissions []Permission
+	ProductIds  []string
+	CreatedBy   string
+	CreatedAt   time.Time
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+// NewAPIKey returns the key together with the plaintext value for the caller,
+// which is not stored anywhere.
+func NewAPIKey(name string, permissions []Permission, productIds []string, createdBy string, now time.Time, expiresAt *time.Time) (*APIKey, string, error) {
+	key := &APIKey{
+		Id:          uuid.New().String(),
+		Name:        strings.TrimSpace(name),
+		Permissions: permissions,
+		ProductIds:  uniqueNonEmpty(productIds),
+		CreatedBy:   createdBy,
+		CreatedAt:   now.UTC(),
+	}
+	if expiresAt != nil {
+		expires := expiresAt.UTC()
+		key.ExpiresAt = &expires
+	}
+	if err := key.validate(now); err != nil {
+		return nil, "", err
+	}
+
+	prefix := make([]byte, 6)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	key.Prefix = apiKeyPrefixTag + hex.EncodeToString(prefix)
+	rawSecret := base64.RawURLEncoding.EncodeToString(secret)
+	key.SecretHash = hashAPIKeySecret(rawSecret)
+	return key, key.Prefix + "." + rawSecret, nil
+}
+
+func (k *APIKey) validate(now time.Time) error {
+	if k.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrAPIKeyInvalid)
+	}
+	if len(k.Permissions) == 0 {
+		return fmt.Errorf("%w: at least one permission is required", ErrAPIKeyInvalid)
+	}
+	for _, permission := range k.Permissions {
+		if _, err := ParsePermission(string(permission)); err != nil {
+			return fmt.Errorf("%w: %v", ErrAPIKeyInvalid, err)
+		}
+		if permission == PermManagersManage {
+			return fmt.Errorf("%w: api keys cannot manage managers", ErrAPIKeyInvalid)
+		}
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expiry must be in the future", ErrAPIKeyInvalid)
+	}
+	return nil
+}
+
+// SplitAPIKey separates a presented key into its lookup prefix and secret.
+func SplitAPIKey(raw string) (string, string, bool) {
+	prefix, secret, ok := strings.Cut(strings.TrimSpace(raw), ".")
+	if !ok || !strings.HasPrefix(prefix, apiKeyPrefixTag) || secret == "" {
+		return "", "", false
+	}
+	return prefix, secret, true
+}
+
+func (k *APIKey) CheckSecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(k.SecretHash), []byte(hashAPIKeySecret(secret))) == 1
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+func (k *APIKey) Principal() Principal {
+	return Principal{
+		APIKeyId:    k.Id,
+		Permissions: k.Permissions,
+		ProductIds:  k.ProductIds,
+	}
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func uniqueNonEmpty(values []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
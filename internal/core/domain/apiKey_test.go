@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPIKey_CheckSecretAndIsActive(t *testing.T) {
+	now := time.Now()
+	expiry := now.Add(time.Hour)
+	key, raw, err := NewAPIKey("scanner", []Permission{PermStockSell}, nil, "admin-1", now, &expiry)
+	if err != nil {
+		t.Fatalf("NewAPIKey() error = %v", err)
+	}
+
+	prefix, secret, ok := SplitAPIKey(raw)
+	if !ok || prefix != key.Prefix {
+		t.Fatalf("SplitAPIKey(%q) = %q, %v", raw, prefix, ok)
+	}
+	if !key.CheckSecret(secret) || key.CheckSecret(secret+"x") {
+		t.Errorf("CheckSecret() accepted the wrong secret or rejected the right one")
+	}
+	if key.SecretHash == secret {
+		t.Errorf("NewAPIKey() stored the plaintext secret")
+	}
+
+	if !key.IsActive(now) || key.IsActive(expiry) {
+		t.Errorf("IsActive() does not honour expiry %v", expiry)
+	}
+	key.RevokedAt = &now
+	if key.IsActive(now) {
+		t.Errorf("IsActive() = true for a revoked key")
+	}
+}
+
+func TestSplitAPIKey(t *testing.T) {
+	for _, raw := range []string{"", "ik_abc", "ik_abc.", "xx_abc.secret", "Bearer token"} {
+		if _, _, ok := SplitAPIKey(raw); ok {
+			t.Errorf("SplitAPIKey(%q) ok = true, want false", raw)
+		}
+	}
+}
+
+func TestPrincipal_APIKeyScope(t *testing.T) {
+	scoped := Principal{APIKeyId: "key-1", Permissions: []Permission{PermStockSell}, ProductIds: []string{"p1"}}
+	manager := Principal{ManagerId: "manager-1", Role: RoleClerk}
+
+	if !scoped.Can(PermStockSell) || scoped.Can(PermProductsRead) {
+		t.Errorf("scoped key permissions = %v", scoped.Permissions)
+	}
+	if !scoped.CanAccessProduct("p1") || scoped.CanAccessProduct("p2") {
+		t.Errorf("scoped key products = %v", scoped.ProductIds)
+	}
+	if !manager.CanAccessProduct("p2") || !manager.Can(PermProductsRead) {
+		t.Errorf("manager principal is restricted: %+v", manager)
+	}
+	if scoped.Actor() != "api-key:key-1" || manager.Actor() != "manager-1" {
+		t.Errorf("Actor() = %q, %q", scoped.Actor(), manager.Actor())
+	}
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditProductCreated    AuditAction = "product.created"
+	AuditPriceChanged      AuditAction = "product.price_changed"
+	AuditCategoryChanged   AuditAction = "product.category_changed"
+	AuditDetailsChanged    AuditAction = "product.details_changed"
+	AuditThresholdsChanged AuditAction = "product.thresholds_changed"
+	AuditProductDeleted    AuditAction = "product.deleted"
+	AuditProductRestored   AuditAction = "product.restored"
+	AuditProductPurged     AuditAction = "product.purged"
+	AuditStockSold         AuditAction = "stock.sold"
+	AuditStockRestocked    AuditAction = "stock.restocked"
+	AuditStockAdjusted     AuditAction = "stock.adjusted"
+	AuditStockWrittenOff   AuditAction = "stock.written_off"
+
+	DefaultAuditPageSize = 100
+	MaxAuditPageSize     = 1000
+)
+
+var auditActions = []AuditAction{AuditProductCreated, AuditPriceChanged, AuditCategoryChanged, AuditDetailsChanged, AuditThresholdsChanged,
+	AuditProductDeleted, AuditProductRestored, AuditProductPurged, AuditStockSold, AuditStockRestocked, AuditStockAdjusted, AuditStockWrittenOff}
+
+// AuditEntry records who changed a product and how. Before is nil for a
+// created product and After is nil for a deleted one.
+type AuditEntry struct {
+	Id        string
+	Action    AuditAction
+	ProductId string
+	Actor     string
+	ClientIP  string
+	RequestId string
+	Before    *Product
+	After     *Product
+	CreatedAt time.Time
+}
+
+// NewAuditEntry takes the actor and request details from ctx.
+func NewAuditEntry(ctx context.Context, action AuditAction, productId string, before, after *Product) *AuditEntry {
+	meta, _ := RequestMetaFromContext(ctx)
+	return &AuditEntry{
+		Id:        uuid.New().String(),
+		Action:    action,
+		ProductId: productId,
+		Actor:     ActorFromContext(ctx),
+		ClientIP:  meta.ClientIP,
+		RequestId: meta.Id,
+		Before:    before,
+		After:     after,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// AuditQuery filters the audit log. A zero Limit means no limit, which only
+// exports use.
+type AuditQuery struct {
+	Actor     string
+	Action    AuditAction
+	ProductId string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+func (query *AuditQuery) Validate() error {
+	if query.Action != "" && !isAuditAction(query.Action) {
+		return fmt.Errorf("%w: unknown audit action %q", ErrInvalidQuery, query.Action)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		return fmt.Errorf("%w: from is after to", ErrInvalidQuery)
+	}
+	if query.Limit < 0 || query.Limit > MaxAuditPageSize {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxAuditPageSize)
+	}
+	return nil
+}
+
+func isAuditAction(action AuditAction) bool {
+	for _, known := range auditActions {
+		if known == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxBinCodeLength = 32
+
+// Bin is a storage slot inside a location. Pickers walk aisles in ascending
+// order and, within an aisle, shelves in ascending order.
+type Bin struct {
+	Id         string
+	LocationId string
+	Code       string
+	Aisle      int
+	Shelf      int
+	CreatedAt  time.Time
+}
+
+// BinStock is how many units of a product have been put away in a bin.
+type BinStock struct {
+	BinId     string
+	ProductId string
+	Quantity  int
+}
+
+// BinSlot is a product's stock in a bin together with where the bin is. In a
+// pick list Quantity is how many units to take from the bin.
+type BinSlot struct {
+	BinId      string
+	LocationId string
+	BinCode    string
+	Aisle      int
+	Shelf      int
+	ProductId  string
+	Quantity   int
+}
+
+type PickRequest struct {
+	ProductId string
+	Quantity  int
+}
+
+// PickShortage is the part of a request that no bin at the location can fill.
+type PickShortage struct {
+	ProductId string
+	Requested int
+	Missing   int
+}
+
+type PickList struct {
+	LocationId string
+	Lines      []BinSlot
+	Shortages  []PickShortage
+}
+
+func NewBin(locationId, code string, aisle, shelf int, now time.Time) (*Bin, error) {
+	bin := &Bin{
+		Id:         uuid.New().String(),
+		LocationId: locationId,
+		Code:       strings.ToUpper(strings.TrimSpace(code)),
+		Aisle:      aisle,
+		Shelf:      shelf,
+		CreatedAt:  now,
+	}
+	if bin.Code == "" || len(bin.Code) > maxBinCodeLength {
+		return nil, fmt.Errorf("%w: code must be between 1 and %d characters", ErrBinInvalid, maxBinCodeLength)
+	}
+	if aisle < 0 || shelf < 0 {
+		return nil, fmt.Errorf("%w: aisle and shelf cannot be negative", ErrBinInvalid)
+	}
+	return bin, nil
+}
+
+func NewBinStock(binId, productId string, quantity int) (*BinStock, error) {
+	if productId == "" {
+		return nil, fmt.Errorf("%w: product is required", ErrBinInvalid)
+	}
+	if quantity < 0 {
+		return nil, fmt.Errorf("%w: quantity cannot be negative", ErrBinInvalid)
+	}
+	return &BinStock{BinId: binId, ProductId: productId, Quantity: quantity}, nil
+}
+
+// NormalizePickRequests merges repeated products and rejects empty requests,
+// keeping the order products were first asked for.
+func NormalizePickRequests(items []PickRequest) ([]PickRequest, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: at least one item is required", ErrPickListInvalid)
+	}
+	merged := []PickRequest{}
+	index := map[string]int{}
+	for _, item := range items {
+		if item.ProductId == "" || item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: every item needs a product and a quantity greater than zero", ErrPickListInvalid)
+		}
+		if i, ok := index[item.ProductId]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductId] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged, nil
+}
+
+// BuildPickList fills each request from the bins in walking order, never
+// taking more than the location has on hand, and returns the lines sorted
+// into a single walk through the location. slots must already be in walking
+// order.
+func BuildPickList(locationId string, items []PickRequest, slots []BinSlot, onHand map[string]int) *PickList {
+	list := &PickList{LocationId: locationId, Lines: []BinSlot{}, Shortages: []PickShortage{}}
+	for _, item := range items {
+		remaining := item.Quantity
+		if onHand[item.ProductId] < remaining {
+			remaining = onHand[item.ProductId]
+		}
+		picked := 0
+		for _, slot := range slots {
+			if remaining == 0 {
+				break
+			}
+			if slot.ProductId != item.ProductId || slot.Quantity <= 0 {
+				continue
+			}
+			take := slot.Quantity
+			if take > remaining {
+				take = remaining
+			}
+			line := slot
+			line.Quantity = take
+			list.Lines = append(list.Lines, line)
+			remaining -= take
+			picked += take
+		}
+		if picked < item.Quantity {
+			list.Shortages = append(list.Shortages, PickShortage{ProductId: item.ProductId, Requested: item.Quantity, Missing: item.Quantity - picked})
+		}
+	}
+
+	sort.SliceStable(list.Lines, func(i, j int) bool {
+		a, b := list.Lines[i], list.Lines[j]
+		if a.Aisle != b.Aisle {
+			return a.Aisle < b.Aisle
+		}
+		if a.Shelf != b.Shelf {
+			return a.Shelf < b.Shelf
+		}
+		return a.BinCode < b.BinCode
+	})
+	return list
+}
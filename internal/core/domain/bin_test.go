@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewBin(t *testing.T) {
+	bin, err := NewBin("main", " a1-03 ", 1, 3, time.Now())
+	if err != nil || bin.Code != "A1-03" || bin.Aisle != 1 || bin.Shelf != 3 {
+		t.Errorf("NewBin() = %+v, %v", bin, err)
+	}
+	if _, err := NewBin("main", "", 1, 3, time.Now()); !errors.Is(err, ErrBinInvalid) {
+		t.Errorf("NewBin() without a code error = %v, want ErrBinInvalid", err)
+	}
+	if _, err := NewBin("main", "A1", -1, 0, time.Now()); !errors.Is(err, ErrBinInvalid) {
+		t.Errorf("NewBin() with a negative aisle error = %v, want ErrBinInvalid", err)
+	}
+}
+
+func TestNormalizePickRequests(t *testing.T) {
+	items, err := NormalizePickRequests([]PickRequest{{"p1", 2}, {"p2", 1}, {"p1", 3}})
+	if err != nil || len(items) != 2 || items[0] != (PickRequest{"p1", 5}) || items[1] != (PickRequest{"p2", 1}) {
+		t.Errorf("NormalizePickRequests() = %+v, %v", items, err)
+	}
+	for _, bad := range [][]PickRequest{nil, {{"p1", 0}}, {{"", 1}}} {
+		if _, err := NormalizePickRequests(bad); !errors.Is(err, ErrPickListInvalid) {
+			t.Errorf("NormalizePickRequests(%+v) error = %v, want ErrPickListInvalid", bad, err)
+		}
+	}
+}
+
+func TestBuildPickList(t *testing.T) {
+	slots := []BinSlot{
+		{BinId: "b1", BinCode: "A1-01", Aisle: 1, Shelf: 1, ProductId: "p2", Quantity: 4},
+		{BinId: "b2", BinCode: "A1-02", Aisle: 1, Shelf: 2, ProductId: "p1", Quantity: 3},
+		{BinId: "b3", BinCode: "A2-01", Aisle: 2, Shelf: 1, ProductId: "p1", Quantity: 10},
+		{BinId: "b4", BinCode: "A3-01", Aisle: 3, Shelf: 1, ProductId: "p3", Quantity: 5},
+	}
+
+	tests := []struct {
+		name          string
+		items         []PickRequest
+		onHand        map[string]int
+		wantBins      []string
+		wantQuantity  []int
+		wantShortages map[string]int
+	}{
+		{"walks_aisles_in_order", []PickRequest{{"p3", 1}, {"p1", 2}, {"p2", 1}}, map[string]int{"p1": 20, "p2": 20, "p3": 20},
+			[]string{"b1", "b2", "b4"}, []int{1, 2, 1}, map[string]int{}},
+		{"spills_into_next_bin", []PickRequest{{"p1", 5}}, map[string]int{"p1": 20},
+			[]string{"b2", "b3"}, []int{3, 2}, map[string]int{}},
+		{"short_in_bins", []PickRequest{{"p3", 8}}, map[string]int{"p3": 20},
+			[]string{"b4"}, []int{5}, map[string]int{"p3": 3}},
+		{"capped_by_stock_on_hand", []PickRequest{{"p1", 5}}, map[string]int{"p1": 1},
+			[]string{"b2"}, []int{1}, map[string]int{"p1": 4}},
+		{"product_in_no_bin", []PickRequest{{"p9", 2}}, map[string]int{"p9": 2},
+			nil, nil, map[string]int{"p9": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := BuildPickList("main", tt.items, slots, tt.onHand)
+			if len(list.Lines) != len(tt.wantBins) {
+				t.Fatalf("BuildPickList() lines = %+v, want bins %v", list.Lines, tt.wantBins)
+			}
+			for i, line := range list.Lines {
+				if line.BinId != tt.wantBins[i] || line.Quantity != tt.wantQuantity[i] {
+					t.Errorf("line %d = %s x%d, want %s x%d", i, line.BinId, line.Quantity, tt.wantBins[i], tt.wantQuantity[i])
+				}
+			}
+			if len(list.Shortages) != len(tt.wantShortages) {
+				t.Fatalf("BuildPickList() shortages = %+v, want %v", list.Shortages, tt.wantShortages)
+			}
+			for _, shortage := range list.Shortages {
+				if shortage.Missing != tt.wantShortages[shortage.ProductId] {
+					t.Errorf("shortage of %s = %d, want %d", shortage.ProductId, shortage.Missing, tt.wantShortages[shortage.ProductId])
+				}
+			}
+		})
+	}
+}
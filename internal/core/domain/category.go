@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxCategoryNameLength = 100
+
+// Category groups products. A category with an empty ParentId is at the top
+// of the tree.
+type Category struct {
+	Id        string
+	Name      string
+	ParentId  string
+	CreatedAt time.Time
+}
+
+// CategoryValue is the stock value of a category. Value includes every
+// subcategory while OwnValue only counts products assigned directly.
+type CategoryValue struct {
+	CategoryId string
+	Name       string
+	ParentId   string
+	Value      float64
+	OwnValue   float64
+}
+
+type InventoryValueReport struct {
+	Total         float64
+	Uncategorized float64
+	Categories    []CategoryValue
+}
+
+func NewCategory(name, parentId string, now time.Time) (*Category, error) {
+	category := &Category{
+		Id:        uuid.New().String(),
+		Name:      strings.TrimSpace(name),
+		ParentId:  strings.TrimSpace(parentId),
+		CreatedAt: now,
+	}
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (category *Category) Validate() error {
+	if category.Name == "" || len(category.Name) > maxCategoryNameLength {
+		return fmt.Errorf("%w: name must be between 1 and %d characters", ErrCategoryInvalid, maxCategoryNameLength)
+	}
+	if category.ParentId == category.Id {
+		return fmt.Errorf("%w: a category cannot be its own parent", ErrCategoryInvalid)
+	}
+	return nil
+}
+
+func (category *Category) Update(name, parentId string) error {
+	updated := *category
+	updated.Name = strings.TrimSpace(name)
+	updated.ParentId = strings.TrimSpace(parentId)
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+	*category = updated
+	return nil
+}
+
+// CheckCategoryParent rejects a parent that does not exist among categories
+// or that sits below the category with id, which would make a cycle.
+func CheckCategoryParent(categories []Category, id, parentId string) error {
+	if parentId == "" {
+		return nil
+	}
+	parents := make(map[string]string, len(categories))
+	for _, category := range categories {
+		parents[category.Id] = category.ParentId
+	}
+	if _, ok := parents[parentId]; !ok {
+		return fmt.Errorf("%w: parent category %s does not exist", ErrCategoryInvalid, parentId)
+	}
+	for current, steps := parentId, 0; current != "" && steps <= len(categories); current, steps = parents[current], steps+1 {
+		if current == id {
+			return fmt.Errorf("%w: a category cannot be moved below one of its subcategories", ErrCategoryInvalid)
+		}
+	}
+	return nil
+}
+
+// CategoryPath returns id followed by its ancestors up to the top of the
+// tree. A category missing from categories ends the path.
+func CategoryPath(categories []Category, id string) []string {
+	parents := make(map[string]string, len(categories))
+	for _, category := range categories {
+		parents[category.Id] = category.ParentId
+	}
+	path := []string{}
+	for current, steps := id, 0; current != "" && steps <= len(categories); steps++ {
+		path = append(path, current)
+		parent, ok := parents[current]
+		if !ok {
+			break
+		}
+		current = parent
+	}
+	return path
+}
+
+// NewInventoryValueReport rolls the value held directly in each category up
+// through its ancestors. values is keyed by category id, with "" for products
+// that have no category.
+func NewInventoryValueReport(categories []Category, values map[string]float64) *InventoryValueReport {
+	report := &InventoryValueReport{Categories: make([]CategoryValue, 0, len(categories))}
+	index := make(map[string]int, len(categories))
+	for i, category := range categories {
+		index[category.Id] = i
+		report.Categories = append(report.Categories, CategoryValue{
+			CategoryId: category.Id,
+			Name:       category.Name,
+			ParentId:   category.ParentId,
+		})
+	}
+
+	for categoryId, value := range values {
+		report.Total += value
+		i, ok := index[categoryId]
+		if !ok {
+			report.Uncategorized += value
+			continue
+		}
+		report.Categories[i].OwnValue += value
+		for steps := 0; ok && steps <= len(categories); steps++ {
+			report.Categories[i].Value += value
+			i, ok = index[report.Categories[i].ParentId]
+		}
+	}
+	return report
+}
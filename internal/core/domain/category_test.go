@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewCategory(t *testing.T) {
+	category, err := NewCategory("  Cables ", "c-1", time.Now())
+	if err != nil || category.Name != "Cables" || category.ParentId != "c-1" || category.Id == "" {
+		t.Errorf("NewCategory() = %+v, %v", category, err)
+	}
+	if _, err := NewCategory(" ", "", time.Now()); !errors.Is(err, ErrCategoryInvalid) {
+		t.Errorf("NewCategory() with an empty name error = %v, want ErrCategoryInvalid", err)
+	}
+	if err := category.Update("Leads", category.Id); !errors.Is(err, ErrCategoryInvalid) || category.Name != "Cables" {
+		t.Errorf("Update() to its own parent error = %v, category = %+v", err, category)
+	}
+}
+
+func TestCheckCategoryParent(t *testing.T) {
+	categories := []Category{
+		{Id: "electronics"},
+		{Id: "cables", ParentId: "electronics"},
+		{Id: "usb", ParentId: "cables"},
+		{Id: "grocery"},
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		parentId  string
+		expectErr bool
+	}{
+		{"top_level", "cables", "", false},
+		{"sibling_tree", "cables", "grocery", false},
+		{"new_category", "new", "usb", false},
+		{"unknown_parent", "cables", "missing", true},
+		{"child_as_parent", "electronics", "cables", true},
+		{"grandchild_as_parent", "electronics", "usb", true},
+		{"itself", "cables", "cables", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCategoryParent(categories, tt.id, tt.parentId)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("CheckCategoryParent(%q, %q) error = %v, expectErr %v", tt.id, tt.parentId, err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestNewInventoryValueReport(t *testing.T) {
+	categories := []Category{
+		{Id: "cables", Name: "Cables", ParentId: "electronics"},
+		{Id: "electronics", Name: "Electronics"},
+		{Id: "usb", Name: "USB", ParentId: "cables"},
+	}
+	report := NewInventoryValueReport(categories, map[string]float64{"usb": 10, "cables": 5, "electronics": 100, "": 7, "gone": 3})
+
+	if report.Total != 125 || report.Uncategorized != 10 {
+		t.Errorf("report total = %v uncategorized = %v, want 125 and 10", report.Total, report.Uncategorized)
+	}
+	want := map[string][2]float64{"cables": {15, 5}, "electronics": {115, 100}, "usb": {10, 10}}
+	for _, value := range report.Categories {
+		if got := [2]float64{value.Value, value.OwnValue}; got != want[value.CategoryId] {
+			t.Errorf("category %s value = %v, want %v", value.CategoryId, got, want[value.CategoryId])
+		}
+	}
+}
+
+func TestCategoryPath(t *testing.T) {
+	categories := []Category{{Id: "food"}, {Id: "dairy", ParentId: "food"}, {Id: "cheese", ParentId: "dairy"}}
+	tests := []struct {
+		name string
+		id   string
+		want []string
+	}{
+		{"top_level", "food", []string{"food"}},
+		{"nested", "cheese", []string{"cheese", "dairy", "food"}},
+		{"unknown", "tools", []string{"tools"}},
+		{"none", "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CategoryPath(categories, tt.id); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CategoryPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
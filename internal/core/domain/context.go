@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type contextKey string
+
+const (
+	principalKey   contextKey = "principal"
+	requestMetaKey contextKey = "request_meta"
+)
+
+// Principal is whoever made a request: a manager holding a token, or an API
+// key. An API key carries its own permissions and product scope instead of a
+// role.
+type Principal struct {
+	ManagerId   string
+	Role        Role
+	TokenId     string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+	APIKeyId    string
+	Permissions []Permission
+	ProductIds  []string
+}
+
+func (principal Principal) Can(permission Permission) bool {
+	if principal.APIKeyId == "" {
+		return principal.Role.Can(permission)
+	}
+	for _, granted := range principal.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessProduct reports whether the principal is scoped to the product.
+// Managers and unscoped keys can reach every product.
+func (principal Principal) CanAccessProduct(productId string) bool {
+	if len(principal.ProductIds) == 0 {
+		return true
+	}
+	for _, id := range principal.ProductIds {
+		if id == productId {
+			return true
+		}
+	}
+	return false
+}
+
+// Actor names the principal in records such as stock movements.
+func (principal Principal) Actor() string {
+	if principal.APIKeyId != "" {
+		return "api-key:" + principal.APIKeyId
+	}
+	return principal.ManagerId
+}
+
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
+
+// RequestMeta identifies the HTTP request a change came from.
+type RequestMeta struct {
+	Id       string
+	ClientIP string
+}
+
+func ContextWithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey, meta)
+}
+
+func RequestMetaFromContext(ctx context.Context) (RequestMeta, bool) {
+	meta, ok := ctx.Value(requestMetaKey).(RequestMeta)
+	return meta, ok
+}
+
+func ContextWithManagerId(ctx context.Context, managerId string) context.Context {
+	return ContextWithPrincipal(ctx, Principal{ManagerId: managerId})
+}
+
+func ManagerIdFromContext(ctx context.Context) string {
+	principal, _ := PrincipalFromContext(ctx)
+	return principal.ManagerId
+}
+
+func ActorFromContext(ctx context.Context) string {
+	principal, _ := PrincipalFromContext(ctx)
+	return principal.Actor()
+}
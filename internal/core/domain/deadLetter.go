@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeadLetter struct {
+	Id        string
+	Channel   string
+	Target    string
+	Event     string
+	Payload   string
+	Error     string
+	Attempts  int
+	CreatedAt time.Time
+}
+
+func NewDeadLetter(channel, target, event, payload, reason string, attempts int) *DeadLetter {
+	return &DeadLetter{
+		Id:        uuid.New().String(),
+		Channel:   channel,
+		Target:    target,
+		Event:     event,
+		Payload:   payload,
+		Error:     reason,
+		Attempts:  attempts,
+		CreatedAt: time.Now().UTC(),
+	}
+}
@@ -3,12 +3,51 @@ package domain
 import "errors"
 
 var (
-	ErrProductNotFound    = errors.New("product not found")
-	ErrProductInvalid     = errors.New("product data is invalid")
-	ErrInsufficientStock  = errors.New("insufficient stock")
-	ErrRepository         = errors.New("repository error")
-	ErrInvalidCredentials = errors.New("invalid email or password")
-	ErrUnauthorized       = errors.New("unauthorized")
-	ErrTokenInvalid       = errors.New("token is invalid")
-	ErrTokenGeneration    = errors.New("something went wrong while generating token")
+	ErrProductNotFound        = errors.New("product not found")
+	ErrProductInvalid         = errors.New("product data is invalid")
+	ErrProductNotDeleted      = errors.New("product is not deleted")
+	ErrDuplicateSKU           = errors.New("a product with this sku already exists")
+	ErrDuplicateBarcode       = errors.New("a product with this barcode already exists")
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrCategoryInvalid        = errors.New("category data is invalid")
+	ErrCategoryExists         = errors.New("a category with this name already exists under the same parent")
+	ErrCategoryInUse          = errors.New("category still has subcategories or products")
+	ErrLocationNotFound       = errors.New("location not found")
+	ErrLocationInvalid        = errors.New("location data is invalid")
+	ErrLocationExists         = errors.New("a location with this code already exists")
+	ErrTransferNotFound       = errors.New("transfer not found")
+	ErrTransferInvalid        = errors.New("transfer request is invalid")
+	ErrTransferNotInTransit   = errors.New("transfer is no longer in transit")
+	ErrBinNotFound            = errors.New("bin not found")
+	ErrBinInvalid             = errors.New("bin data is invalid")
+	ErrBinExists              = errors.New("a bin with this code already exists at the location")
+	ErrPickListInvalid        = errors.New("pick list request is invalid")
+	ErrLotNotFound            = errors.New("lot not found")
+	ErrLotInvalid             = errors.New("lot data is invalid")
+	ErrInsufficientStock      = errors.New("insufficient stock")
+	ErrInvalidQuery           = errors.New("invalid query")
+	ErrConflict               = errors.New("product was modified by another request")
+	ErrAlertNotFound          = errors.New("alert not found")
+	ErrAlertInvalid           = errors.New("alert request is invalid")
+	ErrRepository             = errors.New("repository error")
+	ErrInvalidCredentials     = errors.New("invalid email or password")
+	ErrTooManyAttempts        = errors.New("too many login attempts, try again later")
+	ErrManagerInvalid         = errors.New("manager data is invalid")
+	ErrManagerNotFound        = errors.New("manager not found")
+	ErrManagerExists          = errors.New("a manager with this email already exists")
+	ErrAccountDisabled        = errors.New("account is disabled")
+	ErrPasswordChangeRequired = errors.New("password change required")
+	ErrMFAChallengeInvalid    = errors.New("two-factor challenge is invalid or expired")
+	ErrMFACodeInvalid         = errors.New("invalid verification code")
+	ErrMFANotEnrolled         = errors.New("two-factor authentication has not been set up")
+	ErrMFAAlreadyEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrAPIKeyInvalid          = errors.New("api key data is invalid")
+	ErrAPIKeyNotFound         = errors.New("api key not found")
+	ErrForbidden              = errors.New("forbidden")
+	ErrUnauthorized           = errors.New("unauthorized")
+	ErrTokenInvalid           = errors.New("token is invalid")
+	ErrTokenRevoked           = errors.New("token has been revoked")
+	ErrRefreshTokenInvalid    = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused     = errors.New("refresh token was already used, all sessions from that login have been revoked")
+	ErrTokenGeneration        = errors.New("something went wrong while generating token")
 )
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LocationKind string
+
+const (
+	LocationWarehouse LocationKind = "warehouse"
+	LocationStore     LocationKind = "store"
+
+	// DefaultLocationId is the warehouse every product's stock lived in before
+	// stock was tracked per location. Stock changes that name no location
+	// apply here.
+	DefaultLocationId = "main"
+
+	maxLocationCodeLength = 32
+)
+
+type Location struct {
+	Id        string
+	Code      string
+	Name      string
+	Kind      LocationKind
+	CreatedAt time.Time
+}
+
+// StockLevel is how much of a product sits at one location. A ReorderPoint of
+// zero means the location is only covered by the product's aggregate check.
+type StockLevel struct {
+	ProductId    string
+	LocationId   string
+	Quantity     int
+	ReorderPoint int
+}
+
+// ProductStock breaks a product's quantity down by location. Stock that is
+// being transferred counts towards the product but towards no location.
+type ProductStock struct {
+	ProductId string
+	Levels    []StockLevel
+	InTransit int
+}
+
+func NewLocation(code, name string, kind LocationKind, now time.Time) (*Location, error) {
+	location := &Location{
+		Id:        uuid.New().String(),
+		Code:      strings.ToUpper(strings.TrimSpace(code)),
+		Name:      strings.TrimSpace(name),
+		Kind:      kind,
+		CreatedAt: now,
+	}
+	if location.Kind == "" {
+		location.Kind = LocationWarehouse
+	}
+	if err := location.Validate(); err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+func (location *Location) Validate() error {
+	if location.Code == "" || len(location.Code) > maxLocationCodeLength {
+		return fmt.Errorf("%w: code must be between 1 and %d characters", ErrLocationInvalid, maxLocationCodeLength)
+	}
+	if location.Name == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrLocationInvalid)
+	}
+	if location.Kind != LocationWarehouse && location.Kind != LocationStore {
+		return fmt.Errorf("%w: unknown location kind %q", ErrLocationInvalid, location.Kind)
+	}
+	return nil
+}
+
+func (level *StockLevel) IsLowOnStock() bool {
+	return level.ReorderPoint > 0 && level.Quantity < level.ReorderPoint
+}
+
+func (level *StockLevel) SetReorderPoint(reorderPoint int) error {
+	if reorderPoint < 0 {
+		return fmt.Errorf("%w: reorder point cannot be negative", ErrLocationInvalid)
+	}
+	level.ReorderPoint = reorderPoint
+	return nil
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		locName  string
+		kind     LocationKind
+		wantCode string
+		wantKind LocationKind
+		wantErr  error
+	}{
+		{"defaults_to_warehouse", " north ", "North", "", "NORTH", LocationWarehouse, nil},
+		{"store", "store-1", "High Street", LocationStore, "STORE-1", LocationStore, nil},
+		{"missing_code", " ", "North", "", "", "", ErrLocationInvalid},
+		{"missing_name", "north", "", "", "", "", ErrLocationInvalid},
+		{"unknown_kind", "north", "North", "depot", "", "", ErrLocationInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := NewLocation(tt.code, tt.locName, tt.kind, time.Now())
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("NewLocation() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (location.Code != tt.wantCode || location.Kind != tt.wantKind) {
+				t.Errorf("NewLocation() = %+v, want code %s kind %s", location, tt.wantCode, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestStockLevel_IsLowOnStock(t *testing.T) {
+	tests := []struct {
+		name  string
+		level StockLevel
+		want  bool
+	}{
+		{"no_reorder_point", StockLevel{Quantity: 0}, false},
+		{"below", StockLevel{Quantity: 2, ReorderPoint: 5}, true},
+		{"at_reorder_point", StockLevel{Quantity: 5, ReorderPoint: 5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.level.IsLowOnStock(); got != tt.want {
+				t.Errorf("IsLowOnStock() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTransfer(t *testing.T) {
+	transfer, err := NewTransfer("p-1", "main", "store-1", 3, "manager-1", time.Now())
+	if err != nil || transfer.Status != TransferInTransit || transfer.CompletedAt != nil {
+		t.Errorf("NewTransfer() = %+v, %v", transfer, err)
+	}
+	if _, err := NewTransfer("p-1", "main", "main", 3, "manager-1", time.Now()); !errors.Is(err, ErrTransferInvalid) {
+		t.Errorf("NewTransfer() to the same location error = %v, want ErrTransferInvalid", err)
+	}
+	if _, err := NewTransfer("p-1", "main", "store-1", 0, "manager-1", time.Now()); !errors.Is(err, ErrTransferInvalid) {
+		t.Errorf("NewTransfer() of nothing error = %v, want ErrTransferInvalid", err)
+	}
+	if _, err := ParseTransferStatus("lost"); !errors.Is(err, ErrTransferInvalid) {
+		t.Errorf("ParseTransferStatus() error = %v, want ErrTransferInvalid", err)
+	}
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LoginOutcome string
+
+const (
+	LoginSucceeded LoginOutcome = "success"
+	LoginFailed    LoginOutcome = "failure"
+	LoginThrottled LoginOutcome = "throttled"
+	// LoginChallenged is a right password still waiting for the second factor.
+	LoginChallenged LoginOutcome = "mfa_required"
+)
+
+// LoginAttempt is one row of the auth audit trail. Throttled attempts are
+// recorded but do not count as failures, so an attacker hammering a locked
+// account cannot keep extending the lockout.
+type LoginAttempt struct {
+	Id         string
+	Email      string
+	IP         string
+	Outcome    LoginOutcome
+	Reason     string
+	OccurredAt time.Time
+}
+
+func NewLoginAttempt(email, ip string, outcome LoginOutcome, reason string, at time.Time) *LoginAttempt {
+	return &LoginAttempt{
+		Id:         uuid.New().String(),
+		Email:      email,
+		IP:         ip,
+		Outcome:    outcome,
+		Reason:     reason,
+		OccurredAt: at.UTC(),
+	}
+}
+
+// FailureStats summarises failed attempts in a window. For an email the
+// window also ends at its last successful login.
+type FailureStats struct {
+	Count int
+	First time.Time
+	Last  time.Time
+}
+
+type LoginPolicy struct {
+	MaxFailures      int
+	LockoutDuration  time.Duration
+	Window           time.Duration
+	MaxFailuresPerIP int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+}
+
+// EmailLookback is how far back failures for an email have to be counted so
+// that a lockout lasts its full duration.
+func (p LoginPolicy) EmailLookback() time.Duration {
+	if p.LockoutDuration > p.Window {
+		return p.LockoutDuration
+	}
+	return p.Window
+}
+
+// RetryAfter returns how long the next attempt has to wait. Each failure for an
+// email doubles the delay up to MaxDelay; MaxFailures locks the email for
+// LockoutDuration and MaxFailuresPerIP blocks the address until its oldest
+// failure leaves the window.
+func (p LoginPolicy) RetryAfter(email, ip FailureStats, now time.Time) time.Duration {
+	var until time.Time
+	switch {
+	case p.MaxFailures > 0 && email.Count >= p.MaxFailures:
+		until = email.Last.Add(p.LockoutDuration)
+	case email.Count > 0:
+		until = email.Last.Add(p.delay(email.Count))
+	}
+	if p.MaxFailuresPerIP > 0 && ip.Count >= p.MaxFailuresPerIP {
+		if ipUntil := ip.First.Add(p.Window); ipUntil.After(until) {
+			until = ipUntil
+		}
+	}
+	if until.After(now) {
+		return until.Sub(now)
+	}
+	return 0
+}
+
+func (p LoginPolicy) delay(failures int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// LoginThrottledError is returned instead of checking credentials while an
+// email or address has to wait.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyAttempts
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoginPolicy_RetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := LoginPolicy{
+		MaxFailures:      5,
+		LockoutDuration:  15 * time.Minute,
+		Window:           10 * time.Minute,
+		MaxFailuresPerIP: 20,
+		BaseDelay:        time.Second,
+		MaxDelay:         8 * time.Second,
+	}
+	justNow := now.Add(-500 * time.Millisecond)
+
+	tests := []struct {
+		name  string
+		email FailureStats
+		ip    FailureStats
+		want  time.Duration
+	}{
+		{"no_failures", FailureStats{}, FailureStats{}, 0},
+		{"first_failure_waits_base_delay", FailureStats{Count: 1, Last: justNow}, FailureStats{}, 500 * time.Millisecond},
+		{"delay_doubles", FailureStats{Count: 3, Last: justNow}, FailureStats{}, 3500 * time.Millisecond},
+		{"delay_is_capped", FailureStats{Count: 4, Last: now}, FailureStats{}, 8 * time.Second},
+		{"delay_elapsed", FailureStats{Count: 2, Last: now.Add(-time.Minute)}, FailureStats{}, 0},
+		{"locked_out", FailureStats{Count: 5, Last: now.Add(-5 * time.Minute)}, FailureStats{}, 10 * time.Minute},
+		{"lockout_over", FailureStats{Count: 5, Last: now.Add(-16 * time.Minute)}, FailureStats{}, 0},
+		{"ip_blocked", FailureStats{}, FailureStats{Count: 20, First: now.Add(-4 * time.Minute)}, 6 * time.Minute},
+		{"ip_below_limit", FailureStats{}, FailureStats{Count: 19, First: now.Add(-4 * time.Minute)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.RetryAfter(tt.email, tt.ip, now); got != tt.want {
+				t.Errorf("RetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if lookback := policy.EmailLookback(); lookback != 15*time.Minute {
+		t.Errorf("EmailLookback() = %v, want the longer lockout duration", lookback)
+	}
+}
+
+func TestLoginThrottledError(t *testing.T) {
+	var err error = &LoginThrottledError{RetryAfter: time.Minute}
+	var throttled *LoginThrottledError
+	if !errors.Is(err, ErrTooManyAttempts) || !errors.As(err, &throttled) || throttled.RetryAfter != time.Minute {
+		t.Errorf("LoginThrottledError does not unwrap to ErrTooManyAttempts: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxLotNumberLength = 64
+
+// Lot is one delivery of a product to a location. Stock that arrived without
+// a lot, such as a new product's opening quantity or stock from before lots
+// were tracked, is not covered by any lot and is sold after every lot.
+type Lot struct {
+	Id               string
+	ProductId        string
+	LocationId       string
+	LotNumber        string
+	Quantity         int
+	ReceivedAt       time.Time
+	ExpiresAt        *time.Time
+	ExpiryNotifiedAt *time.Time
+}
+
+// LotReceipt is what the sender printed on a delivery. Both fields are
+// optional; a lot number is generated when none is given.
+type LotReceipt struct {
+	LotNumber string
+	ExpiresAt *time.Time
+}
+
+// LotDraw is how many units a stock decrease takes from one lot.
+type LotDraw struct {
+	LotId    string
+	Quantity int
+}
+
+// ExpiringLot is a lot in the expiring-soon report.
+type ExpiringLot struct {
+	Lot
+	ProductName string
+	CategoryId  string
+	Expired     bool
+}
+
+func NewLot(productId, locationId string, quantity int, receipt LotReceipt, now time.Time) (*Lot, error) {
+	lot := &Lot{
+		Id:         uuid.New().String(),
+		ProductId:  productId,
+		LocationId: locationId,
+		LotNumber:  strings.TrimSpace(receipt.LotNumber),
+		Quantity:   quantity,
+		ReceivedAt: now,
+		ExpiresAt:  receipt.ExpiresAt,
+	}
+	if lot.LotNumber == "" {
+		lot.LotNumber = now.UTC().Format("20060102") + "-" + strings.ToUpper(lot.Id[:8])
+	}
+	if len(lot.LotNumber) > maxLotNumberLength {
+		return nil, fmt.Errorf("%w: lot number cannot be longer than %d characters", ErrLotInvalid, maxLotNumberLength)
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be greater than zero", ErrLotInvalid)
+	}
+	if lot.ExpiresAt != nil && !lot.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expiry date must be in the future", ErrLotInvalid)
+	}
+	return lot, nil
+}
+
+// TransferredTo is the part of the lot that a transfer delivers to another
+// location. It keeps the lot number and expiry so the stock is still traced
+// and sold in expiry order where it arrives.
+func (lot *Lot) TransferredTo(locationId string, quantity int, at time.Time) *Lot {
+	return &Lot{
+		Id:         uuid.New().String(),
+		ProductId:  lot.ProductId,
+		LocationId: locationId,
+		LotNumber:  lot.LotNumber,
+		Quantity:   quantity,
+		ReceivedAt: at,
+		ExpiresAt:  lot.ExpiresAt,
+	}
+}
+
+// IsExpiredAt reports whether the lot's expiry date has passed. Expired
+// stock is never sold or transferred, only written off.
+func (lot *Lot) IsExpiredAt(at time.Time) bool {
+	return lot.ExpiresAt != nil && lot.ExpiresAt.Before(at)
+}
+
+// IsExpiringBy reports whether the lot still holds stock that expires at or
+// before the given time.
+func (lot *Lot) IsExpiringBy(at time.Time) bool {
+	return lot.Quantity > 0 && lot.ExpiresAt != nil && !lot.ExpiresAt.After(at)
+}
+
+// SortLotsFEFO orders lots first-expiry-first-out. Lots without an expiry
+// date go last, and lots expiring together are used oldest first.
+func SortLotsFEFO(lots []Lot) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		a, b := lots[i], lots[j]
+		switch {
+		case a.ExpiresAt == nil && b.ExpiresAt != nil:
+			return false
+		case a.ExpiresAt != nil && b.ExpiresAt == nil:
+			return true
+		case a.ExpiresAt != nil && !a.ExpiresAt.Equal(*b.ExpiresAt):
+			return a.ExpiresAt.Before(*b.ExpiresAt)
+		}
+		return a.ReceivedAt.Before(b.ReceivedAt)
+	})
+}
+
+// DrawLotsFEFO picks the lots a decrease of quantity units comes out of,
+// skipping lots that have expired by now. When the lots hold less than
+// quantity the rest is untracked stock, so the draws can add up to less than
+// was asked for.
+func DrawLotsFEFO(lots []Lot, quantity int, now time.Time) []LotDraw {
+	ordered := append([]Lot(nil), lots...)
+	SortLotsFEFO(ordered)
+
+	draws := []LotDraw{}
+	for _, lot := range ordered {
+		if quantity == 0 {
+			break
+		}
+		if lot.Quantity <= 0 || lot.IsExpiredAt(now) {
+			continue
+		}
+		take := lot.Quantity
+		if take > quantity {
+			take = quantity
+		}
+		draws = append(draws, LotDraw{LotId: lot.Id, Quantity: take})
+		quantity -= take
+	}
+	return draws
+}
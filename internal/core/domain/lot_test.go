@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewLot(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	nextWeek := now.Add(7 * 24 * time.Hour)
+	yesterday := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		quantity int
+		receipt  LotReceipt
+		wantErr  bool
+	}{
+		{"with_number_and_expiry", 5, LotReceipt{LotNumber: " L-100 ", ExpiresAt: &nextWeek}, false},
+		{"generated_number", 5, LotReceipt{}, false},
+		{"zero_quantity", 0, LotReceipt{}, true},
+		{"already_expired", 5, LotReceipt{ExpiresAt: &yesterday}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lot, err := NewLot("p1", "main", tt.quantity, tt.receipt, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrLotInvalid) {
+					t.Errorf("NewLot() error = %v, want ErrLotInvalid", err)
+				}
+				return
+			}
+			if err != nil || lot.LotNumber == "" || lot.ReceivedAt != now {
+				t.Errorf("NewLot() = %+v, %v", lot, err)
+			}
+		})
+	}
+
+	lot, _ := NewLot("p1", "main", 1, LotReceipt{LotNumber: " L-100 "}, now)
+	if lot.LotNumber != "L-100" {
+		t.Errorf("NewLot() lot number = %q, want it trimmed", lot.LotNumber)
+	}
+}
+
+func TestDrawLotsFEFO(t *testing.T) {
+	day := func(d int) *time.Time {
+		at := time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+	lots := []Lot{
+		{Id: "no-expiry", Quantity: 10, ReceivedAt: *day(1)},
+		{Id: "late", Quantity: 4, ExpiresAt: day(20), ReceivedAt: *day(1)},
+		{Id: "soon-newer", Quantity: 3, ExpiresAt: day(10), ReceivedAt: *day(5)},
+		{Id: "soon-older", Quantity: 2, ExpiresAt: day(10), ReceivedAt: *day(2)},
+		{Id: "empty", Quantity: 0, ExpiresAt: day(3), ReceivedAt: *day(1)},
+		{Id: "expired", Quantity: 5, ExpiresAt: day(4), ReceivedAt: *day(1)},
+	}
+	now := *day(6)
+
+	tests := []struct {
+		name     string
+		quantity int
+		want     []LotDraw
+	}{
+		{"earliest_expiry_oldest_first", 3, []LotDraw{{"soon-older", 2}, {"soon-newer", 1}}},
+		{"lots_without_expiry_last", 12, []LotDraw{{"soon-older", 2}, {"soon-newer", 3}, {"late", 4}, {"no-expiry", 3}}},
+		{"more_than_the_lots_hold", 25, []LotDraw{{"soon-older", 2}, {"soon-newer", 3}, {"late", 4}, {"no-expiry", 10}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draws := DrawLotsFEFO(lots, tt.quantity, now)
+			if len(draws) != len(tt.want) {
+				t.Fatalf("DrawLotsFEFO() = %+v, want %+v", draws, tt.want)
+			}
+			for i := range draws {
+				if draws[i] != tt.want[i] {
+					t.Errorf("DrawLotsFEFO()[%d] = %+v, want %+v", i, draws[i], tt.want[i])
+				}
+			}
+		})
+	}
+	if lots[0].Id != "no-expiry" {
+		t.Errorf("DrawLotsFEFO() reordered its input")
+	}
+}
+
+func TestLot_TransferredTo(t *testing.T) {
+	expiresAt := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	lot := Lot{Id: "lot-1", ProductId: "p-1", LocationId: "main", LotNumber: "L-7", Quantity: 10, ExpiresAt: &expiresAt}
+	at := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	moved := lot.TransferredTo("store", 4, at)
+	if moved.Id == lot.Id || moved.LocationId != "store" || moved.Quantity != 4 || !moved.ReceivedAt.Equal(at) {
+		t.Errorf("TransferredTo() = %+v", moved)
+	}
+	if moved.ProductId != "p-1" || moved.LotNumber != "L-7" || moved.ExpiresAt == nil || !moved.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("TransferredTo() did not keep the lot details: %+v", moved)
+	}
+}
+
+func TestLot_IsExpiringBy(t *testing.T) {
+	now := time.Now()
+	soon := now.Add(time.Hour)
+	later := now.Add(48 * time.Hour)
+	tests := []struct {
+		name string
+		lot  Lot
+		want bool
+	}{
+		{"within_window", Lot{Quantity: 1, ExpiresAt: &soon}, true},
+		{"outside_window", Lot{Quantity: 1, ExpiresAt: &later}, false},
+		{"used_up", Lot{Quantity: 0, ExpiresAt: &soon}, false},
+		{"no_expiry", Lot{Quantity: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.lot.IsExpiringBy(now.Add(24 * time.Hour)); got != tt.want {
+				t.Errorf("IsExpiringBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
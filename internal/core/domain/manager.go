@@ -1,13 +1,63 @@
 package domain
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const MinPasswordLength = 10
+
 type Manager struct {
-	Id       string
-	Email    string
-	Password string
+	Id                 string
+	Email              string
+	Password           string
+	Role               Role
+	Disabled           bool
+	MustChangePassword bool
+	TOTPSecret         string
+	TOTPEnabled        bool
+	TOTPLastStep       int64
+}
+
+func NewManager(email, password string, role Role) (*Manager, error) {
+	manager := &Manager{
+		Id:    uuid.New().String(),
+		Email: strings.ToLower(strings.TrimSpace(email)),
+		Role:  role,
+	}
+	if err := manager.Validate(); err != nil {
+		return nil, err
+	}
+	if err := manager.SetPassword(password); err != nil {
+		return nil, err
+	}
+	manager.MustChangePassword = true
+	return manager, nil
+}
+
+func (m *Manager) Validate() error {
+	if at := strings.Index(m.Email, "@"); at < 1 || at == len(m.Email)-1 {
+		return fmt.Errorf("%w: email %q is not valid", ErrManagerInvalid, m.Email)
+	}
+	if _, err := ParseRole(string(m.Role)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Manager) SetPassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("%w: password must be at least %d characters", ErrManagerInvalid, MinPasswordLength)
+	}
+	m.Password = password
+	if err := m.HashPassword(); err != nil {
+		return err
+	}
+	m.MustChangePassword = false
+	return nil
 }
 
 func (m *Manager) HashPassword() error {
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewManager(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		password  string
+		role      Role
+		wantEmail string
+		expectErr bool
+	}{
+		{"success", "Clerk@Example.com ", "long-enough-pw", RoleClerk, "clerk@example.com", false},
+		{"fail_invalid_email", "clerk.example.com", "long-enough-pw", RoleClerk, "", true},
+		{"fail_email_without_domain", "clerk@", "long-enough-pw", RoleClerk, "", true},
+		{"fail_short_password", "clerk@example.com", "short", RoleClerk, "", true},
+		{"fail_unknown_role", "clerk@example.com", "long-enough-pw", Role("owner"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, err := NewManager(tt.email, tt.password, tt.role)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("NewManager() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.expectErr {
+				if !errors.Is(err, ErrManagerInvalid) {
+					t.Errorf("NewManager() error = %v, want ErrManagerInvalid", err)
+				}
+				return
+			}
+			if manager.Email != tt.wantEmail || manager.Id == "" || !manager.MustChangePassword {
+				t.Errorf("NewManager() = %+v", manager)
+			}
+			if manager.Password == tt.password || manager.CheckPassword(tt.password) != nil {
+				t.Errorf("NewManager() did not store a matching password hash")
+			}
+		})
+	}
+}
+
+func TestManager_SetPassword(t *testing.T) {
+	manager, _ := NewManager("admin@example.com", "first-password", RoleAdmin)
+
+	if err := manager.SetPassword("short"); !errors.Is(err, ErrManagerInvalid) {
+		t.Errorf("SetPassword(short) error = %v, want ErrManagerInvalid", err)
+	}
+	if err := manager.SetPassword("second-password"); err != nil {
+		t.Fatalf("SetPassword() returned an unexpected error: %v", err)
+	}
+	if manager.MustChangePassword || manager.CheckPassword("second-password") != nil || manager.CheckPassword("first-password") == nil {
+		t.Errorf("SetPassword() left manager = %+v", manager)
+	}
+}
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	TOTPIssuer        = "Inventory Manager"
+	TOTPDigits        = 6
+	TOTPPeriod        = 30 * time.Second
+	RecoveryCodeCount = 10
+
+	// totpSkew is how many periods either side of now a code is accepted for,
+	// to cover clock drift between the server and the authenticator app.
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPEnrollment is handed to a manager once when they start enrolling, so
+// they can add the secret to an authenticator app.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// MFAChallenge is the first half of a login by a manager with two-factor
+// authentication enabled. It proves the password was right and can be
+// exchanged once, before it expires, together with a code for real tokens.
+type MFAChallenge struct {
+	Id        string
+	ManagerId string
+	ExpiresAt time.Time
+}
+
+// LoginResult carries either tokens or, when a second factor is still needed,
+// the challenge token to present with the code.
+type LoginResult struct {
+	Tokens       *TokenPair
+	MFAToken     string
+	MFAExpiresAt time.Time
+}
+
+func NewTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// TOTPURI builds the otpauth:// URI authenticator apps read from a QR code.
+func TOTPURI(secret, account string) string {
+	label := url.PathEscape(TOTPIssuer + ":" + account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", TOTPIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(TOTPDigits))
+	params.Set("period", fmt.Sprint(int(TOTPPeriod.Seconds())))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPCode computes the RFC 6238 code of secret for a time step.
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("totp secret is not valid base32: %w", err)
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulus := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%modulus), nil
+}
+
+func TOTPStep(at time.Time) int64 {
+	return at.Unix() / int64(TOTPPeriod.Seconds())
+}
+
+// StartTOTPEnrollment stores a fresh secret that stays inactive until a code
+// from it has been confirmed.
+func (m *Manager) StartTOTPEnrollment() (*TOTPEnrollment, error) {
+	if m.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	m.TOTPSecret = secret
+	m.TOTPLastStep = 0
+	return &TOTPEnrollment{Secret: secret, URI: TOTPURI(secret, m.Email)}, nil
+}
+
+// CheckTOTP accepts a code from the manager's secret within the allowed clock
+// skew. A code is only good once: the step it belongs to is remembered and
+// neither it nor earlier steps are accepted again.
+func (m *Manager) CheckTOTP(code string, now time.Time) bool {
+	if m.TOTPSecret == "" || len(code) != TOTPDigits {
+		return false
+	}
+	current := TOTPStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= m.TOTPLastStep {
+			continue
+		}
+		expected, err := TOTPCode(m.TOTPSecret, step)
+		if err != nil {
+			return false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			m.TOTPLastStep = step
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) DisableTOTP() {
+	m.TOTPSecret = ""
+	m.TOTPEnabled = false
+	m.TOTPLastStep = 0
+}
+
+// NewRecoveryCodes returns plaintext codes for the manager to write down and
+// the hashes to store in their place.
+func NewRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashes := make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		buf := make([]byte, 8)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(buf))[:10]
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode ignores case, spaces and dashes so codes can be typed the
+// way they were written down.
+func HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
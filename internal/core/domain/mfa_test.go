@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed "12345678901234567890" of the RFC 6238 test
+// vectors in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil || got != tt.want {
+			t.Errorf("TOTPCode() at %d = %q, %v, want %q", tt.unix, got, err, tt.want)
+		}
+	}
+}
+
+func TestManager_CheckTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	codeAt := func(at time.Time) string {
+		code, _ := TOTPCode(rfc6238Secret, TOTPStep(at))
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		want     bool
+	}{
+		{"current_code", codeAt(now), 0, true},
+		{"previous_period", codeAt(now.Add(-TOTPPeriod)), 0, true},
+		{"next_period", codeAt(now.Add(TOTPPeriod)), 0, true},
+		{"too_old", codeAt(now.Add(-2 * TOTPPeriod)), 0, false},
+		{"already_used", codeAt(now), TOTPStep(now), false},
+		{"wrong_length", "12345", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &Manager{TOTPSecret: rfc6238Secret, TOTPLastStep: tt.lastStep}
+			if got := manager.CheckTOTP(tt.code, now); got != tt.want {
+				t.Errorf("CheckTOTP() = %v, want %v", got, tt.want)
+			}
+			if tt.want && manager.CheckTOTP(tt.code, now) {
+				t.Errorf("CheckTOTP() accepted the same code twice")
+			}
+		})
+	}
+}
+
+func TestManager_StartTOTPEnrollment(t *testing.T) {
+	manager := &Manager{Email: "clerk@example.com"}
+	enrollment, err := manager.StartTOTPEnrollment()
+	if err != nil {
+		t.Fatalf("StartTOTPEnrollment() returned an unexpected error: %v", err)
+	}
+	if manager.TOTPEnabled || manager.TOTPSecret != enrollment.Secret || len(enrollment.Secret) != 32 {
+		t.Errorf("StartTOTPEnrollment() left manager %+v with enrollment %+v", manager, enrollment)
+	}
+	wantPrefix := "otpauth://totp/Inventory%20Manager:clerk@example.com?"
+	if !strings.HasPrefix(enrollment.URI, wantPrefix) || !strings.Contains(enrollment.URI, "issuer=Inventory+Manager") {
+		t.Errorf("URI = %q, want prefix %q and the issuer parameter", enrollment.URI, wantPrefix)
+	}
+
+	manager.TOTPEnabled = true
+	if _, err := manager.StartTOTPEnrollment(); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Errorf("StartTOTPEnrollment() when enabled error = %v, want ErrMFAAlreadyEnabled", err)
+	}
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, hashes, err := NewRecoveryCodes()
+	if err != nil || len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("NewRecoveryCodes() = %d codes, %d hashes, %v", len(codes), len(hashes), err)
+	}
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if len(code) != 11 || code[5] != '-' || seen[code] {
+			t.Errorf("recovery code %q is malformed or repeated", code)
+		}
+		seen[code] = true
+		if hashes[i] != HashRecoveryCode(code) || hashes[i] == code {
+			t.Errorf("hash of %q does not match", code)
+		}
+	}
+	if HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))) != hashes[0] {
+		t.Errorf("HashRecoveryCode() should ignore case, spaces and dashes")
+	}
+}
@@ -1,37 +1,58 @@
 package domain
 
 import (
-	"errors"
+	"fmt"
 
-	"github.com/amangirdhar210/inventory-manager/config"
 	"github.com/google/uuid"
 )
 
 type Product struct {
-	Id       string
-	Name     string
-	Price    float64
-	Quantity int
+	Id              string
+	SKU             string
+	Name            string
+	Barcode         string
+	Description     string
+	CategoryId      string
+	Unit            UnitOfMeasure
+	Tags            []string
+	Price           float64
+	Quantity        int
+	ReorderPoint    int
+	ReorderQuantity int
+	Version         int
 }
 
 func (product *Product) Validate() error {
 	if product.Name == "" {
-		return errors.New("product name cannot be empty")
+		return fmt.Errorf("%w: product name cannot be empty", ErrProductInvalid)
+	} else if err := validateSKU(product.SKU); err != nil {
+		return err
+	} else if err := ValidateBarcode(product.Barcode); err != nil {
+		return err
+	} else if !product.Unit.IsValid() {
+		return fmt.Errorf("%w: unknown unit of measure %q", ErrProductInvalid, product.Unit)
 	} else if !isGreaterThanZero(product.Price) {
-		return errors.New("product price must be greater than zero")
+		return fmt.Errorf("%w: product price must be greater than zero", ErrProductInvalid)
 	} else if product.Quantity < 0 {
-		return errors.New("product quantity cannot be negative")
+		return fmt.Errorf("%w: product quantity cannot be negative", ErrProductInvalid)
+	} else if product.ReorderPoint < 0 || product.ReorderQuantity < 0 {
+		return fmt.Errorf("%w: reorder thresholds cannot be negative", ErrProductInvalid)
 	} else {
 		return nil
 	}
 }
 
 func CreateNewProduct(name string, price float64, quantity int) (*Product, error) {
+	id := uuid.New().String()
 	product := &Product{
-		Id:       uuid.New().String(),
+		Id:       id,
+		SKU:      defaultSKU(id),
 		Name:     name,
+		Unit:     UnitEach,
+		Tags:     []string{},
 		Price:    price,
 		Quantity: quantity,
+		Version:  1,
 	}
 
 	if err := product.Validate(); err != nil {
@@ -46,11 +67,11 @@ func isGreaterThanZero[T int | float64](AmountOrQuantity T) bool {
 
 func (product *Product) SellUnits(qtyToSell int) error {
 	if !isGreaterThanZero(qtyToSell) {
-		return errors.New("the quantity to be sold must be greater than zero")
+		return fmt.Errorf("%w: the quantity to be sold must be greater than zero", ErrProductInvalid)
 	}
 
 	if product.Quantity < qtyToSell {
-		return errors.New("insufficient stock")
+		return ErrInsufficientStock
 	}
 
 	product.Quantity -= qtyToSell
@@ -60,20 +81,54 @@ func (product *Product) SellUnits(qtyToSell int) error {
 
 func (product *Product) Restock(qtyToAdd int) error {
 	if !isGreaterThanZero(qtyToAdd) {
-		return errors.New("restock amount must be positive")
+		return fmt.Errorf("%w: restock amount must be positive", ErrProductInvalid)
 	}
 	product.Quantity += qtyToAdd
 	return nil
 }
 
+func (product *Product) AdjustUnits(delta int) error {
+	if delta == 0 {
+		return fmt.Errorf("%w: adjustment must change the quantity", ErrProductInvalid)
+	}
+	if product.Quantity+delta < 0 {
+		return ErrInsufficientStock
+	}
+	product.Quantity += delta
+	return nil
+}
+
 func (product *Product) UpdateProductPrice(newPrice float64) error {
 	if !isGreaterThanZero(newPrice) {
-		return errors.New("price must be greater than zero")
+		return fmt.Errorf("%w: price must be greater than zero", ErrProductInvalid)
 	}
 	product.Price = newPrice
 	return nil
 }
 
-func (product *Product) IsLowOnStock() bool {
-	return product.Quantity < config.ThresholdAlertQty
+func (product *Product) SetReorderThresholds(reorderPoint, reorderQuantity int) error {
+	if reorderPoint < 0 || reorderQuantity < 0 {
+		return fmt.Errorf("%w: reorder thresholds cannot be negative", ErrProductInvalid)
+	}
+	product.ReorderPoint = reorderPoint
+	product.ReorderQuantity = reorderQuantity
+	return nil
+}
+
+func (product *Product) EffectiveReorderPoint(defaultReorderPoint int) int {
+	if product.ReorderPoint > 0 {
+		return product.ReorderPoint
+	}
+	return defaultReorderPoint
+}
+
+func (product *Product) IsLowOnStock(defaultReorderPoint int) bool {
+	return product.Quantity < product.EffectiveReorderPoint(defaultReorderPoint)
+}
+
+func (product *Product) MatchesVersion(expectedVersion int) error {
+	if expectedVersion != 0 && product.Version != expectedVersion {
+		return ErrConflict
+	}
+	return nil
 }
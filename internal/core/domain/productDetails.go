@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+type UnitOfMeasure string
+
+const (
+	UnitEach     UnitOfMeasure = "each"
+	UnitKilogram UnitOfMeasure = "kg"
+	UnitLitre    UnitOfMeasure = "litre"
+
+	maxSKULength = 64
+)
+
+func (unit UnitOfMeasure) IsValid() bool {
+	switch unit {
+	case UnitEach, UnitKilogram, UnitLitre:
+		return true
+	}
+	return false
+}
+
+// ProductDetails are the catalog fields of a product. An empty SKU or unit
+// keeps the product's current value.
+type ProductDetails struct {
+	SKU         string
+	Barcode     string
+	Description string
+	CategoryId  string
+	Unit        UnitOfMeasure
+	Tags        []string
+}
+
+func (product *Product) SetDetails(details ProductDetails) error {
+	updated := *product
+	if sku := normalizeSKU(details.SKU); sku != "" {
+		updated.SKU = sku
+	}
+	if details.Unit != "" {
+		updated.Unit = details.Unit
+	}
+	updated.Barcode = strings.TrimSpace(details.Barcode)
+	updated.Description = strings.TrimSpace(details.Description)
+	updated.CategoryId = strings.TrimSpace(details.CategoryId)
+	updated.Tags = uniqueNonEmpty(details.Tags)
+
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+	*product = updated
+	return nil
+}
+
+func defaultSKU(id string) string {
+	return "SKU-" + strings.ToUpper(strings.ReplaceAll(id, "-", "")[:12])
+}
+
+func normalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+func validateSKU(sku string) error {
+	if sku == "" || len(sku) > maxSKULength {
+		return fmt.Errorf("%w: sku must be between 1 and %d characters", ErrProductInvalid, maxSKULength)
+	}
+	for _, r := range sku {
+		if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && !strings.ContainsRune("-_.", r) {
+			return fmt.Errorf("%w: sku may only contain letters, digits, '-', '_' and '.'", ErrProductInvalid)
+		}
+	}
+	return nil
+}
+
+// ValidateBarcode accepts an empty barcode or a GTIN-8, GTIN-12 (UPC-A),
+// GTIN-13 (EAN-13) or GTIN-14 with a correct GS1 check digit.
+func ValidateBarcode(code string) error {
+	if code == "" {
+		return nil
+	}
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return fmt.Errorf("%w: barcode must have 8, 12, 13 or 14 digits", ErrProductInvalid)
+	}
+
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if digit < 0 || digit > 9 {
+			return fmt.Errorf("%w: barcode must only contain digits", ErrProductInvalid)
+		}
+		// Weights alternate 3, 1, 3, ... starting next to the check digit.
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	check := int(code[len(code)-1] - '0')
+	if check < 0 || check > 9 || check != (10-sum%10)%10 {
+		return fmt.Errorf("%w: barcode check digit is wrong", ErrProductInvalid)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateBarcode(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		expectErr bool
+	}{
+		{"empty barcode is allowed", "", false},
+		{"valid ean-13", "4006381333931", false},
+		{"valid upc-a", "036000291452", false},
+		{"valid ean-8", "73513537", false},
+		{"valid gtin-14", "10012345678902", false},
+		{"wrong check digit", "4006381333932", true},
+		{"wrong length", "400638133393", true},
+		{"non-digit characters", "40063813339A1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBarcode(tt.code)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("ValidateBarcode(%q) error = %v, expectErr %v", tt.code, err, tt.expectErr)
+			}
+			if err != nil && !errors.Is(err, ErrProductInvalid) {
+				t.Errorf("ValidateBarcode(%q) error = %v, want ErrProductInvalid", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestProduct_SetDetails(t *testing.T) {
+	tests := []struct {
+		name      string
+		details   ProductDetails
+		wantSKU   string
+		wantUnit  UnitOfMeasure
+		expectErr bool
+	}{
+		{"keeps sku and unit when empty", ProductDetails{Description: "A laptop"}, "", UnitEach, false},
+		{"normalizes sku", ProductDetails{SKU: " lap-001 "}, "LAP-001", UnitEach, false},
+		{"sets unit", ProductDetails{Unit: UnitLitre}, "", UnitLitre, false},
+		{"rejects sku with spaces", ProductDetails{SKU: "LAP 001"}, "", UnitEach, true},
+		{"rejects unknown unit", ProductDetails{Unit: "crate"}, "", UnitEach, true},
+		{"rejects bad barcode", ProductDetails{Barcode: "12345"}, "", UnitEach, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product, _ := CreateNewProduct("Laptop", 1000, 5)
+			original := *product
+
+			err := product.SetDetails(tt.details)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("SetDetails() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.expectErr {
+				if product.SKU != original.SKU || product.Unit != original.Unit || product.Barcode != original.Barcode {
+					t.Errorf("SetDetails() changed the product on error: %+v", product)
+				}
+				return
+			}
+			wantSKU := tt.wantSKU
+			if wantSKU == "" {
+				wantSKU = original.SKU
+			}
+			if product.SKU != wantSKU || product.Unit != tt.wantUnit {
+				t.Errorf("SetDetails() sku = %q unit = %q, want %q and %q", product.SKU, product.Unit, wantSKU, tt.wantUnit)
+			}
+		})
+	}
+}
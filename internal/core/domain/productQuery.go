@@ -0,0 +1,59 @@
+package domain
+
+import "fmt"
+
+type ProductSortField string
+
+const (
+	SortByName     ProductSortField = "name"
+	SortByPrice    ProductSortField = "price"
+	SortByQuantity ProductSortField = "quantity"
+
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+type ProductQuery struct {
+	NameContains        string
+	CategoryId          string
+	MinPrice            *float64
+	MaxPrice            *float64
+	MinQuantity         *int
+	MaxQuantity         *int
+	LowStockOnly        bool
+	DefaultReorderPoint int
+	SortBy              ProductSortField
+	Descending          bool
+	Limit               int
+	Cursor              string
+}
+
+type ProductPage struct {
+	Products   []Product
+	NextCursor string
+}
+
+func (query *ProductQuery) Normalize() error {
+	switch query.SortBy {
+	case "":
+		query.SortBy = SortByName
+	case SortByName, SortByPrice, SortByQuantity:
+	default:
+		return fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, query.SortBy)
+	}
+
+	if query.Limit < 0 || query.Limit > MaxPageSize {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxPageSize)
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultPageSize
+	}
+
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return fmt.Errorf("%w: min_price is greater than max_price", ErrInvalidQuery)
+	}
+	if query.MinQuantity != nil && query.MaxQuantity != nil && *query.MinQuantity > *query.MaxQuantity {
+		return fmt.Errorf("%w: min_quantity is greater than max_quantity", ErrInvalidQuery)
+	}
+	return nil
+}
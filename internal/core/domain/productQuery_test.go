@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProductQuery_Normalize(t *testing.T) {
+	low, high := 5.0, 1.0
+	tests := []struct {
+		name      string
+		query     ProductQuery
+		wantSort  ProductSortField
+		wantLimit int
+		expectErr bool
+	}{
+		{"defaults", ProductQuery{}, SortByName, DefaultPageSize, false},
+		{"explicit_sort_and_limit", ProductQuery{SortBy: SortByPrice, Limit: 10}, SortByPrice, 10, false},
+		{"unknown_sort", ProductQuery{SortBy: "color"}, "", 0, true},
+		{"limit_too_large", ProductQuery{Limit: MaxPageSize + 1}, "", 0, true},
+		{"negative_limit", ProductQuery{Limit: -1}, "", 0, true},
+		{"inverted_price_range", ProductQuery{MinPrice: &low, MaxPrice: &high}, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Normalize()
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Normalize() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.expectErr {
+				if !errors.Is(err, ErrInvalidQuery) {
+					t.Errorf("Normalize() error = %v, want ErrInvalidQuery", err)
+				}
+				return
+			}
+			if tt.query.SortBy != tt.wantSort || tt.query.Limit != tt.wantLimit {
+				t.Errorf("Normalize() = %+v, want sort %q limit %d", tt.query, tt.wantSort, tt.wantLimit)
+			}
+		})
+	}
+}
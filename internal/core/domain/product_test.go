@@ -110,6 +110,37 @@ func TestProduct_Restock(t *testing.T) {
 	}
 }
 
+func TestProduct_AdjustUnits(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		initialQty  int
+		delta       int
+		expectedQty int
+		expectErr   bool
+	}{
+		{"should add units for a positive delta", 10, 4, 14, false},
+		{"should remove units for a negative delta", 10, -4, 6, false},
+		{"should fail for zero delta", 10, 0, 10, true},
+		{"should fail when going below zero", 10, -11, 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Product{Id: "test-id", Name: "Test Product", Price: 100, Quantity: tt.initialQty}
+
+			err := p.AdjustUnits(tt.delta)
+
+			if (err != nil) != tt.expectErr {
+				t.Errorf("AdjustUnits() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if p.Quantity != tt.expectedQty {
+				t.Errorf("AdjustUnits() quantity got = %v, want %v", p.Quantity, tt.expectedQty)
+			}
+		})
+	}
+}
+
 func TestProduct_UpdateProductPrice(t *testing.T) {
 	t.Parallel()
 
@@ -160,11 +191,71 @@ func TestProduct_IsLowOnStock(t *testing.T) {
 			t.Parallel()
 			p := &Product{Quantity: tt.quantity}
 
-			if got := p.IsLowOnStock(); got != tt.expectedIsLow {
+			if got := p.IsLowOnStock(ThresholdAlertQty); got != tt.expectedIsLow {
 				t.Errorf("IsLowOnStock() = %v, want %v", got, tt.expectedIsLow)
 			}
 		})
 	}
 }
 
+func TestProduct_IsLowOnStock_PerProductReorderPoint(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		quantity      int
+		reorderPoint  int
+		expectedIsLow bool
+	}{
+		{"should use the product reorder point for bulk items", 4999, 5000, true},
+		{"should not be low above the product reorder point", 5000, 5000, false},
+		{"should ignore the default for low reorder points", 3, 2, false},
+		{"should be low below a small reorder point", 1, 2, true},
+		{"should fall back to the default when unset", ThresholdAlertQty - 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := &Product{Quantity: tt.quantity, ReorderPoint: tt.reorderPoint}
+
+			if got := p.IsLowOnStock(ThresholdAlertQty); got != tt.expectedIsLow {
+				t.Errorf("IsLowOnStock() = %v, want %v", got, tt.expectedIsLow)
+			}
+		})
+	}
+}
+
+func TestProduct_SetReorderThresholds(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		reorderPoint    int
+		reorderQuantity int
+		expectErr       bool
+	}{
+		{"should set both thresholds", 5000, 20000, false},
+		{"should allow clearing thresholds", 0, 0, false},
+		{"should fail for negative reorder point", -1, 10, true},
+		{"should fail for negative reorder quantity", 10, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Product{Id: "test-id", Name: "Test Product", Price: 100, Quantity: 10, ReorderPoint: 7, ReorderQuantity: 9}
+
+			err := p.SetReorderThresholds(tt.reorderPoint, tt.reorderQuantity)
+
+			if (err != nil) != tt.expectErr {
+				t.Errorf("SetReorderThresholds() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if !tt.expectErr && (p.ReorderPoint != tt.reorderPoint || p.ReorderQuantity != tt.reorderQuantity) {
+				t.Errorf("SetReorderThresholds() got = %d/%d, want %d/%d", p.ReorderPoint, p.ReorderQuantity, tt.reorderPoint, tt.reorderQuantity)
+			}
+			if tt.expectErr && (p.ReorderPoint != 7 || p.ReorderQuantity != 9) {
+				t.Errorf("SetReorderThresholds() modified thresholds on error")
+			}
+		})
+	}
+}
+
 //implement testing for UpdateProductPrice
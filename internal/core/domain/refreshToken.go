@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TokenPair struct {
+	AccessToken     string
+	AccessExpiresAt time.Time
+	RefreshToken    string
+}
+
+// RefreshToken is the stored half of an opaque refresh token. Only the hash of
+// the token handed to the client is kept. Every rotation stays in the family of
+// the login that started it, so a replayed token can revoke the whole chain.
+type RefreshToken struct {
+	Id        string
+	FamilyId  string
+	ManagerId string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	RotatedAt *time.Time
+	RevokedAt *time.Time
+}
+
+// NewRefreshToken starts a new family when familyId is empty and returns the
+// token together with the plaintext value for the client.
+func NewRefreshToken(managerId, familyId string, now time.Time, ttl time.Duration) (*RefreshToken, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+	if familyId == "" {
+		familyId = uuid.New().String()
+	}
+	return &RefreshToken{
+		Id:        uuid.New().String(),
+		FamilyId:  familyId,
+		ManagerId: managerId,
+		TokenHash: HashRefreshToken(raw),
+		ExpiresAt: now.Add(ttl).UTC(),
+		CreatedAt: now.UTC(),
+	}, raw, nil
+}
+
+func HashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RotatedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRefreshToken(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	first, raw, err := NewRefreshToken("manager-1", "", now, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRefreshToken() returned an unexpected error: %v", err)
+	}
+	if first.FamilyId == "" || first.TokenHash != HashRefreshToken(raw) || first.TokenHash == raw {
+		t.Errorf("NewRefreshToken() = %+v, want a new family and a hashed token", first)
+	}
+
+	rotated, rotatedRaw, _ := NewRefreshToken("manager-1", first.FamilyId, now, time.Hour)
+	if rotated.FamilyId != first.FamilyId || rotatedRaw == raw {
+		t.Errorf("rotated token should stay in family %s with a fresh value, got %+v", first.FamilyId, rotated)
+	}
+}
+
+func TestRefreshToken_IsActive(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	used := now.Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		token RefreshToken
+		want  bool
+	}{
+		{"active", RefreshToken{ExpiresAt: now.Add(time.Hour)}, true},
+		{"expired", RefreshToken{ExpiresAt: now}, false},
+		{"rotated", RefreshToken{ExpiresAt: now.Add(time.Hour), RotatedAt: &used}, false},
+		{"revoked", RefreshToken{ExpiresAt: now.Add(time.Hour), RevokedAt: &used}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.token.IsActive(now); got != tt.want {
+				t.Errorf("IsActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
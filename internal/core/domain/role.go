@@ -0,0 +1,76 @@
+package domain
+
+import "fmt"
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleManager  Role = "manager"
+	RoleClerk    Role = "clerk"
+	RoleReadOnly Role = "read_only"
+)
+
+type Permission string
+
+const (
+	PermProductsRead    Permission = "products:read"
+	PermProductsWrite   Permission = "products:write"
+	PermProductsDelete  Permission = "products:delete"
+	PermStockSell       Permission = "stock:sell"
+	PermStockRestock    Permission = "stock:restock"
+	PermStockAdjust     Permission = "stock:adjust"
+	PermReportsRead     Permission = "reports:read"
+	PermAlertsRead      Permission = "alerts:read"
+	PermAlertsAck       Permission = "alerts:ack"
+	PermManagersManage  Permission = "managers:manage"
+	PermAuditRead       Permission = "audit:read"
+	PermProductsPurge   Permission = "products:purge"
+	PermStockTransfer   Permission = "stock:transfer"
+	PermLocationsManage Permission = "locations:manage"
+)
+
+var allPermissions = []Permission{
+	PermProductsRead, PermProductsWrite, PermProductsDelete, PermStockSell, PermStockRestock,
+	PermStockAdjust, PermReportsRead, PermAlertsRead, PermAlertsAck, PermManagersManage, PermAuditRead, PermProductsPurge,
+	PermStockTransfer, PermLocationsManage,
+}
+
+var readOnlyPermissions = []Permission{PermProductsRead, PermReportsRead, PermAlertsRead}
+
+var clerkPermissions = append([]Permission{PermStockSell, PermStockRestock, PermStockTransfer, PermAlertsAck}, readOnlyPermissions...)
+
+var managerPermissions = append([]Permission{PermProductsWrite, PermProductsDelete, PermStockAdjust}, clerkPermissions...)
+
+var rolePermissions = map[Role][]Permission{
+	RoleReadOnly: readOnlyPermissions,
+	RoleClerk:    clerkPermissions,
+	RoleManager:  managerPermissions,
+	RoleAdmin:    append([]Permission{PermManagersManage, PermAuditRead, PermProductsPurge, PermLocationsManage}, managerPermissions...),
+}
+
+func ParseRole(value string) (Role, error) {
+	role := Role(value)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("%w: unknown role %q", ErrManagerInvalid, value)
+	}
+	return role, nil
+}
+
+func ParsePermission(value string) (Permission, error) {
+	for _, permission := range allPermissions {
+		if string(permission) == value {
+			return permission, nil
+		}
+	}
+	return "", fmt.Errorf("unknown permission %q", value)
+}
+
+func (role Role) Can(permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
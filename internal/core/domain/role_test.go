@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRole_Can(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       Role
+		permission Permission
+		want       bool
+	}{
+		{"admin_manages_managers", RoleAdmin, PermManagersManage, true},
+		{"admin_deletes_products", RoleAdmin, PermProductsDelete, true},
+		{"admin_reads_audit", RoleAdmin, PermAuditRead, true},
+		{"manager_cannot_read_audit", RoleManager, PermAuditRead, false},
+		{"admin_purges_products", RoleAdmin, PermProductsPurge, true},
+		{"manager_cannot_purge_products", RoleManager, PermProductsPurge, false},
+		{"manager_changes_prices", RoleManager, PermProductsWrite, true},
+		{"manager_cannot_manage_managers", RoleManager, PermManagersManage, false},
+		{"clerk_sells", RoleClerk, PermStockSell, true},
+		{"clerk_restocks", RoleClerk, PermStockRestock, true},
+		{"clerk_transfers_stock", RoleClerk, PermStockTransfer, true},
+		{"admin_manages_locations", RoleAdmin, PermLocationsManage, true},
+		{"manager_cannot_manage_locations", RoleManager, PermLocationsManage, false},
+		{"clerk_cannot_change_prices", RoleClerk, PermProductsWrite, false},
+		{"clerk_cannot_delete", RoleClerk, PermProductsDelete, false},
+		{"read_only_reads", RoleReadOnly, PermProductsRead, true},
+		{"read_only_cannot_sell", RoleReadOnly, PermStockSell, false},
+		{"unknown_role_has_nothing", Role("guest"), PermProductsRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.role.Can(tt.permission); got != tt.want {
+				t.Errorf("%s.Can(%s) = %v, want %v", tt.role, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, value := range []string{"admin", "manager", "clerk", "read_only"} {
+		if role, err := ParseRole(value); err != nil || string(role) != value {
+			t.Errorf("ParseRole(%q) = %q, %v", value, role, err)
+		}
+	}
+	if _, err := ParseRole("superuser"); !errors.Is(err, ErrManagerInvalid) {
+		t.Errorf("ParseRole(superuser) error = %v, want ErrManagerInvalid", err)
+	}
+}
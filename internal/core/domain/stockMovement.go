@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MovementReason string
+
+const (
+	// MovementInitial records the quantity a product was created with.
+	MovementInitial    MovementReason = "initial"
+	MovementSale       MovementReason = "sale"
+	MovementRestock    MovementReason = "restock"
+	MovementAdjustment MovementReason = "adjustment"
+	MovementDelete     MovementReason = "delete"
+	MovementRestore    MovementReason = "restore"
+	MovementWriteOff   MovementReason = "write_off"
+)
+
+type StockMovement struct {
+	Id                string
+	ProductId         string
+	LocationId        string
+	LotId             string
+	Delta             int
+	Reason            MovementReason
+	ResultingQuantity int
+	ManagerId         string
+	CreatedAt         time.Time
+}
+
+func NewStockMovement(productId string, delta int, reason MovementReason, managerId string) *StockMovement {
+	return &StockMovement{
+		Id:        uuid.New().String(),
+		ProductId: productId,
+		Delta:     delta,
+		Reason:    reason,
+		ManagerId: managerId,
+		CreatedAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransferStatus string
+
+const (
+	TransferInTransit TransferStatus = "in_transit"
+	TransferReceived  TransferStatus = "received"
+	TransferCancelled TransferStatus = "cancelled"
+)
+
+// Transfer moves stock between two locations. While it is in transit the
+// quantity has left the source but not yet reached the destination.
+type Transfer struct {
+	Id             string
+	ProductId      string
+	FromLocationId string
+	ToLocationId   string
+	Quantity       int
+	Status         TransferStatus
+	CreatedBy      string
+	CreatedAt      time.Time
+	CompletedAt    *time.Time
+}
+
+func NewTransfer(productId, fromLocationId, toLocationId string, quantity int, createdBy string, now time.Time) (*Transfer, error) {
+	if productId == "" || fromLocationId == "" || toLocationId == "" {
+		return nil, fmt.Errorf("%w: product and both locations are required", ErrTransferInvalid)
+	}
+	if fromLocationId == toLocationId {
+		return nil, fmt.Errorf("%w: source and destination must differ", ErrTransferInvalid)
+	}
+	if !isGreaterThanZero(quantity) {
+		return nil, fmt.Errorf("%w: quantity must be greater than zero", ErrTransferInvalid)
+	}
+	return &Transfer{
+		Id:             uuid.New().String(),
+		ProductId:      productId,
+		FromLocationId: fromLocationId,
+		ToLocationId:   toLocationId,
+		Quantity:       quantity,
+		Status:         TransferInTransit,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+	}, nil
+}
+
+func ParseTransferStatus(value string) (TransferStatus, error) {
+	switch status := TransferStatus(value); status {
+	case "", TransferInTransit, TransferReceived, TransferCancelled:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: unknown transfer status %q", ErrTransferInvalid, value)
+	}
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type AlertRepository interface {
+	OpenAlert(alert *domain.Alert, notTriggeredSince time.Time) (bool, error)
+	ResolveAlerts(productId, locationId string, resolvedAt time.Time) error
+	ListAlerts(status domain.AlertStatus) ([]domain.Alert, error)
+	AcknowledgeAlert(id string, managerId string, acknowledgedAt time.Time) (*domain.Alert, error)
+}
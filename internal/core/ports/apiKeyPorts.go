@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type APIKeyRepository interface {
+	SaveAPIKey(key *domain.APIKey) error
+	FindAPIKeyByPrefix(prefix string) (*domain.APIKey, error)
+	ListAPIKeys() ([]domain.APIKey, error)
+	RevokeAPIKey(id string, revokedAt time.Time) error
+	TouchAPIKey(id string, usedAt time.Time) error
+}
@@ -0,0 +1,11 @@
+package ports
+
+import "github.com/amangirdhar210/inventory-manager/internal/core/domain"
+
+// AuditRepository only ever appends; entries cannot be changed once recorded.
+type AuditRepository interface {
+	RecordAudit(entry *domain.AuditEntry) error
+	// WalkAuditEntries calls visit for each matching entry, newest first when
+	// newestFirst is set, and stops at the first error visit returns.
+	WalkAuditEntries(query domain.AuditQuery, newestFirst bool, visit func(domain.AuditEntry) error) error
+}
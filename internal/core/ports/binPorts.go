@@ -0,0 +1,12 @@
+package ports
+
+import "github.com/amangirdhar210/inventory-manager/internal/core/domain"
+
+type BinRepository interface {
+	SaveBin(bin *domain.Bin) error
+	FindBinById(id string) (*domain.Bin, error)
+	ListBins(locationId string) ([]domain.Bin, error)
+	SetBinStock(stock *domain.BinStock) error
+	ProductBins(productId string) ([]domain.BinSlot, error)
+	BinSlots(locationId string, productIds []string) ([]domain.BinSlot, error)
+}
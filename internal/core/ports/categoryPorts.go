@@ -0,0 +1,11 @@
+package ports
+
+import "github.com/amangirdhar210/inventory-manager/internal/core/domain"
+
+type CategoryRepository interface {
+	SaveCategory(category *domain.Category) error
+	FindCategoryById(id string) (*domain.Category, error)
+	ListCategories() ([]domain.Category, error)
+	UpdateCategory(category *domain.Category) error
+	DeleteCategory(id string) error
+}
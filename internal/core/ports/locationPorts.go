@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type LocationRepository interface {
+	SaveLocation(location *domain.Location) error
+	FindLocationById(id string) (*domain.Location, error)
+	ListLocations() ([]domain.Location, error)
+	FindStockLevel(productId, locationId string) (*domain.StockLevel, error)
+	ProductStock(productId string) (*domain.ProductStock, error)
+	SetStockReorderPoint(level *domain.StockLevel) error
+}
+
+type TransferRepository interface {
+	CreateTransfer(transfer *domain.Transfer) error
+	ReceiveTransfer(id string, receivedAt time.Time) (*domain.Transfer, error)
+	CancelTransfer(id string, cancelledAt time.Time) (*domain.Transfer, error)
+	ListTransfers(status domain.TransferStatus) ([]domain.Transfer, error)
+}
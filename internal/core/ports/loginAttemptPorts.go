@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type LoginAttemptTracker interface {
+	RecordLoginAttempt(attempt *domain.LoginAttempt) error
+	// EmailFailures counts failures since the later of since and the email's
+	// last successful login.
+	EmailFailures(email string, since time.Time) (domain.FailureStats, error)
+	IPFailures(ip string, since time.Time) (domain.FailureStats, error)
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type LotRepository interface {
+	// ReceiveLot applies a restock movement and stores the lot it delivered
+	// in one transaction, together with the audit entry like
+	// ProductRepository.ApplyStockMovement.
+	ReceiveLot(lot *domain.Lot, movement *domain.StockMovement, expectedVersion int, audit *domain.AuditEntry) (*domain.Product, error)
+	FindLotById(id string) (*domain.Lot, error)
+	ListLots(productId string) ([]domain.Lot, error)
+	ExpiringLots(by time.Time, locationId string) ([]domain.ExpiringLot, error)
+	MarkLotExpiryNotified(id string, at time.Time) error
+}
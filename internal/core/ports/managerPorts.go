@@ -4,4 +4,15 @@ import "github.com/amangirdhar210/inventory-manager/internal/core/domain"
 
 type ManagerRepository interface {
 	FindByEmail(email string) (*domain.Manager, error)
+	FindManagerById(id string) (*domain.Manager, error)
+	ListManagers() ([]domain.Manager, error)
+	SaveManager(manager *domain.Manager) error
+	UpdateManager(manager *domain.Manager) error
+	// UpdateManagerTOTP saves only the two-factor fields, so it cannot undo a
+	// concurrent change to the rest of the account.
+	UpdateManagerTOTP(manager *domain.Manager) error
+	// RecordTOTPStep saves step as the last TOTP step used. It returns
+	// ErrMFACodeInvalid when that step or a later one was already used.
+	RecordTOTPStep(managerId string, step int64) error
+	DeleteManager(id string) error
 }
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type MFAChallengeIssuer interface {
+	IssueMFAChallenge(manager *domain.Manager) (string, *domain.MFAChallenge, error)
+	ParseMFAChallenge(token string) (*domain.MFAChallenge, error)
+}
+
+type RecoveryCodeRepository interface {
+	// ReplaceRecoveryCodes drops every code of the manager and stores the new
+	// hashes; passing none just drops them.
+	ReplaceRecoveryCodes(managerId string, codeHashes []string) error
+	// UseRecoveryCode marks an unused code as used, or returns
+	// ErrMFACodeInvalid if the manager has no such unused code.
+	UseRecoveryCode(managerId, codeHash string, usedAt time.Time) error
+}
@@ -0,0 +1,7 @@
+package ports
+
+import "github.com/amangirdhar210/inventory-manager/internal/core/domain"
+
+type DeadLetterRepository interface {
+	SaveDeadLetter(letter *domain.DeadLetter) error
+}
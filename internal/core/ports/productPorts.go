@@ -1,15 +1,31 @@
 package ports
 
-import "github.com/amangirdhar210/inventory-manager/internal/core/domain"
+import (
+	"time"
 
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+// ProductRepository writes each change's audit entry, when one is given, in
+// the same transaction as the change, setting its After to the product as
+// the change left it.
 type ProductRepository interface {
 	FindById(id string) (*domain.Product, error)
-	ListAll() ([]domain.Product, error)
-	Save(product *domain.Product) error
-	Update(product *domain.Product) error
-	DeleteById(id string) error
+	FindBySKU(sku string) (*domain.Product, error)
+	FindByBarcode(barcode string) (*domain.Product, error)
+	ListProducts(query domain.ProductQuery) (*domain.ProductPage, error)
+	InventoryValueByCategory(locationId string) (map[string]float64, error)
+	// Save records movement as the product's opening stock at the default location.
+	Save(product *domain.Product, movement *domain.StockMovement, audit *domain.AuditEntry) error
+	Update(product *domain.Product, audit *domain.AuditEntry) error
+	ApplyStockMovement(movement *domain.StockMovement, expectedVersion int, audit *domain.AuditEntry) (*domain.Product, error)
+	DeleteById(id string, movement *domain.StockMovement, audit *domain.AuditEntry) error
+	RestoreById(id string, movement *domain.StockMovement, audit *domain.AuditEntry) (*domain.Product, error)
+	PurgeDeletedProducts(deletedBefore time.Time, audit func(product *domain.Product) *domain.AuditEntry) ([]domain.Product, error)
+	ListMovements(productId string, from, to time.Time) ([]domain.StockMovement, error)
 }
 
 type Notifier interface {
 	NotifyLowStock(product *domain.Product)
+	NotifyExpiring(lot *domain.ExpiringLot)
 }
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type RefreshTokenRepository interface {
+	SaveRefreshToken(token *domain.RefreshToken) error
+	FindRefreshToken(tokenHash string) (*domain.RefreshToken, error)
+	// RotateRefreshToken marks current as rotated and stores next in one step.
+	// It returns ErrRefreshTokenReused if current was already rotated or revoked.
+	RotateRefreshToken(currentId string, next *domain.RefreshToken, rotatedAt time.Time) error
+	RevokeRefreshTokenFamily(familyId string, revokedAt time.Time) error
+	RevokeManagerRefreshTokens(managerId string, revokedAt time.Time) error
+	PruneRefreshTokens(now time.Time) (int64, error)
+}
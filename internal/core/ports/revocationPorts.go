@@ -0,0 +1,16 @@
+package ports
+
+import "time"
+
+type RevocationStore interface {
+	RevokeToken(tokenId string, expiresAt time.Time) error
+	IsTokenRevoked(tokenId string) (bool, error)
+	// ConsumeToken uses up a single-use token such as an MFA challenge. It
+	// reports false when the token was already used or revoked.
+	ConsumeToken(tokenId string, expiresAt time.Time) (bool, error)
+	// RevokeManagerTokens revokes every access token issued to the manager before
+	// revokedAt. It is kept until expiresAt, when those tokens have expired anyway.
+	RevokeManagerTokens(managerId string, revokedAt, expiresAt time.Time) error
+	IsManagerTokenRevoked(managerId string, issuedAt time.Time) (bool, error)
+	PruneRevokedTokens(now time.Time) (int64, error)
+}
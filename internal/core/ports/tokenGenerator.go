@@ -1,7 +1,16 @@
 package ports
 
-import "github.com/amangirdhar210/inventory-manager/internal/core/domain"
+import (
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
 
 type TokenGenerator interface {
 	GenerateToken(manager *domain.Manager) (string, error)
+	TokenTTL() time.Duration
+}
+
+type TokenValidator interface {
+	ValidateToken(tokenString string) (*domain.Principal, error)
 }
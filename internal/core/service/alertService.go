@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+type alertService struct {
+	alerts            ports.AlertRepository
+	notifier          ports.Notifier
+	lowStockThreshold int
+	cooldown          time.Duration
+	now               func() time.Time
+}
+
+func NewAlertService(alerts ports.AlertRepository, notifier ports.Notifier, lowStockThreshold int, cooldown time.Duration) AlertService {
+	return &alertService{
+		alerts:            alerts,
+		notifier:          notifier,
+		lowStockThreshold: lowStockThreshold,
+		cooldown:          cooldown,
+		now:               time.Now,
+	}
+}
+
+func (alertService *alertService) CheckStockLevel(product *domain.Product) {
+	now := alertService.now()
+	if !product.IsLowOnStock(alertService.lowStockThreshold) {
+		if err := alertService.alerts.ResolveAlerts(product.Id, "", now); err != nil {
+			log.Printf("could not resolve low stock alerts for product %s: %v", product.Id, err)
+		}
+		return
+	}
+
+	alert := domain.NewLowStockAlert(product, product.EffectiveReorderPoint(alertService.lowStockThreshold))
+	alert.TriggeredAt = now.UTC()
+	opened, err := alertService.alerts.OpenAlert(alert, now.Add(-alertService.cooldown))
+	if err != nil {
+		log.Printf("could not record low stock alert for product %s: %v", product.Id, err)
+		return
+	}
+	if opened {
+		alertService.notifier.NotifyLowStock(product)
+	}
+}
+
+// CheckLocationStock tracks alerts against a location's own reorder point.
+// They show up in the alert list but are not sent to the notifier, which only
+// hears about products running low overall.
+func (alertService *alertService) CheckLocationStock(level *domain.StockLevel) {
+	now := alertService.now()
+	if !level.IsLowOnStock() {
+		if err := alertService.alerts.ResolveAlerts(level.ProductId, level.LocationId, now); err != nil {
+			log.Printf("could not resolve low stock alerts for product %s at %s: %v", level.ProductId, level.LocationId, err)
+		}
+		return
+	}
+
+	alert := domain.NewLocationLowStockAlert(level)
+	alert.TriggeredAt = now.UTC()
+	if _, err := alertService.alerts.OpenAlert(alert, now.Add(-alertService.cooldown)); err != nil {
+		log.Printf("could not record low stock alert for product %s at %s: %v", level.ProductId, level.LocationId, err)
+	}
+}
+
+func (alertService *alertService) ListAlerts(status string) ([]domain.Alert, error) {
+	alertStatus, err := domain.ParseAlertStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := alertService.alerts.ListAlerts(alertStatus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func (alertService *alertService) AcknowledgeAlert(ctx context.Context, id string) (*domain.Alert, error) {
+	alert, err := alertService.alerts.AcknowledgeAlert(id, domain.ActorFromContext(ctx), alertService.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to acknowledge alert %s: %w", id, err)
+	}
+	return alert, nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type mockAlertRepository struct {
+	mu          sync.Mutex
+	alerts      []*domain.Alert
+	shouldError bool
+}
+
+func newMockAlertRepository() *mockAlertRepository {
+	return &mockAlertRepository{}
+}
+
+func (m *mockAlertRepository) OpenAlert(alert *domain.Alert, notTriggeredSince time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldError {
+		return false, ErrRepoFailed
+	}
+	for _, existing := range m.alerts {
+		if existing.ProductId != alert.ProductId || existing.LocationId != alert.LocationId {
+			continue
+		}
+		if existing.Status != domain.AlertResolved || existing.TriggeredAt.After(notTriggeredSince) {
+			return false, nil
+		}
+	}
+	m.alerts = append(m.alerts, alert)
+	return true, nil
+}
+
+func (m *mockAlertRepository) ResolveAlerts(productId, locationId string, resolvedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	for _, alert := range m.alerts {
+		if alert.ProductId == productId && alert.LocationId == locationId && alert.Status != domain.AlertResolved {
+			alert.Status = domain.AlertResolved
+			alert.ResolvedAt = &resolvedAt
+		}
+	}
+	return nil
+}
+
+func (m *mockAlertRepository) ListAlerts(status domain.AlertStatus) ([]domain.Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	alerts := []domain.Alert{}
+	for _, alert := range m.alerts {
+		if status == "" || alert.Status == status {
+			alerts = append(alerts, *alert)
+		}
+	}
+	return alerts, nil
+}
+
+func (m *mockAlertRepository) AcknowledgeAlert(id string, managerId string, acknowledgedAt time.Time) (*domain.Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	for _, alert := range m.alerts {
+		if alert.Id != id {
+			continue
+		}
+		if alert.Status == domain.AlertOpen {
+			alert.Status = domain.AlertAcknowledged
+			alert.AcknowledgedAt = &acknowledgedAt
+			alert.AcknowledgedBy = managerId
+		}
+		clone := *alert
+		return &clone, nil
+	}
+	return nil, domain.ErrAlertNotFound
+}
+
+type countingNotifier struct {
+	calls int
+}
+
+func (c *countingNotifier) NotifyLowStock(product *domain.Product) {
+	c.calls++
+}
+
+func (c *countingNotifier) NotifyExpiring(lot *domain.ExpiringLot) {}
+
+func TestAlertService_CheckStockLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		cooldown   time.Duration
+		quantities []int
+		advance    time.Duration
+		wantCalls  int
+		wantAlerts int
+	}{
+		{"fires_once_while_low", 0, []int{5, 4, 3, 2}, 0, 1, 1},
+		{"never_fires_above_threshold", 0, []int{50, 40, 30}, 0, 0, 0},
+		{"re_arms_after_restock", 0, []int{5, 4, 20, 3}, time.Second, 2, 2},
+		{"cooldown_suppresses_re_alert", time.Hour, []int{5, 20, 3}, time.Minute, 1, 1},
+		{"fires_again_after_cooldown", time.Hour, []int{5, 20, 3}, 2 * time.Hour, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := newMockAlertRepository()
+			notifier := &countingNotifier{}
+			service := NewAlertService(alerts, notifier, testLowStockThreshold, tt.cooldown).(*alertService)
+			clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+			service.now = func() time.Time { return clock }
+
+			for _, quantity := range tt.quantities {
+				service.CheckStockLevel(&domain.Product{Id: "prod-1", Quantity: quantity})
+				clock = clock.Add(tt.advance)
+			}
+
+			if notifier.calls != tt.wantCalls {
+				t.Errorf("notifier called %d times, want %d", notifier.calls, tt.wantCalls)
+			}
+			if len(alerts.alerts) != tt.wantAlerts {
+				t.Errorf("recorded %d alerts, want %d", len(alerts.alerts), tt.wantAlerts)
+			}
+		})
+	}
+}
+
+func TestAlertService_CheckStockLevel_RepositoryError(t *testing.T) {
+	alerts := newMockAlertRepository()
+	alerts.shouldError = true
+	notifier := &countingNotifier{}
+	service := NewAlertService(alerts, notifier, testLowStockThreshold, 0)
+
+	service.CheckStockLevel(&domain.Product{Id: "prod-1", Quantity: 1})
+
+	if notifier.calls != 0 {
+		t.Errorf("notifier called %d times after a repository error, want 0", notifier.calls)
+	}
+}
+
+func TestAlertService_CheckLocationStock(t *testing.T) {
+	alerts := newMockAlertRepository()
+	notifier := &countingNotifier{}
+	service := NewAlertService(alerts, notifier, testLowStockThreshold, 0)
+
+	service.CheckStockLevel(&domain.Product{Id: "prod-1", Quantity: 1})
+	service.CheckLocationStock(&domain.StockLevel{ProductId: "prod-1", LocationId: "store-1", Quantity: 1, ReorderPoint: 5})
+	service.CheckLocationStock(&domain.StockLevel{ProductId: "prod-1", LocationId: "store-2", Quantity: 1})
+	if len(alerts.alerts) != 2 || alerts.alerts[1].LocationId != "store-1" {
+		t.Fatalf("recorded alerts = %+v, want an aggregate and a store-1 alert", alerts.alerts)
+	}
+	if notifier.calls != 1 {
+		t.Errorf("notifier called %d times, want only the aggregate alert sent", notifier.calls)
+	}
+
+	service.CheckLocationStock(&domain.StockLevel{ProductId: "prod-1", LocationId: "store-1", Quantity: 9, ReorderPoint: 5})
+	if alerts.alerts[0].Status != domain.AlertOpen || alerts.alerts[1].Status != domain.AlertResolved {
+		t.Errorf("after restocking store-1 alerts = %s and %s, want only the location alert resolved",
+			alerts.alerts[0].Status, alerts.alerts[1].Status)
+	}
+}
+
+func TestAlertService_ListAlerts(t *testing.T) {
+	alerts := newMockAlertRepository()
+	service := NewAlertService(alerts, &countingNotifier{}, testLowStockThreshold, 0)
+	service.CheckStockLevel(&domain.Product{Id: "prod-1", Quantity: 1})
+	service.CheckStockLevel(&domain.Product{Id: "prod-2", Quantity: 1})
+	service.CheckStockLevel(&domain.Product{Id: "prod-2", Quantity: 50})
+
+	tests := []struct {
+		name      string
+		status    string
+		wantCount int
+		wantErr   error
+	}{
+		{"all", "", 2, nil},
+		{"open", "open", 1, nil},
+		{"resolved", "resolved", 1, nil},
+		{"unknown_status", "snoozed", 0, domain.ErrAlertInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := service.ListAlerts(tt.status)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListAlerts() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(list) != tt.wantCount {
+				t.Errorf("ListAlerts() returned %d alerts, want %d", len(list), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestAlertService_AcknowledgeAlert(t *testing.T) {
+	alerts := newMockAlertRepository()
+	service := NewAlertService(alerts, &countingNotifier{}, testLowStockThreshold, 0)
+	service.CheckStockLevel(&domain.Product{Id: "prod-1", Quantity: 1})
+	alertId := alerts.alerts[0].Id
+
+	tests := []struct {
+		name      string
+		id        string
+		wantErr   error
+		expectErr bool
+	}{
+		{"success", alertId, nil, false},
+		{"already_acknowledged", alertId, nil, false},
+		{"not_found", "missing", domain.ErrAlertNotFound, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domain.ContextWithManagerId(context.Background(), "manager-1")
+			alert, err := service.AcknowledgeAlert(ctx, tt.id)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("AcknowledgeAlert() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("AcknowledgeAlert() error = %v, want %v", err, tt.wantErr)
+			}
+			if !tt.expectErr && (alert.Status != domain.AlertAcknowledged || alert.AcknowledgedBy != "manager-1") {
+				t.Errorf("AcknowledgeAlert() = %+v", alert)
+			}
+		})
+	}
+}
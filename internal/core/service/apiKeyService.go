@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+// lastUsedResolution limits how often a busy key writes its last-used time.
+const lastUsedResolution = time.Minute
+
+type apiKeyService struct {
+	repo ports.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewAPIKeyService(repo ports.APIKeyRepository) APIKeyService {
+	return &apiKeyService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, name string, permissions []domain.Permission, productIds []string,
+	expiresAt *time.Time) (*domain.APIKey, string, error) {
+	key, raw, err := domain.NewAPIKey(name, permissions, productIds, domain.ManagerIdFromContext(ctx), s.now(), expiresAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create api key: %w", err)
+	}
+	if err := s.repo.SaveAPIKey(key); err != nil {
+		return nil, "", fmt.Errorf("failed to save api key: %w", err)
+	}
+	return key, raw, nil
+}
+
+func (s *apiKeyService) ListAPIKeys() ([]domain.APIKey, error) {
+	keys, err := s.repo.ListAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(id string) error {
+	if err := s.repo.RevokeAPIKey(id, s.now()); err != nil {
+		return fmt.Errorf("failed to revoke api key %s: %w", id, err)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey turns a presented key into the principal it acts as.
+// Unknown, revoked and expired keys all look the same to the caller.
+func (s *apiKeyService) AuthenticateAPIKey(raw string) (*domain.Principal, error) {
+	prefix, secret, ok := domain.SplitAPIKey(raw)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	key, err := s.repo.FindAPIKeyByPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	now := s.now()
+	if !key.CheckSecret(secret) || !key.IsActive(now) {
+		return nil, domain.ErrUnauthorized
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchAPIKey(key.Id, now); err != nil {
+			log.Printf("could not record use of api key %s: %v", key.Id, err)
+		}
+	}
+
+	principal := key.Principal()
+	return &principal, nil
+}
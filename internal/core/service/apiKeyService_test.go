@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type mockAPIKeyRepository struct {
+	mu      sync.Mutex
+	keys    map[string]*domain.APIKey
+	touches int
+}
+
+func newMockAPIKeyRepository() *mockAPIKeyRepository {
+	return &mockAPIKeyRepository{keys: make(map[string]*domain.APIKey)}
+}
+
+func (m *mockAPIKeyRepository) SaveAPIKey(key *domain.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[key.Id] = key
+	return nil
+}
+
+func (m *mockAPIKeyRepository) FindAPIKeyByPrefix(prefix string) (*domain.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range m.keys {
+		if key.Prefix == prefix {
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyRepository) ListAPIKeys() ([]domain.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := []domain.APIKey{}
+	for _, key := range m.keys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepository) RevokeAPIKey(id string, revokedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, ok := m.keys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+	key.RevokedAt = &revokedAt
+	return nil
+}
+
+func (m *mockAPIKeyRepository) TouchAPIKey(id string, usedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[id].LastUsedAt = &usedAt
+	m.touches++
+	return nil
+}
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		keyName     string
+		permissions []domain.Permission
+		expiresAt   *time.Time
+		wantErr     error
+	}{
+		{"success", "till 1", []domain.Permission{domain.PermStockSell}, nil, nil},
+		{"fail_no_name", " ", []domain.Permission{domain.PermStockSell}, nil, domain.ErrAPIKeyInvalid},
+		{"fail_no_permissions", "till 1", nil, nil, domain.ErrAPIKeyInvalid},
+		{"fail_unknown_permission", "till 1", []domain.Permission{"stock:steal"}, nil, domain.ErrAPIKeyInvalid},
+		{"fail_manage_managers", "till 1", []domain.Permission{domain.PermManagersManage}, nil, domain.ErrAPIKeyInvalid},
+		{"fail_expired", "till 1", []domain.Permission{domain.PermStockSell}, &past, domain.ErrAPIKeyInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockAPIKeyRepository()
+			service := NewAPIKeyService(repo)
+			ctx := domain.ContextWithManagerId(context.Background(), "admin-1")
+
+			key, raw, err := service.CreateAPIKey(ctx, tt.keyName, tt.permissions, []string{"p1", "p1", ""}, tt.expiresAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateAPIKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if repo.keys[key.Id] == nil || key.CreatedBy != "admin-1" || len(key.ProductIds) != 1 {
+				t.Errorf("CreateAPIKey() = %+v", key)
+			}
+			if prefix, _, ok := domain.SplitAPIKey(raw); !ok || prefix != key.Prefix {
+				t.Errorf("CreateAPIKey() raw key %q does not match prefix %q", raw, key.Prefix)
+			}
+		})
+	}
+}
+
+func TestAPIKeyService_AuthenticateAPIKey(t *testing.T) {
+	repo := newMockAPIKeyRepository()
+	service := NewAPIKeyService(repo)
+	ctx := domain.ContextWithManagerId(context.Background(), "admin-1")
+	expiry := time.Now().Add(time.Hour)
+
+	active, activeRaw, _ := service.CreateAPIKey(ctx, "till 1", []domain.Permission{domain.PermStockSell}, []string{"p1"}, nil)
+	revoked, revokedRaw, _ := service.CreateAPIKey(ctx, "till 2", []domain.Permission{domain.PermStockSell}, nil, nil)
+	service.RevokeAPIKey(revoked.Id)
+	expired, expiredRaw, _ := service.CreateAPIKey(ctx, "till 3", []domain.Permission{domain.PermStockSell}, nil, &expiry)
+	past := time.Now().Add(-time.Minute)
+	repo.keys[expired.Id].ExpiresAt = &past
+	prefix, _, _ := domain.SplitAPIKey(activeRaw)
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{"success", activeRaw, nil},
+		{"fail_malformed", "not-a-key", domain.ErrUnauthorized},
+		{"fail_unknown_prefix", "ik_000000000000.secret", domain.ErrUnauthorized},
+		{"fail_wrong_secret", prefix + ".wrong", domain.ErrUnauthorized},
+		{"fail_revoked", revokedRaw, domain.ErrUnauthorized},
+		{"fail_expired", expiredRaw, domain.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := service.AuthenticateAPIKey(tt.raw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthenticateAPIKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (principal.APIKeyId != active.Id || !principal.Can(domain.PermStockSell) ||
+				principal.Can(domain.PermStockRestock) || !principal.CanAccessProduct("p1") || principal.CanAccessProduct("p2")) {
+				t.Errorf("AuthenticateAPIKey() = %+v", principal)
+			}
+		})
+	}
+
+	service.AuthenticateAPIKey(activeRaw)
+	if repo.touches != 1 || repo.keys[active.Id].LastUsedAt == nil {
+		t.Errorf("AuthenticateAPIKey() recorded %d uses, want 1 within a minute", repo.touches)
+	}
+}
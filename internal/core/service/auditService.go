@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+type auditService struct {
+	repo ports.AuditRepository
+}
+
+func NewAuditService(repo ports.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// ListAuditEntries returns the newest matching entries first.
+func (s *auditService) ListAuditEntries(query domain.AuditQuery) ([]domain.AuditEntry, error) {
+	if query.Limit == 0 {
+		query.Limit = domain.DefaultAuditPageSize
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	entries := []domain.AuditEntry{}
+	err := s.repo.WalkAuditEntries(query, true, func(entry domain.AuditEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+// ExportAuditEntries hands every matching entry to visit in the order they
+// were recorded, without holding the whole log in memory.
+func (s *auditService) ExportAuditEntries(query domain.AuditQuery, visit func(domain.AuditEntry) error) error {
+	query.Limit = 0
+	if err := query.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.WalkAuditEntries(query, false, visit); err != nil {
+		return fmt.Errorf("failed to export audit entries: %w", err)
+	}
+	return nil
+}
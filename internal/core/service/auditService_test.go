@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestAuditService_ListAuditEntries(t *testing.T) {
+	repo := &mockAuditRepository{}
+	ctx := domain.ContextWithManagerId(context.Background(), "manager-1")
+	for i := 0; i < 3; i++ {
+		repo.RecordAudit(domain.NewAuditEntry(ctx, domain.AuditStockSold, "prod-1", nil, nil))
+	}
+	repo.RecordAudit(domain.NewAuditEntry(ctx, domain.AuditPriceChanged, "prod-2", nil, nil))
+
+	tests := []struct {
+		name      string
+		query     domain.AuditQuery
+		wantCount int
+		wantErr   error
+	}{
+		{"all", domain.AuditQuery{}, 4, nil},
+		{"by_product", domain.AuditQuery{ProductId: "prod-1"}, 3, nil},
+		{"limited", domain.AuditQuery{Limit: 2}, 2, nil},
+		{"fail_unknown_action", domain.AuditQuery{Action: "product.renamed"}, 0, domain.ErrInvalidQuery},
+		{"fail_limit_too_large", domain.AuditQuery{Limit: domain.MaxAuditPageSize + 1}, 0, domain.ErrInvalidQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := NewAuditService(repo).ListAuditEntries(tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListAuditEntries() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(entries) != tt.wantCount {
+				t.Errorf("ListAuditEntries() returned %d entries, want %d", len(entries), tt.wantCount)
+			}
+		})
+	}
+
+	entries, _ := NewAuditService(repo).ListAuditEntries(domain.AuditQuery{})
+	if entries[0].Action != domain.AuditPriceChanged {
+		t.Errorf("ListAuditEntries() did not return the newest entry first: %+v", entries[0])
+	}
+}
+
+func TestAuditService_ExportAuditEntries(t *testing.T) {
+	repo := &mockAuditRepository{}
+	ctx := domain.ContextWithManagerId(context.Background(), "manager-1")
+	for i := 0; i < domain.MaxAuditPageSize+5; i++ {
+		repo.RecordAudit(domain.NewAuditEntry(ctx, domain.AuditStockSold, "prod-1", nil, nil))
+	}
+
+	count := 0
+	err := NewAuditService(repo).ExportAuditEntries(domain.AuditQuery{Limit: 10}, func(entry domain.AuditEntry) error {
+		count++
+		return nil
+	})
+	if err != nil || count != domain.MaxAuditPageSize+5 {
+		t.Errorf("ExportAuditEntries() visited %d entries, %v, want all %d", count, err, domain.MaxAuditPageSize+5)
+	}
+
+	stop := errors.New("client went away")
+	err = NewAuditService(repo).ExportAuditEntries(domain.AuditQuery{}, func(entry domain.AuditEntry) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("ExportAuditEntries() error = %v, want the visitor's error", err)
+	}
+}
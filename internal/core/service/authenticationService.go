@@ -1,36 +1,433 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
 	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
 )
 
 type authService struct {
-	repo           ports.ManagerRepository
-	tokenGenerator ports.TokenGenerator
+	repo            ports.ManagerRepository
+	tokenGenerator  ports.TokenGenerator
+	challenges      ports.MFAChallengeIssuer
+	revocations     ports.RevocationStore
+	refreshTokens   ports.RefreshTokenRepository
+	recoveryCodes   ports.RecoveryCodeRepository
+	loginAttempts   ports.LoginAttemptTracker
+	refreshTokenTTL time.Duration
+	loginPolicy     domain.LoginPolicy
+	now             func() time.Time
 }
 
-func NewAuthService(repo ports.ManagerRepository, tokenGenerator ports.TokenGenerator) AuthService {
+func NewAuthService(repo ports.ManagerRepository, tokenGenerator ports.TokenGenerator, challenges ports.MFAChallengeIssuer,
+	revocations ports.RevocationStore, refreshTokens ports.RefreshTokenRepository, recoveryCodes ports.RecoveryCodeRepository,
+	loginAttempts ports.LoginAttemptTracker, refreshTokenTTL time.Duration, loginPolicy domain.LoginPolicy) AuthService {
 	return &authService{
-		repo:           repo,
-		tokenGenerator: tokenGenerator,
+		repo:            repo,
+		tokenGenerator:  tokenGenerator,
+		challenges:      challenges,
+		revocations:     revocations,
+		refreshTokens:   refreshTokens,
+		recoveryCodes:   recoveryCodes,
+		loginAttempts:   loginAttempts,
+		refreshTokenTTL: refreshTokenTTL,
+		loginPolicy:     loginPolicy,
+		now:             time.Now,
 	}
 }
 
-func (s *authService) Login(email, password string) (string, error) {
+// authenticate checks a password, refusing to even look at it while the email
+// or the client address is throttled. Every outcome lands in the auth audit. A
+// right password for a manager with two-factor authentication is not yet a
+// success, so it does not clear earlier failures of the email.
+func (s *authService) authenticate(email, password, clientIP string) (*domain.Manager, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	now := s.now()
+	if err := s.checkThrottle(email, clientIP, now); err != nil {
+		return nil, err
+	}
+
 	manager, err := s.repo.FindByEmail(email)
 	if err != nil {
-		return "", domain.ErrInvalidCredentials
+		s.recordAttempt(email, clientIP, domain.LoginFailed, "unknown email", now)
+		return nil, domain.ErrInvalidCredentials
 	}
 
 	if err := manager.CheckPassword(password); err != nil {
-		return "", domain.ErrInvalidCredentials
+		s.recordAttempt(email, clientIP, domain.LoginFailed, "wrong password", now)
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	if manager.Disabled {
+		s.recordAttempt(email, clientIP, domain.LoginFailed, "account disabled", now)
+		return nil, domain.ErrAccountDisabled
+	}
+	if manager.TOTPEnabled {
+		s.recordAttempt(email, clientIP, domain.LoginChallenged, "", now)
+	} else {
+		s.recordAttempt(email, clientIP, domain.LoginSucceeded, "", now)
+	}
+	return manager, nil
+}
+
+func (s *authService) checkThrottle(email, clientIP string, now time.Time) error {
+	emailFailures, err := s.loginAttempts.EmailFailures(email, now.Add(-s.loginPolicy.EmailLookback()))
+	if err != nil {
+		return fmt.Errorf("could not check login attempts: %w", err)
+	}
+	ipFailures, err := s.loginAttempts.IPFailures(clientIP, now.Add(-s.loginPolicy.Window))
+	if err != nil {
+		return fmt.Errorf("could not check login attempts: %w", err)
+	}
+
+	if wait := s.loginPolicy.RetryAfter(emailFailures, ipFailures, now); wait > 0 {
+		s.recordAttempt(email, clientIP, domain.LoginThrottled, "", now)
+		return &domain.LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (s *authService) recordAttempt(email, clientIP string, outcome domain.LoginOutcome, reason string, at time.Time) {
+	attempt := domain.NewLoginAttempt(email, clientIP, outcome, reason, at)
+	if err := s.loginAttempts.RecordLoginAttempt(attempt); err != nil {
+		log.Printf("could not record login attempt for %s: %v", email, err)
+	}
+}
+
+func (s *authService) Login(email, password, clientIP string) (*domain.LoginResult, error) {
+	manager, err := s.authenticate(email, password, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	if manager.MustChangePassword {
+		return nil, domain.ErrPasswordChangeRequired
+	}
+
+	return s.completeLogin(manager)
+}
+
+// completeLogin hands out tokens, or only a challenge when the manager still
+// has to present a second factor.
+func (s *authService) completeLogin(manager *domain.Manager) (*domain.LoginResult, error) {
+	if !manager.TOTPEnabled {
+		pair, err := s.issueTokens(manager, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LoginResult{Tokens: pair}, nil
+	}
+
+	token, challenge, err := s.challenges.IssueMFAChallenge(manager)
+	if err != nil {
+		return nil, domain.ErrTokenGeneration
+	}
+	return &domain.LoginResult{MFAToken: token, MFAExpiresAt: challenge.ExpiresAt}, nil
+}
+
+func (s *authService) VerifyMFA(mfaToken, code, clientIP string) (*domain.TokenPair, error) {
+	challenge, err := s.challenges.ParseMFAChallenge(mfaToken)
+	if err != nil {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+	used, err := s.revocations.IsTokenRevoked(challenge.Id)
+	if err != nil {
+		return nil, fmt.Errorf("could not check mfa challenge: %w", err)
+	}
+	if used {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+
+	manager, err := s.repo.FindManagerById(challenge.ManagerId)
+	if err != nil {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+	if manager.Disabled {
+		return nil, domain.ErrAccountDisabled
+	}
+	if !manager.TOTPEnabled {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+
+	now := s.now()
+	if err := s.checkThrottle(manager.Email, clientIP, now); err != nil {
+		return nil, err
+	}
+	if err := s.checkLoginSecondFactor(manager, code, clientIP, now); err != nil {
+		return nil, err
 	}
 
-	token, err := s.tokenGenerator.GenerateToken(manager)
+	consumed, err := s.revocations.ConsumeToken(challenge.Id, challenge.ExpiresAt)
 	if err != nil {
-		return "", domain.ErrTokenGeneration
+		return nil, fmt.Errorf("could not use up mfa challenge: %w", err)
+	}
+	if !consumed {
+		return nil, domain.ErrMFAChallengeInvalid
 	}
+	s.recordAttempt(manager.Email, clientIP, domain.LoginSucceeded, "", now)
+	return s.issueTokens(manager, nil)
+}
 
-	return token, nil
+// checkLoginSecondFactor is checkSecondFactor during a login, where a wrong
+// code counts as a failed attempt.
+func (s *authService) checkLoginSecondFactor(manager *domain.Manager, code, clientIP string, now time.Time) error {
+	err := s.checkSecondFactor(manager, code, now)
+	if errors.Is(err, domain.ErrMFACodeInvalid) {
+		s.recordAttempt(manager.Email, clientIP, domain.LoginFailed, "wrong verification code", now)
+	}
+	return err
+}
+
+// checkSecondFactor accepts a current TOTP code or an unused recovery code.
+// The TOTP step is only recorded if no other request used it or a later one
+// first, so a code cannot be replayed even by concurrent requests.
+func (s *authService) checkSecondFactor(manager *domain.Manager, code string, now time.Time) error {
+	code = strings.TrimSpace(code)
+	if manager.CheckTOTP(code, now) {
+		if err := s.repo.RecordTOTPStep(manager.Id, manager.TOTPLastStep); err != nil {
+			if errors.Is(err, domain.ErrMFACodeInvalid) {
+				return err
+			}
+			return fmt.Errorf("could not save totp state: %w", err)
+		}
+		return nil
+	}
+	if code == "" || len(code) == domain.TOTPDigits {
+		return domain.ErrMFACodeInvalid
+	}
+	if err := s.recoveryCodes.UseRecoveryCode(manager.Id, domain.HashRecoveryCode(code), now); err != nil {
+		if errors.Is(err, domain.ErrMFACodeInvalid) {
+			return err
+		}
+		return fmt.Errorf("could not check recovery code: %w", err)
+	}
+	return nil
+}
+
+func (s *authService) EnrollTOTP(ctx context.Context) (*domain.TOTPEnrollment, error) {
+	manager, err := s.currentManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := manager.StartTOTPEnrollment()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateManagerTOTP(manager); err != nil {
+		return nil, fmt.Errorf("could not save totp secret: %w", err)
+	}
+	return enrollment, nil
+}
+
+// ConfirmTOTP switches two-factor authentication on once the manager proves
+// their app produces codes, and returns fresh recovery codes in plaintext.
+// They are not shown again.
+func (s *authService) ConfirmTOTP(ctx context.Context, code string) ([]string, error) {
+	manager, err := s.currentManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if manager.TOTPEnabled {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	if manager.TOTPSecret == "" {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	if !manager.CheckTOTP(strings.TrimSpace(code), s.now()) {
+		return nil, domain.ErrMFACodeInvalid
+	}
+
+	codes, hashes, err := domain.NewRecoveryCodes()
+	if err != nil {
+		return nil, domain.ErrTokenGeneration
+	}
+	if err := s.recoveryCodes.ReplaceRecoveryCodes(manager.Id, hashes); err != nil {
+		return nil, fmt.Errorf("could not save recovery codes: %w", err)
+	}
+	manager.TOTPEnabled = true
+	if err := s.repo.UpdateManagerTOTP(manager); err != nil {
+		return nil, fmt.Errorf("could not enable totp: %w", err)
+	}
+	return codes, nil
+}
+
+func (s *authService) DisableTOTP(ctx context.Context, code string) error {
+	manager, err := s.currentManager(ctx)
+	if err != nil {
+		return err
+	}
+	if !manager.TOTPEnabled {
+		return domain.ErrMFANotEnrolled
+	}
+	if err := s.checkSecondFactor(manager, code, s.now()); err != nil {
+		return err
+	}
+
+	manager.DisableTOTP()
+	if err := s.repo.UpdateManagerTOTP(manager); err != nil {
+		return fmt.Errorf("could not disable totp: %w", err)
+	}
+	if err := s.recoveryCodes.ReplaceRecoveryCodes(manager.Id, nil); err != nil {
+		return fmt.Errorf("could not drop recovery codes: %w", err)
+	}
+	return nil
+}
+
+func (s *authService) currentManager(ctx context.Context) (*domain.Manager, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || principal.ManagerId == "" {
+		return nil, domain.ErrUnauthorized
+	}
+	manager, err := s.repo.FindManagerById(principal.ManagerId)
+	if err != nil {
+		if errors.Is(err, domain.ErrManagerNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+	return manager, nil
+}
+
+// ChangePassword needs a second factor from managers who enabled one, so a
+// phished password alone cannot lock the owner out. It ends every existing
+// session and, the second factor already checked, returns tokens.
+func (s *authService) ChangePassword(email, currentPassword, newPassword, code, clientIP string) (*domain.LoginResult, error) {
+	manager, err := s.authenticate(email, currentPassword, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	if newPassword == currentPassword {
+		return nil, fmt.Errorf("%w: new password must differ from the current one", domain.ErrManagerInvalid)
+	}
+	if err := manager.SetPassword(newPassword); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if manager.TOTPEnabled {
+		if err := s.checkLoginSecondFactor(manager, code, clientIP, now); err != nil {
+			return nil, err
+		}
+		s.recordAttempt(manager.Email, clientIP, domain.LoginSucceeded, "", now)
+	}
+	if err := s.repo.UpdateManager(manager); err != nil {
+		return nil, fmt.Errorf("could not save the new password: %w", err)
+	}
+	if err := s.refreshTokens.RevokeManagerRefreshTokens(manager.Id, now); err != nil {
+		return nil, fmt.Errorf("could not end existing sessions: %w", err)
+	}
+	if err := s.revocations.RevokeManagerTokens(manager.Id, tokenCutoff(now), now.Add(s.tokenGenerator.TokenTTL())); err != nil {
+		return nil, fmt.Errorf("could not end existing sessions: %w", err)
+	}
+
+	pair, err := s.issueTokens(manager, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{Tokens: pair}, nil
+}
+
+func (s *authService) Refresh(refreshToken string) (*domain.TokenPair, error) {
+	current, err := s.refreshTokens.FindRefreshToken(domain.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not look up refresh token: %w", err)
+	}
+
+	if current.RotatedAt != nil {
+		s.revokeFamily(current)
+		return nil, domain.ErrRefreshTokenReused
+	}
+	if !current.IsActive(s.now()) {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+
+	manager, err := s.repo.FindManagerById(current.ManagerId)
+	if err != nil {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+	if manager.Disabled {
+		s.revokeFamily(current)
+		return nil, domain.ErrAccountDisabled
+	}
+	if manager.MustChangePassword {
+		return nil, domain.ErrPasswordChangeRequired
+	}
+
+	pair, err := s.issueTokens(manager, current)
+	if errors.Is(err, domain.ErrRefreshTokenReused) {
+		s.revokeFamily(current)
+	}
+	return pair, err
+}
+
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || principal.TokenId == "" {
+		return domain.ErrUnauthorized
+	}
+
+	if err := s.revocations.RevokeToken(principal.TokenId, principal.ExpiresAt); err != nil {
+		return fmt.Errorf("could not revoke token: %w", err)
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	current, err := s.refreshTokens.FindRefreshToken(domain.HashRefreshToken(refreshToken))
+	if err != nil || current.ManagerId != principal.ManagerId {
+		return nil
+	}
+	if err := s.refreshTokens.RevokeRefreshTokenFamily(current.FamilyId, s.now()); err != nil {
+		return fmt.Errorf("could not revoke refresh token: %w", err)
+	}
+	return nil
+}
+
+// issueTokens starts a new refresh token family when current is nil and
+// otherwise rotates current within its family.
+func (s *authService) issueTokens(manager *domain.Manager, current *domain.RefreshToken) (*domain.TokenPair, error) {
+	now := s.now()
+	familyId := ""
+	if current != nil {
+		familyId = current.FamilyId
+	}
+
+	refresh, raw, err := domain.NewRefreshToken(manager.Id, familyId, now, s.refreshTokenTTL)
+	if err != nil {
+		return nil, domain.ErrTokenGeneration
+	}
+	if current == nil {
+		err = s.refreshTokens.SaveRefreshToken(refresh)
+	} else {
+		err = s.refreshTokens.RotateRefreshToken(current.Id, refresh, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.tokenGenerator.GenerateToken(manager)
+	if err != nil {
+		return nil, domain.ErrTokenGeneration
+	}
+
+	return &domain.TokenPair{
+		AccessToken:     accessToken,
+		AccessExpiresAt: now.Add(s.tokenGenerator.TokenTTL()),
+		RefreshToken:    raw,
+	}, nil
+}
+
+func (s *authService) revokeFamily(token *domain.RefreshToken) {
+	if err := s.refreshTokens.RevokeRefreshTokenFamily(token.FamilyId, s.now()); err != nil {
+		log.Printf("could not revoke refresh token family %s: %v", token.FamilyId, err)
+	}
 }
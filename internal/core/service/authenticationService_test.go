@@ -0,0 +1,629 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/utils/auth"
+)
+
+type mockManagerRepository struct {
+	managers    map[string]*domain.Manager
+	shouldError bool
+}
+
+func newMockManagerRepository(managers ...*domain.Manager) *mockManagerRepository {
+	repo := &mockManagerRepository{managers: make(map[string]*domain.Manager)}
+	for _, manager := range managers {
+		repo.managers[manager.Id] = manager
+	}
+	return repo
+}
+
+func (m *mockManagerRepository) FindByEmail(email string) (*domain.Manager, error) {
+	for _, manager := range m.managers {
+		if manager.Email == email {
+			clone := *manager
+			return &clone, nil
+		}
+	}
+	return nil, domain.ErrInvalidCredentials
+}
+
+func (m *mockManagerRepository) FindManagerById(id string) (*domain.Manager, error) {
+	manager, ok := m.managers[id]
+	if !ok {
+		return nil, domain.ErrManagerNotFound
+	}
+	clone := *manager
+	return &clone, nil
+}
+
+func (m *mockManagerRepository) ListManagers() ([]domain.Manager, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	managers := []domain.Manager{}
+	for _, manager := range m.managers {
+		managers = append(managers, *manager)
+	}
+	return managers, nil
+}
+
+func (m *mockManagerRepository) SaveManager(manager *domain.Manager) error {
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	for _, existing := range m.managers {
+		if existing.Email == manager.Email {
+			return domain.ErrManagerExists
+		}
+	}
+	m.managers[manager.Id] = manager
+	return nil
+}
+
+func (m *mockManagerRepository) UpdateManager(manager *domain.Manager) error {
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	if _, ok := m.managers[manager.Id]; !ok {
+		return domain.ErrManagerNotFound
+	}
+	clone := *manager
+	m.managers[manager.Id] = &clone
+	return nil
+}
+
+func (m *mockManagerRepository) UpdateManagerTOTP(manager *domain.Manager) error {
+	stored, ok := m.managers[manager.Id]
+	if !ok {
+		return domain.ErrManagerNotFound
+	}
+	stored.TOTPSecret, stored.TOTPEnabled, stored.TOTPLastStep = manager.TOTPSecret, manager.TOTPEnabled, manager.TOTPLastStep
+	return nil
+}
+
+func (m *mockManagerRepository) RecordTOTPStep(managerId string, step int64) error {
+	stored, ok := m.managers[managerId]
+	if !ok || stored.TOTPLastStep >= step {
+		return domain.ErrMFACodeInvalid
+	}
+	stored.TOTPLastStep = step
+	return nil
+}
+
+func (m *mockManagerRepository) DeleteManager(id string) error {
+	if _, ok := m.managers[id]; !ok {
+		return domain.ErrManagerNotFound
+	}
+	delete(m.managers, id)
+	return nil
+}
+
+type mockTokenGenerator struct{}
+
+func (m *mockTokenGenerator) GenerateToken(manager *domain.Manager) (string, error) {
+	return "token-for-" + manager.Id, nil
+}
+
+func (m *mockTokenGenerator) TokenTTL() time.Duration {
+	return 15 * time.Minute
+}
+
+type mockRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*domain.RefreshToken
+}
+
+func newMockRefreshTokenRepository() *mockRefreshTokenRepository {
+	return &mockRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}
+}
+
+func (m *mockRefreshTokenRepository) SaveRefreshToken(token *domain.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clone := *token
+	m.tokens[token.TokenHash] = &clone
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) FindRefreshToken(tokenHash string) (*domain.RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token, ok := m.tokens[tokenHash]
+	if !ok {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+	clone := *token
+	return &clone, nil
+}
+
+func (m *mockRefreshTokenRepository) RotateRefreshToken(currentId string, next *domain.RefreshToken, rotatedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, token := range m.tokens {
+		if token.Id == currentId {
+			if token.RotatedAt != nil || token.RevokedAt != nil {
+				return domain.ErrRefreshTokenReused
+			}
+			token.RotatedAt = &rotatedAt
+			clone := *next
+			m.tokens[next.TokenHash] = &clone
+			return nil
+		}
+	}
+	return domain.ErrRefreshTokenReused
+}
+
+func (m *mockRefreshTokenRepository) revokeWhere(match func(*domain.RefreshToken) bool, revokedAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, token := range m.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+		}
+	}
+}
+
+func (m *mockRefreshTokenRepository) RevokeRefreshTokenFamily(familyId string, revokedAt time.Time) error {
+	m.revokeWhere(func(token *domain.RefreshToken) bool { return token.FamilyId == familyId }, revokedAt)
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeManagerRefreshTokens(managerId string, revokedAt time.Time) error {
+	m.revokeWhere(func(token *domain.RefreshToken) bool { return token.ManagerId == managerId }, revokedAt)
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) PruneRefreshTokens(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockRefreshTokenRepository) activeCount(managerId string, now time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, token := range m.tokens {
+		if token.ManagerId == managerId && token.IsActive(now) {
+			count++
+		}
+	}
+	return count
+}
+
+type mockRecoveryCodeRepository struct {
+	mu     sync.Mutex
+	unused map[string][]string
+}
+
+func newMockRecoveryCodeRepository() *mockRecoveryCodeRepository {
+	return &mockRecoveryCodeRepository{unused: make(map[string][]string)}
+}
+
+func (m *mockRecoveryCodeRepository) ReplaceRecoveryCodes(managerId string, codeHashes []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unused[managerId] = append([]string(nil), codeHashes...)
+	return nil
+}
+
+func (m *mockRecoveryCodeRepository) UseRecoveryCode(managerId, codeHash string, usedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, unused := range m.unused[managerId] {
+		if unused == codeHash {
+			m.unused[managerId] = append(m.unused[managerId][:i], m.unused[managerId][i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrMFACodeInvalid
+}
+
+var testKeyRing, _ = auth.NewKeyRing(auth.NewHMACKey("test", []byte("auth-service-test-secret")))
+
+var testChallenges = auth.NewJWTGenerator(testKeyRing, time.Hour, 5*time.Minute)
+
+const testClientIP = "10.0.0.1"
+
+func newTestAuthService(repo *mockManagerRepository, refreshTokens *mockRefreshTokenRepository) AuthService {
+	return NewAuthService(repo, &mockTokenGenerator{}, testChallenges, auth.NewMemoryRevocationStore(), refreshTokens,
+		newMockRecoveryCodeRepository(), auth.NewMemoryLoginAttemptTracker(), time.Hour, domain.LoginPolicy{})
+}
+
+func newTestManager(t *testing.T, email string, mutate func(*domain.Manager)) *domain.Manager {
+	manager, err := domain.NewManager(email, "correct-password", domain.RoleClerk)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.MustChangePassword = false
+	if mutate != nil {
+		mutate(manager)
+	}
+	return manager
+}
+
+func TestAuthService_Login(t *testing.T) {
+	active := newTestManager(t, "active@example.com", nil)
+	disabled := newTestManager(t, "disabled@example.com", func(m *domain.Manager) { m.Disabled = true })
+	fresh := newTestManager(t, "fresh@example.com", func(m *domain.Manager) { m.MustChangePassword = true })
+	refreshTokens := newMockRefreshTokenRepository()
+	service := newTestAuthService(newMockManagerRepository(active, disabled, fresh), refreshTokens)
+
+	tests := []struct {
+		name      string
+		email     string
+		password  string
+		wantToken string
+		wantErr   error
+	}{
+		{"success", "active@example.com", "correct-password", "token-for-" + active.Id, nil},
+		{"success_email_case_insensitive", " Active@Example.com", "correct-password", "token-for-" + active.Id, nil},
+		{"fail_wrong_password", "active@example.com", "wrong-password", "", domain.ErrInvalidCredentials},
+		{"fail_unknown_email", "nobody@example.com", "correct-password", "", domain.ErrInvalidCredentials},
+		{"fail_disabled", "disabled@example.com", "correct-password", "", domain.ErrAccountDisabled},
+		{"fail_disabled_wrong_password_hides_state", "disabled@example.com", "wrong-password", "", domain.ErrInvalidCredentials},
+		{"fail_must_change_password", "fresh@example.com", "correct-password", "", domain.ErrPasswordChangeRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Login(tt.email, tt.password, testClientIP)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			pair := result.Tokens
+			if pair.AccessToken != tt.wantToken || pair.RefreshToken == "" {
+				t.Errorf("Login() = %+v, want access token %q and a refresh token", pair, tt.wantToken)
+			}
+			if _, err := refreshTokens.FindRefreshToken(domain.HashRefreshToken(pair.RefreshToken)); err != nil {
+				t.Errorf("refresh token was not stored hashed: %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     string
+		newPassword string
+		wantErr     error
+	}{
+		{"success", "correct-password", "brand-new-password", nil},
+		{"fail_wrong_current_password", "wrong-password", "brand-new-password", domain.ErrInvalidCredentials},
+		{"fail_same_password", "correct-password", "correct-password", domain.ErrManagerInvalid},
+		{"fail_short_password", "correct-password", "short", domain.ErrManagerInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, "fresh@example.com", func(m *domain.Manager) { m.MustChangePassword = true })
+			repo := newMockManagerRepository(manager)
+			service := newTestAuthService(repo, newMockRefreshTokenRepository())
+
+			result, err := service.ChangePassword("fresh@example.com", tt.current, tt.newPassword, "", testClientIP)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			stored := repo.managers[manager.Id]
+			if result.Tokens.AccessToken == "" || stored.MustChangePassword || stored.CheckPassword(tt.newPassword) != nil {
+				t.Errorf("ChangePassword() tokens %+v stored %+v", result.Tokens, stored)
+			}
+			if _, err := service.Login("fresh@example.com", tt.newPassword, testClientIP); err != nil {
+				t.Errorf("Login() with the new password returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	manager := newTestManager(t, "clerk@example.com", nil)
+	repo := newMockManagerRepository(manager)
+	refreshTokens := newMockRefreshTokenRepository()
+	service := newTestAuthService(repo, refreshTokens)
+
+	login, err := service.Login("clerk@example.com", "correct-password", testClientIP)
+	if err != nil {
+		t.Fatalf("Login() returned an unexpected error: %v", err)
+	}
+
+	rotated, err := service.Refresh(login.Tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() returned an unexpected error: %v", err)
+	}
+	if rotated.RefreshToken == login.Tokens.RefreshToken || rotated.AccessToken == "" {
+		t.Errorf("Refresh() = %+v, want a new refresh token and an access token", rotated)
+	}
+	if _, err := service.Refresh("not-a-refresh-token"); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Errorf("Refresh() of an unknown token error = %v, want ErrRefreshTokenInvalid", err)
+	}
+
+	if _, err := service.Refresh(login.Tokens.RefreshToken); !errors.Is(err, domain.ErrRefreshTokenReused) {
+		t.Fatalf("Refresh() replaying a rotated token error = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := service.Refresh(rotated.RefreshToken); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Errorf("Refresh() after reuse detection error = %v, want the family to be revoked", err)
+	}
+
+	other, _ := service.Login("clerk@example.com", "correct-password", testClientIP)
+	repo.managers[manager.Id].Disabled = true
+	if _, err := service.Refresh(other.Tokens.RefreshToken); !errors.Is(err, domain.ErrAccountDisabled) {
+		t.Errorf("Refresh() for a disabled manager error = %v, want ErrAccountDisabled", err)
+	}
+	if refreshTokens.activeCount(manager.Id, time.Now()) != 0 {
+		t.Errorf("expected no active refresh tokens left")
+	}
+}
+
+func TestAuthService_RefreshExpired(t *testing.T) {
+	manager := newTestManager(t, "clerk@example.com", nil)
+	service := newTestAuthService(newMockManagerRepository(manager), newMockRefreshTokenRepository()).(*authService)
+
+	login, _ := service.Login("clerk@example.com", "correct-password", testClientIP)
+	service.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := service.Refresh(login.Tokens.RefreshToken); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Errorf("Refresh() of an expired token error = %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestAuthService_LoginThrottling(t *testing.T) {
+	manager := newTestManager(t, "clerk@example.com", nil)
+	attempts := auth.NewMemoryLoginAttemptTracker()
+	policy := domain.LoginPolicy{
+		MaxFailures:      3,
+		LockoutDuration:  15 * time.Minute,
+		Window:           15 * time.Minute,
+		MaxFailuresPerIP: 5,
+		BaseDelay:        time.Second,
+		MaxDelay:         4 * time.Second,
+	}
+	service := NewAuthService(newMockManagerRepository(manager), &mockTokenGenerator{}, testChallenges, auth.NewMemoryRevocationStore(),
+		newMockRefreshTokenRepository(), newMockRecoveryCodeRepository(), attempts, time.Hour, policy).(*authService)
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return clock }
+
+	steps := []struct {
+		name           string
+		advance        time.Duration
+		email          string
+		password       string
+		ip             string
+		wantErr        error
+		wantRetryAfter time.Duration
+	}{
+		{"first_failure", 0, "clerk@example.com", "wrong-password", testClientIP, domain.ErrInvalidCredentials, 0},
+		{"too_soon_after_failure", 500 * time.Millisecond, "clerk@example.com", "correct-password", testClientIP, domain.ErrTooManyAttempts, 500 * time.Millisecond},
+		{"second_failure_after_delay", 500 * time.Millisecond, "clerk@example.com", "wrong-password", testClientIP, domain.ErrInvalidCredentials, 0},
+		{"delay_doubled", time.Second, "clerk@example.com", "wrong-password", testClientIP, domain.ErrTooManyAttempts, time.Second},
+		{"third_failure_locks_account", time.Second, "clerk@example.com", "wrong-password", "10.0.0.2", domain.ErrInvalidCredentials, 0},
+		{"locked_even_with_correct_password", time.Minute, "clerk@example.com", "correct-password", "10.0.0.3", domain.ErrTooManyAttempts, 14 * time.Minute},
+		{"other_email_from_same_ip_allowed", 0, "nobody@example.com", "whatever-pass", testClientIP, domain.ErrInvalidCredentials, 0},
+		{"lockout_expires", 14 * time.Minute, "clerk@example.com", "correct-password", testClientIP, nil, 0},
+		{"success_resets_email_failures", 0, "clerk@example.com", "wrong-password", "10.0.0.4", domain.ErrInvalidCredentials, 0},
+		{"ip_failure_4", 2 * time.Second, "a@example.com", "wrong-password", "10.9.9.9", domain.ErrInvalidCredentials, 0},
+		{"ip_failure_5", 0, "b@example.com", "wrong-password", "10.9.9.9", domain.ErrInvalidCredentials, 0},
+		{"ip_failure_6", 0, "c@example.com", "wrong-password", "10.9.9.9", domain.ErrInvalidCredentials, 0},
+		{"ip_failure_7", 0, "d@example.com", "wrong-password", "10.9.9.9", domain.ErrInvalidCredentials, 0},
+		{"ip_failure_8", 0, "e@example.com", "wrong-password", "10.9.9.9", domain.ErrInvalidCredentials, 0},
+		{"ip_blocked_for_any_email", time.Minute, "clerk@example.com", "correct-password", "10.9.9.9", domain.ErrTooManyAttempts, 14 * time.Minute},
+	}
+
+	for _, step := range steps {
+		clock = clock.Add(step.advance)
+		_, err := service.Login(step.email, step.password, step.ip)
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: Login() error = %v, want %v", step.name, err, step.wantErr)
+		}
+		var throttled *domain.LoginThrottledError
+		if errors.As(err, &throttled) && throttled.RetryAfter != step.wantRetryAfter {
+			t.Errorf("%s: RetryAfter = %v, want %v", step.name, throttled.RetryAfter, step.wantRetryAfter)
+		}
+	}
+
+	if _, err := service.ChangePassword("clerk@example.com", "correct-password", "another-password", "", "10.9.9.9"); !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("ChangePassword() from a blocked address error = %v, want ErrTooManyAttempts", err)
+	}
+}
+
+func totpCodeAt(t *testing.T, secret string, at time.Time) string {
+	code, err := domain.TOTPCode(secret, domain.TOTPStep(at))
+	if err != nil {
+		t.Fatalf("TOTPCode() returned an unexpected error: %v", err)
+	}
+	return code
+}
+
+func TestAuthService_TOTPEnrollmentAndLogin(t *testing.T) {
+	manager := newTestManager(t, "clerk@example.com", nil)
+	repo := newMockManagerRepository(manager)
+	recoveryCodes := newMockRecoveryCodeRepository()
+	service := NewAuthService(repo, &mockTokenGenerator{}, testChallenges, auth.NewMemoryRevocationStore(), newMockRefreshTokenRepository(),
+		recoveryCodes, auth.NewMemoryLoginAttemptTracker(), time.Hour, domain.LoginPolicy{}).(*authService)
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return clock }
+	ctx := domain.ContextWithManagerId(context.Background(), manager.Id)
+
+	if _, err := service.ConfirmTOTP(ctx, "123456"); !errors.Is(err, domain.ErrMFANotEnrolled) {
+		t.Fatalf("ConfirmTOTP() before enrolling error = %v, want ErrMFANotEnrolled", err)
+	}
+	enrollment, err := service.EnrollTOTP(ctx)
+	if err != nil {
+		t.Fatalf("EnrollTOTP() returned an unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/") || !strings.Contains(enrollment.URI, "secret="+enrollment.Secret) {
+		t.Errorf("EnrollTOTP() URI = %q", enrollment.URI)
+	}
+	if result, _ := service.Login("clerk@example.com", "correct-password", testClientIP); result.Tokens == nil {
+		t.Errorf("Login() before confirming the enrollment should still return tokens")
+	}
+
+	if _, err := service.ConfirmTOTP(ctx, "000000"); !errors.Is(err, domain.ErrMFACodeInvalid) {
+		t.Fatalf("ConfirmTOTP() with a wrong code error = %v, want ErrMFACodeInvalid", err)
+	}
+	recovery, err := service.ConfirmTOTP(ctx, totpCodeAt(t, enrollment.Secret, clock))
+	if err != nil || len(recovery) != domain.RecoveryCodeCount {
+		t.Fatalf("ConfirmTOTP() = %v, %v, want %d recovery codes", recovery, err, domain.RecoveryCodeCount)
+	}
+	if _, err := service.EnrollTOTP(ctx); !errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+		t.Errorf("EnrollTOTP() when enabled error = %v, want ErrMFAAlreadyEnabled", err)
+	}
+
+	login := func() string {
+		result, err := service.Login("clerk@example.com", "correct-password", testClientIP)
+		if err != nil || result.Tokens != nil || result.MFAToken == "" {
+			t.Fatalf("Login() = %+v, %v, want only an mfa challenge", result, err)
+		}
+		return result.MFAToken
+	}
+
+	challenge := login()
+	clock = clock.Add(domain.TOTPPeriod)
+	steps := []struct {
+		name      string
+		challenge string
+		code      string
+		wantErr   error
+	}{
+		{"wrong_code", challenge, "000000", domain.ErrMFACodeInvalid},
+		{"forged_challenge", "not-a-token", totpCodeAt(t, enrollment.Secret, clock), domain.ErrMFAChallengeInvalid},
+		{"access_token_as_challenge", "token-for-" + manager.Id, totpCodeAt(t, enrollment.Secret, clock), domain.ErrMFAChallengeInvalid},
+		{"totp_code", challenge, totpCodeAt(t, enrollment.Secret, clock), nil},
+		{"challenge_used_twice", challenge, totpCodeAt(t, enrollment.Secret, clock), domain.ErrMFAChallengeInvalid},
+		{"totp_code_replayed", login(), totpCodeAt(t, enrollment.Secret, clock), domain.ErrMFACodeInvalid},
+		{"recovery_code", login(), strings.ToUpper(recovery[0]), nil},
+		{"recovery_code_used_twice", login(), recovery[0], domain.ErrMFACodeInvalid},
+	}
+	for _, step := range steps {
+		pair, err := service.VerifyMFA(step.challenge, step.code, testClientIP)
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: VerifyMFA() error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if err == nil && pair.AccessToken != "token-for-"+manager.Id {
+			t.Errorf("%s: VerifyMFA() = %+v", step.name, pair)
+		}
+	}
+
+	if err := service.DisableTOTP(ctx, "000000"); !errors.Is(err, domain.ErrMFACodeInvalid) {
+		t.Errorf("DisableTOTP() with a wrong code error = %v, want ErrMFACodeInvalid", err)
+	}
+	if err := service.DisableTOTP(ctx, recovery[1]); err != nil {
+		t.Fatalf("DisableTOTP() returned an unexpected error: %v", err)
+	}
+	if result, err := service.Login("clerk@example.com", "correct-password", testClientIP); err != nil || result.Tokens == nil {
+		t.Errorf("Login() after disabling = %+v, %v, want tokens", result, err)
+	}
+	if err := recoveryCodes.UseRecoveryCode(manager.Id, domain.HashRecoveryCode(recovery[2]), clock); !errors.Is(err, domain.ErrMFACodeInvalid) {
+		t.Errorf("recovery codes should be dropped with the second factor, got %v", err)
+	}
+}
+
+func TestAuthService_ChangePasswordNeedsSecondFactor(t *testing.T) {
+	manager := newTestManager(t, "clerk@example.com", nil)
+	secret, _ := domain.NewTOTPSecret()
+	manager.TOTPSecret = secret
+	manager.TOTPEnabled = true
+	repo := newMockManagerRepository(manager)
+	revocations := auth.NewMemoryRevocationStore()
+	service := NewAuthService(repo, &mockTokenGenerator{}, testChallenges, revocations, newMockRefreshTokenRepository(),
+		newMockRecoveryCodeRepository(), auth.NewMemoryLoginAttemptTracker(), time.Hour, domain.LoginPolicy{})
+	issuedAt := time.Now().Truncate(time.Second).Add(-time.Second)
+
+	for _, code := range []string{"", "000000"} {
+		if _, err := service.ChangePassword("clerk@example.com", "correct-password", "brand-new-password", code, testClientIP); !errors.Is(err, domain.ErrMFACodeInvalid) {
+			t.Errorf("ChangePassword() with code %q error = %v, want ErrMFACodeInvalid", code, err)
+		}
+	}
+	if repo.managers[manager.Id].CheckPassword("correct-password") != nil {
+		t.Fatalf("the password changed without a second factor")
+	}
+
+	result, err := service.ChangePassword("clerk@example.com", "correct-password", "brand-new-password",
+		totpCodeAt(t, secret, time.Now()), testClientIP)
+	if err != nil || result.Tokens == nil {
+		t.Fatalf("ChangePassword() = %+v, %v, want tokens without another challenge", result, err)
+	}
+	if repo.managers[manager.Id].CheckPassword("brand-new-password") != nil {
+		t.Errorf("the new password was not stored")
+	}
+	if revoked, _ := revocations.IsManagerTokenRevoked(manager.Id, issuedAt); !revoked {
+		t.Errorf("access tokens issued before the change should be revoked")
+	}
+	if revoked, _ := revocations.IsManagerTokenRevoked(manager.Id, time.Now().Truncate(time.Second)); revoked {
+		t.Errorf("the access token handed out with the change should stay valid")
+	}
+}
+
+func TestAuthService_MFAFailuresAreThrottled(t *testing.T) {
+	manager := newTestManager(t, "clerk@example.com", nil)
+	secret, _ := domain.NewTOTPSecret()
+	manager.TOTPSecret = secret
+	manager.TOTPEnabled = true
+	policy := domain.LoginPolicy{MaxFailures: 2, LockoutDuration: time.Hour, Window: time.Hour, MaxFailuresPerIP: 100}
+	service := NewAuthService(newMockManagerRepository(manager), &mockTokenGenerator{}, testChallenges, auth.NewMemoryRevocationStore(),
+		newMockRefreshTokenRepository(), newMockRecoveryCodeRepository(), auth.NewMemoryLoginAttemptTracker(), time.Hour, policy)
+
+	result, err := service.Login("clerk@example.com", "correct-password", testClientIP)
+	if err != nil {
+		t.Fatalf("Login() returned an unexpected error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := service.VerifyMFA(result.MFAToken, "000000", testClientIP); !errors.Is(err, domain.ErrMFACodeInvalid) {
+			t.Fatalf("VerifyMFA() error = %v, want ErrMFACodeInvalid", err)
+		}
+	}
+	if _, err := service.Login("clerk@example.com", "correct-password", testClientIP); !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("a right password must not clear failed codes, Login() error = %v, want ErrTooManyAttempts", err)
+	}
+	if _, err := service.VerifyMFA(result.MFAToken, totpCodeAt(t, secret, time.Now()), testClientIP); !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("VerifyMFA() while locked error = %v, want ErrTooManyAttempts", err)
+	}
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	revocations := auth.NewMemoryRevocationStore()
+	refreshTokens := newMockRefreshTokenRepository()
+	service := NewAuthService(newMockManagerRepository(), &mockTokenGenerator{}, testChallenges, revocations, refreshTokens,
+		newMockRecoveryCodeRepository(), auth.NewMemoryLoginAttemptTracker(), time.Hour, domain.LoginPolicy{})
+	refresh, raw, _ := domain.NewRefreshToken("manager-1", "", time.Now(), time.Hour)
+	refreshTokens.SaveRefreshToken(refresh)
+	expiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		refreshToken string
+		wantErr      error
+	}{
+		{"success", domain.ContextWithPrincipal(context.Background(), domain.Principal{ManagerId: "manager-1", TokenId: "jti-1", ExpiresAt: expiresAt}), raw, nil},
+		{"success_unknown_refresh_token", domain.ContextWithPrincipal(context.Background(), domain.Principal{ManagerId: "manager-1", TokenId: "jti-2", ExpiresAt: expiresAt}), "unknown", nil},
+		{"fail_no_principal", context.Background(), "", domain.ErrUnauthorized},
+		{"fail_token_without_id", domain.ContextWithManagerId(context.Background(), "manager-1"), "", domain.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.Logout(tt.ctx, tt.refreshToken); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Logout() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if revoked, _ := revocations.IsTokenRevoked("jti-1"); !revoked {
+		t.Errorf("Logout() did not revoke the token")
+	}
+	if refreshTokens.activeCount("manager-1", time.Now()) != 0 {
+		t.Errorf("Logout() did not revoke the refresh token")
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+type binService struct {
+	bins      ports.BinRepository
+	locations ports.LocationRepository
+	products  ports.ProductRepository
+	now       func() time.Time
+}
+
+func NewBinService(bins ports.BinRepository, locations ports.LocationRepository, products ports.ProductRepository) BinService {
+	return &binService{
+		bins:      bins,
+		locations: locations,
+		products:  products,
+		now:       time.Now,
+	}
+}
+
+func (s *binService) CreateBin(locationId, code string, aisle, shelf int) (*domain.Bin, error) {
+	if _, err := s.locations.FindLocationById(locationId); err != nil {
+		return nil, fmt.Errorf("could not find the location for the bin: %w", err)
+	}
+	bin, err := domain.NewBin(locationId, code, aisle, shelf, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bin: %w", err)
+	}
+	if err := s.bins.SaveBin(bin); err != nil {
+		return nil, fmt.Errorf("failed to save bin: %w", err)
+	}
+	return bin, nil
+}
+
+func (s *binService) ListBins(locationId string) ([]domain.Bin, error) {
+	if _, err := s.locations.FindLocationById(locationId); err != nil {
+		return nil, fmt.Errorf("could not find the location to list its bins: %w", err)
+	}
+	bins, err := s.bins.ListBins(locationId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bins: %w", err)
+	}
+	return bins, nil
+}
+
+func (s *binService) SetBinStock(binId, productId string, quantity int) (*domain.BinStock, error) {
+	if _, err := s.bins.FindBinById(binId); err != nil {
+		return nil, fmt.Errorf("could not find the bin to put stock in: %w", err)
+	}
+	if _, err := s.products.FindById(productId); err != nil {
+		return nil, fmt.Errorf("could not find the product to put in the bin: %w", err)
+	}
+	stock, err := domain.NewBinStock(binId, productId, quantity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update bin stock: %w", err)
+	}
+	if err := s.bins.SetBinStock(stock); err != nil {
+		return nil, fmt.Errorf("could not save the bin stock: %w", err)
+	}
+	return stock, nil
+}
+
+func (s *binService) ProductBins(productId string) ([]domain.BinSlot, error) {
+	if _, err := s.products.FindById(productId); err != nil {
+		return nil, fmt.Errorf("failed to get bins of product %s: %w", productId, err)
+	}
+	slots, err := s.bins.ProductBins(productId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bins of product %s: %w", productId, err)
+	}
+	return slots, nil
+}
+
+// GetPickList plans one walk through a location to collect the requested
+// units. Bins are only trusted up to what the location has on hand, so stock
+// sold since it was put away is not picked twice.
+func (s *binService) GetPickList(locationId string, items []domain.PickRequest) (*domain.PickList, error) {
+	if locationId == "" {
+		locationId = domain.DefaultLocationId
+	}
+	if _, err := s.locations.FindLocationById(locationId); err != nil {
+		if errors.Is(err, domain.ErrLocationNotFound) {
+			return nil, fmt.Errorf("%w: location %s does not exist", domain.ErrPickListInvalid, locationId)
+		}
+		return nil, fmt.Errorf("failed to build pick list: %w", err)
+	}
+	requests, err := domain.NormalizePickRequests(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pick list: %w", err)
+	}
+
+	productIds := make([]string, 0, len(requests))
+	onHand := make(map[string]int, len(requests))
+	for _, request := range requests {
+		level, err := s.locations.FindStockLevel(request.ProductId, locationId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build pick list: %w", err)
+		}
+		productIds = append(productIds, request.ProductId)
+		onHand[request.ProductId] = level.Quantity
+	}
+	slots, err := s.bins.BinSlots(locationId, productIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pick list: %w", err)
+	}
+	return domain.BuildPickList(locationId, requests, slots, onHand), nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type mockBinRepository struct {
+	bins        map[string]*domain.Bin
+	stock       map[[2]string]int
+	shouldError bool
+}
+
+func newMockBinRepository() *mockBinRepository {
+	return &mockBinRepository{
+		bins:  make(map[string]*domain.Bin),
+		stock: make(map[[2]string]int),
+	}
+}
+
+func (m *mockBinRepository) SaveBin(bin *domain.Bin) error {
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	for _, existing := range m.bins {
+		if existing.LocationId == bin.LocationId && existing.Code == bin.Code {
+			return domain.ErrBinExists
+		}
+	}
+	saved := *bin
+	m.bins[bin.Id] = &saved
+	return nil
+}
+
+func (m *mockBinRepository) FindBinById(id string) (*domain.Bin, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	bin, ok := m.bins[id]
+	if !ok {
+		return nil, domain.ErrBinNotFound
+	}
+	found := *bin
+	return &found, nil
+}
+
+func (m *mockBinRepository) ListBins(locationId string) ([]domain.Bin, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	bins := []domain.Bin{}
+	for _, bin := range m.bins {
+		if bin.LocationId == locationId {
+			bins = append(bins, *bin)
+		}
+	}
+	return bins, nil
+}
+
+func (m *mockBinRepository) SetBinStock(stock *domain.BinStock) error {
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	key := [2]string{stock.BinId, stock.ProductId}
+	if stock.Quantity == 0 {
+		delete(m.stock, key)
+		return nil
+	}
+	m.stock[key] = stock.Quantity
+	return nil
+}
+
+func (m *mockBinRepository) slots(match func(bin *domain.Bin, productId string) bool) []domain.BinSlot {
+	slots := []domain.BinSlot{}
+	for key, quantity := range m.stock {
+		bin := m.bins[key[0]]
+		if match(bin, key[1]) {
+			slots = append(slots, domain.BinSlot{BinId: bin.Id, LocationId: bin.LocationId, BinCode: bin.Code,
+				Aisle: bin.Aisle, Shelf: bin.Shelf, ProductId: key[1], Quantity: quantity})
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].Aisle != slots[j].Aisle {
+			return slots[i].Aisle < slots[j].Aisle
+		}
+		if slots[i].Shelf != slots[j].Shelf {
+			return slots[i].Shelf < slots[j].Shelf
+		}
+		return slots[i].BinCode < slots[j].BinCode
+	})
+	return slots
+}
+
+func (m *mockBinRepository) ProductBins(productId string) ([]domain.BinSlot, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	return m.slots(func(_ *domain.Bin, id string) bool { return id == productId }), nil
+}
+
+func (m *mockBinRepository) BinSlots(locationId string, productIds []string) ([]domain.BinSlot, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	wanted := map[string]bool{}
+	for _, id := range productIds {
+		wanted[id] = true
+	}
+	return m.slots(func(bin *domain.Bin, id string) bool { return bin.LocationId == locationId && wanted[id] }), nil
+}
+
+func TestBinService_CreateBin(t *testing.T) {
+	tests := []struct {
+		name       string
+		locationId string
+		code       string
+		aisle      int
+		wantErr    error
+	}{
+		{"valid", domain.DefaultLocationId, "a1-01", 1, nil},
+		{"unknown_location", "nowhere", "A1-01", 1, domain.ErrLocationNotFound},
+		{"taken_code", domain.DefaultLocationId, "TAKEN", 1, domain.ErrBinExists},
+		{"negative_aisle", domain.DefaultLocationId, "A1-02", -1, domain.ErrBinInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bins := newMockBinRepository()
+			service := NewBinService(bins, newMockLocationRepository(), newMockProductRepository())
+			if _, err := service.CreateBin(domain.DefaultLocationId, "taken", 9, 9); err != nil {
+				t.Fatalf("CreateBin() returned an unexpected error: %v", err)
+			}
+
+			bin, err := service.CreateBin(tt.locationId, tt.code, tt.aisle, 1)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("CreateBin() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (bin.Code != "A1-01" || bins.bins[bin.Id] == nil) {
+				t.Errorf("CreateBin() = %+v, want it saved with an upper-case code", bin)
+			}
+		})
+	}
+}
+
+func TestBinService_SetBinStock(t *testing.T) {
+	products := newMockProductRepository()
+	product, _ := domain.CreateNewProduct("Lamp", 10, 8)
+	products.Save(product, nil, nil)
+	bins := newMockBinRepository()
+	service := NewBinService(bins, newMockLocationRepository(), products)
+	bin, _ := service.CreateBin(domain.DefaultLocationId, "A1-01", 1, 1)
+
+	tests := []struct {
+		name      string
+		binId     string
+		productId string
+		quantity  int
+		wantErr   bool
+		errIs     error
+	}{
+		{"valid", bin.Id, product.Id, 4, false, nil},
+		{"unknown_bin", "missing", product.Id, 4, true, domain.ErrBinNotFound},
+		{"unknown_product", bin.Id, "missing", 4, true, nil},
+		{"negative_quantity", bin.Id, product.Id, -1, true, domain.ErrBinInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SetBinStock(tt.binId, tt.productId, tt.quantity)
+			if (err != nil) != tt.wantErr || (tt.errIs != nil && !errors.Is(err, tt.errIs)) {
+				t.Errorf("SetBinStock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	slots, err := service.ProductBins(product.Id)
+	if err != nil || len(slots) != 1 || slots[0].Quantity != 4 || slots[0].BinCode != "A1-01" {
+		t.Errorf("ProductBins() = %+v, %v, want four units in A1-01", slots, err)
+	}
+}
+
+func TestBinService_GetPickList(t *testing.T) {
+	products := newMockProductRepository()
+	bins := newMockBinRepository()
+	locations := newMockLocationRepository()
+	service := NewBinService(bins, locations, products)
+	back, _ := service.CreateBin(domain.DefaultLocationId, "C1-01", 3, 1)
+	front, _ := service.CreateBin(domain.DefaultLocationId, "A2-01", 1, 2)
+	bins.SetBinStock(&domain.BinStock{BinId: back.Id, ProductId: "lamp", Quantity: 5})
+	bins.SetBinStock(&domain.BinStock{BinId: front.Id, ProductId: "lamp", Quantity: 2})
+	bins.SetBinStock(&domain.BinStock{BinId: front.Id, ProductId: "bulb", Quantity: 10})
+	locations.level("lamp", domain.DefaultLocationId).Quantity = 7
+	locations.level("bulb", domain.DefaultLocationId).Quantity = 3
+
+	list, err := service.GetPickList("", []domain.PickRequest{
+		{ProductId: "lamp", Quantity: 2}, {ProductId: "bulb", Quantity: 5}, {ProductId: "lamp", Quantity: 2},
+	})
+	if err != nil {
+		t.Fatalf("GetPickList() returned an unexpected error: %v", err)
+	}
+	if list.LocationId != domain.DefaultLocationId || len(list.Lines) != 3 {
+		t.Fatalf("GetPickList() = %+v, want three lines at the main location", list)
+	}
+	if list.Lines[2].BinId != back.Id || list.Lines[2].Quantity != 2 {
+		t.Errorf("last line = %+v, want the rest of the lamps from the back bin", list.Lines[2])
+	}
+	if len(list.Shortages) != 1 || list.Shortages[0].ProductId != "bulb" || list.Shortages[0].Missing != 2 {
+		t.Errorf("GetPickList() shortages = %+v, want two bulbs missing", list.Shortages)
+	}
+
+	if _, err := service.GetPickList("nowhere", []domain.PickRequest{{ProductId: "lamp", Quantity: 1}}); !errors.Is(err, domain.ErrPickListInvalid) {
+		t.Errorf("GetPickList() at an unknown location error = %v, want ErrPickListInvalid", err)
+	}
+	if _, err := service.GetPickList("", nil); !errors.Is(err, domain.ErrPickListInvalid) {
+		t.Errorf("GetPickList() without items error = %v, want ErrPickListInvalid", err)
+	}
+}
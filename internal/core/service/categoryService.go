@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+type categoryService struct {
+	repo ports.CategoryRepository
+	now  func() time.Time
+}
+
+func NewCategoryService(repo ports.CategoryRepository) CategoryService {
+	return &categoryService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (s *categoryService) CreateCategory(name, parentId string) (*domain.Category, error) {
+	category, err := domain.NewCategory(name, parentId, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	if err := s.checkParent(category); err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	if err := s.repo.SaveCategory(category); err != nil {
+		return nil, fmt.Errorf("failed to save category: %w", err)
+	}
+	return category, nil
+}
+
+func (s *categoryService) GetCategory(id string) (*domain.Category, error) {
+	category, err := s.repo.FindCategoryById(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category %s: %w", id, err)
+	}
+	return category, nil
+}
+
+func (s *categoryService) ListCategories() ([]domain.Category, error) {
+	categories, err := s.repo.ListCategories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return categories, nil
+}
+
+// UpdateCategory renames a category and moves it, together with everything
+// below it, under parentId. An empty parentId moves it to the top level.
+func (s *categoryService) UpdateCategory(id, name, parentId string) (*domain.Category, error) {
+	category, err := s.repo.FindCategoryById(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the category to update: %w", err)
+	}
+	if err := category.Update(name, parentId); err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+	if err := s.checkParent(category); err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+	if err := s.repo.UpdateCategory(category); err != nil {
+		return nil, fmt.Errorf("could not save the updated category: %w", err)
+	}
+	return category, nil
+}
+
+func (s *categoryService) DeleteCategory(id string) error {
+	if err := s.repo.DeleteCategory(id); err != nil {
+		return fmt.Errorf("failed to delete category %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *categoryService) checkParent(category *domain.Category) error {
+	if category.ParentId == "" {
+		return nil
+	}
+	categories, err := s.repo.ListCategories()
+	if err != nil {
+		return err
+	}
+	return domain.CheckCategoryParent(categories, category.Id, category.ParentId)
+}
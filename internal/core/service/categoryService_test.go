@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type mockCategoryRepository struct {
+	categories  map[string]*domain.Category
+	inUse       map[string]bool
+	shouldError bool
+}
+
+func newMockCategoryRepository(categories ...domain.Category) *mockCategoryRepository {
+	m := &mockCategoryRepository{categories: make(map[string]*domain.Category), inUse: make(map[string]bool)}
+	for i := range categories {
+		m.categories[categories[i].Id] = &categories[i]
+	}
+	return m
+}
+
+func (m *mockCategoryRepository) SaveCategory(category *domain.Category) error {
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	for _, existing := range m.categories {
+		if existing.ParentId == category.ParentId && strings.EqualFold(existing.Name, category.Name) {
+			return domain.ErrCategoryExists
+		}
+	}
+	saved := *category
+	m.categories[category.Id] = &saved
+	return nil
+}
+
+func (m *mockCategoryRepository) FindCategoryById(id string) (*domain.Category, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	category, ok := m.categories[id]
+	if !ok {
+		return nil, domain.ErrCategoryNotFound
+	}
+	found := *category
+	return &found, nil
+}
+
+func (m *mockCategoryRepository) ListCategories() ([]domain.Category, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	categories := []domain.Category{}
+	for _, category := range m.categories {
+		categories = append(categories, *category)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	return categories, nil
+}
+
+func (m *mockCategoryRepository) UpdateCategory(category *domain.Category) error {
+	if _, ok := m.categories[category.Id]; !ok {
+		return domain.ErrCategoryNotFound
+	}
+	saved := *category
+	m.categories[category.Id] = &saved
+	return nil
+}
+
+func (m *mockCategoryRepository) DeleteCategory(id string) error {
+	if _, ok := m.categories[id]; !ok {
+		return domain.ErrCategoryNotFound
+	}
+	if m.inUse[id] {
+		return domain.ErrCategoryInUse
+	}
+	delete(m.categories, id)
+	return nil
+}
+
+func TestCategoryService_CreateCategory(t *testing.T) {
+	tests := []struct {
+		name       string
+		catName    string
+		parentId   string
+		repoShould bool
+		wantErr    error
+	}{
+		{"top_level", "Grocery", "", false, nil},
+		{"subcategory", "Cables", "c-electronics", false, nil},
+		{"empty_name", "  ", "", false, domain.ErrCategoryInvalid},
+		{"unknown_parent", "Cables", "c-missing", false, domain.ErrCategoryInvalid},
+		{"duplicate_name", "electronics", "", false, domain.ErrCategoryExists},
+		{"repo_error", "Grocery", "", true, ErrRepoFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockCategoryRepository(domain.Category{Id: "c-electronics", Name: "Electronics"})
+			repo.shouldError = tt.repoShould
+			category, err := NewCategoryService(repo).CreateCategory(tt.catName, tt.parentId)
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("CreateCategory() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (category.ParentId != tt.parentId || repo.categories[category.Id] == nil) {
+				t.Errorf("CreateCategory() = %+v, want it saved under %q", category, tt.parentId)
+			}
+		})
+	}
+}
+
+func TestCategoryService_UpdateCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		parentId string
+		wantErr  error
+	}{
+		{"move_to_top", "c-cables", "", nil},
+		{"move_under_sibling", "c-cables", "c-grocery", nil},
+		{"own_parent", "c-cables", "c-cables", domain.ErrCategoryInvalid},
+		{"below_descendant", "c-electronics", "c-usb", domain.ErrCategoryInvalid},
+		{"unknown_parent", "c-cables", "c-missing", domain.ErrCategoryInvalid},
+		{"unknown_category", "c-missing", "", domain.ErrCategoryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockCategoryRepository(
+				domain.Category{Id: "c-electronics", Name: "Electronics"},
+				domain.Category{Id: "c-cables", Name: "Cables", ParentId: "c-electronics"},
+				domain.Category{Id: "c-usb", Name: "USB", ParentId: "c-cables"},
+				domain.Category{Id: "c-grocery", Name: "Grocery"},
+			)
+			category, err := NewCategoryService(repo).UpdateCategory(tt.id, "Renamed", tt.parentId)
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("UpdateCategory() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (category.Name != "Renamed" || repo.categories[tt.id].ParentId != tt.parentId) {
+				t.Errorf("UpdateCategory() = %+v, want it renamed and under %q", category, tt.parentId)
+			}
+		})
+	}
+}
+
+func TestCategoryService_DeleteCategory(t *testing.T) {
+	repo := newMockCategoryRepository(domain.Category{Id: "c-1", Name: "Grocery"}, domain.Category{Id: "c-2", Name: "Toys"})
+	repo.inUse["c-2"] = true
+	service := NewCategoryService(repo)
+
+	if err := service.DeleteCategory("c-1"); err != nil {
+		t.Errorf("DeleteCategory() error = %v", err)
+	}
+	if err := service.DeleteCategory("c-2"); !errors.Is(err, domain.ErrCategoryInUse) {
+		t.Errorf("DeleteCategory() of a category in use error = %v, want ErrCategoryInUse", err)
+	}
+	if _, err := service.GetCategory("c-1"); !errors.Is(err, domain.ErrCategoryNotFound) {
+		t.Errorf("GetCategory() after delete error = %v, want ErrCategoryNotFound", err)
+	}
+}
@@ -1,34 +1,126 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
 	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
 )
 
 type inventoryService struct {
-	repo     ports.ProductRepository
-	notifier ports.Notifier
+	repo              ports.ProductRepository
+	categories        ports.CategoryRepository
+	locations         ports.LocationRepository
+	lots              ports.LotRepository
+	alerts            AlertService
+	lowStockThreshold int
 }
 
-func NewInventoryService(repo ports.ProductRepository, notifier ports.Notifier) InventoryService {
+func NewInventoryService(repo ports.ProductRepository, categories ports.CategoryRepository, locations ports.LocationRepository,
+	lots ports.LotRepository, alerts AlertService, lowStockThreshold int) InventoryService {
 	return &inventoryService{
-		repo:     repo,
-		notifier: notifier,
+		repo:              repo,
+		categories:        categories,
+		locations:         locations,
+		lots:              lots,
+		alerts:            alerts,
+		lowStockThreshold: lowStockThreshold,
 	}
 }
 
-func (invService *inventoryService) AddProduct(name string, price float64, quantity int) (*domain.Product, error) {
+// maxUnversionedAttempts bounds how often an update sent without an expected
+// version is retried.
+const maxUnversionedAttempts = 3
+
+// retryUnversioned runs update again when a concurrent change, such as a sale,
+// bumped the product version between reading and saving it. Only callers that
+// did not ask for a particular version get the retry; each attempt reloads the
+// product, so nothing the other change wrote is lost.
+func retryUnversioned(expectedVersion int, update func() (*domain.Product, error)) (*domain.Product, error) {
+	for attempt := 1; ; attempt++ {
+		product, err := update()
+		if expectedVersion != 0 || attempt == maxUnversionedAttempts || !errors.Is(err, domain.ErrConflict) {
+			return product, err
+		}
+	}
+}
+
+// audit starts the entry for a change. The repository records it in the
+// change's transaction and fills in After, so a change is never saved
+// without its entry.
+func audit(ctx context.Context, action domain.AuditAction, productId string, before *domain.Product) *domain.AuditEntry {
+	return domain.NewAuditEntry(ctx, action, productId, before, nil)
+}
+
+// requireCategory reports an unknown category as invalid, since the id comes
+// from the request body or query rather than the path.
+func (invService *inventoryService) requireCategory(categoryId string, invalid error) error {
+	if categoryId == "" {
+		return nil
+	}
+	if _, err := invService.categories.FindCategoryById(categoryId); err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			return fmt.Errorf("%w: category %s does not exist", invalid, categoryId)
+		}
+		return err
+	}
+	return nil
+}
+
+// requireLocation resolves the location a stock change applies to, with an
+// empty id meaning the default location.
+func (invService *inventoryService) requireLocation(locationId string) (string, error) {
+	if locationId == "" || locationId == domain.DefaultLocationId {
+		return domain.DefaultLocationId, nil
+	}
+	if _, err := invService.locations.FindLocationById(locationId); err != nil {
+		if errors.Is(err, domain.ErrLocationNotFound) {
+			return "", fmt.Errorf("%w: location %s does not exist", domain.ErrLocationInvalid, locationId)
+		}
+		return "", err
+	}
+	return locationId, nil
+}
+
+// checkLocationStock runs the per-location low stock check after a stock
+// change at that location.
+func (invService *inventoryService) checkLocationStock(productId, locationId string) {
+	level, err := invService.locations.FindStockLevel(productId, locationId)
+	if err != nil {
+		log.Printf("could not check stock of product %s at %s: %v", productId, locationId, err)
+		return
+	}
+	invService.alerts.CheckLocationStock(level)
+}
+
+func (invService *inventoryService) AddProduct(ctx context.Context, name string, price float64, quantity int, reorderPoint int, reorderQuantity int,
+	details domain.ProductDetails) (*domain.Product, error) {
 	product, err := domain.CreateNewProduct(name, price, quantity)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new product : %w", err)
 	}
 
-	if err := invService.repo.Save(product); err != nil {
-		return nil, fmt.Errorf("failed to save product: %w ", err)
+	if err := product.SetReorderThresholds(reorderPoint, reorderQuantity); err != nil {
+		return nil, fmt.Errorf("failed to create new product : %w", err)
 	}
 
+	if err := product.SetDetails(details); err != nil {
+		return nil, fmt.Errorf("failed to create new product : %w", err)
+	}
+
+	if err := invService.requireCategory(product.CategoryId, domain.ErrProductInvalid); err != nil {
+		return nil, fmt.Errorf("failed to create new product : %w", err)
+	}
+
+	movement := domain.NewStockMovement(product.Id, product.Quantity, domain.MovementInitial, domain.ActorFromContext(ctx))
+	if err := invService.repo.Save(product, movement, audit(ctx, domain.AuditProductCreated, product.Id, nil)); err != nil {
+		return nil, fmt.Errorf("failed to save product: %w ", err)
+	}
 	return product, nil
 }
 
@@ -40,88 +132,353 @@ func (invService *inventoryService) GetProduct(id string) (*domain.Product, erro
 	return product, nil
 }
 
-func (invService *inventoryService) SellProductUnits(id string, quantity int) (*domain.Product, error) {
+func (invService *inventoryService) GetProductBySKU(sku string) (*domain.Product, error) {
+	product, err := invService.repo.FindBySKU(strings.ToUpper(strings.TrimSpace(sku)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product with sku %s: %w", sku, err)
+	}
+	return product, nil
+}
+
+func (invService *inventoryService) GetProductByBarcode(barcode string) (*domain.Product, error) {
+	product, err := invService.repo.FindByBarcode(strings.TrimSpace(barcode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product with barcode %s: %w", barcode, err)
+	}
+	return product, nil
+}
+
+func (invService *inventoryService) SellProductUnits(ctx context.Context, id string, locationId string, quantity int, expectedVersion int) (*domain.Product, error) {
+	locationId, err := invService.requireLocation(locationId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sell the product: %w", err)
+	}
+
 	product, err := invService.repo.FindById(id)
 	if err != nil {
 		return nil, fmt.Errorf("could not find the product for sale: %w", err)
 	}
 
+	if err := product.MatchesVersion(expectedVersion); err != nil {
+		return nil, fmt.Errorf("failed to sell the product: %w", err)
+	}
+
+	before := *product
 	if err := product.SellUnits(quantity); err != nil {
 		return nil, fmt.Errorf("failed to sell the product: %w", err)
 	}
 
-	if err := invService.repo.Update(product); err != nil {
+	movement := domain.NewStockMovement(id, -quantity, domain.MovementSale, domain.ActorFromContext(ctx))
+	movement.LocationId = locationId
+	product, err = invService.repo.ApplyStockMovement(movement, expectedVersion, audit(ctx, domain.AuditStockSold, id, &before))
+	if err != nil {
 		return nil, fmt.Errorf("failed to update product stock after sale: %w", err)
 	}
 
-	if product.IsLowOnStock() {
-		invService.notifier.NotifyLowStock(product)
-	}
+	invService.alerts.CheckStockLevel(product)
+	invService.checkLocationStock(id, locationId)
 	return product, nil
 }
 
-func (invService *inventoryService) RestockProduct(id string, quantity int) (*domain.Product, error) {
+// RestockProduct receives the delivery as a new lot at the location.
+func (invService *inventoryService) RestockProduct(ctx context.Context, id string, locationId string, quantity int, receipt domain.LotReceipt, expectedVersion int) (*domain.Product, error) {
+	locationId, err := invService.requireLocation(locationId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restock the product: %w", err)
+	}
+
 	product, err := invService.repo.FindById(id)
 	if err != nil {
 		return nil, fmt.Errorf("could not find the product to be restocked: %w", err)
 	}
 
+	if err := product.MatchesVersion(expectedVersion); err != nil {
+		return nil, fmt.Errorf("failed to restock the product: %w", err)
+	}
+
+	before := *product
 	if err := product.Restock(quantity); err != nil {
 		return nil, fmt.Errorf("failed to restock the product: %w", err)
 	}
 
-	if err := invService.repo.Update(product); err != nil {
+	lot, err := domain.NewLot(id, locationId, quantity, receipt, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to restock the product: %w", err)
+	}
+
+	movement := domain.NewStockMovement(id, quantity, domain.MovementRestock, domain.ActorFromContext(ctx))
+	movement.LocationId = locationId
+	product, err = invService.lots.ReceiveLot(lot, movement, expectedVersion, audit(ctx, domain.AuditStockRestocked, id, &before))
+	if err != nil {
 		return nil, fmt.Errorf("failed to update product stock after restock: %w", err)
 	}
 
+	invService.alerts.CheckStockLevel(product)
+	invService.checkLocationStock(id, locationId)
+	return product, nil
+
+}
+
+func (invService *inventoryService) AdjustProductStock(ctx context.Context, id string, locationId string, delta int, expectedVersion int) (*domain.Product, error) {
+	locationId, err := invService.requireLocation(locationId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust the product stock: %w", err)
+	}
+
+	product, err := invService.repo.FindById(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the product to be adjusted: %w", err)
+	}
+
+	if err := product.MatchesVersion(expectedVersion); err != nil {
+		return nil, fmt.Errorf("failed to adjust the product stock: %w", err)
+	}
+
+	before := *product
+	if err := product.AdjustUnits(delta); err != nil {
+		return nil, fmt.Errorf("failed to adjust the product stock: %w", err)
+	}
+
+	movement := domain.NewStockMovement(id, delta, domain.MovementAdjustment, domain.ActorFromContext(ctx))
+	movement.LocationId = locationId
+	product, err = invService.repo.ApplyStockMovement(movement, expectedVersion, audit(ctx, domain.AuditStockAdjusted, id, &before))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update product stock after adjustment: %w", err)
+	}
+
+	invService.alerts.CheckStockLevel(product)
+	invService.checkLocationStock(id, locationId)
 	return product, nil
+}
+
+// WriteOffLot removes what is left of a lot from stock. It is the only way
+// stock in an expired lot leaves the inventory, since sales and transfers
+// skip expired lots.
+func (invService *inventoryService) WriteOffLot(ctx context.Context, id string, lotId string, expectedVersion int) (*domain.Product, error) {
+	lot, err := invService.lots.FindLotById(lotId)
+	if err == nil && lot.ProductId != id {
+		err = domain.ErrLotNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find the lot to write off: %w", err)
+	}
+	if lot.Quantity == 0 {
+		return nil, fmt.Errorf("failed to write off the lot: %w: lot %s holds no stock", domain.ErrLotInvalid, lot.LotNumber)
+	}
 
+	product, err := invService.repo.FindById(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the product to write off: %w", err)
+	}
+
+	if err := product.MatchesVersion(expectedVersion); err != nil {
+		return nil, fmt.Errorf("failed to write off the lot: %w", err)
+	}
+
+	before := *product
+	if err := product.AdjustUnits(-lot.Quantity); err != nil {
+		return nil, fmt.Errorf("failed to write off the lot: %w", err)
+	}
+
+	movement := domain.NewStockMovement(id, -lot.Quantity, domain.MovementWriteOff, domain.ActorFromContext(ctx))
+	movement.LocationId = lot.LocationId
+	movement.LotId = lot.Id
+	product, err = invService.repo.ApplyStockMovement(movement, expectedVersion, audit(ctx, domain.AuditStockWrittenOff, id, &before))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update product stock after write-off: %w", err)
+	}
+
+	invService.alerts.CheckStockLevel(product)
+	invService.checkLocationStock(id, lot.LocationId)
+	return product, nil
 }
 
-func (invService *inventoryService) GetAllProducts() ([]domain.Product, error) {
-	products, err := invService.repo.ListAll()
+func (invService *inventoryService) ListProducts(query domain.ProductQuery) (*domain.ProductPage, error) {
+	query.DefaultReorderPoint = invService.lowStockThreshold
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	if err := invService.requireCategory(query.CategoryId, domain.ErrInvalidQuery); err != nil {
+		return nil, err
+	}
+
+	page, err := invService.repo.ListProducts(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list all products: %w", err)
+		return nil, fmt.Errorf("failed to list products: %w", err)
 	}
-	return products, nil
+	return page, nil
 }
 
-func (invService *inventoryService) DeleteProduct(id string) error {
-	err := invService.repo.DeleteById(id)
+func (invService *inventoryService) DeleteProduct(ctx context.Context, id string) error {
+	before, err := invService.repo.FindById(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete product with id %s: %w", id, err)
+	}
+
+	movement := domain.NewStockMovement(id, 0, domain.MovementDelete, domain.ActorFromContext(ctx))
+	err = invService.repo.DeleteById(id, movement, audit(ctx, domain.AuditProductDeleted, id, before))
 	if err != nil {
 		return fmt.Errorf("failed to delete product with id %s: %w", id, err)
 	}
 	return nil
 }
 
-func (invService *inventoryService) GetInventoryValue() (float64, error) {
-	products, err := invService.repo.ListAll()
+func (invService *inventoryService) RestoreProduct(ctx context.Context, id string) (*domain.Product, error) {
+	movement := domain.NewStockMovement(id, 0, domain.MovementRestore, domain.ActorFromContext(ctx))
+	product, err := invService.repo.RestoreById(id, movement, audit(ctx, domain.AuditProductRestored, id, nil))
 	if err != nil {
-		return 0, fmt.Errorf("failed to list all products: %w", err)
+		return nil, fmt.Errorf("failed to restore product with id %s: %w", id, err)
 	}
+	invService.alerts.CheckStockLevel(product)
+	return product, nil
+}
 
-	var totalValue float64 = 0
-	for _, product := range products {
-		totalValue += float64(product.Quantity) * product.Price
+// PurgeDeletedProducts permanently removes products that were deleted at
+// least olderThanDays days ago. Their stock movements and audit entries stay.
+func (invService *inventoryService) PurgeDeletedProducts(ctx context.Context, olderThanDays int) ([]domain.Product, error) {
+	if olderThanDays < 1 {
+		return nil, fmt.Errorf("%w: older_than_days must be at least 1", domain.ErrInvalidQuery)
 	}
-	return totalValue, nil
-}
 
-func (invService *inventoryService) UpdateProductPrice(id string, newPrice float64) error {
-	product, err := invService.repo.FindById(id)
+	cutoff := time.Now().Add(-time.Duration(olderThanDays) * 24 * time.Hour)
+	purged, err := invService.repo.PurgeDeletedProducts(cutoff, func(product *domain.Product) *domain.AuditEntry {
+		return audit(ctx, domain.AuditProductPurged, product.Id, product)
+	})
 	if err != nil {
-		return fmt.Errorf("%w: could not find product with id %s", domain.ErrProductNotFound, id)
+		return nil, fmt.Errorf("failed to purge deleted products: %w", err)
 	}
+	return purged, nil
+}
 
-	err = product.UpdateProductPrice(newPrice)
+// GetInventoryValue values the stock on hand at one location, or all stock
+// including transfers in transit when locationId is empty.
+func (invService *inventoryService) GetInventoryValue(locationId string) (*domain.InventoryValueReport, error) {
+	if locationId != "" {
+		if _, err := invService.locations.FindLocationById(locationId); err != nil {
+			if errors.Is(err, domain.ErrLocationNotFound) {
+				return nil, fmt.Errorf("%w: location %s does not exist", domain.ErrInvalidQuery, locationId)
+			}
+			return nil, fmt.Errorf("failed to calculate inventory value: %w", err)
+		}
+	}
+	values, err := invService.repo.InventoryValueByCategory(locationId)
 	if err != nil {
-		return fmt.Errorf("failed to update price of the product: %w", err)
+		return nil, fmt.Errorf("failed to calculate inventory value: %w", err)
 	}
+	categories, err := invService.categories.ListCategories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate inventory value: %w", err)
+	}
+	return domain.NewInventoryValueReport(categories, values), nil
+}
 
-	err = invService.repo.Update(product)
+func (invService *inventoryService) GetStockMovements(id string, from, to time.Time) ([]domain.StockMovement, error) {
+	movements, err := invService.repo.ListMovements(id, from, to)
 	if err != nil {
-		return fmt.Errorf("could not save the updated price: %w", err)
+		return nil, fmt.Errorf("failed to list stock movements for product %s: %w", id, err)
 	}
+	return movements, nil
+}
 
-	return nil
+func (invService *inventoryService) UpdateProductPrice(ctx context.Context, id string, newPrice float64, expectedVersion int) (*domain.Product, error) {
+	return retryUnversioned(expectedVersion, func() (*domain.Product, error) {
+		product, err := invService.repo.FindById(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not find product with id %s", domain.ErrProductNotFound, id)
+		}
+
+		if err := product.MatchesVersion(expectedVersion); err != nil {
+			return nil, fmt.Errorf("failed to update price of the product: %w", err)
+		}
+
+		before := *product
+		err = product.UpdateProductPrice(newPrice)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update price of the product: %w", err)
+		}
+
+		err = invService.repo.Update(product, audit(ctx, domain.AuditPriceChanged, id, &before))
+		if err != nil {
+			return nil, fmt.Errorf("could not save the updated price: %w", err)
+		}
+		return product, nil
+	})
+}
+
+func (invService *inventoryService) UpdateProductDetails(ctx context.Context, id string, details domain.ProductDetails, expectedVersion int) (*domain.Product, error) {
+	return retryUnversioned(expectedVersion, func() (*domain.Product, error) {
+		product, err := invService.repo.FindById(id)
+		if err != nil {
+			return nil, fmt.Errorf("could not find the product to update details: %w", err)
+		}
+
+		if err := product.MatchesVersion(expectedVersion); err != nil {
+			return nil, fmt.Errorf("failed to update product details: %w", err)
+		}
+
+		before := *product
+		if err := product.SetDetails(details); err != nil {
+			return nil, fmt.Errorf("failed to update product details: %w", err)
+		}
+
+		if product.CategoryId != before.CategoryId {
+			if err := invService.requireCategory(product.CategoryId, domain.ErrProductInvalid); err != nil {
+				return nil, fmt.Errorf("failed to update product details: %w", err)
+			}
+		}
+
+		if err := invService.repo.Update(product, audit(ctx, domain.AuditDetailsChanged, id, &before)); err != nil {
+			return nil, fmt.Errorf("could not save the updated product details: %w", err)
+		}
+		return product, nil
+	})
+}
+
+func (invService *inventoryService) AssignProductCategory(ctx context.Context, id string, categoryId string, expectedVersion int) (*domain.Product, error) {
+	return retryUnversioned(expectedVersion, func() (*domain.Product, error) {
+		product, err := invService.repo.FindById(id)
+		if err != nil {
+			return nil, fmt.Errorf("could not find the product to assign a category: %w", err)
+		}
+
+		if err := product.MatchesVersion(expectedVersion); err != nil {
+			return nil, fmt.Errorf("failed to assign product category: %w", err)
+		}
+
+		if err := invService.requireCategory(categoryId, domain.ErrProductInvalid); err != nil {
+			return nil, fmt.Errorf("failed to assign product category: %w", err)
+		}
+
+		before := *product
+		product.CategoryId = categoryId
+		if err := invService.repo.Update(product, audit(ctx, domain.AuditCategoryChanged, id, &before)); err != nil {
+			return nil, fmt.Errorf("could not save the product category: %w", err)
+		}
+		return product, nil
+	})
+}
+
+func (invService *inventoryService) UpdateReorderThresholds(ctx context.Context, id string, reorderPoint int, reorderQuantity int, expectedVersion int) (*domain.Product, error) {
+	return retryUnversioned(expectedVersion, func() (*domain.Product, error) {
+		product, err := invService.repo.FindById(id)
+		if err != nil {
+			return nil, fmt.Errorf("could not find the product to update thresholds: %w", err)
+		}
+
+		if err := product.MatchesVersion(expectedVersion); err != nil {
+			return nil, fmt.Errorf("failed to update reorder thresholds: %w", err)
+		}
+
+		before := *product
+		if err := product.SetReorderThresholds(reorderPoint, reorderQuantity); err != nil {
+			return nil, fmt.Errorf("failed to update reorder thresholds: %w", err)
+		}
+
+		if err := invService.repo.Update(product, audit(ctx, domain.AuditThresholdsChanged, id, &before)); err != nil {
+			return nil, fmt.Errorf("could not save the updated reorder thresholds: %w", err)
+		}
+
+		invService.alerts.CheckStockLevel(product)
+		return product, nil
+	})
 }
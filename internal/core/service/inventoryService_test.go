@@ -1,32 +1,72 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
 )
 
 var (
 	ErrRepoFailed = errors.New("repository failed")
 )
 
+const testLowStockThreshold = 10
+
 type mockProductRepository struct {
+	mu          sync.Mutex
 	products    map[string]*domain.Product
+	deleted     map[string]*domain.Product
+	deletedAt   map[string]time.Time
+	movements   []domain.StockMovement
+	audits      []domain.AuditEntry
+	lastQuery   domain.ProductQuery
 	shouldError bool
+	// concurrentSales makes that many Update calls lose a race with a sale
+	// that commits between reading the product and saving it.
+	concurrentSales int
 }
 
 func newMockProductRepository() *mockProductRepository {
 	return &mockProductRepository{
-		products: make(map[string]*domain.Product),
+		products:  make(map[string]*domain.Product),
+		deleted:   make(map[string]*domain.Product),
+		deletedAt: make(map[string]time.Time),
+	}
+}
+
+// recordAudit stores the entry the way the repository does, with After set
+// to the product as the change left it.
+func (m *mockProductRepository) recordAudit(audit *domain.AuditEntry, after *domain.Product) {
+	if audit == nil {
+		return
+	}
+	if after != nil {
+		snapshot := *after
+		audit.After = &snapshot
 	}
+	m.audits = append(m.audits, *audit)
 }
 
-func (m *mockProductRepository) Save(product *domain.Product) error {
+func (m *mockProductRepository) Save(product *domain.Product, movement *domain.StockMovement, audit *domain.AuditEntry) error {
 	if m.shouldError {
 		return ErrRepoFailed
 	}
+	for _, existing := range m.products {
+		if existing.SKU != "" && existing.SKU == product.SKU {
+			return domain.ErrDuplicateSKU
+		}
+	}
 	m.products[product.Id] = product
+	if movement != nil {
+		m.movements = append(m.movements, *movement)
+	}
+	m.recordAudit(audit, product)
 	return nil
 }
 
@@ -42,42 +82,181 @@ func (m *mockProductRepository) FindById(id string) (*domain.Product, error) {
 	return &clone, nil
 }
 
-func (m *mockProductRepository) Update(product *domain.Product) error {
+func (m *mockProductRepository) FindBySKU(sku string) (*domain.Product, error) {
+	return m.findBy(func(p *domain.Product) bool { return p.SKU == sku })
+}
+
+func (m *mockProductRepository) FindByBarcode(barcode string) (*domain.Product, error) {
+	return m.findBy(func(p *domain.Product) bool { return barcode != "" && p.Barcode == barcode })
+}
+
+func (m *mockProductRepository) findBy(match func(*domain.Product) bool) (*domain.Product, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	for _, product := range m.products {
+		if match(product) {
+			clone := *product
+			return &clone, nil
+		}
+	}
+	return nil, domain.ErrProductNotFound
+}
+
+func (m *mockProductRepository) Update(product *domain.Product, audit *domain.AuditEntry) error {
 	if m.shouldError {
 		return ErrRepoFailed
 	}
-	if _, ok := m.products[product.Id]; !ok {
+	stored, ok := m.products[product.Id]
+	if !ok {
 		return errors.New("product not found for update")
 	}
+	if m.concurrentSales > 0 {
+		m.concurrentSales--
+		stored.Quantity--
+		stored.Version++
+	}
+	if stored.Version != product.Version {
+		return domain.ErrConflict
+	}
+	product.Version++
 	m.products[product.Id] = product
+	m.recordAudit(audit, product)
 	return nil
 }
 
-func (m *mockProductRepository) ListAll() ([]domain.Product, error) {
+func (m *mockProductRepository) ApplyStockMovement(movement *domain.StockMovement, expectedVersion int, audit *domain.AuditEntry) (*domain.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.shouldError {
 		return nil, ErrRepoFailed
 	}
-	var productList []domain.Product
+	delta := movement.Delta
+	product, ok := m.products[movement.ProductId]
+	if !ok {
+		return nil, domain.ErrProductNotFound
+	}
+	if err := product.MatchesVersion(expectedVersion); err != nil {
+		return nil, err
+	}
+	if product.Quantity+delta < 0 {
+		return nil, domain.ErrInsufficientStock
+	}
+	product.Quantity += delta
+	product.Version++
+	movement.ResultingQuantity = product.Quantity
+	m.movements = append(m.movements, *movement)
+	m.recordAudit(audit, product)
+	clone := *product
+	return &clone, nil
+}
+
+func (m *mockProductRepository) ListMovements(productId string, from, to time.Time) ([]domain.StockMovement, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	movements := []domain.StockMovement{}
+	for _, movement := range m.movements {
+		if movement.ProductId != productId {
+			continue
+		}
+		if (!from.IsZero() && movement.CreatedAt.Before(from)) || (!to.IsZero() && movement.CreatedAt.After(to)) {
+			continue
+		}
+		movements = append(movements, movement)
+	}
+	return movements, nil
+}
+
+func (m *mockProductRepository) ListProducts(query domain.ProductQuery) (*domain.ProductPage, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	m.lastQuery = query
+	page := &domain.ProductPage{Products: []domain.Product{}}
 	for _, p := range m.products {
-		productList = append(productList, *p)
+		if query.NameContains != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(query.NameContains)) {
+			continue
+		}
+		if query.LowStockOnly && !p.IsLowOnStock(query.DefaultReorderPoint) {
+			continue
+		}
+		page.Products = append(page.Products, *p)
 	}
-	return productList, nil
+	return page, nil
 }
 
-func (m *mockProductRepository) DeleteById(id string) error {
+func (m *mockProductRepository) InventoryValueByCategory(locationId string) (map[string]float64, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	values := map[string]float64{}
+	for _, p := range m.products {
+		values[p.CategoryId] += float64(p.Quantity) * p.Price
+	}
+	return values, nil
+}
+
+func (m *mockProductRepository) DeleteById(id string, movement *domain.StockMovement, audit *domain.AuditEntry) error {
 	if m.shouldError {
 		return ErrRepoFailed
 	}
-	if _, ok := m.products[id]; !ok {
+	product, ok := m.products[id]
+	if !ok {
 		return errors.New("product not found for deletion")
 	}
+	movement.Delta = -product.Quantity
+	m.movements = append(m.movements, *movement)
+	m.deleted[id] = product
+	m.deletedAt[id] = movement.CreatedAt
 	delete(m.products, id)
+	m.recordAudit(audit, nil)
 	return nil
 }
 
+func (m *mockProductRepository) RestoreById(id string, movement *domain.StockMovement, audit *domain.AuditEntry) (*domain.Product, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	product, ok := m.deleted[id]
+	if !ok {
+		if _, live := m.products[id]; live {
+			return nil, domain.ErrProductNotDeleted
+		}
+		return nil, domain.ErrProductNotFound
+	}
+	product.Version++
+	movement.Delta = product.Quantity
+	movement.ResultingQuantity = product.Quantity
+	m.movements = append(m.movements, *movement)
+	m.products[id] = product
+	delete(m.deleted, id)
+	m.recordAudit(audit, product)
+	clone := *product
+	return &clone, nil
+}
+
+func (m *mockProductRepository) PurgeDeletedProducts(deletedBefore time.Time, audit func(product *domain.Product) *domain.AuditEntry) ([]domain.Product, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	purged := []domain.Product{}
+	for id, product := range m.deleted {
+		if m.deletedAt[id].Before(deletedBefore) {
+			purged = append(purged, *product)
+			delete(m.deleted, id)
+			if audit != nil {
+				m.recordAudit(audit(product), nil)
+			}
+		}
+	}
+	return purged, nil
+}
+
 type mockNotifier struct {
 	notifiedProduct *domain.Product
 	wasCalled       bool
+	expiringLots    []domain.ExpiringLot
 }
 
 func (m *mockNotifier) NotifyLowStock(product *domain.Product) {
@@ -85,28 +264,88 @@ func (m *mockNotifier) NotifyLowStock(product *domain.Product) {
 	m.notifiedProduct = product
 }
 
+func (m *mockNotifier) NotifyExpiring(lot *domain.ExpiringLot) {
+	m.expiringLots = append(m.expiringLots, *lot)
+}
+
+type mockAuditRepository struct {
+	mu          sync.Mutex
+	entries     []domain.AuditEntry
+	shouldError bool
+}
+
+func (m *mockAuditRepository) RecordAudit(entry *domain.AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *mockAuditRepository) WalkAuditEntries(query domain.AuditQuery, newestFirst bool, visit func(domain.AuditEntry) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	matched := []domain.AuditEntry{}
+	for _, entry := range m.entries {
+		if (query.Actor == "" || entry.Actor == query.Actor) && (query.Action == "" || entry.Action == query.Action) &&
+			(query.ProductId == "" || entry.ProductId == query.ProductId) {
+			matched = append(matched, entry)
+		}
+	}
+	for i := range matched {
+		if query.Limit > 0 && i == query.Limit {
+			break
+		}
+		entry := matched[i]
+		if newestFirst {
+			entry = matched[len(matched)-1-i]
+		}
+		if err := visit(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestInventoryService(repo *mockProductRepository, notifier ports.Notifier) InventoryService {
+	return NewInventoryService(repo, newMockCategoryRepository(), newMockLocationRepository(), newMockLotRepository(repo), NewAlertService(newMockAlertRepository(), notifier, testLowStockThreshold, 0),
+		testLowStockThreshold)
+}
+
 func TestInventoryService_AddProduct(t *testing.T) {
 	tests := []struct {
-		name        string
-		productName string
-		price       float64
-		quantity    int
-		repoShould  bool
-		expectErr   bool
+		name         string
+		productName  string
+		price        float64
+		quantity     int
+		reorderPoint int
+		details      domain.ProductDetails
+		repoShould   bool
+		expectErr    bool
 	}{
-		{"success", "Laptop", 1200.00, 10, false, false},
-		{"fail_invalid_name", "", 1200.00, 10, false, true},
-		{"fail_invalid_price", "Laptop", -1, 10, false, true},
-		{"fail_repo_save", "Laptop", 1200.00, 10, true, true},
+		{"success", "Laptop", 1200.00, 10, 0, domain.ProductDetails{}, false, false},
+		{"success_with_reorder_point", "Laptop", 1200.00, 10, 4, domain.ProductDetails{}, false, false},
+		{"success_with_details", "Laptop", 1200.00, 10, 0, domain.ProductDetails{SKU: "lap-1", Barcode: "4006381333931", Unit: domain.UnitEach}, false, false},
+		{"fail_invalid_name", "", 1200.00, 10, 0, domain.ProductDetails{}, false, true},
+		{"fail_invalid_price", "Laptop", -1, 10, 0, domain.ProductDetails{}, false, true},
+		{"fail_negative_reorder_point", "Laptop", 1200.00, 10, -4, domain.ProductDetails{}, false, true},
+		{"fail_invalid_barcode", "Laptop", 1200.00, 10, 0, domain.ProductDetails{Barcode: "4006381333932"}, false, true},
+		{"fail_invalid_unit", "Laptop", 1200.00, 10, 0, domain.ProductDetails{Unit: "crate"}, false, true},
+		{"fail_repo_save", "Laptop", 1200.00, 10, 0, domain.ProductDetails{}, true, true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := newMockProductRepository()
 			repo.shouldError = tt.repoShould
-			service := NewInventoryService(repo, &mockNotifier{})
+			service := newTestInventoryService(repo, &mockNotifier{})
 
-			product, err := service.AddProduct(tt.productName, tt.price, tt.quantity)
+			product, err := service.AddProduct(context.Background(), tt.productName, tt.price, tt.quantity, tt.reorderPoint, 0, tt.details)
 
 			if (err != nil) != tt.expectErr {
 				t.Errorf("AddProduct() error = %v, expectErr %v", err, tt.expectErr)
@@ -115,14 +354,191 @@ func TestInventoryService_AddProduct(t *testing.T) {
 			if !tt.expectErr && (product == nil || len(repo.products) != 1) {
 				t.Errorf("AddProduct() failed to create or save the product")
 			}
+			if !tt.expectErr && product.ReorderPoint != tt.reorderPoint {
+				t.Errorf("AddProduct() reorder point = %d, want %d", product.ReorderPoint, tt.reorderPoint)
+			}
+			if !tt.expectErr && (len(repo.movements) != 1 || repo.movements[0].Reason != domain.MovementInitial || repo.movements[0].Delta != tt.quantity) {
+				t.Errorf("AddProduct() movements = %+v, want one initial movement of %d", repo.movements, tt.quantity)
+			}
+		})
+	}
+}
+
+func TestInventoryService_CatalogDetails(t *testing.T) {
+	repo := newMockProductRepository()
+	service := NewInventoryService(repo, newMockCategoryRepository(domain.Category{Id: "c-grocery", Name: "Grocery"}), newMockLocationRepository(), newMockLotRepository(repo),
+		NewAlertService(newMockAlertRepository(), &mockNotifier{}, 0, 0), 0)
+	ctx := context.Background()
+
+	product, err := service.AddProduct(ctx, "Coffee", 12, 5, 0, 0, domain.ProductDetails{
+		SKU: "cof-250", Barcode: "4006381333931", Unit: domain.UnitKilogram, Tags: []string{"beans", "beans", ""},
+	})
+	if err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	if product.SKU != "COF-250" || len(product.Tags) != 1 {
+		t.Errorf("AddProduct() sku = %q tags = %v, want normalized values", product.SKU, product.Tags)
+	}
+	if _, err := service.AddProduct(ctx, "Coffee", 12, 5, 0, 0, domain.ProductDetails{SKU: "COF-250"}); !errors.Is(err, domain.ErrDuplicateSKU) {
+		t.Errorf("AddProduct() duplicate sku error = %v, want ErrDuplicateSKU", err)
+	}
+
+	if found, err := service.GetProductBySKU(" cof-250 "); err != nil || found.Id != product.Id {
+		t.Errorf("GetProductBySKU() = %v, %v", found, err)
+	}
+	if found, err := service.GetProductByBarcode("4006381333931"); err != nil || found.Id != product.Id {
+		t.Errorf("GetProductByBarcode() = %v, %v", found, err)
+	}
+	if _, err := service.GetProductByBarcode(""); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("GetProductByBarcode(\"\") error = %v, want ErrProductNotFound", err)
+	}
+
+	updated, err := service.UpdateProductDetails(ctx, product.Id, domain.ProductDetails{Description: "Whole beans", CategoryId: "c-grocery"}, product.Version)
+	if err != nil {
+		t.Fatalf("UpdateProductDetails() error = %v", err)
+	}
+	if updated.SKU != "COF-250" || updated.Unit != domain.UnitKilogram || updated.Description != "Whole beans" {
+		t.Errorf("UpdateProductDetails() = %+v, want sku and unit kept", updated)
+	}
+	if _, err := service.UpdateProductDetails(ctx, product.Id, domain.ProductDetails{}, product.Version); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("UpdateProductDetails() stale version error = %v, want ErrConflict", err)
+	}
+	if _, err := service.UpdateProductDetails(ctx, product.Id, domain.ProductDetails{Barcode: "123"}, updated.Version); !errors.Is(err, domain.ErrProductInvalid) {
+		t.Errorf("UpdateProductDetails() bad barcode error = %v, want ErrProductInvalid", err)
+	}
+	if _, err := service.UpdateProductDetails(ctx, product.Id, domain.ProductDetails{CategoryId: "c-missing"}, updated.Version); !errors.Is(err, domain.ErrProductInvalid) {
+		t.Errorf("UpdateProductDetails() unknown category error = %v, want ErrProductInvalid", err)
+	}
+}
+
+func TestInventoryService_AssignProductCategory(t *testing.T) {
+	p, _ := domain.CreateNewProduct("Cable", 4, 10)
+
+	tests := []struct {
+		name            string
+		categoryId      string
+		expectedVersion int
+		wantErr         error
+	}{
+		{"assign", "c-cables", 1, nil},
+		{"unassign", "", 0, nil},
+		{"unknown_category", "c-missing", 0, domain.ErrProductInvalid},
+		{"stale_version", "c-cables", 5, domain.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockProductRepository()
+			clone := *p
+			clone.CategoryId = "c-old"
+			repo.Save(&clone, nil, nil)
+			categories := newMockCategoryRepository(domain.Category{Id: "c-cables", Name: "Cables"})
+			service := NewInventoryService(repo, categories, newMockLocationRepository(), newMockLotRepository(repo), NewAlertService(newMockAlertRepository(), &mockNotifier{}, 0, 0), 0)
+
+			updated, err := service.AssignProductCategory(context.Background(), p.Id, tt.categoryId, tt.expectedVersion)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("AssignProductCategory() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if repo.products[p.Id].CategoryId != "c-old" || len(repo.audits) != 0 {
+					t.Errorf("AssignProductCategory() changed the product on error")
+				}
+				return
+			}
+			if updated.CategoryId != tt.categoryId || len(repo.audits) != 1 || repo.audits[0].Action != domain.AuditCategoryChanged {
+				t.Errorf("AssignProductCategory() = %+v with audit %+v", updated, repo.audits)
+			}
 		})
 	}
 }
 
+func TestInventoryService_ListProductsByUnknownCategory(t *testing.T) {
+	service := newTestInventoryService(newMockProductRepository(), &mockNotifier{})
+	if _, err := service.ListProducts(domain.ProductQuery{CategoryId: "c-missing"}); !errors.Is(err, domain.ErrInvalidQuery) {
+		t.Errorf("ListProducts() with an unknown category error = %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestInventoryService_RecordsAuditEntries(t *testing.T) {
+	repo := newMockProductRepository()
+	service := NewInventoryService(repo, newMockCategoryRepository(), newMockLocationRepository(), newMockLotRepository(repo), NewAlertService(newMockAlertRepository(), &mockNotifier{}, 0, 0), 0)
+	ctx := domain.ContextWithManagerId(context.Background(), "manager-1")
+	ctx = domain.ContextWithRequestMeta(ctx, domain.RequestMeta{Id: "req-1", ClientIP: "10.0.0.1"})
+
+	product, err := service.AddProduct(ctx, "Laptop", 100, 10, 0, 0, domain.ProductDetails{})
+	if err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	if _, err := service.UpdateProductPrice(ctx, product.Id, 120, 0); err != nil {
+		t.Fatalf("UpdateProductPrice() error = %v", err)
+	}
+	if _, err := service.SellProductUnits(ctx, product.Id, "", 3, 0); err != nil {
+		t.Fatalf("SellProductUnits() error = %v", err)
+	}
+	if _, err := service.RestockProduct(ctx, product.Id, "", 5, domain.LotReceipt{}, 0); err != nil {
+		t.Fatalf("RestockProduct() error = %v", err)
+	}
+	if _, err := service.SellProductUnits(ctx, product.Id, "", 100, 0); err == nil {
+		t.Fatalf("SellProductUnits() of too many units succeeded")
+	}
+	if _, err := service.AdjustProductStock(ctx, product.Id, "", -2, 0); err != nil {
+		t.Fatalf("AdjustProductStock() error = %v", err)
+	}
+	if _, err := service.UpdateReorderThresholds(ctx, product.Id, 2, 4, 0); err != nil {
+		t.Fatalf("UpdateReorderThresholds() error = %v", err)
+	}
+	if err := service.DeleteProduct(ctx, product.Id); err != nil {
+		t.Fatalf("DeleteProduct() error = %v", err)
+	}
+
+	tests := []struct {
+		action       domain.AuditAction
+		wantBefore   bool
+		wantAfter    bool
+		wantQuantity int
+		wantPrice    float64
+	}{
+		{domain.AuditProductCreated, false, true, 10, 100},
+		{domain.AuditPriceChanged, true, true, 10, 120},
+		{domain.AuditStockSold, true, true, 7, 120},
+		{domain.AuditStockRestocked, true, true, 12, 120},
+		{domain.AuditStockAdjusted, true, true, 10, 120},
+		{domain.AuditThresholdsChanged, true, true, 10, 120},
+		{domain.AuditProductDeleted, true, false, 10, 120},
+	}
+	if len(repo.audits) != len(tests) {
+		t.Fatalf("recorded %d audit entries, want %d: %+v", len(repo.audits), len(tests), repo.audits)
+	}
+	for i, tt := range tests {
+		entry := repo.audits[i]
+		if entry.Action != tt.action || entry.ProductId != product.Id || entry.Actor != "manager-1" ||
+			entry.ClientIP != "10.0.0.1" || entry.RequestId != "req-1" {
+			t.Errorf("entry %d = %+v, want %s by manager-1", i, entry, tt.action)
+		}
+		if (entry.Before != nil) != tt.wantBefore || (entry.After != nil) != tt.wantAfter {
+			t.Errorf("entry %d snapshots before=%v after=%v", i, entry.Before, entry.After)
+			continue
+		}
+		snapshot := entry.After
+		if snapshot == nil {
+			snapshot = entry.Before
+		}
+		if snapshot.Quantity != tt.wantQuantity || snapshot.Price != tt.wantPrice {
+			t.Errorf("entry %d snapshot = %+v, want quantity %d at %.2f", i, snapshot, tt.wantQuantity, tt.wantPrice)
+		}
+	}
+	if sold := repo.audits[2]; sold.Before.Quantity != 10 {
+		t.Errorf("sale before snapshot = %+v, want quantity 10", sold.Before)
+	}
+	if thresholds := repo.audits[5]; thresholds.Before.ReorderPoint == 2 || thresholds.After.ReorderPoint != 2 {
+		t.Errorf("thresholds change snapshots = %+v -> %+v, want reorder point 2 after only", thresholds.Before, thresholds.After)
+	}
+}
+
 func TestInventoryService_GetProduct(t *testing.T) {
 	repo := newMockProductRepository()
 	p, _ := domain.CreateNewProduct("Test Book", 25.50, 50)
-	repo.Save(p)
+	repo.Save(p, nil, nil)
 
 	tests := []struct {
 		name      string
@@ -135,7 +551,7 @@ func TestInventoryService_GetProduct(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			service := NewInventoryService(repo, &mockNotifier{})
+			service := newTestInventoryService(repo, &mockNotifier{})
 			product, err := service.GetProduct(tt.productID)
 
 			if (err != nil) != tt.expectErr {
@@ -151,21 +567,27 @@ func TestInventoryService_GetProduct(t *testing.T) {
 
 func TestInventoryService_SellProductUnits(t *testing.T) {
 	p, _ := domain.CreateNewProduct("Monitor", 300, 20)
+	pBulk, _ := domain.CreateNewProduct("Screws", 0.05, 20)
+	pBulk.ReorderPoint = 16
 
 	tests := []struct {
-		name           string
-		initialProduct *domain.Product
-		sellQuantity   int
-		repoShould     bool
-		expectErr      bool
-		notifierCalled bool
-		finalQuantity  int
+		name            string
+		initialProduct  *domain.Product
+		sellQuantity    int
+		expectedVersion int
+		repoShould      bool
+		expectErr       bool
+		notifierCalled  bool
+		finalQuantity   int
 	}{
-		{"success", p, 5, false, false, false, 15},
-		{"success_low_stock_notification", p, 11, false, false, true, 9},
-		{"fail_insufficient_stock", p, 25, false, true, false, 20},
-		{"fail_product_not_found", p, 5, false, true, false, 0},
-		{"fail_repo_update", p, 5, true, true, false, 20},
+		{"success", p, 5, 0, false, false, false, 15},
+		{"success_matching_version", p, 5, 1, false, false, false, 15},
+		{"success_low_stock_notification", p, 11, 0, false, false, true, 9},
+		{"success_product_reorder_point_notification", pBulk, 5, 0, false, false, true, 15},
+		{"fail_insufficient_stock", p, 25, 0, false, true, false, 20},
+		{"fail_stale_version", p, 5, 2, false, true, false, 20},
+		{"fail_product_not_found", p, 5, 0, false, true, false, 0},
+		{"fail_repo_update", p, 5, 0, true, true, false, 20},
 	}
 
 	for _, tt := range tests {
@@ -173,18 +595,19 @@ func TestInventoryService_SellProductUnits(t *testing.T) {
 			repo := newMockProductRepository()
 			if tt.name != "fail_product_not_found" {
 				clone := *tt.initialProduct
-				repo.Save(&clone)
+				repo.Save(&clone, nil, nil)
 			}
 			repo.shouldError = tt.repoShould
 			notifier := &mockNotifier{}
-			service := NewInventoryService(repo, notifier)
+			service := newTestInventoryService(repo, notifier)
 
 			productID := tt.initialProduct.Id
 			if tt.name == "fail_product_not_found" {
 				productID = "wrong-id"
 			}
 
-			_, err := service.SellProductUnits(productID, tt.sellQuantity)
+			ctx := domain.ContextWithManagerId(context.Background(), "manager-1")
+			_, err := service.SellProductUnits(ctx, productID, "", tt.sellQuantity, tt.expectedVersion)
 
 			if (err != nil) != tt.expectErr {
 				t.Errorf("SellProductUnits() error = %v, expectErr %v", err, tt.expectErr)
@@ -195,6 +618,16 @@ func TestInventoryService_SellProductUnits(t *testing.T) {
 				if updatedProduct.Quantity != tt.finalQuantity {
 					t.Errorf("SellProductUnits() final quantity = %d, want %d", updatedProduct.Quantity, tt.finalQuantity)
 				}
+				if len(repo.movements) != 1 {
+					t.Fatalf("SellProductUnits() recorded %d movements, want 1", len(repo.movements))
+				}
+				movement := repo.movements[0]
+				if movement.Reason != domain.MovementSale || movement.Delta != -tt.sellQuantity ||
+					movement.ResultingQuantity != tt.finalQuantity || movement.ManagerId != "manager-1" {
+					t.Errorf("SellProductUnits() recorded movement = %+v", movement)
+				}
+			} else if len(repo.movements) != 0 {
+				t.Errorf("SellProductUnits() recorded %d movements on failure, want 0", len(repo.movements))
 			}
 
 			if notifier.wasCalled != tt.notifierCalled {
@@ -204,6 +637,34 @@ func TestInventoryService_SellProductUnits(t *testing.T) {
 	}
 }
 
+func TestInventoryService_LowStockAlertsOncePerCrossing(t *testing.T) {
+	p, _ := domain.CreateNewProduct("Cable", 5, 12)
+	repo := newMockProductRepository()
+	repo.Save(p, nil, nil)
+	notifier := &countingNotifier{}
+	service := newTestInventoryService(repo, notifier)
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		if _, err := service.SellProductUnits(ctx, p.Id, "", 1, 0); err != nil {
+			t.Fatalf("SellProductUnits() returned an unexpected error: %v", err)
+		}
+	}
+	if notifier.calls != 1 {
+		t.Fatalf("notifier called %d times while stock stayed low, want 1", notifier.calls)
+	}
+
+	if _, err := service.RestockProduct(ctx, p.Id, "", 20, domain.LotReceipt{}, 0); err != nil {
+		t.Fatalf("RestockProduct() returned an unexpected error: %v", err)
+	}
+	if _, err := service.SellProductUnits(ctx, p.Id, "", 25, 0); err != nil {
+		t.Fatalf("SellProductUnits() returned an unexpected error: %v", err)
+	}
+	if notifier.calls != 2 {
+		t.Errorf("notifier called %d times after restock and a new crossing, want 2", notifier.calls)
+	}
+}
+
 func TestInventoryService_RestockProduct(t *testing.T) {
 	p, _ := domain.CreateNewProduct("Keyboard", 75, 10)
 
@@ -223,11 +684,11 @@ func TestInventoryService_RestockProduct(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := newMockProductRepository()
 			clone := *p
-			repo.Save(&clone)
+			repo.Save(&clone, nil, nil)
 			repo.shouldError = tt.repoShould
-			service := NewInventoryService(repo, &mockNotifier{})
+			service := newTestInventoryService(repo, &mockNotifier{})
 
-			_, err := service.RestockProduct(p.Id, tt.restockQty)
+			_, err := service.RestockProduct(context.Background(), p.Id, "", tt.restockQty, domain.LotReceipt{}, 0)
 
 			if (err != nil) != tt.expectErr {
 				t.Errorf("RestockProduct() error = %v, expectErr %v", err, tt.expectErr)
@@ -243,57 +704,88 @@ func TestInventoryService_RestockProduct(t *testing.T) {
 	}
 }
 
-func TestInventoryService_GetAllProducts(t *testing.T) {
+func TestInventoryService_ListProducts(t *testing.T) {
 	p1, _ := domain.CreateNewProduct("Product A", 10, 1)
-	p2, _ := domain.CreateNewProduct("Product B", 20, 2)
+	p2, _ := domain.CreateNewProduct("Product B", 20, 50)
 
 	tests := []struct {
-		name       string
-		setupRepo  func() *mockProductRepository
-		wantCount  int
-		expectErr  bool
-		wantResult []domain.Product
+		name      string
+		query     domain.ProductQuery
+		setupRepo func() *mockProductRepository
+		wantCount int
+		wantErr   error
+		expectErr bool
 	}{
 		{
 			"success_with_products",
+			domain.ProductQuery{},
+			func() *mockProductRepository {
+				repo := newMockProductRepository()
+				repo.Save(p1, nil, nil)
+				repo.Save(p2, nil, nil)
+				return repo
+			},
+			2, nil, false,
+		},
+		{
+			"success_low_stock_only",
+			domain.ProductQuery{LowStockOnly: true},
 			func() *mockProductRepository {
 				repo := newMockProductRepository()
-				repo.Save(p1)
-				repo.Save(p2)
+				repo.Save(p1, nil, nil)
+				repo.Save(p2, nil, nil)
 				return repo
 			},
-			2, false, []domain.Product{*p1, *p2},
+			1, nil, false,
 		},
 		{
 			"success_no_products",
+			domain.ProductQuery{},
+			func() *mockProductRepository {
+				return newMockProductRepository()
+			},
+			0, nil, false,
+		},
+		{
+			"fail_invalid_query",
+			domain.ProductQuery{SortBy: "colour"},
 			func() *mockProductRepository {
 				return newMockProductRepository()
 			},
-			0, false, []domain.Product{},
+			0, domain.ErrInvalidQuery, true,
 		},
 		{
 			"fail_repo_error",
+			domain.ProductQuery{},
 			func() *mockProductRepository {
 				repo := newMockProductRepository()
 				repo.shouldError = true
 				return repo
 			},
-			0, true, nil,
+			0, nil, true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := tt.setupRepo()
-			service := NewInventoryService(repo, &mockNotifier{})
-			products, err := service.GetAllProducts()
+			service := newTestInventoryService(repo, &mockNotifier{})
+			page, err := service.ListProducts(tt.query)
 
 			if (err != nil) != tt.expectErr {
-				t.Errorf("GetAllProducts() error = %v, expectErr %v", err, tt.expectErr)
+				t.Fatalf("ListProducts() error = %v, expectErr %v", err, tt.expectErr)
 			}
-
-			if !tt.expectErr && len(products) != tt.wantCount {
-				t.Errorf("GetAllProducts() count = %d, want %d", len(products), tt.wantCount)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ListProducts() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.expectErr {
+				return
+			}
+			if len(page.Products) != tt.wantCount {
+				t.Errorf("ListProducts() count = %d, want %d", len(page.Products), tt.wantCount)
+			}
+			if repo.lastQuery.DefaultReorderPoint != testLowStockThreshold || repo.lastQuery.Limit != domain.DefaultPageSize {
+				t.Errorf("ListProducts() passed query %+v to the repository", repo.lastQuery)
 			}
 		})
 	}
@@ -312,7 +804,7 @@ func TestInventoryService_DeleteProduct(t *testing.T) {
 			"success", p.Id,
 			func() *mockProductRepository {
 				repo := newMockProductRepository()
-				repo.Save(p)
+				repo.Save(p, nil, nil)
 				return repo
 			},
 			false,
@@ -321,7 +813,7 @@ func TestInventoryService_DeleteProduct(t *testing.T) {
 			"fail_not_found", "wrong-id",
 			func() *mockProductRepository {
 				repo := newMockProductRepository()
-				repo.Save(p)
+				repo.Save(p, nil, nil)
 				return repo
 			},
 			true,
@@ -330,7 +822,7 @@ func TestInventoryService_DeleteProduct(t *testing.T) {
 			"fail_repo_error", p.Id,
 			func() *mockProductRepository {
 				repo := newMockProductRepository()
-				repo.Save(p)
+				repo.Save(p, nil, nil)
 				repo.shouldError = true
 				return repo
 			},
@@ -341,8 +833,8 @@ func TestInventoryService_DeleteProduct(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := tt.setupRepo()
-			service := NewInventoryService(repo, &mockNotifier{})
-			err := service.DeleteProduct(tt.productID)
+			service := newTestInventoryService(repo, &mockNotifier{})
+			err := service.DeleteProduct(context.Background(), tt.productID)
 
 			if (err != nil) != tt.expectErr {
 				t.Errorf("DeleteProduct() error = %v, expectErr %v", err, tt.expectErr)
@@ -351,6 +843,92 @@ func TestInventoryService_DeleteProduct(t *testing.T) {
 				if _, ok := repo.products[tt.productID]; ok {
 					t.Error("DeleteProduct() failed to remove product from repo")
 				}
+				if len(repo.movements) != 1 || repo.movements[0].Reason != domain.MovementDelete || repo.movements[0].Delta != -p.Quantity {
+					t.Errorf("DeleteProduct() recorded movements = %+v", repo.movements)
+				}
+			}
+		})
+	}
+}
+
+func TestInventoryService_RestoreProduct(t *testing.T) {
+	live, _ := domain.CreateNewProduct("Live", 1, 3)
+	gone, _ := domain.CreateNewProduct("Gone", 1, 3)
+
+	tests := []struct {
+		name       string
+		id         string
+		repoShould bool
+		wantErr    error
+	}{
+		{"success", gone.Id, false, nil},
+		{"fail_not_deleted", live.Id, false, domain.ErrProductNotDeleted},
+		{"fail_not_found", "missing", false, domain.ErrProductNotFound},
+		{"fail_repo_error", gone.Id, true, ErrRepoFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockProductRepository()
+			liveClone, goneClone := *live, *gone
+			repo.Save(&liveClone, nil, nil)
+			repo.Save(&goneClone, nil, nil)
+			service := NewInventoryService(repo, newMockCategoryRepository(), newMockLocationRepository(), newMockLotRepository(repo), NewAlertService(newMockAlertRepository(), &mockNotifier{}, 0, 0), 0)
+			ctx := domain.ContextWithManagerId(context.Background(), "manager-1")
+			if err := service.DeleteProduct(ctx, gone.Id); err != nil {
+				t.Fatalf("DeleteProduct() error = %v", err)
+			}
+			repo.shouldError = tt.repoShould
+
+			product, err := service.RestoreProduct(ctx, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RestoreProduct() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if product.Quantity != 3 || repo.products[gone.Id] == nil {
+				t.Errorf("RestoreProduct() = %+v", product)
+			}
+			last := repo.movements[len(repo.movements)-1]
+			if last.Reason != domain.MovementRestore || last.Delta != 3 || last.ManagerId != "manager-1" {
+				t.Errorf("RestoreProduct() recorded movement %+v", last)
+			}
+			if entry := repo.audits[len(repo.audits)-1]; entry.Action != domain.AuditProductRestored || entry.After == nil {
+				t.Errorf("RestoreProduct() recorded audit entry %+v", entry)
+			}
+		})
+	}
+}
+
+func TestInventoryService_PurgeDeletedProducts(t *testing.T) {
+	tests := []struct {
+		name          string
+		olderThanDays int
+		deletedAgo    time.Duration
+		wantPurged    int
+		wantErr       error
+	}{
+		{"purges_old_deletions", 30, 31 * 24 * time.Hour, 1, nil},
+		{"keeps_recent_deletions", 30, 29 * 24 * time.Hour, 0, nil},
+		{"fail_zero_days", 0, 31 * 24 * time.Hour, 0, domain.ErrInvalidQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockProductRepository()
+			product, _ := domain.CreateNewProduct("Old", 1, 1)
+			repo.Save(product, nil, nil)
+			repo.DeleteById(product.Id, domain.NewStockMovement(product.Id, 0, domain.MovementDelete, ""), nil)
+			repo.deletedAt[product.Id] = time.Now().Add(-tt.deletedAgo)
+			service := NewInventoryService(repo, newMockCategoryRepository(), newMockLocationRepository(), newMockLotRepository(repo), NewAlertService(newMockAlertRepository(), &mockNotifier{}, 0, 0), 0)
+
+			purged, err := service.PurgeDeletedProducts(context.Background(), tt.olderThanDays)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PurgeDeletedProducts() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(purged) != tt.wantPurged || len(repo.audits) != tt.wantPurged {
+				t.Errorf("PurgeDeletedProducts() purged %d with %d audit entries, want %d", len(purged), len(repo.audits), tt.wantPurged)
 			}
 		})
 	}
@@ -370,8 +948,8 @@ func TestInventoryService_GetInventoryValue(t *testing.T) {
 			"success",
 			func() *mockProductRepository {
 				repo := newMockProductRepository()
-				repo.Save(p1)
-				repo.Save(p2)
+				repo.Save(p1, nil, nil)
+				repo.Save(p2, nil, nil)
 				return repo
 			},
 			205.00, false,
@@ -397,39 +975,386 @@ func TestInventoryService_GetInventoryValue(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := tt.setupRepo()
-			service := NewInventoryService(repo, &mockNotifier{})
-			value, err := service.GetInventoryValue()
+			service := newTestInventoryService(repo, &mockNotifier{})
+			report, err := service.GetInventoryValue("")
 
 			if (err != nil) != tt.expectErr {
 				t.Errorf("GetInventoryValue() error = %v, expectErr %v", err, tt.expectErr)
 			}
-			if !tt.expectErr && value != tt.wantValue {
-				t.Errorf("GetInventoryValue() got = %f, want %f", value, tt.wantValue)
+			if !tt.expectErr && report.Total != tt.wantValue {
+				t.Errorf("GetInventoryValue() got = %f, want %f", report.Total, tt.wantValue)
 			}
 		})
 	}
 }
 
-// func TestInventoryService_UpdateProductPrice(t *testing.T) {
-// 	p, _ := domain.CreateNewProduct("Mouse", 50, 5)
+func TestInventoryService_GetInventoryValueByCategory(t *testing.T) {
+	repo := newMockProductRepository()
+	for _, p := range []struct {
+		categoryId string
+		price      float64
+		quantity   int
+	}{{"c-cables", 4, 10}, {"c-electronics", 100, 2}, {"", 1, 5}} {
+		product, _ := domain.CreateNewProduct("Product", p.price, p.quantity)
+		product.CategoryId = p.categoryId
+		repo.Save(product, nil, nil)
+	}
+	categories := newMockCategoryRepository(
+		domain.Category{Id: "c-electronics", Name: "Electronics"},
+		domain.Category{Id: "c-cables", Name: "Cables", ParentId: "c-electronics"},
+	)
+	service := NewInventoryService(repo, categories, newMockLocationRepository(), newMockLotRepository(repo), NewAlertService(newMockAlertRepository(), &mockNotifier{}, 0, 0), 0)
 
-// 	tests := []struct {
-// 		name       string
-// 		productID  string
-// 		newPrice   float64
-// 		repoShould bool
-// 		expectErr  bool
-// 		wantPrice  float64
-// 	}{
-// 		{"success", p.Id, 55.50, false, false, 55.50},
-// 	}
+	report, err := service.GetInventoryValue("")
+	if err != nil {
+		t.Fatalf("GetInventoryValue() error = %v", err)
+	}
+	if report.Total != 245 || report.Uncategorized != 5 || len(report.Categories) != 2 {
+		t.Fatalf("GetInventoryValue() = %+v", report)
+	}
+	if cables := report.Categories[0]; cables.Value != 40 || cables.OwnValue != 40 {
+		t.Errorf("cables value = %+v, want 40", cables)
+	}
+	if electronics := report.Categories[1]; electronics.Value != 240 || electronics.OwnValue != 200 {
+		t.Errorf("electronics value = %+v, want 240 including cables", electronics)
+	}
 
-// 	for _, tt := range tests {
-// 		t.Run(tt.name, func(t *testing.T) {
-// 			repo := newMockProductRepository()
-// 			clone := *p
-// 			repo.Save(&clone)
+	categories.shouldError = true
+	if _, err := service.GetInventoryValue(""); err == nil {
+		t.Error("GetInventoryValue() with a failing category repository returned no error")
+	}
+}
+
+func TestInventoryService_UpdateProductPrice(t *testing.T) {
+	p, _ := domain.CreateNewProduct("Mouse", 50, 5)
+
+	tests := []struct {
+		name            string
+		productID       string
+		newPrice        float64
+		expectedVersion int
+		repoShould      bool
+		wantErr         error
+		wantPrice       float64
+	}{
+		{"success", p.Id, 55.50, 0, false, nil, 55.50},
+		{"success_matching_version", p.Id, 55.50, 1, false, nil, 55.50},
+		{"fail_invalid_price", p.Id, -1, 0, false, nil, 50},
+		{"fail_stale_version", p.Id, 55.50, 3, false, domain.ErrConflict, 50},
+		{"fail_not_found", "wrong-id", 55.50, 0, false, domain.ErrProductNotFound, 50},
+		{"fail_repo_error", p.Id, 55.50, 0, true, domain.ErrProductNotFound, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockProductRepository()
+			clone := *p
+			repo.Save(&clone, nil, nil)
+			repo.shouldError = tt.repoShould
+			service := newTestInventoryService(repo, &mockNotifier{})
 
-// 		})
-// 	}
-// }
+			updated, err := service.UpdateProductPrice(context.Background(), tt.productID, tt.newPrice, tt.expectedVersion)
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateProductPrice() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (updated.Price != tt.wantPrice || updated.Version != 2) {
+				t.Errorf("UpdateProductPrice() got = %+v, want price %f at version 2", updated, tt.wantPrice)
+			}
+			if repo.products[p.Id].Price != tt.wantPrice {
+				t.Errorf("UpdateProductPrice() stored price = %f, want %f", repo.products[p.Id].Price, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestInventoryService_UnversionedUpdateRetriesConflicts(t *testing.T) {
+	p, _ := domain.CreateNewProduct("Keyboard", 30, 10)
+
+	tests := []struct {
+		name            string
+		concurrentSales int
+		expectedVersion int
+		wantErr         error
+	}{
+		{"success_after_a_sale", 1, 0, nil},
+		{"success_after_several_sales", maxUnversionedAttempts - 1, 0, nil},
+		{"fail_keeps_losing", maxUnversionedAttempts, 0, domain.ErrConflict},
+		{"fail_expected_version_not_retried", 1, 1, domain.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockProductRepository()
+			clone := *p
+			repo.Save(&clone, nil, nil)
+			repo.concurrentSales = tt.concurrentSales
+			service := newTestInventoryService(repo, &mockNotifier{})
+
+			_, err := service.UpdateProductPrice(context.Background(), p.Id, 35, tt.expectedVersion)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateProductPrice() error = %v, want %v", err, tt.wantErr)
+			}
+			stored := repo.products[p.Id]
+			if tt.wantErr == nil && (stored.Price != 35 || stored.Quantity != 10-tt.concurrentSales) {
+				t.Errorf("UpdateProductPrice() stored = %+v, want the new price next to the sold stock", stored)
+			}
+		})
+	}
+}
+
+func TestInventoryService_AdjustProductStock(t *testing.T) {
+	p, _ := domain.CreateNewProduct("Pallet", 40, 30)
+
+	tests := []struct {
+		name          string
+		delta         int
+		repoShould    bool
+		wantErr       error
+		expectErr     bool
+		finalQuantity int
+	}{
+		{"success_decrease", -12, false, nil, false, 18},
+		{"success_increase", 5, false, nil, false, 35},
+		{"fail_zero_delta", 0, false, nil, true, 30},
+		{"fail_below_zero", -31, false, domain.ErrInsufficientStock, true, 30},
+		{"fail_repo_error", -1, true, nil, true, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockProductRepository()
+			clone := *p
+			repo.Save(&clone, nil, nil)
+			repo.shouldError = tt.repoShould
+			service := newTestInventoryService(repo, &mockNotifier{})
+
+			ctx := domain.ContextWithManagerId(context.Background(), "manager-7")
+			_, err := service.AdjustProductStock(ctx, p.Id, "", tt.delta, 0)
+
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("AdjustProductStock() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("AdjustProductStock() error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.products[p.Id].Quantity != tt.finalQuantity {
+				t.Errorf("AdjustProductStock() final quantity = %d, want %d", repo.products[p.Id].Quantity, tt.finalQuantity)
+			}
+			if !tt.expectErr && (len(repo.movements) != 1 || repo.movements[0].Reason != domain.MovementAdjustment || repo.movements[0].ManagerId != "manager-7") {
+				t.Errorf("AdjustProductStock() recorded movements = %+v", repo.movements)
+			}
+		})
+	}
+}
+
+func TestInventoryService_StockAtLocation(t *testing.T) {
+	p, _ := domain.CreateNewProduct("Lamp", 10, 30)
+
+	tests := []struct {
+		name         string
+		locationId   string
+		wantErr      error
+		wantLocation string
+	}{
+		{"default_location", "", nil, domain.DefaultLocationId},
+		{"named_location", "store-1", nil, "store-1"},
+		{"unknown_location", "nowhere", domain.ErrLocationInvalid, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockProductRepository()
+			clone := *p
+			repo.Save(&clone, nil, nil)
+			locations := newMockLocationRepository(domain.Location{Id: "store-1", Code: "STORE-1", Name: "High Street"})
+			locations.level(p.Id, "store-1").ReorderPoint = 5
+			alerts := newMockAlertRepository()
+			service := NewInventoryService(repo, newMockCategoryRepository(), locations, newMockLotRepository(repo),
+				NewAlertService(alerts, &mockNotifier{}, 0, 0), 0)
+
+			_, err := service.SellProductUnits(context.Background(), p.Id, tt.locationId, 2, 0)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("SellProductUnits() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(repo.movements) != 0 {
+					t.Errorf("SellProductUnits() recorded movements for an unknown location")
+				}
+				return
+			}
+			if len(repo.movements) != 1 || repo.movements[0].LocationId != tt.wantLocation {
+				t.Errorf("SellProductUnits() recorded movements = %+v, want one at %s", repo.movements, tt.wantLocation)
+			}
+			wantAlerts := 0
+			if tt.wantLocation == "store-1" {
+				wantAlerts = 1
+			}
+			if len(alerts.alerts) != wantAlerts {
+				t.Errorf("recorded %d location alerts, want %d", len(alerts.alerts), wantAlerts)
+			}
+		})
+	}
+}
+
+func TestInventoryService_GetInventoryValueAtLocation(t *testing.T) {
+	service := newTestInventoryService(newMockProductRepository(), &mockNotifier{})
+	if _, err := service.GetInventoryValue(domain.DefaultLocationId); err != nil {
+		t.Errorf("GetInventoryValue() at the main location error = %v", err)
+	}
+	if _, err := service.GetInventoryValue("nowhere"); !errors.Is(err, domain.ErrInvalidQuery) {
+		t.Errorf("GetInventoryValue() at an unknown location error = %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestInventoryService_GetStockMovements(t *testing.T) {
+	repo := newMockProductRepository()
+	now := time.Now().UTC()
+	repo.movements = []domain.StockMovement{
+		{Id: "m1", ProductId: "p1", Delta: -2, Reason: domain.MovementSale, CreatedAt: now.Add(-48 * time.Hour)},
+		{Id: "m2", ProductId: "p1", Delta: 10, Reason: domain.MovementRestock, CreatedAt: now.Add(-time.Hour)},
+		{Id: "m3", ProductId: "p2", Delta: -1, Reason: domain.MovementSale, CreatedAt: now.Add(-time.Hour)},
+	}
+	service := newTestInventoryService(repo, &mockNotifier{})
+
+	tests := []struct {
+		name      string
+		from      time.Time
+		to        time.Time
+		wantCount int
+	}{
+		{"all_history", time.Time{}, time.Time{}, 2},
+		{"from_yesterday", now.Add(-24 * time.Hour), time.Time{}, 1},
+		{"until_yesterday", time.Time{}, now.Add(-24 * time.Hour), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movements, err := service.GetStockMovements("p1", tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GetStockMovements() returned an unexpected error: %v", err)
+			}
+			if len(movements) != tt.wantCount {
+				t.Errorf("GetStockMovements() count = %d, want %d", len(movements), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestInventoryService_UpdateReorderThresholds(t *testing.T) {
+	p, _ := domain.CreateNewProduct("Server", 9000, 3)
+
+	tests := []struct {
+		name            string
+		productID       string
+		reorderPoint    int
+		reorderQuantity int
+		expectedVersion int
+		wantErr         error
+		expectErr       bool
+	}{
+		{"success", p.Id, 2, 4, 0, nil, false},
+		{"success_matching_version", p.Id, 2, 4, 1, nil, false},
+		{"fail_negative_threshold", p.Id, -2, 4, 0, domain.ErrProductInvalid, true},
+		{"fail_stale_version", p.Id, 2, 4, 9, domain.ErrConflict, true},
+		{"fail_not_found", "wrong-id", 2, 4, 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockProductRepository()
+			clone := *p
+			repo.Save(&clone, nil, nil)
+			service := newTestInventoryService(repo, &mockNotifier{})
+
+			updated, err := service.UpdateReorderThresholds(context.Background(), tt.productID, tt.reorderPoint, tt.reorderQuantity, tt.expectedVersion)
+
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("UpdateReorderThresholds() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateReorderThresholds() error = %v, want %v", err, tt.wantErr)
+			}
+			if !tt.expectErr {
+				stored := repo.products[p.Id]
+				if stored.ReorderPoint != tt.reorderPoint || stored.ReorderQuantity != tt.reorderQuantity || updated.Version != 2 {
+					t.Errorf("UpdateReorderThresholds() stored = %+v", stored)
+				}
+			}
+		})
+	}
+}
+
+func TestInventoryService_RestockCreatesLot(t *testing.T) {
+	repo := newMockProductRepository()
+	p, _ := domain.CreateNewProduct("Milk", 2, 0)
+	repo.Save(p, nil, nil)
+	lots := newMockLotRepository(repo)
+	service := NewInventoryService(repo, newMockCategoryRepository(), newMockLocationRepository(), lots,
+		NewAlertService(newMockAlertRepository(), &mockNotifier{}, 0, 0), 0)
+
+	expiresAt := time.Now().Add(72 * time.Hour)
+	if _, err := service.RestockProduct(context.Background(), p.Id, "", 12, domain.LotReceipt{LotNumber: "L-7", ExpiresAt: &expiresAt}, 0); err != nil {
+		t.Fatalf("RestockProduct() returned an unexpected error: %v", err)
+	}
+	if len(lots.lots) != 1 || lots.lots[0].LotNumber != "L-7" || lots.lots[0].Quantity != 12 || lots.lots[0].LocationId != domain.DefaultLocationId {
+		t.Errorf("lots after restock = %+v, want one lot of 12 at the main location", lots.lots)
+	}
+
+	expired := time.Now().Add(-time.Hour)
+	if _, err := service.RestockProduct(context.Background(), p.Id, "", 3, domain.LotReceipt{ExpiresAt: &expired}, 0); !errors.Is(err, domain.ErrLotInvalid) {
+		t.Errorf("RestockProduct() of an expired lot error = %v, want ErrLotInvalid", err)
+	}
+	if product, _ := repo.FindById(p.Id); product.Quantity != 12 {
+		t.Errorf("quantity after the rejected restock = %d, want 12", product.Quantity)
+	}
+}
+
+func TestInventoryService_WriteOffLot(t *testing.T) {
+	repo := newMockProductRepository()
+	p, _ := domain.CreateNewProduct("Milk", 2, 7)
+	repo.Save(p, nil, nil)
+	lots := newMockLotRepository(repo)
+	service := NewInventoryService(repo, newMockCategoryRepository(), newMockLocationRepository(), lots,
+		NewAlertService(newMockAlertRepository(), &mockNotifier{}, 0, 0), 0)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	lots.lots = []domain.Lot{
+		{Id: "expired", ProductId: p.Id, LocationId: domain.DefaultLocationId, LotNumber: "L-1", Quantity: 4, ExpiresAt: &yesterday},
+		{Id: "empty", ProductId: p.Id, LocationId: domain.DefaultLocationId, LotNumber: "L-2"},
+		{Id: "other", ProductId: "p-other", LocationId: domain.DefaultLocationId, LotNumber: "L-3", Quantity: 1},
+	}
+
+	tests := []struct {
+		name    string
+		lotId   string
+		wantErr error
+	}{
+		{"unknown_lot", "missing", domain.ErrLotNotFound},
+		{"lot_of_another_product", "other", domain.ErrLotNotFound},
+		{"empty_lot", "empty", domain.ErrLotInvalid},
+		{"expired_lot", "expired", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product, err := service.WriteOffLot(context.Background(), p.Id, tt.lotId, 0)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("WriteOffLot() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || product.Quantity != 3 {
+				t.Fatalf("WriteOffLot() = %+v, %v, want 3 left", product, err)
+			}
+		})
+	}
+
+	movements, _ := repo.ListMovements(p.Id, time.Time{}, time.Time{})
+	last := movements[len(movements)-1]
+	if last.Reason != domain.MovementWriteOff || last.Delta != -4 || last.LotId != "expired" {
+		t.Errorf("last movement = %+v, want a write-off of the lot", last)
+	}
+	if len(repo.audits) != 1 || repo.audits[0].Action != domain.AuditStockWrittenOff {
+		t.Errorf("audit entries = %+v, want one write-off", repo.audits)
+	}
+}
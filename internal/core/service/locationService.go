@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+type locationService struct {
+	locations ports.LocationRepository
+	transfers ports.TransferRepository
+	products  ports.ProductRepository
+	alerts    AlertService
+	now       func() time.Time
+}
+
+func NewLocationService(locations ports.LocationRepository, transfers ports.TransferRepository, products ports.ProductRepository,
+	alerts AlertService) LocationService {
+	return &locationService{
+		locations: locations,
+		transfers: transfers,
+		products:  products,
+		alerts:    alerts,
+		now:       time.Now,
+	}
+}
+
+func (s *locationService) CreateLocation(code, name string, kind domain.LocationKind) (*domain.Location, error) {
+	location, err := domain.NewLocation(code, name, kind, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create location: %w", err)
+	}
+	if err := s.locations.SaveLocation(location); err != nil {
+		return nil, fmt.Errorf("failed to save location: %w", err)
+	}
+	return location, nil
+}
+
+func (s *locationService) ListLocations() ([]domain.Location, error) {
+	locations, err := s.locations.ListLocations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locations: %w", err)
+	}
+	return locations, nil
+}
+
+func (s *locationService) GetProductStock(productId string) (*domain.ProductStock, error) {
+	if _, err := s.products.FindById(productId); err != nil {
+		return nil, fmt.Errorf("failed to get stock of product %s: %w", productId, err)
+	}
+	stock, err := s.locations.ProductStock(productId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock of product %s: %w", productId, err)
+	}
+	return stock, nil
+}
+
+func (s *locationService) SetLocationReorderPoint(productId, locationId string, reorderPoint int) (*domain.StockLevel, error) {
+	if _, err := s.products.FindById(productId); err != nil {
+		return nil, fmt.Errorf("could not find the product to update its reorder point: %w", err)
+	}
+	if _, err := s.locations.FindLocationById(locationId); err != nil {
+		return nil, fmt.Errorf("could not find the location to update its reorder point: %w", err)
+	}
+
+	level, err := s.locations.FindStockLevel(productId, locationId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update reorder point: %w", err)
+	}
+	if err := level.SetReorderPoint(reorderPoint); err != nil {
+		return nil, fmt.Errorf("failed to update reorder point: %w", err)
+	}
+	if err := s.locations.SetStockReorderPoint(level); err != nil {
+		return nil, fmt.Errorf("could not save the updated reorder point: %w", err)
+	}
+
+	s.alerts.CheckLocationStock(level)
+	return level, nil
+}
+
+// CreateTransfer sends stock from one location towards another. Until it is
+// received or cancelled the quantity is counted as in transit.
+func (s *locationService) CreateTransfer(ctx context.Context, productId, fromLocationId, toLocationId string, quantity int) (*domain.Transfer, error) {
+	transfer, err := domain.NewTransfer(productId, fromLocationId, toLocationId, quantity, domain.ActorFromContext(ctx), s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transfer: %w", err)
+	}
+	for _, locationId := range []string{fromLocationId, toLocationId} {
+		if _, err := s.locations.FindLocationById(locationId); err != nil {
+			if errors.Is(err, domain.ErrLocationNotFound) {
+				return nil, fmt.Errorf("%w: location %s does not exist", domain.ErrTransferInvalid, locationId)
+			}
+			return nil, fmt.Errorf("failed to create transfer: %w", err)
+		}
+	}
+
+	if err := s.transfers.CreateTransfer(transfer); err != nil {
+		return nil, fmt.Errorf("failed to create transfer: %w", err)
+	}
+	s.checkLocationStock(transfer.ProductId, transfer.FromLocationId)
+	return transfer, nil
+}
+
+func (s *locationService) ReceiveTransfer(id string) (*domain.Transfer, error) {
+	transfer, err := s.transfers.ReceiveTransfer(id, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive transfer %s: %w", id, err)
+	}
+	s.checkLocationStock(transfer.ProductId, transfer.ToLocationId)
+	return transfer, nil
+}
+
+func (s *locationService) CancelTransfer(id string) (*domain.Transfer, error) {
+	transfer, err := s.transfers.CancelTransfer(id, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel transfer %s: %w", id, err)
+	}
+	s.checkLocationStock(transfer.ProductId, transfer.FromLocationId)
+	return transfer, nil
+}
+
+func (s *locationService) ListTransfers(status string) ([]domain.Transfer, error) {
+	transferStatus, err := domain.ParseTransferStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := s.transfers.ListTransfers(transferStatus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+func (s *locationService) checkLocationStock(productId, locationId string) {
+	level, err := s.locations.FindStockLevel(productId, locationId)
+	if err != nil {
+		log.Printf("could not check stock of product %s at %s: %v", productId, locationId, err)
+		return
+	}
+	s.alerts.CheckLocationStock(level)
+}
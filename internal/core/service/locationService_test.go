@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type mockLocationRepository struct {
+	locations   map[string]*domain.Location
+	levels      map[[2]string]*domain.StockLevel
+	transfers   map[string]*domain.Transfer
+	shouldError bool
+}
+
+func newMockLocationRepository(locations ...domain.Location) *mockLocationRepository {
+	m := &mockLocationRepository{
+		locations: map[string]*domain.Location{domain.DefaultLocationId: {Id: domain.DefaultLocationId, Code: "MAIN", Name: "Main warehouse"}},
+		levels:    make(map[[2]string]*domain.StockLevel),
+		transfers: make(map[string]*domain.Transfer),
+	}
+	for i := range locations {
+		m.locations[locations[i].Id] = &locations[i]
+	}
+	return m
+}
+
+func (m *mockLocationRepository) level(productId, locationId string) *domain.StockLevel {
+	key := [2]string{productId, locationId}
+	if _, ok := m.levels[key]; !ok {
+		m.levels[key] = &domain.StockLevel{ProductId: productId, LocationId: locationId}
+	}
+	return m.levels[key]
+}
+
+func (m *mockLocationRepository) SaveLocation(location *domain.Location) error {
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	for _, existing := range m.locations {
+		if existing.Code == location.Code {
+			return domain.ErrLocationExists
+		}
+	}
+	saved := *location
+	m.locations[location.Id] = &saved
+	return nil
+}
+
+func (m *mockLocationRepository) FindLocationById(id string) (*domain.Location, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	location, ok := m.locations[id]
+	if !ok {
+		return nil, domain.ErrLocationNotFound
+	}
+	found := *location
+	return &found, nil
+}
+
+func (m *mockLocationRepository) ListLocations() ([]domain.Location, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	locations := []domain.Location{}
+	for _, location := range m.locations {
+		locations = append(locations, *location)
+	}
+	return locations, nil
+}
+
+func (m *mockLocationRepository) FindStockLevel(productId, locationId string) (*domain.StockLevel, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	level := *m.level(productId, locationId)
+	return &level, nil
+}
+
+func (m *mockLocationRepository) ProductStock(productId string) (*domain.ProductStock, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	stock := &domain.ProductStock{ProductId: productId, Levels: []domain.StockLevel{}}
+	for key, level := range m.levels {
+		if key[0] == productId {
+			stock.Levels = append(stock.Levels, *level)
+		}
+	}
+	for _, transfer := range m.transfers {
+		if transfer.ProductId == productId && transfer.Status == domain.TransferInTransit {
+			stock.InTransit += transfer.Quantity
+		}
+	}
+	return stock, nil
+}
+
+func (m *mockLocationRepository) SetStockReorderPoint(level *domain.StockLevel) error {
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	m.level(level.ProductId, level.LocationId).ReorderPoint = level.ReorderPoint
+	return nil
+}
+
+func (m *mockLocationRepository) CreateTransfer(transfer *domain.Transfer) error {
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	source := m.level(transfer.ProductId, transfer.FromLocationId)
+	if source.Quantity < transfer.Quantity {
+		return domain.ErrInsufficientStock
+	}
+	source.Quantity -= transfer.Quantity
+	saved := *transfer
+	m.transfers[transfer.Id] = &saved
+	return nil
+}
+
+func (m *mockLocationRepository) ReceiveTransfer(id string, receivedAt time.Time) (*domain.Transfer, error) {
+	return m.completeTransfer(id, domain.TransferReceived, receivedAt)
+}
+
+func (m *mockLocationRepository) CancelTransfer(id string, cancelledAt time.Time) (*domain.Transfer, error) {
+	return m.completeTransfer(id, domain.TransferCancelled, cancelledAt)
+}
+
+func (m *mockLocationRepository) completeTransfer(id string, status domain.TransferStatus, at time.Time) (*domain.Transfer, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	transfer, ok := m.transfers[id]
+	if !ok {
+		return nil, domain.ErrTransferNotFound
+	}
+	if transfer.Status != domain.TransferInTransit {
+		return nil, domain.ErrTransferNotInTransit
+	}
+	transfer.Status = status
+	transfer.CompletedAt = &at
+	locationId := transfer.ToLocationId
+	if status == domain.TransferCancelled {
+		locationId = transfer.FromLocationId
+	}
+	m.level(transfer.ProductId, locationId).Quantity += transfer.Quantity
+	completed := *transfer
+	return &completed, nil
+}
+
+func (m *mockLocationRepository) ListTransfers(status domain.TransferStatus) ([]domain.Transfer, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	transfers := []domain.Transfer{}
+	for _, transfer := range m.transfers {
+		if status == "" || transfer.Status == status {
+			transfers = append(transfers, *transfer)
+		}
+	}
+	return transfers, nil
+}
+
+func newTestLocationService(products *mockProductRepository, locations *mockLocationRepository, alerts *mockAlertRepository) LocationService {
+	return NewLocationService(locations, locations, products, NewAlertService(alerts, &mockNotifier{}, 0, 0))
+}
+
+func TestLocationService_CreateLocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		locName string
+		kind    domain.LocationKind
+		wantErr error
+	}{
+		{"warehouse_by_default", "north", "North warehouse", "", nil},
+		{"store", "store-1", "High Street", domain.LocationStore, nil},
+		{"taken_code", "MAIN", "Another main", "", domain.ErrLocationExists},
+		{"missing_name", "east", " ", "", domain.ErrLocationInvalid},
+		{"unknown_kind", "east", "East", "depot", domain.ErrLocationInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locations := newMockLocationRepository()
+			service := newTestLocationService(newMockProductRepository(), locations, newMockAlertRepository())
+
+			location, err := service.CreateLocation(tt.code, tt.locName, tt.kind)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("CreateLocation() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (location.Kind == "" || locations.locations[location.Id] == nil) {
+				t.Errorf("CreateLocation() = %+v, want it saved with a kind", location)
+			}
+		})
+	}
+}
+
+func TestLocationService_Transfers(t *testing.T) {
+	products := newMockProductRepository()
+	product, _ := domain.CreateNewProduct("Lamp", 10, 8)
+	products.Save(product, nil, nil)
+	locations := newMockLocationRepository(domain.Location{Id: "store-1", Code: "STORE-1", Name: "High Street"})
+	locations.level(product.Id, domain.DefaultLocationId).Quantity = 8
+	locations.level(product.Id, domain.DefaultLocationId).ReorderPoint = 5
+	alerts := newMockAlertRepository()
+	service := newTestLocationService(products, locations, alerts)
+	ctx := domain.ContextWithManagerId(context.Background(), "manager-1")
+
+	tests := []struct {
+		name     string
+		from, to string
+		quantity int
+		wantErr  error
+	}{
+		{"same_location", domain.DefaultLocationId, domain.DefaultLocationId, 1, domain.ErrTransferInvalid},
+		{"zero_quantity", domain.DefaultLocationId, "store-1", 0, domain.ErrTransferInvalid},
+		{"unknown_destination", domain.DefaultLocationId, "nowhere", 1, domain.ErrTransferInvalid},
+		{"more_than_source_holds", domain.DefaultLocationId, "store-1", 9, domain.ErrInsufficientStock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreateTransfer(ctx, product.Id, tt.from, tt.to, tt.quantity); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateTransfer() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	transfer, err := service.CreateTransfer(ctx, product.Id, domain.DefaultLocationId, "store-1", 6)
+	if err != nil {
+		t.Fatalf("CreateTransfer() returned an unexpected error: %v", err)
+	}
+	if transfer.Status != domain.TransferInTransit || transfer.CreatedBy != "manager-1" {
+		t.Errorf("CreateTransfer() = %+v", transfer)
+	}
+	if stock, _ := service.GetProductStock(product.Id); stock.InTransit != 6 {
+		t.Errorf("GetProductStock() in transit = %d, want 6", stock.InTransit)
+	}
+	if len(alerts.alerts) != 1 || alerts.alerts[0].LocationId != domain.DefaultLocationId {
+		t.Errorf("alerts after emptying the source = %+v, want one for the main location", alerts.alerts)
+	}
+
+	if _, err := service.ReceiveTransfer(transfer.Id); err != nil {
+		t.Fatalf("ReceiveTransfer() returned an unexpected error: %v", err)
+	}
+	if _, err := service.CancelTransfer(transfer.Id); !errors.Is(err, domain.ErrTransferNotInTransit) {
+		t.Errorf("CancelTransfer() of a received transfer error = %v, want ErrTransferNotInTransit", err)
+	}
+	if level, _ := locations.FindStockLevel(product.Id, "store-1"); level.Quantity != 6 {
+		t.Errorf("store level after receiving = %d, want 6", level.Quantity)
+	}
+	if received, _ := service.ListTransfers("received"); len(received) != 1 {
+		t.Errorf("ListTransfers(received) returned %d transfers, want 1", len(received))
+	}
+	if _, err := service.ListTransfers("lost"); !errors.Is(err, domain.ErrTransferInvalid) {
+		t.Errorf("ListTransfers() with an unknown status error = %v, want ErrTransferInvalid", err)
+	}
+}
+
+func TestLocationService_SetLocationReorderPoint(t *testing.T) {
+	products := newMockProductRepository()
+	product, _ := domain.CreateNewProduct("Lamp", 10, 3)
+	products.Save(product, nil, nil)
+
+	tests := []struct {
+		name         string
+		productId    string
+		locationId   string
+		reorderPoint int
+		wantErr      error
+		wantAlert    bool
+	}{
+		{"raises_alert_when_below", product.Id, domain.DefaultLocationId, 5, nil, true},
+		{"zero_disables_check", product.Id, domain.DefaultLocationId, 0, nil, false},
+		{"negative", product.Id, domain.DefaultLocationId, -1, domain.ErrLocationInvalid, false},
+		{"unknown_location", product.Id, "nowhere", 5, domain.ErrLocationNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locations := newMockLocationRepository()
+			locations.level(product.Id, domain.DefaultLocationId).Quantity = 3
+			alerts := newMockAlertRepository()
+			service := newTestLocationService(products, locations, alerts)
+
+			level, err := service.SetLocationReorderPoint(tt.productId, tt.locationId, tt.reorderPoint)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("SetLocationReorderPoint() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && level.ReorderPoint != tt.reorderPoint {
+				t.Errorf("SetLocationReorderPoint() = %+v", level)
+			}
+			if (len(alerts.alerts) == 1) != tt.wantAlert {
+				t.Errorf("recorded %d alerts, want alert %v", len(alerts.alerts), tt.wantAlert)
+			}
+		})
+	}
+}
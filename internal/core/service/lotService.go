@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+type lotService struct {
+	lots          ports.LotRepository
+	products      ports.ProductRepository
+	locations     ports.LocationRepository
+	notifier      ports.Notifier
+	warningWindow time.Duration
+	now           func() time.Time
+}
+
+// NewLotService warns about lots expiring within warningWindow, which is
+// also the window the expiring-soon report uses when none is asked for.
+func NewLotService(lots ports.LotRepository, products ports.ProductRepository, locations ports.LocationRepository,
+	notifier ports.Notifier, warningWindow time.Duration) LotService {
+	return &lotService{
+		lots:          lots,
+		products:      products,
+		locations:     locations,
+		notifier:      notifier,
+		warningWindow: warningWindow,
+		now:           time.Now,
+	}
+}
+
+func (s *lotService) ListLots(productId string) ([]domain.Lot, error) {
+	if _, err := s.products.FindById(productId); err != nil {
+		return nil, fmt.Errorf("failed to get lots of product %s: %w", productId, err)
+	}
+	lots, err := s.lots.ListLots(productId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lots of product %s: %w", productId, err)
+	}
+	return lots, nil
+}
+
+// ExpiringLots reports lots that hold stock expiring within the window,
+// including lots that have already expired. A zero window means the
+// configured warning window.
+func (s *lotService) ExpiringLots(locationId string, within time.Duration) ([]domain.ExpiringLot, error) {
+	if within < 0 {
+		return nil, fmt.Errorf("%w: days cannot be negative", domain.ErrInvalidQuery)
+	}
+	if within == 0 {
+		within = s.warningWindow
+	}
+	if locationId != "" {
+		if _, err := s.locations.FindLocationById(locationId); err != nil {
+			if errors.Is(err, domain.ErrLocationNotFound) {
+				return nil, fmt.Errorf("%w: location %s does not exist", domain.ErrInvalidQuery, locationId)
+			}
+			return nil, fmt.Errorf("failed to list expiring lots: %w", err)
+		}
+	}
+	return s.expiringLots(locationId, within)
+}
+
+func (s *lotService) expiringLots(locationId string, within time.Duration) ([]domain.ExpiringLot, error) {
+	now := s.now()
+	lots, err := s.lots.ExpiringLots(now.Add(within), locationId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring lots: %w", err)
+	}
+	for i := range lots {
+		lots[i].Expired = !lots[i].ExpiresAt.After(now)
+	}
+	return lots, nil
+}
+
+// NotifyExpiringLots sends one notification per lot the first time it is
+// found within the warning window and returns how many were sent.
+func (s *lotService) NotifyExpiringLots() (int, error) {
+	lots, err := s.expiringLots("", s.warningWindow)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for i := range lots {
+		if lots[i].ExpiryNotifiedAt != nil {
+			continue
+		}
+		// Mark first so a failure to record it cannot repeat the warning on
+		// every check.
+		if err := s.lots.MarkLotExpiryNotified(lots[i].Id, s.now()); err != nil {
+			return sent, fmt.Errorf("could not record expiry notification for lot %s: %w", lots[i].Id, err)
+		}
+		s.notifier.NotifyExpiring(&lots[i])
+		sent++
+	}
+	return sent, nil
+}
+
+// RunExpiryChecker checks for expiring lots every interval until ctx is done.
+func RunExpiryChecker(ctx context.Context, lots LotService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := lots.NotifyExpiringLots()
+			if err != nil {
+				log.Printf("expiry checker: %v", err)
+			}
+			if sent > 0 {
+				log.Printf("expiry checker: sent %d expiring lot notification(s)", sent)
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+type mockLotRepository struct {
+	products    *mockProductRepository
+	lots        []domain.Lot
+	names       map[string]string
+	shouldError bool
+}
+
+func newMockLotRepository(products *mockProductRepository) *mockLotRepository {
+	return &mockLotRepository{products: products, names: make(map[string]string)}
+}
+
+func (m *mockLotRepository) ReceiveLot(lot *domain.Lot, movement *domain.StockMovement, expectedVersion int, audit *domain.AuditEntry) (*domain.Product, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	movement.LotId = lot.Id
+	product, err := m.products.ApplyStockMovement(movement, expectedVersion, audit)
+	if err != nil {
+		return nil, err
+	}
+	m.lots = append(m.lots, *lot)
+	return product, nil
+}
+
+func (m *mockLotRepository) FindLotById(id string) (*domain.Lot, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	for i := range m.lots {
+		if m.lots[i].Id == id {
+			lot := m.lots[i]
+			return &lot, nil
+		}
+	}
+	return nil, domain.ErrLotNotFound
+}
+
+func (m *mockLotRepository) ListLots(productId string) ([]domain.Lot, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	lots := []domain.Lot{}
+	for _, lot := range m.lots {
+		if lot.ProductId == productId {
+			lots = append(lots, lot)
+		}
+	}
+	return lots, nil
+}
+
+func (m *mockLotRepository) ExpiringLots(by time.Time, locationId string) ([]domain.ExpiringLot, error) {
+	if m.shouldError {
+		return nil, ErrRepoFailed
+	}
+	lots := []domain.ExpiringLot{}
+	for _, lot := range m.lots {
+		if lot.IsExpiringBy(by) && (locationId == "" || lot.LocationId == locationId) {
+			lots = append(lots, domain.ExpiringLot{Lot: lot, ProductName: m.names[lot.ProductId]})
+		}
+	}
+	return lots, nil
+}
+
+func (m *mockLotRepository) MarkLotExpiryNotified(id string, at time.Time) error {
+	if m.shouldError {
+		return ErrRepoFailed
+	}
+	for i := range m.lots {
+		if m.lots[i].Id == id {
+			m.lots[i].ExpiryNotifiedAt = &at
+		}
+	}
+	return nil
+}
+
+func newTestLotService(now time.Time) (LotService, *mockLotRepository, *mockNotifier) {
+	products := newMockProductRepository()
+	lots := newMockLotRepository(products)
+	notifier := &mockNotifier{}
+	service := NewLotService(lots, products, newMockLocationRepository(), notifier, 7*24*time.Hour).(*lotService)
+	service.now = func() time.Time { return now }
+
+	at := func(days int) *time.Time {
+		t := now.Add(time.Duration(days) * 24 * time.Hour)
+		return &t
+	}
+	lots.names["milk"] = "Milk"
+	lots.lots = []domain.Lot{
+		{Id: "expired", ProductId: "milk", LocationId: domain.DefaultLocationId, Quantity: 2, ExpiresAt: at(-1)},
+		{Id: "in-three-days", ProductId: "milk", LocationId: "store-1", Quantity: 5, ExpiresAt: at(3)},
+		{Id: "in-ten-days", ProductId: "milk", LocationId: domain.DefaultLocationId, Quantity: 5, ExpiresAt: at(10)},
+		{Id: "sold-out", ProductId: "milk", LocationId: domain.DefaultLocationId, Quantity: 0, ExpiresAt: at(1)},
+		{Id: "no-expiry", ProductId: "milk", LocationId: domain.DefaultLocationId, Quantity: 5},
+	}
+	return service, lots, notifier
+}
+
+func TestLotService_ExpiringLots(t *testing.T) {
+	tests := []struct {
+		name       string
+		locationId string
+		within     time.Duration
+		wantIds    []string
+		wantErr    error
+	}{
+		{"default_window", "", 0, []string{"expired", "in-three-days"}, nil},
+		{"wider_window", "", 14 * 24 * time.Hour, []string{"expired", "in-three-days", "in-ten-days"}, nil},
+		{"one_location", domain.DefaultLocationId, 0, []string{"expired"}, nil},
+		{"unknown_location", "nowhere", 0, nil, domain.ErrInvalidQuery},
+		{"negative_window", "", -time.Hour, nil, domain.ErrInvalidQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _ := newTestLotService(time.Now())
+			lots, err := service.ExpiringLots(tt.locationId, tt.within)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("ExpiringLots() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(lots) != len(tt.wantIds) {
+				t.Fatalf("ExpiringLots() = %+v, want %v", lots, tt.wantIds)
+			}
+			for i, lot := range lots {
+				if lot.Id != tt.wantIds[i] || lot.Expired != (lot.Id == "expired") {
+					t.Errorf("ExpiringLots()[%d] = %s (expired %v), want %s", i, lot.Id, lot.Expired, tt.wantIds[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLotService_NotifyExpiringLots(t *testing.T) {
+	service, lots, notifier := newTestLotService(time.Now())
+
+	sent, err := service.NotifyExpiringLots()
+	if err != nil || sent != 2 {
+		t.Fatalf("NotifyExpiringLots() = %d, %v, want 2 sent", sent, err)
+	}
+	if len(notifier.expiringLots) != 2 || notifier.expiringLots[0].ProductName != "Milk" || !notifier.expiringLots[0].Expired {
+		t.Errorf("notifications = %+v, want the expired lot first", notifier.expiringLots)
+	}
+
+	if sent, err := service.NotifyExpiringLots(); err != nil || sent != 0 {
+		t.Errorf("second NotifyExpiringLots() = %d, %v, want nothing sent again", sent, err)
+	}
+
+	lots.shouldError = true
+	if _, err := service.NotifyExpiringLots(); !errors.Is(err, ErrRepoFailed) {
+		t.Errorf("NotifyExpiringLots() error = %v, want ErrRepoFailed", err)
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+type managerService struct {
+	repo          ports.ManagerRepository
+	refreshTokens ports.RefreshTokenRepository
+	revocations   ports.RevocationStore
+	tokenTTL      time.Duration
+}
+
+// NewManagerService needs the access token TTL to know how long a revocation
+// of a manager's access tokens has to be kept.
+func NewManagerService(repo ports.ManagerRepository, refreshTokens ports.RefreshTokenRepository,
+	revocations ports.RevocationStore, tokenTTL time.Duration) ManagerService {
+	return &managerService{
+		repo:          repo,
+		refreshTokens: refreshTokens,
+		revocations:   revocations,
+		tokenTTL:      tokenTTL,
+	}
+}
+
+func (s *managerService) CreateManager(email, password string, role domain.Role) (*domain.Manager, error) {
+	manager, err := domain.NewManager(email, password, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manager: %w", err)
+	}
+
+	if err := s.repo.SaveManager(manager); err != nil {
+		return nil, fmt.Errorf("failed to save manager: %w", err)
+	}
+	return manager, nil
+}
+
+func (s *managerService) ListManagers() ([]domain.Manager, error) {
+	managers, err := s.repo.ListManagers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managers: %w", err)
+	}
+	return managers, nil
+}
+
+func (s *managerService) SetManagerDisabled(ctx context.Context, id string, disabled bool) (*domain.Manager, error) {
+	if disabled && id == domain.ManagerIdFromContext(ctx) {
+		return nil, fmt.Errorf("%w: you cannot disable your own account", domain.ErrManagerInvalid)
+	}
+
+	manager, err := s.repo.FindManagerById(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not find manager %s: %w", id, err)
+	}
+
+	manager.Disabled = disabled
+	if err := s.repo.UpdateManager(manager); err != nil {
+		return nil, fmt.Errorf("failed to update manager %s: %w", id, err)
+	}
+	if disabled {
+		if err := s.endSessions(id); err != nil {
+			return nil, err
+		}
+	}
+	return manager, nil
+}
+
+func (s *managerService) ResetPassword(id, newPassword string) (*domain.Manager, error) {
+	manager, err := s.repo.FindManagerById(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not find manager %s: %w", id, err)
+	}
+
+	if err := manager.SetPassword(newPassword); err != nil {
+		return nil, fmt.Errorf("failed to reset password: %w", err)
+	}
+	manager.MustChangePassword = true
+
+	if err := s.repo.UpdateManager(manager); err != nil {
+		return nil, fmt.Errorf("failed to update manager %s: %w", id, err)
+	}
+	if err := s.endSessions(id); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+func (s *managerService) DeleteManager(ctx context.Context, id string) error {
+	if id == domain.ManagerIdFromContext(ctx) {
+		return fmt.Errorf("%w: you cannot delete your own account", domain.ErrManagerInvalid)
+	}
+
+	if err := s.repo.DeleteManager(id); err != nil {
+		return fmt.Errorf("failed to delete manager %s: %w", id, err)
+	}
+	return s.endSessions(id)
+}
+
+// tokenCutoff returns the time before which a manager's access tokens are revoked.
+// Token issue times have whole-second precision, so tokens issued in the same
+// second as the change, such as the ones handed out with it, stay valid.
+func tokenCutoff(now time.Time) time.Time {
+	return now.Truncate(time.Second)
+}
+
+// endSessions revokes the manager's refresh tokens and every access token
+// already issued to them.
+func (s *managerService) endSessions(id string) error {
+	now := time.Now()
+	if err := s.refreshTokens.RevokeManagerRefreshTokens(id, now); err != nil {
+		return fmt.Errorf("failed to revoke sessions of manager %s: %w", id, err)
+	}
+	if err := s.revocations.RevokeManagerTokens(id, tokenCutoff(now), now.Add(s.tokenTTL)); err != nil {
+		return fmt.Errorf("failed to revoke access tokens of manager %s: %w", id, err)
+	}
+	return nil
+}
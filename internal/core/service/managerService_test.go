@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/utils/auth"
+)
+
+func TestManagerService_CreateManager(t *testing.T) {
+	existing := newTestManager(t, "taken@example.com", nil)
+
+	tests := []struct {
+		name       string
+		email      string
+		password   string
+		role       domain.Role
+		repoShould bool
+		wantErr    error
+		expectErr  bool
+	}{
+		{"success", "new@example.com", "long-enough-pw", domain.RoleManager, false, nil, false},
+		{"fail_invalid_role", "new@example.com", "long-enough-pw", domain.Role("owner"), false, domain.ErrManagerInvalid, true},
+		{"fail_duplicate_email", "taken@example.com", "long-enough-pw", domain.RoleClerk, false, domain.ErrManagerExists, true},
+		{"fail_repo_error", "new@example.com", "long-enough-pw", domain.RoleClerk, true, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockManagerRepository(existing)
+			repo.shouldError = tt.repoShould
+			service := NewManagerService(repo, newMockRefreshTokenRepository(), auth.NewMemoryRevocationStore(), time.Hour)
+
+			manager, err := service.CreateManager(tt.email, tt.password, tt.role)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("CreateManager() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateManager() error = %v, want %v", err, tt.wantErr)
+			}
+			if !tt.expectErr && (repo.managers[manager.Id] == nil || !manager.MustChangePassword || manager.Role != tt.role) {
+				t.Errorf("CreateManager() = %+v", manager)
+			}
+		})
+	}
+}
+
+func TestManagerService_SetManagerDisabled(t *testing.T) {
+	admin := newTestManager(t, "admin@example.com", func(m *domain.Manager) { m.Role = domain.RoleAdmin })
+	clerk := newTestManager(t, "clerk@example.com", nil)
+
+	tests := []struct {
+		name     string
+		id       string
+		disabled bool
+		wantErr  error
+	}{
+		{"disable_other_manager", clerk.Id, true, nil},
+		{"enable_other_manager", clerk.Id, false, nil},
+		{"fail_disable_self", admin.Id, true, domain.ErrManagerInvalid},
+		{"fail_not_found", "missing", true, domain.ErrManagerNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockManagerRepository(admin, clerk)
+			refreshTokens := newMockRefreshTokenRepository()
+			session, _, _ := domain.NewRefreshToken(clerk.Id, "", time.Now(), time.Hour)
+			refreshTokens.SaveRefreshToken(session)
+			revocations := auth.NewMemoryRevocationStore()
+			service := NewManagerService(repo, refreshTokens, revocations, time.Hour)
+			issuedAt := time.Now().Truncate(time.Second).Add(-time.Second)
+			ctx := domain.ContextWithManagerId(context.Background(), admin.Id)
+
+			manager, err := service.SetManagerDisabled(ctx, tt.id, tt.disabled)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetManagerDisabled() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (manager.Disabled != tt.disabled || repo.managers[tt.id].Disabled != tt.disabled) {
+				t.Errorf("SetManagerDisabled() stored = %+v", repo.managers[tt.id])
+			}
+			if tt.disabled && tt.wantErr == nil && refreshTokens.activeCount(clerk.Id, time.Now()) != 0 {
+				t.Errorf("disabling the manager left active refresh tokens")
+			}
+			if revoked, _ := revocations.IsManagerTokenRevoked(clerk.Id, issuedAt); revoked != (tt.disabled && tt.wantErr == nil) {
+				t.Errorf("access token issued before the change revoked = %v", revoked)
+			}
+		})
+	}
+}
+
+func TestManagerService_ResetPassword(t *testing.T) {
+	clerk := newTestManager(t, "clerk@example.com", nil)
+
+	tests := []struct {
+		name     string
+		id       string
+		password string
+		wantErr  error
+	}{
+		{"success", clerk.Id, "temporary-password", nil},
+		{"fail_short_password", clerk.Id, "short", domain.ErrManagerInvalid},
+		{"fail_not_found", "missing", "temporary-password", domain.ErrManagerNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockManagerRepository(clerk)
+			service := NewManagerService(repo, newMockRefreshTokenRepository(), auth.NewMemoryRevocationStore(), time.Hour)
+
+			_, err := service.ResetPassword(tt.id, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResetPassword() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			stored := repo.managers[tt.id]
+			if !stored.MustChangePassword || stored.CheckPassword(tt.password) != nil {
+				t.Errorf("ResetPassword() stored = %+v", stored)
+			}
+		})
+	}
+}
+
+func TestManagerService_DeleteManager(t *testing.T) {
+	admin := newTestManager(t, "admin@example.com", nil)
+	clerk := newTestManager(t, "clerk@example.com", nil)
+	repo := newMockManagerRepository(admin, clerk)
+	service := NewManagerService(repo, newMockRefreshTokenRepository(), auth.NewMemoryRevocationStore(), time.Hour)
+	ctx := domain.ContextWithManagerId(context.Background(), admin.Id)
+
+	if err := service.DeleteManager(ctx, admin.Id); !errors.Is(err, domain.ErrManagerInvalid) {
+		t.Errorf("DeleteManager(self) error = %v, want ErrManagerInvalid", err)
+	}
+	if err := service.DeleteManager(ctx, clerk.Id); err != nil || repo.managers[clerk.Id] != nil {
+		t.Errorf("DeleteManager() error = %v, manager still stored = %v", err, repo.managers[clerk.Id] != nil)
+	}
+	if err := service.DeleteManager(ctx, clerk.Id); !errors.Is(err, domain.ErrManagerNotFound) {
+		t.Errorf("DeleteManager() of a missing manager error = %v, want ErrManagerNotFound", err)
+	}
+}
@@ -1,18 +1,100 @@
 package service
 
-import "github.com/amangirdhar210/inventory-manager/internal/core/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
 
 type InventoryService interface {
-	AddProduct(name string, price float64, quantity int) (*domain.Product, error)
+	AddProduct(ctx context.Context, name string, price float64, quantity int, reorderPoint int, reorderQuantity int, details domain.ProductDetails) (*domain.Product, error)
 	GetProduct(id string) (*domain.Product, error)
-	SellProductUnits(id string, quantity int) (*domain.Product, error)
-	RestockProduct(id string, quantity int) (*domain.Product, error)
-	UpdateProductPrice(id string, newPrice float64) error
-	GetAllProducts() ([]domain.Product, error)
-	DeleteProduct(id string) error
-	GetInventoryValue() (float64, error)
+	GetProductBySKU(sku string) (*domain.Product, error)
+	GetProductByBarcode(barcode string) (*domain.Product, error)
+	SellProductUnits(ctx context.Context, id string, locationId string, quantity int, expectedVersion int) (*domain.Product, error)
+	RestockProduct(ctx context.Context, id string, locationId string, quantity int, receipt domain.LotReceipt, expectedVersion int) (*domain.Product, error)
+	AdjustProductStock(ctx context.Context, id string, locationId string, delta int, expectedVersion int) (*domain.Product, error)
+	WriteOffLot(ctx context.Context, id string, lotId string, expectedVersion int) (*domain.Product, error)
+	UpdateProductPrice(ctx context.Context, id string, newPrice float64, expectedVersion int) (*domain.Product, error)
+	UpdateProductDetails(ctx context.Context, id string, details domain.ProductDetails, expectedVersion int) (*domain.Product, error)
+	AssignProductCategory(ctx context.Context, id string, categoryId string, expectedVersion int) (*domain.Product, error)
+	UpdateReorderThresholds(ctx context.Context, id string, reorderPoint int, reorderQuantity int, expectedVersion int) (*domain.Product, error)
+	ListProducts(query domain.ProductQuery) (*domain.ProductPage, error)
+	DeleteProduct(ctx context.Context, id string) error
+	RestoreProduct(ctx context.Context, id string) (*domain.Product, error)
+	PurgeDeletedProducts(ctx context.Context, olderThanDays int) ([]domain.Product, error)
+	GetInventoryValue(locationId string) (*domain.InventoryValueReport, error)
+	GetStockMovements(id string, from, to time.Time) ([]domain.StockMovement, error)
+}
+
+type CategoryService interface {
+	CreateCategory(name, parentId string) (*domain.Category, error)
+	GetCategory(id string) (*domain.Category, error)
+	ListCategories() ([]domain.Category, error)
+	UpdateCategory(id, name, parentId string) (*domain.Category, error)
+	DeleteCategory(id string) error
+}
+
+type LocationService interface {
+	CreateLocation(code, name string, kind domain.LocationKind) (*domain.Location, error)
+	ListLocations() ([]domain.Location, error)
+	GetProductStock(productId string) (*domain.ProductStock, error)
+	SetLocationReorderPoint(productId, locationId string, reorderPoint int) (*domain.StockLevel, error)
+	CreateTransfer(ctx context.Context, productId, fromLocationId, toLocationId string, quantity int) (*domain.Transfer, error)
+	ReceiveTransfer(id string) (*domain.Transfer, error)
+	CancelTransfer(id string) (*domain.Transfer, error)
+	ListTransfers(status string) ([]domain.Transfer, error)
+}
+
+type BinService interface {
+	CreateBin(locationId, code string, aisle, shelf int) (*domain.Bin, error)
+	ListBins(locationId string) ([]domain.Bin, error)
+	SetBinStock(binId, productId string, quantity int) (*domain.BinStock, error)
+	ProductBins(productId string) ([]domain.BinSlot, error)
+	GetPickList(locationId string, items []domain.PickRequest) (*domain.PickList, error)
+}
+
+type LotService interface {
+	ListLots(productId string) ([]domain.Lot, error)
+	ExpiringLots(locationId string, within time.Duration) ([]domain.ExpiringLot, error)
+	NotifyExpiringLots() (int, error)
+}
+
+type AlertService interface {
+	CheckStockLevel(product *domain.Product)
+	CheckLocationStock(level *domain.StockLevel)
+	ListAlerts(status string) ([]domain.Alert, error)
+	AcknowledgeAlert(ctx context.Context, id string) (*domain.Alert, error)
 }
 
 type AuthService interface {
-	Login(email, password string) (string, error)
+	Login(email, password, clientIP string) (*domain.LoginResult, error)
+	VerifyMFA(mfaToken, code, clientIP string) (*domain.TokenPair, error)
+	ChangePassword(email, currentPassword, newPassword, code, clientIP string) (*domain.LoginResult, error)
+	Refresh(refreshToken string) (*domain.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	EnrollTOTP(ctx context.Context) (*domain.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, code string) error
+}
+
+type ManagerService interface {
+	CreateManager(email, password string, role domain.Role) (*domain.Manager, error)
+	ListManagers() ([]domain.Manager, error)
+	SetManagerDisabled(ctx context.Context, id string, disabled bool) (*domain.Manager, error)
+	ResetPassword(id, newPassword string) (*domain.Manager, error)
+	DeleteManager(ctx context.Context, id string) error
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, name string, permissions []domain.Permission, productIds []string, expiresAt *time.Time) (*domain.APIKey, string, error)
+	ListAPIKeys() ([]domain.APIKey, error)
+	RevokeAPIKey(id string) error
+	AuthenticateAPIKey(raw string) (*domain.Principal, error)
+}
+
+type AuditService interface {
+	ListAuditEntries(query domain.AuditQuery) ([]domain.AuditEntry, error)
+	ExportAuditEntries(query domain.AuditQuery, visit func(domain.AuditEntry) error) error
 }
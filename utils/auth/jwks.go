@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+)
+
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyId     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS lists the public halves of the ring's asymmetric keys. HS256 secrets are
+// never published.
+func (ring *KeyRing) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, kid := range ring.order {
+		key := ring.keys[kid]
+		jwk := JSONWebKey{KeyId: key.Id, Algorithm: key.Algorithm, Use: "sig"}
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = encodeBase64URL(public.N.Bytes())
+			jwk.Exponent = encodeBase64URL(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = encodeBase64URL(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func NewJWKSHandler(ring *KeyRing) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := json.Marshal(ring.JWKS())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write(body)
+	}
+}
+
+func encodeBase64URL(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
@@ -6,27 +6,130 @@ import (
 	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
 	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var _ ports.TokenGenerator = (*JWTGenerator)(nil)
+var _ ports.TokenValidator = (*JWTGenerator)(nil)
+var _ ports.MFAChallengeIssuer = (*JWTGenerator)(nil)
+
+const (
+	TokenIssuer   = "inventory-manager"
+	TokenAudience = "managers"
+	// MFAAudience keeps challenge tokens from being accepted as access tokens
+	// and the other way round.
+	MFAAudience = "mfa-challenge"
+)
 
 type JWTGenerator struct {
-	secretKey string
+	keys         *KeyRing
+	tokenTTL     time.Duration
+	challengeTTL time.Duration
+	parser       *jwt.Parser
+	mfaParser    *jwt.Parser
+}
+
+func NewJWTGenerator(keys *KeyRing, tokenTTL, challengeTTL time.Duration) *JWTGenerator {
+	return &JWTGenerator{
+		keys:         keys,
+		tokenTTL:     tokenTTL,
+		challengeTTL: challengeTTL,
+		parser:       newParser(keys, TokenAudience),
+		mfaParser:    newParser(keys, MFAAudience),
+	}
 }
 
-func NewJWTGenerator(secretKey string) *JWTGenerator {
-	return &JWTGenerator{secretKey: secretKey}
+func newParser(keys *KeyRing, audience string) *jwt.Parser {
+	return jwt.NewParser(
+		jwt.WithValidMethods(keys.algorithms),
+		jwt.WithIssuer(TokenIssuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+}
+
+type managerClaims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
 }
 
 func (g *JWTGenerator) GenerateToken(manager *domain.Manager) (string, error) {
-	claims := &jwt.RegisteredClaims{
-		Issuer:    "inventory-manager",
-		Subject:   manager.Id,
-		Audience:  jwt.ClaimStrings{"managers"},
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	claims := &managerClaims{
+		Role: string(manager.Role),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer,
+			Subject:   manager.Id,
+			ID:        uuid.NewString(),
+			Audience:  jwt.ClaimStrings{TokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(g.tokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return g.keys.sign(claims)
+}
+
+func (g *JWTGenerator) TokenTTL() time.Duration {
+	return g.tokenTTL
+}
+
+func (g *JWTGenerator) ValidateToken(tokenString string) (*domain.Principal, error) {
+	claims := &managerClaims{}
+	_, err := g.parser.ParseWithClaims(tokenString, claims, g.keys.verificationKey)
+	if err != nil {
+		return nil, domain.ErrTokenInvalid
+	}
+	role, err := domain.ParseRole(claims.Role)
+	if err != nil {
+		return nil, domain.ErrTokenInvalid
 	}
+	if claims.ID == "" {
+		return nil, domain.ErrTokenInvalid
+	}
+	// A token without an issue time counts as issued before any revocation.
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return &domain.Principal{
+		ManagerId: claims.Subject,
+		Role:      role,
+		TokenId:   claims.ID,
+		IssuedAt:  issuedAt,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(g.secretKey))
+func (g *JWTGenerator) IssueMFAChallenge(manager *domain.Manager) (string, *domain.MFAChallenge, error) {
+	now := time.Now()
+	challenge := &domain.MFAChallenge{
+		Id:        uuid.NewString(),
+		ManagerId: manager.Id,
+		ExpiresAt: now.Add(g.challengeTTL),
+	}
+	token, err := g.keys.sign(&jwt.RegisteredClaims{
+		Issuer:    TokenIssuer,
+		Subject:   challenge.ManagerId,
+		ID:        challenge.Id,
+		Audience:  jwt.ClaimStrings{MFAAudience},
+		ExpiresAt: jwt.NewNumericDate(challenge.ExpiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return token, challenge, nil
+}
+
+func (g *JWTGenerator) ParseMFAChallenge(tokenString string) (*domain.MFAChallenge, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := g.mfaParser.ParseWithClaims(tokenString, claims, g.keys.verificationKey)
+	if err != nil || claims.ID == "" || claims.Subject == "" {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+	return &domain.MFAChallenge{
+		Id:        claims.ID,
+		ManagerId: claims.Subject,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
 }
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey is one entry of a KeyRing. Keys loaded from a public key only can
+// verify tokens but never sign them.
+type SigningKey struct {
+	Id        string
+	Algorithm string
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func NewHMACKey(kid string, secret []byte) *SigningKey {
+	return &SigningKey{Id: kid, Algorithm: AlgorithmHS256, signKey: secret, verifyKey: secret}
+}
+
+func NewRSAKey(kid string, key *rsa.PrivateKey) *SigningKey {
+	return &SigningKey{Id: kid, Algorithm: AlgorithmRS256, signKey: key, verifyKey: &key.PublicKey}
+}
+
+func NewRSAPublicKey(kid string, key *rsa.PublicKey) *SigningKey {
+	return &SigningKey{Id: kid, Algorithm: AlgorithmRS256, verifyKey: key}
+}
+
+func NewEd25519Key(kid string, key ed25519.PrivateKey) *SigningKey {
+	return &SigningKey{Id: kid, Algorithm: AlgorithmEdDSA, signKey: key, verifyKey: key.Public()}
+}
+
+func NewEd25519PublicKey(kid string, key ed25519.PublicKey) *SigningKey {
+	return &SigningKey{Id: kid, Algorithm: AlgorithmEdDSA, verifyKey: key}
+}
+
+// ParseSigningKey builds a key from an HS256 secret or a PEM encoded private key.
+func ParseSigningKey(kid, algorithm string, material []byte) (*SigningKey, error) {
+	switch algorithm {
+	case AlgorithmHS256:
+		return NewHMACKey(kid, material), nil
+	case AlgorithmRS256:
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(material)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", kid, err)
+		}
+		return NewRSAKey(kid, key), nil
+	case AlgorithmEdDSA:
+		key, err := jwt.ParseEdPrivateKeyFromPEM(material)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", kid, err)
+		}
+		return NewEd25519Key(kid, key.(ed25519.PrivateKey)), nil
+	default:
+		return nil, fmt.Errorf("key %q: unsupported algorithm %q", kid, algorithm)
+	}
+}
+
+// ParseVerificationKey builds a verify-only key from a PEM encoded public key.
+func ParseVerificationKey(kid, algorithm string, material []byte) (*SigningKey, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err := jwt.ParseRSAPublicKeyFromPEM(material)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", kid, err)
+		}
+		return NewRSAPublicKey(kid, key), nil
+	case AlgorithmEdDSA:
+		key, err := jwt.ParseEdPublicKeyFromPEM(material)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", kid, err)
+		}
+		return NewEd25519PublicKey(kid, key.(ed25519.PublicKey)), nil
+	default:
+		return nil, fmt.Errorf("key %q: algorithm %q has no public key", kid, algorithm)
+	}
+}
+
+func (key *SigningKey) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(key.Algorithm)
+}
+
+// KeyRing signs with its active key and verifies with any key it holds, so
+// tokens signed by a retired key stay valid until they expire.
+type KeyRing struct {
+	active     *SigningKey
+	keys       map[string]*SigningKey
+	order      []string
+	algorithms []string
+}
+
+func NewKeyRing(active *SigningKey, retired ...*SigningKey) (*KeyRing, error) {
+	if active == nil {
+		return nil, errors.New("key ring needs an active key")
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("active key %q has no private key", active.Id)
+	}
+	ring := &KeyRing{active: active, keys: make(map[string]*SigningKey)}
+	seenAlgorithms := make(map[string]bool)
+	for _, key := range append([]*SigningKey{active}, retired...) {
+		if key.Id == "" {
+			return nil, errors.New("every key needs a kid")
+		}
+		if _, exists := ring.keys[key.Id]; exists {
+			return nil, fmt.Errorf("kid %q is used by more than one key", key.Id)
+		}
+		if key.method() == nil {
+			return nil, fmt.Errorf("key %q: unsupported algorithm %q", key.Id, key.Algorithm)
+		}
+		ring.keys[key.Id] = key
+		ring.order = append(ring.order, key.Id)
+		if !seenAlgorithms[key.Algorithm] {
+			seenAlgorithms[key.Algorithm] = true
+			ring.algorithms = append(ring.algorithms, key.Algorithm)
+		}
+	}
+	return ring, nil
+}
+
+func (ring *KeyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ring.active.method(), claims)
+	token.Header["kid"] = ring.active.Id
+	return token.SignedString(ring.active.signKey)
+}
+
+func (ring *KeyRing) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ring.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("kid %q expects %s, token is signed with %s", kid, key.Algorithm, token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testManager = &domain.Manager{Id: "manager-1", Role: domain.RoleManager}
+
+func generateTestKeys(t *testing.T) (*rsa.PrivateKey, ed25519.PrivateKey) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	return rsaKey, edKey
+}
+
+func mustKeyRing(t *testing.T, active *SigningKey, retired ...*SigningKey) *KeyRing {
+	ring, err := NewKeyRing(active, retired...)
+	if err != nil {
+		t.Fatalf("NewKeyRing() returned an unexpected error: %v", err)
+	}
+	return ring
+}
+
+func TestJWTGenerator_Algorithms(t *testing.T) {
+	rsaKey, edKey := generateTestKeys(t)
+	keys := []*SigningKey{
+		NewHMACKey("hmac", []byte("0123456789abcdef0123456789abcdef")),
+		NewRSAKey("rsa", rsaKey),
+		NewEd25519Key("ed", edKey),
+	}
+
+	for _, key := range keys {
+		t.Run(key.Algorithm, func(t *testing.T) {
+			generator := NewJWTGenerator(mustKeyRing(t, key), time.Hour, 5*time.Minute)
+			token, err := generator.GenerateToken(testManager)
+			if err != nil {
+				t.Fatalf("GenerateToken() returned an unexpected error: %v", err)
+			}
+			parsed, _, _ := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			if parsed.Header["kid"] != key.Id || parsed.Method.Alg() != key.Algorithm {
+				t.Errorf("token header = %v, want kid %q and alg %s", parsed.Header, key.Id, key.Algorithm)
+			}
+			principal, err := generator.ValidateToken(token)
+			if err != nil || principal.ManagerId != testManager.Id || principal.Role != testManager.Role {
+				t.Errorf("ValidateToken() = %+v, %v", principal, err)
+			}
+		})
+	}
+}
+
+func TestJWTGenerator_Rotation(t *testing.T) {
+	rsaKey, edKey := generateTestKeys(t)
+	oldKey := NewRSAKey("2024-01", rsaKey)
+	newKey := NewEd25519Key("2024-05", edKey)
+
+	before := NewJWTGenerator(mustKeyRing(t, oldKey), time.Hour, 5*time.Minute)
+	oldToken, _ := before.GenerateToken(testManager)
+
+	after := NewJWTGenerator(mustKeyRing(t, newKey, NewRSAPublicKey(oldKey.Id, &rsaKey.PublicKey)), time.Hour, 5*time.Minute)
+	if _, err := after.ValidateToken(oldToken); err != nil {
+		t.Errorf("token signed by the retired key should still verify, got %v", err)
+	}
+	newToken, _ := after.GenerateToken(testManager)
+	if _, err := after.ValidateToken(newToken); err != nil {
+		t.Errorf("token signed by the active key should verify, got %v", err)
+	}
+
+	dropped := NewJWTGenerator(mustKeyRing(t, newKey), time.Hour, 5*time.Minute)
+	if _, err := dropped.ValidateToken(oldToken); !errors.Is(err, domain.ErrTokenInvalid) {
+		t.Errorf("token signed by a removed key error = %v, want ErrTokenInvalid", err)
+	}
+}
+
+func TestJWTGenerator_RejectsTokens(t *testing.T) {
+	rsaKey, _ := generateTestKeys(t)
+	hmacSecret := []byte("0123456789abcdef0123456789abcdef")
+	generator := NewJWTGenerator(mustKeyRing(t, NewRSAKey("rsa", rsaKey), NewHMACKey("hmac", hmacSecret)), time.Hour, 5*time.Minute)
+	publicPEM, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "manager-1", "role": "manager", "jti": "jti-1",
+			"iss": TokenIssuer, "aud": TokenAudience, "exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, mutate func(jwt.MapClaims)) string {
+		claims := validClaims()
+		if mutate != nil {
+			mutate(claims)
+		}
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign test token: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid_hmac_key", sign(jwt.SigningMethodHS256, "hmac", hmacSecret, nil), false},
+		{"missing_kid", sign(jwt.SigningMethodHS256, "", hmacSecret, nil), true},
+		{"unknown_kid", sign(jwt.SigningMethodHS256, "other", hmacSecret, nil), true},
+		{"hmac_signed_with_rsa_public_key", sign(jwt.SigningMethodHS256, "rsa", publicPEM, nil), true},
+		{"wrong_issuer", sign(jwt.SigningMethodHS256, "hmac", hmacSecret, func(c jwt.MapClaims) { c["iss"] = "someone-else" }), true},
+		{"wrong_audience", sign(jwt.SigningMethodHS256, "hmac", hmacSecret, func(c jwt.MapClaims) { c["aud"] = "customers" }), true},
+		{"missing_expiry", sign(jwt.SigningMethodHS256, "hmac", hmacSecret, func(c jwt.MapClaims) { delete(c, "exp") }), true},
+		{"missing_jti", sign(jwt.SigningMethodHS256, "hmac", hmacSecret, func(c jwt.MapClaims) { delete(c, "jti") }), true},
+		{"expired", sign(jwt.SigningMethodHS256, "hmac", hmacSecret, func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generator.ValidateToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewKeyRing_Errors(t *testing.T) {
+	_, edKey := generateTestKeys(t)
+	hmac := NewHMACKey("k1", []byte("secret"))
+
+	tests := []struct {
+		name    string
+		active  *SigningKey
+		retired []*SigningKey
+	}{
+		{"no_active_key", nil, nil},
+		{"public_only_active_key", NewEd25519PublicKey("ed", edKey.Public().(ed25519.PublicKey)), nil},
+		{"duplicate_kid", hmac, []*SigningKey{NewEd25519Key("k1", edKey)}},
+		{"missing_kid", NewHMACKey("", []byte("secret")), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyRing(tt.active, tt.retired...); err == nil {
+				t.Errorf("NewKeyRing() expected an error")
+			}
+		})
+	}
+}
+
+func TestParseKeysFromPEM(t *testing.T) {
+	rsaKey, edKey := generateTestKeys(t)
+	encode := func(blockType string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	}
+	rsaPrivate, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	rsaPublic, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	edPrivate, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	edPublic, _ := x509.MarshalPKIXPublicKey(edKey.Public())
+
+	signer, err := ParseSigningKey("ed", AlgorithmEdDSA, encode("PRIVATE KEY", edPrivate))
+	if err != nil {
+		t.Fatalf("ParseSigningKey(EdDSA) returned an unexpected error: %v", err)
+	}
+	if _, err := ParseSigningKey("rsa", AlgorithmRS256, encode("PRIVATE KEY", rsaPrivate)); err != nil {
+		t.Fatalf("ParseSigningKey(RS256) returned an unexpected error: %v", err)
+	}
+	verifier, err := ParseVerificationKey("ed", AlgorithmEdDSA, encode("PUBLIC KEY", edPublic))
+	if err != nil {
+		t.Fatalf("ParseVerificationKey(EdDSA) returned an unexpected error: %v", err)
+	}
+	if _, err := ParseVerificationKey("rsa", AlgorithmRS256, encode("PUBLIC KEY", rsaPublic)); err != nil {
+		t.Fatalf("ParseVerificationKey(RS256) returned an unexpected error: %v", err)
+	}
+	if _, err := ParseSigningKey("rsa", AlgorithmRS256, encode("PRIVATE KEY", edPrivate)); err == nil {
+		t.Errorf("ParseSigningKey() accepted an Ed25519 key as RS256")
+	}
+	if _, err := ParseVerificationKey("hmac", AlgorithmHS256, []byte("secret")); err == nil {
+		t.Errorf("ParseVerificationKey() accepted an HS256 secret")
+	}
+
+	token, _ := NewJWTGenerator(mustKeyRing(t, signer), time.Hour, 5*time.Minute).GenerateToken(testManager)
+	verifyOnly := mustKeyRing(t, NewHMACKey("unused", []byte("secret")), verifier)
+	if _, err := NewJWTGenerator(verifyOnly, time.Hour, 5*time.Minute).ValidateToken(token); err != nil {
+		t.Errorf("token did not verify with the PEM public key: %v", err)
+	}
+}
+
+func TestJWTGenerator_MFAChallenge(t *testing.T) {
+	generator := NewJWTGenerator(mustKeyRing(t, NewHMACKey("hmac", []byte("0123456789abcdef0123456789abcdef"))), time.Hour, 5*time.Minute)
+
+	token, issued, err := generator.IssueMFAChallenge(testManager)
+	if err != nil {
+		t.Fatalf("IssueMFAChallenge() returned an unexpected error: %v", err)
+	}
+	parsed, err := generator.ParseMFAChallenge(token)
+	if err != nil || parsed.Id != issued.Id || parsed.ManagerId != testManager.Id || time.Until(parsed.ExpiresAt) > 5*time.Minute {
+		t.Fatalf("ParseMFAChallenge() = %+v, %v, want %+v", parsed, err, issued)
+	}
+	if _, err := generator.ValidateToken(token); !errors.Is(err, domain.ErrTokenInvalid) {
+		t.Errorf("a challenge must not be accepted as an access token, got %v", err)
+	}
+
+	accessToken, _ := generator.GenerateToken(testManager)
+	if _, err := generator.ParseMFAChallenge(accessToken); !errors.Is(err, domain.ErrMFAChallengeInvalid) {
+		t.Errorf("an access token must not be accepted as a challenge, got %v", err)
+	}
+}
+
+func TestKeyRing_JWKS(t *testing.T) {
+	rsaKey, edKey := generateTestKeys(t)
+	ring := mustKeyRing(t, NewEd25519Key("ed", edKey), NewRSAPublicKey("rsa", &rsaKey.PublicKey), NewHMACKey("hmac", []byte("secret")))
+
+	set := ring.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS() = %+v, want the two asymmetric keys only", set)
+	}
+	ed, rsaJWK := set.Keys[0], set.Keys[1]
+	if ed.KeyId != "ed" || ed.KeyType != "OKP" || ed.Curve != "Ed25519" || ed.Algorithm != AlgorithmEdDSA || ed.X == "" {
+		t.Errorf("Ed25519 JWK = %+v", ed)
+	}
+	if rsaJWK.KeyId != "rsa" || rsaJWK.KeyType != "RSA" || rsaJWK.Exponent != "AQAB" || rsaJWK.Modulus == "" {
+		t.Errorf("RSA JWK = %+v", rsaJWK)
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.LoginAttemptTracker = (*memoryLoginAttemptTracker)(nil)
+
+type memoryLoginAttemptTracker struct {
+	mu       sync.Mutex
+	attempts []domain.LoginAttempt
+}
+
+func NewMemoryLoginAttemptTracker() *memoryLoginAttemptTracker {
+	return &memoryLoginAttemptTracker{}
+}
+
+func (tracker *memoryLoginAttemptTracker) RecordLoginAttempt(attempt *domain.LoginAttempt) error {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.attempts = append(tracker.attempts, *attempt)
+	return nil
+}
+
+func (tracker *memoryLoginAttemptTracker) EmailFailures(email string, since time.Time) (domain.FailureStats, error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	for _, attempt := range tracker.attempts {
+		if attempt.Email == email && attempt.Outcome == domain.LoginSucceeded && attempt.OccurredAt.After(since) {
+			since = attempt.OccurredAt
+		}
+	}
+	return tracker.failures(func(attempt domain.LoginAttempt) bool { return attempt.Email == email }, since), nil
+}
+
+func (tracker *memoryLoginAttemptTracker) IPFailures(ip string, since time.Time) (domain.FailureStats, error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	return tracker.failures(func(attempt domain.LoginAttempt) bool { return attempt.IP == ip }, since), nil
+}
+
+func (tracker *memoryLoginAttemptTracker) failures(match func(domain.LoginAttempt) bool, since time.Time) domain.FailureStats {
+	var stats domain.FailureStats
+	for _, attempt := range tracker.attempts {
+		if !match(attempt) || attempt.Outcome != domain.LoginFailed || !attempt.OccurredAt.After(since) {
+			continue
+		}
+		if stats.Count == 0 || attempt.OccurredAt.Before(stats.First) {
+			stats.First = attempt.OccurredAt
+		}
+		if attempt.OccurredAt.After(stats.Last) {
+			stats.Last = attempt.OccurredAt
+		}
+		stats.Count++
+	}
+	return stats
+}
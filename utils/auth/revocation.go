@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+	"github.com/amangirdhar210/inventory-manager/internal/core/ports"
+)
+
+var _ ports.TokenValidator = (*revokingValidator)(nil)
+var _ ports.RevocationStore = (*memoryRevocationStore)(nil)
+
+type revokingValidator struct {
+	validator   ports.TokenValidator
+	revocations ports.RevocationStore
+}
+
+// NewRevokingValidator rejects otherwise valid tokens whose id has been revoked.
+func NewRevokingValidator(validator ports.TokenValidator, revocations ports.RevocationStore) ports.TokenValidator {
+	return &revokingValidator{validator: validator, revocations: revocations}
+}
+
+func (v *revokingValidator) ValidateToken(tokenString string) (*domain.Principal, error) {
+	principal, err := v.validator.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := v.revocations.IsTokenRevoked(principal.TokenId)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		revoked, err = v.revocations.IsManagerTokenRevoked(principal.ManagerId, principal.IssuedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if revoked {
+		return nil, domain.ErrTokenRevoked
+	}
+	return principal, nil
+}
+
+type managerRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+type memoryRevocationStore struct {
+	mu       sync.Mutex
+	revoked  map[string]time.Time
+	managers map[string]managerRevocation
+}
+
+func NewMemoryRevocationStore() *memoryRevocationStore {
+	return &memoryRevocationStore{
+		revoked:  make(map[string]time.Time),
+		managers: make(map[string]managerRevocation),
+	}
+}
+
+func (store *memoryRevocationStore) RevokeToken(tokenId string, expiresAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.revoked[tokenId] = expiresAt
+	return nil
+}
+
+func (store *memoryRevocationStore) ConsumeToken(tokenId string, expiresAt time.Time) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if _, used := store.revoked[tokenId]; used {
+		return false, nil
+	}
+	store.revoked[tokenId] = expiresAt
+	return true, nil
+}
+
+func (store *memoryRevocationStore) IsTokenRevoked(tokenId string) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	_, revoked := store.revoked[tokenId]
+	return revoked, nil
+}
+
+func (store *memoryRevocationStore) RevokeManagerTokens(managerId string, revokedAt, expiresAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	current := store.managers[managerId]
+	if revokedAt.After(current.revokedAt) {
+		current.revokedAt = revokedAt
+	}
+	if expiresAt.After(current.expiresAt) {
+		current.expiresAt = expiresAt
+	}
+	store.managers[managerId] = current
+	return nil
+}
+
+func (store *memoryRevocationStore) IsManagerTokenRevoked(managerId string, issuedAt time.Time) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	revocation, ok := store.managers[managerId]
+	return ok && issuedAt.Before(revocation.revokedAt), nil
+}
+
+func (store *memoryRevocationStore) PruneRevokedTokens(now time.Time) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	var pruned int64
+	for tokenId, expiresAt := range store.revoked {
+		if !expiresAt.After(now) {
+			delete(store.revoked, tokenId)
+			pruned++
+		}
+	}
+	for managerId, revocation := range store.managers {
+		if !revocation.expiresAt.After(now) {
+			delete(store.managers, managerId)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// RunTokenPruner deletes revoked and refresh tokens that have expired anyway,
+// every interval until ctx is cancelled.
+func RunTokenPruner(ctx context.Context, revocations ports.RevocationStore, refreshTokens ports.RefreshTokenRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			pruneTokens("revoked token", now, revocations.PruneRevokedTokens)
+			pruneTokens("refresh token", now, refreshTokens.PruneRefreshTokens)
+		}
+	}
+}
+
+func pruneTokens(kind string, now time.Time, prune func(time.Time) (int64, error)) {
+	pruned, err := prune(now)
+	if err != nil {
+		log.Printf("token pruner: could not prune %ss: %v", kind, err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("token pruner: removed %d expired %s(s)", pruned, kind)
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amangirdhar210/inventory-manager/internal/core/domain"
+)
+
+func TestRevokingValidator(t *testing.T) {
+	generator := NewJWTGenerator(mustKeyRing(t, NewHMACKey("test", []byte("revocation-test-secret"))), time.Hour, 5*time.Minute)
+	revocations := NewMemoryRevocationStore()
+	validator := NewRevokingValidator(generator, revocations)
+
+	token, err := generator.GenerateToken(&domain.Manager{Id: "manager-1", Role: domain.RoleClerk})
+	if err != nil {
+		t.Fatalf("GenerateToken() returned an unexpected error: %v", err)
+	}
+	principal, err := validator.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() returned an unexpected error: %v", err)
+	}
+	if principal.TokenId == "" || principal.ExpiresAt.Before(time.Now()) {
+		t.Errorf("principal = %+v, want a token id and a future expiry", principal)
+	}
+
+	if err := revocations.RevokeToken(principal.TokenId, principal.ExpiresAt); err != nil {
+		t.Fatalf("RevokeToken() returned an unexpected error: %v", err)
+	}
+	if _, err := validator.ValidateToken(token); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("ValidateToken() of a revoked token error = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := generator.ValidateToken(token); err != nil {
+		t.Errorf("the plain generator should not know about revocations, got %v", err)
+	}
+}
+
+func TestRevokingValidator_ManagerTokens(t *testing.T) {
+	generator := NewJWTGenerator(mustKeyRing(t, NewHMACKey("test", []byte("revocation-test-secret"))), time.Hour, 5*time.Minute)
+	revocations := NewMemoryRevocationStore()
+	validator := NewRevokingValidator(generator, revocations)
+
+	token, _ := generator.GenerateToken(&domain.Manager{Id: "manager-1", Role: domain.RoleClerk})
+	other, _ := generator.GenerateToken(&domain.Manager{Id: "manager-2", Role: domain.RoleClerk})
+	now := time.Now().Add(time.Second)
+	if err := revocations.RevokeManagerTokens("manager-1", now, now.Add(time.Hour)); err != nil {
+		t.Fatalf("RevokeManagerTokens() returned an unexpected error: %v", err)
+	}
+	if _, err := validator.ValidateToken(token); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("ValidateToken() of a token issued before the revocation error = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := validator.ValidateToken(other); err != nil {
+		t.Errorf("ValidateToken() of another manager's token returned %v", err)
+	}
+	if revoked, _ := revocations.IsManagerTokenRevoked("manager-1", now); revoked {
+		t.Errorf("a token issued after the revocation should stay valid")
+	}
+}
+
+func TestMemoryRevocationStore_Prune(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	revocations := NewMemoryRevocationStore()
+	revocations.RevokeToken("expired", now.Add(-time.Second))
+	revocations.RevokeToken("live", now.Add(time.Hour))
+	revocations.RevokeManagerTokens("expired-manager", now.Add(-time.Hour), now.Add(-time.Second))
+
+	pruned, err := revocations.PruneRevokedTokens(now)
+	if err != nil || pruned != 2 {
+		t.Fatalf("PruneRevokedTokens() = %d, %v, want 2", pruned, err)
+	}
+	if revoked, _ := revocations.IsTokenRevoked("live"); !revoked {
+		t.Errorf("live revocation was pruned")
+	}
+}